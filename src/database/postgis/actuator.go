@@ -0,0 +1,221 @@
+package postgis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+func actuatorParamFactory(values map[string]interface{}) (entities.Entity, error) {
+	a := &entities.Actuator{}
+	for as, value := range values {
+		if value == nil {
+			continue
+		}
+
+		if as == asMappings[entities.EntityTypeActuator][actuatorID] {
+			a.ID = value
+		} else if as == asMappings[entities.EntityTypeActuator][actuatorName] {
+			a.Name = value.(string)
+		} else if as == asMappings[entities.EntityTypeActuator][actuatorDescription] {
+			a.Description = value.(string)
+		} else if as == asMappings[entities.EntityTypeActuator][actuatorEncodingType] {
+			encodingType := value.(int64)
+			if encodingType != 0 {
+				a.EncodingType = entities.EncodingValues[encodingType].Value
+			}
+		} else if as == asMappings[entities.EntityTypeActuator][actuatorMetadata] {
+			a.Metadata = value.(string)
+		}
+	}
+
+	return a, nil
+}
+
+// GetActuator returns an actuator by id
+func (gdb *GostDatabase) GetActuator(id interface{}, qo *odata.QueryOptions) (*entities.Actuator, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("Actuator does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Actuator{}, qo, "", "", nil)+" from %s.actuator where id = %v", gdb.Schema, intID)
+	return processActuator(gdb.Db, sql, qo)
+}
+
+// GetActuatorByTaskingCapability retrieves the actuator used by the given TaskingCapability
+func (gdb *GostDatabase) GetActuatorByTaskingCapability(id interface{}, qo *odata.QueryOptions) (*entities.Actuator, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("TaskingCapability does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Actuator{}, qo, "actuator.", "", nil)+" from %s.actuator inner join %s.taskingcapability on taskingcapability.actuator_id = actuator.id where taskingcapability.id = %v", gdb.Schema, gdb.Schema, intID)
+	return processActuator(gdb.Db, sql, qo)
+}
+
+// GetActuators retrieves all actuators based on the QueryOptions
+func (gdb *GostDatabase) GetActuators(qo *odata.QueryOptions) ([]*entities.Actuator, int, error) {
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeActuator, selectMappingsResolver(entities.EntityTypeActuator))
+	queryString, err := CreateFilterQueryString(qo, resolver, "WHERE ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Actuator{}, qo, "", "", nil)+" FROM %s.actuator %sorder by %s %s", gdb.Schema, queryString, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.actuator %s", gdb.Schema, queryString)
+	return processActuators(gdb.Db, sql, qo, countSQL)
+}
+
+func processActuator(db Executor, sql string, qo *odata.QueryOptions) (*entities.Actuator, error) {
+	actuators, _, err := processActuators(db, sql, qo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(actuators) == 0 {
+		return nil, gostErrors.NewRequestNotFound(errors.New("Actuator not found"))
+	}
+
+	return actuators[0], nil
+}
+
+func processActuators(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Actuator, int, error) {
+	rows, err := db.Query(sql)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+	defer rows.Close()
+
+	var actuators = []*entities.Actuator{}
+	for rows.Next() {
+		var id interface{}
+		var encodingType int
+		var name, description, metadata string
+
+		var params []interface{}
+		for _, p := range selectedProperties(&entities.Actuator{}, qo) {
+			switch strings.ToLower(p) {
+			case "id":
+				params = append(params, &id)
+			case "name":
+				params = append(params, &name)
+			case "description":
+				params = append(params, &description)
+			case "encodingtype":
+				params = append(params, &encodingType)
+			case "metadata":
+				params = append(params, &metadata)
+			}
+		}
+
+		if err = rows.Scan(params...); err != nil {
+			return nil, 0, err
+		}
+
+		actuator := entities.Actuator{}
+		actuator.ID = id
+		actuator.Name = name
+		actuator.Description = description
+		actuator.Metadata = metadata
+		if encodingType != 0 {
+			actuator.EncodingType = entities.EncodingValues[encodingType].Value
+		}
+
+		actuators = append(actuators, &actuator)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	return actuators, count, nil
+}
+
+// PostActuator posts an actuator to the database
+func (gdb *GostDatabase) PostActuator(actuator *entities.Actuator) (*entities.Actuator, error) {
+	var actuatorID int
+	encoding, err := entities.CreateEncodingType(actuator.EncodingType)
+	if err != nil {
+		return nil, err
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s.actuator (name, description, encodingtype, metadata) VALUES ($1, $2, $3, $4) RETURNING id", gdb.Schema)
+	if err = gdb.Db.QueryRow(sql, actuator.Name, actuator.Description, encoding.Code, actuator.Metadata).Scan(&actuatorID); err != nil {
+		return nil, err
+	}
+
+	actuator.ID = actuatorID
+	return actuator, nil
+}
+
+// ActuatorExists checks if an actuator is present in the database based on a given id
+func (gdb *GostDatabase) ActuatorExists(id int) bool {
+	return EntityExists(gdb, id, "actuator")
+}
+
+// PatchActuator updates an actuator in the database
+func (gdb *GostDatabase) PatchActuator(id interface{}, a *entities.Actuator) (*entities.Actuator, error) {
+	var intID int
+	var ok bool
+	updates := make(map[string]interface{})
+
+	if intID, ok = ToIntID(id); !ok || !gdb.ActuatorExists(intID) {
+		return nil, gostErrors.NewRequestNotFound(errors.New("Actuator does not exist"))
+	}
+
+	if len(a.Name) > 0 {
+		updates["name"] = a.Name
+	}
+
+	if len(a.Description) > 0 {
+		updates["description"] = a.Description
+	}
+
+	if len(a.Metadata) > 0 {
+		updates["metadata"] = a.Metadata
+	}
+
+	if len(a.EncodingType) > 0 {
+		encoding, err := entities.CreateEncodingType(a.EncodingType)
+		if err != nil {
+			return nil, err
+		}
+		updates["encodingtype"] = encoding.Code
+	}
+
+	if err := gdb.updateEntityColumns("actuator", updates, intID); err != nil {
+		return nil, err
+	}
+
+	return gdb.GetActuator(intID, nil)
+}
+
+// PutActuator replaces an Actuator in the database, all properties of an Actuator are mandatory
+func (gdb *GostDatabase) PutActuator(id interface{}, actuator *entities.Actuator) (*entities.Actuator, error) {
+	intID, err := gdb.putEntity(entities.EntityTypeActuator, id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return gdb.PatchActuator(intID, actuator)
+}
+
+// DeleteActuator tries to delete an Actuator by the given id
+func (gdb *GostDatabase) DeleteActuator(id interface{}) error {
+	return DeleteEntity(gdb, id, "actuator")
+}
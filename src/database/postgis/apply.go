@@ -0,0 +1,266 @@
+package postgis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// observationApplyColumns maps navigation properties which can be used in $apply onto the foreign keys of the observation table
+var observationApplyColumns = map[string]string{
+	"datastream/id":        "observation.stream_id",
+	"featureofinterest/id": "observation.featureofinterest_id",
+}
+
+// observationTimeProperties are compared as ISO 8601 text by min and max and can be used in a bucket
+var observationTimeProperties = []string{"phenomenontime", "resulttime", "validtime"}
+
+// AggregateObservations returns the result of $apply over all observations
+func (gdb *GostDatabase) AggregateObservations(qo *odata.QueryOptions) ([]json.RawMessage, int, error) {
+	return gdb.aggregateObservations("", qo)
+}
+
+// AggregateObservationsByDatastream returns the result of $apply over the observations of a Datastream
+func (gdb *GostDatabase) AggregateObservationsByDatastream(id interface{}, qo *odata.QueryOptions) ([]json.RawMessage, int, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Datastream does not exist"))
+	}
+
+	return gdb.aggregateObservations(fmt.Sprintf("observation.stream_id = %v", intID), qo)
+}
+
+// AggregateObservationsByFeatureOfInterest returns the result of $apply over the observations of a FeatureOfInterest
+func (gdb *GostDatabase) AggregateObservationsByFeatureOfInterest(id interface{}, qo *odata.QueryOptions) ([]json.RawMessage, int, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("FeatureOfInterest does not exist"))
+	}
+
+	return gdb.aggregateObservations(fmt.Sprintf("observation.featureofinterest_id = %v", intID), qo)
+}
+
+// aggregateObservations runs the aggregation over the observations matching the condition, every group is returned
+// as JSON object with the aliases of the group properties and aggregates as keys, the count is the number of groups
+func (gdb *GostDatabase) aggregateObservations(condition string, qo *odata.QueryOptions) ([]json.RawMessage, int, error) {
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere)
+	sql, countSQL, err := CreateApplyQueryString(qo, resolver, fmt.Sprintf("%s.observation", gdb.Schema), condition)
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	rows, err := gdb.Db.Query(sql)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+	defer rows.Close()
+
+	results := []json.RawMessage{}
+	for rows.Next() {
+		var result string
+		if err = rows.Scan(&result); err != nil {
+			return nil, 0, err
+		}
+
+		results = append(results, json.RawMessage(result))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(gdb.Db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	return results, count, nil
+}
+
+// CreateApplyQueryString creates the query of the $apply aggregation over the observations in from which match the
+// condition, the filters of $apply are translated by the resolver and added to the condition. The groups are
+// ordered by $orderby or else by the group properties and selected as JSON object. countSQL returns the number of groups
+func CreateApplyQueryString(qo *odata.QueryOptions, resolver FilterPropertyResolver, from string, condition string) (sql string, countSQL string, err error) {
+	qa := qo.QueryApply
+	conditions := []string{}
+	if len(condition) > 0 {
+		conditions = append(conditions, condition)
+	}
+
+	for _, f := range qa.Filters() {
+		c, err := BuildFilter(f, resolver)
+		if err != nil {
+			return "", "", err
+		}
+
+		conditions = append(conditions, c)
+	}
+
+	aggregation := qa.Aggregation()
+	selects := []string{}
+	groupBy := []string{}
+	for i, g := range aggregation.GroupBy {
+		column, err := applyGroupColumn(g)
+		if err != nil {
+			return "", "", err
+		}
+
+		selects = append(selects, fmt.Sprintf("%s AS %s", column, quoteAlias(g.Alias)))
+		groupBy = append(groupBy, fmt.Sprintf("%v", i+1))
+	}
+
+	for _, a := range aggregation.Aggregates {
+		column, err := applyAggregateColumn(a)
+		if err != nil {
+			return "", "", err
+		}
+
+		selects = append(selects, fmt.Sprintf("%s AS %s", column, quoteAlias(a.Alias)))
+	}
+
+	inner := fmt.Sprintf("SELECT %s FROM %s", strings.Join(selects, ", "), from)
+	if len(conditions) > 0 {
+		inner = fmt.Sprintf("%s WHERE %s", inner, strings.Join(conditions, " AND "))
+	}
+
+	if len(groupBy) > 0 {
+		inner = fmt.Sprintf("%s GROUP BY %s", inner, strings.Join(groupBy, ", "))
+	}
+
+	orderBy := []string{}
+	if !qo.QueryOrderBy.IsNil() {
+		for _, p := range qo.QueryOrderBy.Params {
+			orderBy = append(orderBy, fmt.Sprintf("a.%s %s", quoteAlias(applyAlias(qa, p.Property)), strings.ToUpper(p.Suffix)))
+		}
+	} else {
+		for _, g := range aggregation.GroupBy {
+			orderBy = append(orderBy, fmt.Sprintf("a.%s ASC", quoteAlias(g.Alias)))
+		}
+	}
+
+	sql = fmt.Sprintf("SELECT row_to_json(a)::text FROM (%s) a", inner)
+	if len(orderBy) > 0 {
+		sql = fmt.Sprintf("%s ORDER BY %s", sql, strings.Join(orderBy, ", "))
+	}
+
+	sql += CreateTopSkipQueryString(qo)
+	countSQL = fmt.Sprintf("SELECT COUNT(*) FROM (%s) a", inner)
+	return sql, countSQL, nil
+}
+
+// applyColumn returns the column of a property used inside $apply, navigation properties can only be used
+// when they reference the id of the Datastream or FeatureOfInterest
+func applyColumn(property string) (*FilterColumn, error) {
+	if column, ok := observationApplyColumns[strings.ToLower(property)]; ok {
+		return &FilterColumn{SQL: column}, nil
+	}
+
+	column, err := observationParamFactoryWhere(strings.Split(property, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("Unable to aggregate %s: %v", property, err)
+	}
+
+	return column, nil
+}
+
+// applyGroupColumn returns the SQL of a group property, a bucket is returned as the start time of the bucket
+func applyGroupColumn(g *odata.ApplyGroupProperty) (string, error) {
+	column, err := applyColumn(g.Property)
+	if err != nil {
+		return "", err
+	}
+
+	if g.Interval == nil {
+		return column.SQL, nil
+	}
+
+	if !isTimeProperty(g.Property) {
+		return "", fmt.Errorf("Unable to create buckets of %s, only phenomenonTime, resultTime and validTime can be used", g.Property)
+	}
+
+	// the start of a time interval such as 2016-01-01T00:00:00Z/2016-01-01T01:00:00Z is used
+	t := fmt.Sprintf("split_part(%s #>> '{}', '/', 1)::timestamptz", column.SQL)
+	var bucket string
+	switch {
+	case g.Interval.Months == 12:
+		bucket = fmt.Sprintf("date_trunc('year', %s AT TIME ZONE 'UTC')", t)
+	case g.Interval.Months == 1:
+		bucket = fmt.Sprintf("date_trunc('month', %s AT TIME ZONE 'UTC')", t)
+	default:
+		bucket = fmt.Sprintf("to_timestamp(floor(extract(epoch from %s) / %v) * %v) AT TIME ZONE 'UTC'", t, g.Interval.Seconds, g.Interval.Seconds)
+	}
+
+	return fmt.Sprintf("to_char(%s, '%s')", bucket, TimeFormat), nil
+}
+
+// applyAggregateColumn returns the SQL of an aggregate, sum and average only use numeric values and
+// min and max compare time properties as text and other properties by their numeric values
+func applyAggregateColumn(a *odata.ApplyAggregate) (string, error) {
+	if a.Method == odata.ApplyMethodCount {
+		return "count(*)", nil
+	}
+
+	column, err := applyColumn(a.Property)
+	if err != nil {
+		return "", err
+	}
+
+	value := column.SQL
+	if column.JSON {
+		value = fmt.Sprintf("CASE WHEN jsonb_typeof(%s) = 'number' THEN (%s #>> '{}')::double precision END", column.SQL, column.SQL)
+		if isTimeProperty(a.Property) {
+			if a.Method == odata.ApplyMethodSum || a.Method == odata.ApplyMethodAverage {
+				return "", fmt.Errorf("Unable to use %s on %s", a.Method, a.Property)
+			}
+
+			value = fmt.Sprintf("%s #>> '{}'", column.SQL)
+		}
+	}
+
+	switch a.Method {
+	case odata.ApplyMethodSum:
+		return fmt.Sprintf("sum(%s)", value), nil
+	case odata.ApplyMethodMin:
+		return fmt.Sprintf("min(%s)", value), nil
+	case odata.ApplyMethodMax:
+		return fmt.Sprintf("max(%s)", value), nil
+	case odata.ApplyMethodAverage:
+		return fmt.Sprintf("avg(%s)", value), nil
+	case odata.ApplyMethodCountDistinct:
+		return fmt.Sprintf("count(DISTINCT %s)", column.SQL), nil
+	}
+
+	return "", fmt.Errorf("Unknown aggregation method %s", a.Method)
+}
+
+// applyAlias returns the alias as used in $apply for a property of $orderby, which may differ in case
+func applyAlias(qa *odata.QueryApply, property string) string {
+	for _, a := range qa.Aliases() {
+		if strings.ToLower(a) == strings.ToLower(property) {
+			return a
+		}
+	}
+
+	return property
+}
+
+// isTimeProperty returns true when the property of an observation holds a time
+func isTimeProperty(property string) bool {
+	for _, p := range observationTimeProperties {
+		if strings.ToLower(property) == p {
+			return true
+		}
+	}
+
+	return false
+}
+
+// quoteAlias quotes an alias so it can be used as column name
+func quoteAlias(alias string) string {
+	return fmt.Sprintf("\"%s\"", strings.Replace(alias, "\"", "\"\"", -1))
+}
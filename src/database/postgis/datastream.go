@@ -5,8 +5,6 @@ import (
 	"errors"
 	"fmt"
 
-	"database/sql"
-
 	gostErrors "github.com/geodan/gost/src/errors"
 	"github.com/geodan/gost/src/sensorthings/entities"
 	"github.com/geodan/gost/src/sensorthings/odata"
@@ -113,8 +111,19 @@ func (gdb *GostDatabase) GetDatastream(id interface{}, qo *odata.QueryOptions) (
 
 // GetDatastreams retrieves all datastreams
 func (gdb *GostDatabase) GetDatastreams(qo *odata.QueryOptions) ([]*entities.Datastream, int, error) {
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Datastream{}, qo, "", "", dsMapping)+" FROM %s.datastream order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.datastream", gdb.Schema)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeDatastream, selectMappingsResolver(entities.EntityTypeDatastream))
+	queryString, err := CreateFilterQueryString(qo, resolver, "WHERE ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Datastream{}, qo, "", "", dsMapping)+" FROM %s.datastream %sorder by %s %s", gdb.Schema, queryString, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.datastream %s", gdb.Schema, queryString)
 	return processDatastreams(gdb.Db, sql, qo, countSQL)
 }
 
@@ -138,8 +147,19 @@ func (gdb *GostDatabase) GetDatastreamsByThing(thingID interface{}, qo *odata.Qu
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Datastream does not exist"))
 	}
 
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Datastream{}, qo, "datastream.", "", dsMapping)+" FROM %s.datastream inner join %s.thing on thing.id = datastream.thing_id where thing.id = %v order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema, gdb.Schema, intID)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.datastream inner join %s.thing on thing.id = datastream.thing_id where thing.id = %v", gdb.Schema, gdb.Schema, intID)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeDatastream, selectMappingsResolver(entities.EntityTypeDatastream))
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "datastream.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Datastream{}, qo, "datastream.", "", dsMapping)+" FROM %s.datastream where datastream.thing_id = %v %sorder by %s"+CreateTopSkipQueryString(qo), gdb.Schema, intID, queryString, orderBy)
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.datastream where datastream.thing_id = %v %s", gdb.Schema, intID, queryString)
 	return processDatastreams(gdb.Db, sql, qo, countSQL)
 }
 
@@ -150,8 +170,19 @@ func (gdb *GostDatabase) GetDatastreamsBySensor(sensorID interface{}, qo *odata.
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Datastream does not exist"))
 	}
 
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Datastream{}, qo, "datastream.", "", dsMapping)+" FROM %s.datastream inner join %s.sensor on sensor.id = datastream.sensor_id where sensor.id = %v order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema, gdb.Schema, intID)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.datastream inner join %s.sensor on sensor.id = datastream.sensor_id where sensor.id = %v", gdb.Schema, gdb.Schema, intID)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeDatastream, selectMappingsResolver(entities.EntityTypeDatastream))
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "datastream.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Datastream{}, qo, "datastream.", "", dsMapping)+" FROM %s.datastream where datastream.sensor_id = %v %sorder by %s"+CreateTopSkipQueryString(qo), gdb.Schema, intID, queryString, orderBy)
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.datastream where datastream.sensor_id = %v %s", gdb.Schema, intID, queryString)
 	return processDatastreams(gdb.Db, sql, qo, countSQL)
 }
 
@@ -162,12 +193,23 @@ func (gdb *GostDatabase) GetDatastreamsByObservedProperty(oID interface{}, qo *o
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Datastream does not exist"))
 	}
 
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Datastream{}, qo, "datastream.", "", dsMapping)+" FROM %s.datastream inner join %s.observedproperty on observedproperty.id = datastream.observedproperty_id where observedproperty.id = %v order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema, gdb.Schema, intID)
-	CountSQL := fmt.Sprintf("select COUNT(*) FROM %s.datastream inner join %s.observedproperty on observedproperty.id = datastream.observedproperty_id where observedproperty.id = %v", gdb.Schema, gdb.Schema, intID)
-	return processDatastreams(gdb.Db, sql, qo, CountSQL)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeDatastream, selectMappingsResolver(entities.EntityTypeDatastream))
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "datastream.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Datastream{}, qo, "datastream.", "", dsMapping)+" FROM %s.datastream where datastream.observedproperty_id = %v %sorder by %s"+CreateTopSkipQueryString(qo), gdb.Schema, intID, queryString, orderBy)
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.datastream where datastream.observedproperty_id = %v %s", gdb.Schema, intID, queryString)
+	return processDatastreams(gdb.Db, sql, qo, countSQL)
 }
 
-func processDatastream(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.Datastream, error) {
+func processDatastream(db Executor, sql string, qo *odata.QueryOptions) (*entities.Datastream, error) {
 	datastreams, _, err := processDatastreams(db, sql, qo, "")
 	if err != nil {
 		return nil, err
@@ -180,13 +222,12 @@ func processDatastream(db *sql.DB, sql string, qo *odata.QueryOptions) (*entitie
 	return datastreams[0], nil
 }
 
-func processDatastreams(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Datastream, int, error) {
+func processDatastreams(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Datastream, int, error) {
 	rows, err := db.Query(sql)
-	defer rows.Close()
-
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, filterQueryError(err)
 	}
+	defer rows.Close()
 
 	var datastreams = []*entities.Datastream{}
 	for rows.Next() {
@@ -198,13 +239,7 @@ func processDatastreams(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL
 		var ot int64
 
 		var params []interface{}
-		var qp []string
-		if qo == nil || qo.QuerySelect == nil || len(qo.QuerySelect.Params) == 0 {
-			d := &entities.Datastream{}
-			qp = d.GetPropertyNames()
-		} else {
-			qp = qo.QuerySelect.Params
-		}
+		qp := selectedProperties(&entities.Datastream{}, qo)
 
 		for _, p := range qp {
 			p = strings.ToLower(p)
@@ -262,9 +297,13 @@ func processDatastreams(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL
 		datastreams = append(datastreams, &datastream)
 	}
 
-	var count int
-	if len(countSQL) > 0 {
-		db.QueryRow(countSQL).Scan(&count)
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
 	}
 
 	return datastreams, count, nil
@@ -303,10 +342,10 @@ func (gdb *GostDatabase) PostDatastream(d *entities.Datastream) (*entities.Datas
 	var dsID int
 
 	unitOfMeasurement, _ := json.Marshal(d.UnitOfMeasurement)
-	geom := "NULL"
+	var observedArea interface{}
 	if len(d.ObservedArea) != 0 {
 		observedAreaBytes, _ := json.Marshal(d.ObservedArea)
-		geom = fmt.Sprintf("ST_SetSRID(ST_GeomFromGeoJSON('%s'),4326)", string(observedAreaBytes[:]))
+		observedArea = string(observedAreaBytes[:])
 	}
 
 	phenomenonTime := "NULL"
@@ -321,8 +360,8 @@ func (gdb *GostDatabase) PostDatastream(d *entities.Datastream) (*entities.Datas
 		return nil, gostErrors.NewBadRequestError(errors.New("ObservationType does not exist"))
 	}
 
-	sql := fmt.Sprintf("INSERT INTO %s.datastream (name, description, unitofmeasurement, observedarea, thing_id, sensor_id, observedproperty_id, observationtype, phenomenonTime) VALUES ($1, $2, $3, %s, $4, $5, $6, $7, %s) RETURNING id", gdb.Schema, geom, phenomenonTime)
-	err = gdb.Db.QueryRow(sql, d.Name, d.Description, unitOfMeasurement, tID, sID, oID, observationType.Code).Scan(&dsID)
+	sql := fmt.Sprintf("INSERT INTO %s.datastream (name, description, unitofmeasurement, observedarea, thing_id, sensor_id, observedproperty_id, observationtype, phenomenonTime) VALUES ($1, $2, $3, ST_SetSRID(ST_GeomFromGeoJSON($4::text),4326), $5, $6, $7, $8, %s) RETURNING id", gdb.Schema, phenomenonTime)
+	err = gdb.Db.QueryRow(sql, d.Name, d.Description, unitOfMeasurement, observedArea, tID, sID, oID, observationType.Code).Scan(&dsID)
 	if err != nil {
 		return nil, err
 	}
@@ -371,8 +410,7 @@ func (gdb *GostDatabase) PatchDatastream(id interface{}, ds *entities.Datastream
 	}
 
 	if len(ds.ObservedArea) > 0 {
-		observedAreaBytes, _ := json.Marshal(ds.ObservedArea)
-		updates["observedarea"] = fmt.Sprintf("ST_SetSRID(ST_GeomFromGeoJSON('%s'),4326)", string(observedAreaBytes[:]))
+		updates["observedarea"] = newGeoJSON(ds.ObservedArea)
 	}
 
 	if err = gdb.updateEntityColumns("datastream", updates, intID); err != nil {
@@ -383,52 +421,33 @@ func (gdb *GostDatabase) PatchDatastream(id interface{}, ds *entities.Datastream
 	return nd, nil
 }
 
-// PutDatastream receives a Datastream entity and changes it in the database
-// returns the adapted Datastream
+// PutDatastream replaces a Datastream in the database, the observedArea is cleared when omitted and the
+// Datastream is linked to the given Thing, Sensor and ObservedProperty
 func (gdb *GostDatabase) PutDatastream(id interface{}, datastream *entities.Datastream) (*entities.Datastream, error) {
-	return gdb.PatchDatastream(id, datastream)
-
-	/*err := CheckDatastreamRelationsExist(gdb, datastream)
-	if err != nil {
-		return nil, err
+	var cleared []string
+	if len(datastream.ObservedArea) == 0 {
+		cleared = append(cleared, datastreamObservedArea)
 	}
-	tID, _ := ToIntID(datastream.Thing.ID)
-	sID, _ := ToIntID(datastream.Sensor.ID)
-	oID, _ := ToIntID(datastream.ObservedProperty.ID)
 
-	var intID int
-	var ok bool
-	if intID, ok = ToIntID(id); !ok || !gdb.DatastreamExists(intID) {
-		return nil, gostErrors.NewRequestNotFound(errors.New("Datastream does not exist"))
+	relations := map[entities.EntityType]interface{}{}
+	if datastream.Thing != nil {
+		relations[entities.EntityTypeThing] = datastream.Thing.ID
 	}
 
-	unitOfMeasurement, _ := json.Marshal(datastream.UnitOfMeasurement)
-	geom := "NULL"
-	if len(datastream.ObservedArea) != 0 {
-		observedAreaBytes, _ := json.Marshal(datastream.ObservedArea)
-		geom = fmt.Sprintf("ST_SetSRID(ST_GeomFromGeoJSON('%s'),4326)", string(observedAreaBytes[:]))
+	if datastream.Sensor != nil {
+		relations[entities.EntityTypeSensor] = datastream.Sensor.ID
 	}
 
-	// get the ObservationType id in the lookup table
-	observationType, err := entities.GetObservationTypeByValue(datastream.ObservationType)
-
-	if err != nil {
-		return nil, gostErrors.NewBadRequestError(errors.New("ObservationType does not exist"))
+	if datastream.ObservedProperty != nil {
+		relations[entities.EntityTypeObservedProperty] = datastream.ObservedProperty.ID
 	}
 
-	sql := fmt.Sprintf("update %s.datastream set name=$1, description=$2, unitofmeasurement=$3, observedarea=%s, thing_id=$4, sensor_id=$5, observedproperty_id=$6, observationtype=$7 where id = $8", gdb.Schema, geom)
-	_, err2 := gdb.Db.Exec(sql, datastream.Name, datastream.Description, unitOfMeasurement, tID, sID, oID, observationType.Code, intID)
-	if err2 != nil {
+	intID, err := gdb.putEntity(entities.EntityTypeDatastream, id, cleared, relations)
+	if err != nil {
 		return nil, err
 	}
-	datastream.ID = intID
-
-	// clear inner entities to serves links upon response
-	datastream.Thing = nil
-	datastream.Sensor = nil
-	datastream.ObservedProperty = nil
 
-	return datastream, nil*/
+	return gdb.PatchDatastream(intID, datastream)
 }
 
 // DeleteDatastream tries to delete a Datastream by the given id
@@ -7,16 +7,21 @@ import (
 
 // tables as defined in postgis
 var (
-	thingTable                        = "thing"
-	locationTable                     = "location"
-	historicalLocationTable           = "historicallocation"
-	sensorTable                       = "sensor"
-	observedPropertyTable             = "observedproperty"
-	datastreamTable                   = "datastream"
-	observationTable                  = "observation"
-	featureOfInterestTable            = "featureofinterest"
-	thingToLocationTable              = "thing_to_location"
-	locationToHistoricalLocationTable = "location_to_historicallocation"
+	thingTable                             = "thing"
+	locationTable                          = "location"
+	historicalLocationTable                = "historicallocation"
+	sensorTable                            = "sensor"
+	observedPropertyTable                  = "observedproperty"
+	datastreamTable                        = "datastream"
+	multiDatastreamTable                   = "multidatastream"
+	observationTable                       = "observation"
+	featureOfInterestTable                 = "featureofinterest"
+	thingToLocationTable                   = "thing_to_location"
+	locationToHistoricalLocationTable      = "location_to_historicallocation"
+	multiDatastreamToObservedPropertyTable = "multidatastream_to_observedproperty"
+	actuatorTable                          = "actuator"
+	taskingCapabilityTable                 = "taskingcapability"
+	taskTable                              = "task"
 )
 
 // thing fields
@@ -93,6 +98,28 @@ var (
 	datastreamObservedPropertyID = "observedproperty_id"
 )
 
+// multidatastream fields
+var (
+	multiDatastreamID                        = idField
+	multiDatastreamName                      = "name"
+	multiDatastreamDescription               = "description"
+	multiDatastreamUnitOfMeasurements        = "unitofmeasurements"
+	multiDatastreamObservationType           = "observationtype"
+	multiDatastreamMultiObservationDataTypes = "multiobservationdatatypes"
+	multiDatastreamObservedArea              = "observedarea"
+	multiDatastreamPhenomenonTime            = "phenomenontime"
+	multiDatastreamResultTime                = "resulttime"
+	multiDatastreamThingID                   = "thing_id"
+	multiDatastreamSensorID                  = "sensor_id"
+)
+
+// multiDatastreamToObservedProperty fields, rank holds the position of the ObservedProperty in the MultiDatastream
+var (
+	multiDatastreamToObservedPropertyMultiDatastreamID  = "multidatastream_id"
+	multiDatastreamToObservedPropertyObservedPropertyID = "observedproperty_id"
+	multiDatastreamToObservedPropertyRank               = "rank"
+)
+
 // observation fields
 var (
 	observationID                  = idField
@@ -105,6 +132,35 @@ var (
 	observationParameters          = "parameters"
 	observationStreamID            = "stream_id"
 	observationFeatureOfInterestID = "featureofinterest_id"
+	observationMultiDatastreamID   = "multidatastream_id"
+)
+
+// actuator fields
+var (
+	actuatorID           = idField
+	actuatorName         = "name"
+	actuatorDescription  = "description"
+	actuatorEncodingType = "encodingtype"
+	actuatorMetadata     = "metadata"
+)
+
+// tasking capability fields
+var (
+	taskingCapabilityID                = idField
+	taskingCapabilityName              = "name"
+	taskingCapabilityDescription       = "description"
+	taskingCapabilityProperties        = "properties"
+	taskingCapabilityTaskingParameters = "taskingparameters"
+	taskingCapabilityThingID           = "thing_id"
+	taskingCapabilityActuatorID        = "actuator_id"
+)
+
+// task fields
+var (
+	taskID                  = idField
+	taskCreationTime        = "creationtime"
+	taskTaskingParameters   = "taskingparameters"
+	taskTaskingCapabilityID = "taskingcapability_id"
 )
 
 // feature of interest fields
@@ -155,6 +211,10 @@ func (q *QueryParseInfo) Init(entityType entities.EntityType, queryIndex int) {
 		q.Entity = &entities.Datastream{}
 		q.ParamFactory = datastreamParamFactory
 		break
+	case entities.EntityTypeMultiDatastream:
+		q.Entity = &entities.MultiDatastream{}
+		q.ParamFactory = multiDatastreamParamFactory
+		break
 	case entities.EntityTypeHistoricalLocation:
 		q.Entity = &entities.HistoricalLocation{}
 		q.ParamFactory = historicalLocationParamFactory
@@ -163,6 +223,18 @@ func (q *QueryParseInfo) Init(entityType entities.EntityType, queryIndex int) {
 		q.Entity = &entities.Sensor{}
 		q.ParamFactory = sensorParamFactory
 		break
+	case entities.EntityTypeActuator:
+		q.Entity = &entities.Actuator{}
+		q.ParamFactory = actuatorParamFactory
+		break
+	case entities.EntityTypeTaskingCapability:
+		q.Entity = &entities.TaskingCapability{}
+		q.ParamFactory = taskingCapabilityParamFactory
+		break
+	case entities.EntityTypeTask:
+		q.Entity = &entities.Task{}
+		q.ParamFactory = taskParamFactory
+		break
 	}
 }
 
@@ -268,6 +340,7 @@ var asMappings = map[entities.EntityType]map[string]string{
 		observationParameters:          constructAs(observationTable, observationParameters),
 		observationStreamID:            constructAs(observationTable, observationStreamID),
 		observationFeatureOfInterestID: constructAs(observationTable, observationFeatureOfInterestID),
+		observationMultiDatastreamID:   constructAs(observationTable, observationMultiDatastreamID),
 	},
 	entities.EntityTypeFeatureOfInterest: {
 		foiID:                 constructAs(featureOfInterestTable, foiID),
@@ -290,6 +363,46 @@ var asMappings = map[entities.EntityType]map[string]string{
 		datastreamSensorID:           constructAs(datastreamTable, datastreamSensorID),
 		datastreamObservedPropertyID: constructAs(datastreamTable, datastreamObservedPropertyID),
 	},
+	entities.EntityTypeMultiDatastream: {
+		multiDatastreamID:                        constructAs(multiDatastreamTable, multiDatastreamID),
+		multiDatastreamName:                      constructAs(multiDatastreamTable, multiDatastreamName),
+		multiDatastreamDescription:               constructAs(multiDatastreamTable, multiDatastreamDescription),
+		multiDatastreamUnitOfMeasurements:        constructAs(multiDatastreamTable, multiDatastreamUnitOfMeasurements),
+		multiDatastreamObservationType:           constructAs(multiDatastreamTable, multiDatastreamObservationType),
+		multiDatastreamMultiObservationDataTypes: constructAs(multiDatastreamTable, multiDatastreamMultiObservationDataTypes),
+		multiDatastreamObservedArea:              constructAs(multiDatastreamTable, multiDatastreamObservedArea),
+		multiDatastreamPhenomenonTime:            constructAs(multiDatastreamTable, multiDatastreamPhenomenonTime),
+		multiDatastreamResultTime:                constructAs(multiDatastreamTable, multiDatastreamResultTime),
+		multiDatastreamThingID:                   constructAs(multiDatastreamTable, multiDatastreamThingID),
+		multiDatastreamSensorID:                  constructAs(multiDatastreamTable, multiDatastreamSensorID),
+	},
+	entities.EntityTypeMultiDatastreamToObservedProperty: {
+		multiDatastreamToObservedPropertyMultiDatastreamID:  constructAs(multiDatastreamToObservedPropertyTable, multiDatastreamToObservedPropertyMultiDatastreamID),
+		multiDatastreamToObservedPropertyObservedPropertyID: constructAs(multiDatastreamToObservedPropertyTable, multiDatastreamToObservedPropertyObservedPropertyID),
+		multiDatastreamToObservedPropertyRank:               constructAs(multiDatastreamToObservedPropertyTable, multiDatastreamToObservedPropertyRank),
+	},
+	entities.EntityTypeActuator: {
+		actuatorID:           constructAs(actuatorTable, actuatorID),
+		actuatorName:         constructAs(actuatorTable, actuatorName),
+		actuatorDescription:  constructAs(actuatorTable, actuatorDescription),
+		actuatorEncodingType: constructAs(actuatorTable, actuatorEncodingType),
+		actuatorMetadata:     constructAs(actuatorTable, actuatorMetadata),
+	},
+	entities.EntityTypeTaskingCapability: {
+		taskingCapabilityID:                constructAs(taskingCapabilityTable, taskingCapabilityID),
+		taskingCapabilityName:              constructAs(taskingCapabilityTable, taskingCapabilityName),
+		taskingCapabilityDescription:       constructAs(taskingCapabilityTable, taskingCapabilityDescription),
+		taskingCapabilityProperties:        constructAs(taskingCapabilityTable, taskingCapabilityProperties),
+		taskingCapabilityTaskingParameters: constructAs(taskingCapabilityTable, taskingCapabilityTaskingParameters),
+		taskingCapabilityThingID:           constructAs(taskingCapabilityTable, taskingCapabilityThingID),
+		taskingCapabilityActuatorID:        constructAs(taskingCapabilityTable, taskingCapabilityActuatorID),
+	},
+	entities.EntityTypeTask: {
+		taskID:                  constructAs(taskTable, taskID),
+		taskCreationTime:        constructAs(taskTable, taskCreationTime),
+		taskTaskingParameters:   constructAs(taskTable, taskTaskingParameters),
+		taskTaskingCapabilityID: constructAs(taskTable, taskTaskingCapabilityID),
+	},
 }
 
 func constructAs(table, field string) string {
@@ -297,15 +410,20 @@ func constructAs(table, field string) string {
 }
 
 var tableMappings = map[entities.EntityType]string{
-	entities.EntityTypeThing:              thingTable,
-	entities.EntityTypeLocation:           locationTable,
-	entities.EntityTypeThingToLocation:    thingToLocationTable,
-	entities.EntityTypeHistoricalLocation: historicalLocationTable,
-	entities.EntityTypeSensor:             sensorTable,
-	entities.EntityTypeObservedProperty:   observedPropertyTable,
-	entities.EntityTypeObservation:        observationTable,
-	entities.EntityTypeFeatureOfInterest:  featureOfInterestTable,
-	entities.EntityTypeDatastream:         datastreamTable,
+	entities.EntityTypeThing:                             thingTable,
+	entities.EntityTypeLocation:                          locationTable,
+	entities.EntityTypeThingToLocation:                   thingToLocationTable,
+	entities.EntityTypeHistoricalLocation:                historicalLocationTable,
+	entities.EntityTypeSensor:                            sensorTable,
+	entities.EntityTypeObservedProperty:                  observedPropertyTable,
+	entities.EntityTypeObservation:                       observationTable,
+	entities.EntityTypeFeatureOfInterest:                 featureOfInterestTable,
+	entities.EntityTypeDatastream:                        datastreamTable,
+	entities.EntityTypeMultiDatastream:                   multiDatastreamTable,
+	entities.EntityTypeMultiDatastreamToObservedProperty: multiDatastreamToObservedPropertyTable,
+	entities.EntityTypeActuator:                          actuatorTable,
+	entities.EntityTypeTaskingCapability:                 taskingCapabilityTable,
+	entities.EntityTypeTask:                              taskTable,
 }
 
 // maps an entity property name to the right field
@@ -357,6 +475,7 @@ var selectMappings = map[entities.EntityType]map[string]string{
 		observationParameters:          fmt.Sprintf("%s.%s -> '%s'", observationTable, observationData, observationParameters),
 		observationStreamID:            fmt.Sprintf("%s.%s", observationTable, observationStreamID),
 		observationFeatureOfInterestID: fmt.Sprintf("%s.%s", observationTable, observationFeatureOfInterestID),
+		observationMultiDatastreamID:   fmt.Sprintf("%s.%s", observationTable, observationMultiDatastreamID),
 	},
 	entities.EntityTypeFeatureOfInterest: {
 		foiID:                 fmt.Sprintf("%s.%s", featureOfInterestTable, foiID),
@@ -379,13 +498,55 @@ var selectMappings = map[entities.EntityType]map[string]string{
 		datastreamSensorID:           fmt.Sprintf("%s.%s", datastreamTable, datastreamSensorID),
 		datastreamObservedPropertyID: fmt.Sprintf("%s.%s", datastreamTable, datastreamObservedPropertyID),
 	},
+	entities.EntityTypeMultiDatastream: {
+		multiDatastreamID:                        fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamID),
+		multiDatastreamName:                      fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamName),
+		multiDatastreamDescription:               fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamDescription),
+		multiDatastreamUnitOfMeasurements:        fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamUnitOfMeasurements),
+		multiDatastreamObservationType:           fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamObservationType),
+		multiDatastreamMultiObservationDataTypes: fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamMultiObservationDataTypes),
+		multiDatastreamObservedArea:              fmt.Sprintf("public.ST_AsGeoJSON(%s.%s)", multiDatastreamTable, multiDatastreamObservedArea),
+		multiDatastreamPhenomenonTime:            fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamPhenomenonTime),
+		multiDatastreamResultTime:                fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamResultTime),
+		multiDatastreamThingID:                   fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamThingID),
+		multiDatastreamSensorID:                  fmt.Sprintf("%s.%s", multiDatastreamTable, multiDatastreamSensorID),
+	},
+	entities.EntityTypeMultiDatastreamToObservedProperty: {
+		multiDatastreamToObservedPropertyMultiDatastreamID:  fmt.Sprintf("%s.%s", multiDatastreamToObservedPropertyTable, multiDatastreamToObservedPropertyMultiDatastreamID),
+		multiDatastreamToObservedPropertyObservedPropertyID: fmt.Sprintf("%s.%s", multiDatastreamToObservedPropertyTable, multiDatastreamToObservedPropertyObservedPropertyID),
+		multiDatastreamToObservedPropertyRank:               fmt.Sprintf("%s.%s", multiDatastreamToObservedPropertyTable, multiDatastreamToObservedPropertyRank),
+	},
+	entities.EntityTypeActuator: {
+		actuatorID:           fmt.Sprintf("%s.%s", actuatorTable, actuatorID),
+		actuatorName:         fmt.Sprintf("%s.%s", actuatorTable, actuatorName),
+		actuatorDescription:  fmt.Sprintf("%s.%s", actuatorTable, actuatorDescription),
+		actuatorEncodingType: fmt.Sprintf("%s.%s", actuatorTable, actuatorEncodingType),
+		actuatorMetadata:     fmt.Sprintf("%s.%s", actuatorTable, actuatorMetadata),
+	},
+	entities.EntityTypeTaskingCapability: {
+		taskingCapabilityID:                fmt.Sprintf("%s.%s", taskingCapabilityTable, taskingCapabilityID),
+		taskingCapabilityName:              fmt.Sprintf("%s.%s", taskingCapabilityTable, taskingCapabilityName),
+		taskingCapabilityDescription:       fmt.Sprintf("%s.%s", taskingCapabilityTable, taskingCapabilityDescription),
+		taskingCapabilityProperties:        fmt.Sprintf("%s.%s", taskingCapabilityTable, taskingCapabilityProperties),
+		taskingCapabilityTaskingParameters: fmt.Sprintf("%s.%s", taskingCapabilityTable, taskingCapabilityTaskingParameters),
+		taskingCapabilityThingID:           fmt.Sprintf("%s.%s", taskingCapabilityTable, taskingCapabilityThingID),
+		taskingCapabilityActuatorID:        fmt.Sprintf("%s.%s", taskingCapabilityTable, taskingCapabilityActuatorID),
+	},
+	entities.EntityTypeTask: {
+		taskID:                  fmt.Sprintf("%s.%s", taskTable, taskID),
+		taskCreationTime:        fmt.Sprintf("%s.%s", taskTable, taskCreationTime),
+		taskTaskingParameters:   fmt.Sprintf("%s.%s", taskTable, taskTaskingParameters),
+		taskTaskingCapabilityID: fmt.Sprintf("%s.%s", taskTable, taskTaskingCapabilityID),
+	},
 }
 
 func createJoinMappings(tableMappings map[entities.EntityType]string) map[entities.EntityType]map[entities.EntityType]string {
 	joinMappings := map[entities.EntityType]map[entities.EntityType]string{
 		entities.EntityTypeThing: { // get thing by ...
 			entities.EntityTypeDatastream:         fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeThing][thingID], selectMappings[entities.EntityTypeDatastream][datastreamThingID]),
+			entities.EntityTypeMultiDatastream:    fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeThing][thingID], selectMappings[entities.EntityTypeMultiDatastream][multiDatastreamThingID]),
 			entities.EntityTypeHistoricalLocation: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeThing][thingID], selectMappings[entities.EntityTypeHistoricalLocation][historicalLocationThingID]),
+			entities.EntityTypeTaskingCapability:  fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeThing][thingID], selectMappings[entities.EntityTypeTaskingCapability][taskingCapabilityThingID]),
 			entities.EntityTypeLocation: fmt.Sprintf("INNER JOIN %s ON %s = %s AND %s = %s",
 				tableMappings[entities.EntityTypeThingToLocation],
 				selectMappings[entities.EntityTypeThing][thingID],
@@ -417,14 +578,22 @@ func createJoinMappings(tableMappings map[entities.EntityType]string) map[entiti
 			entities.EntityTypeThing: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeHistoricalLocation][historicalLocationThingID], selectMappings[entities.EntityTypeThing][thingID]),
 		},
 		entities.EntityTypeSensor: { // get sensor by ...
-			entities.EntityTypeDatastream: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeSensor][sensorID], selectMappings[entities.EntityTypeDatastream][datastreamSensorID]),
+			entities.EntityTypeDatastream:      fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeSensor][sensorID], selectMappings[entities.EntityTypeDatastream][datastreamSensorID]),
+			entities.EntityTypeMultiDatastream: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeSensor][sensorID], selectMappings[entities.EntityTypeMultiDatastream][multiDatastreamSensorID]),
 		},
 		entities.EntityTypeObservedProperty: { // get observed property by ...
 			entities.EntityTypeDatastream: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeObservedProperty][observedPropertyID], selectMappings[entities.EntityTypeDatastream][datastreamObservedPropertyID]),
+			entities.EntityTypeMultiDatastream: fmt.Sprintf("INNER JOIN %s ON %s = %s AND %s = %s",
+				tableMappings[entities.EntityTypeMultiDatastreamToObservedProperty],
+				selectMappings[entities.EntityTypeMultiDatastreamToObservedProperty][multiDatastreamToObservedPropertyObservedPropertyID],
+				selectMappings[entities.EntityTypeObservedProperty][observedPropertyID],
+				selectMappings[entities.EntityTypeMultiDatastreamToObservedProperty][multiDatastreamToObservedPropertyMultiDatastreamID],
+				selectMappings[entities.EntityTypeMultiDatastream][multiDatastreamID]),
 		},
 		entities.EntityTypeObservation: { // get observation by ...
 			entities.EntityTypeDatastream:        fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeObservation][observationStreamID], selectMappings[entities.EntityTypeDatastream][datastreamID]),
 			entities.EntityTypeFeatureOfInterest: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeObservation][observationFeatureOfInterestID], selectMappings[entities.EntityTypeFeatureOfInterest][foiID]),
+			entities.EntityTypeMultiDatastream:   fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeObservation][observationMultiDatastreamID], selectMappings[entities.EntityTypeMultiDatastream][multiDatastreamID]),
 		},
 		entities.EntityTypeFeatureOfInterest: { // get feature of interest by ...
 			entities.EntityTypeObservation: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeFeatureOfInterest][foiID], selectMappings[entities.EntityTypeObservation][observationFeatureOfInterestID]),
@@ -435,6 +604,28 @@ func createJoinMappings(tableMappings map[entities.EntityType]string) map[entiti
 			entities.EntityTypeObservedProperty: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeDatastream][datastreamObservedPropertyID], selectMappings[entities.EntityTypeObservedProperty][observedPropertyID]),
 			entities.EntityTypeObservation:      fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeDatastream][datastreamID], selectMappings[entities.EntityTypeObservation][observationStreamID]),
 		},
+		entities.EntityTypeMultiDatastream: { // get MultiDatastream by ...
+			entities.EntityTypeThing:  fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeMultiDatastream][multiDatastreamThingID], selectMappings[entities.EntityTypeThing][thingID]),
+			entities.EntityTypeSensor: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeMultiDatastream][multiDatastreamSensorID], selectMappings[entities.EntityTypeSensor][sensorID]),
+			entities.EntityTypeObservedProperty: fmt.Sprintf("INNER JOIN %s ON %s = %s AND %s = %s",
+				tableMappings[entities.EntityTypeMultiDatastreamToObservedProperty],
+				selectMappings[entities.EntityTypeMultiDatastreamToObservedProperty][multiDatastreamToObservedPropertyMultiDatastreamID],
+				selectMappings[entities.EntityTypeMultiDatastream][multiDatastreamID],
+				selectMappings[entities.EntityTypeMultiDatastreamToObservedProperty][multiDatastreamToObservedPropertyObservedPropertyID],
+				selectMappings[entities.EntityTypeObservedProperty][observedPropertyID]),
+			entities.EntityTypeObservation: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeMultiDatastream][multiDatastreamID], selectMappings[entities.EntityTypeObservation][observationMultiDatastreamID]),
+		},
+		entities.EntityTypeActuator: { // get Actuator by ...
+			entities.EntityTypeTaskingCapability: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeActuator][actuatorID], selectMappings[entities.EntityTypeTaskingCapability][taskingCapabilityActuatorID]),
+		},
+		entities.EntityTypeTaskingCapability: { // get TaskingCapability by ...
+			entities.EntityTypeThing:    fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeTaskingCapability][taskingCapabilityThingID], selectMappings[entities.EntityTypeThing][thingID]),
+			entities.EntityTypeActuator: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeTaskingCapability][taskingCapabilityActuatorID], selectMappings[entities.EntityTypeActuator][actuatorID]),
+			entities.EntityTypeTask:     fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeTaskingCapability][taskingCapabilityID], selectMappings[entities.EntityTypeTask][taskTaskingCapabilityID]),
+		},
+		entities.EntityTypeTask: { // get Task by ...
+			entities.EntityTypeTaskingCapability: fmt.Sprintf("WHERE %s = %s", selectMappings[entities.EntityTypeTask][taskTaskingCapabilityID], selectMappings[entities.EntityTypeTaskingCapability][taskingCapabilityID]),
+		},
 	}
 
 	return joinMappings
@@ -446,16 +637,21 @@ func createTableMappings(schema string) map[entities.EntityType]string {
 	}
 
 	tables := map[entities.EntityType]string{
-		entities.EntityTypeThing:                        fmt.Sprintf("%s%s", schema, thingTable),
-		entities.EntityTypeLocation:                     fmt.Sprintf("%s%s", schema, locationTable),
-		entities.EntityTypeHistoricalLocation:           fmt.Sprintf("%s%s", schema, historicalLocationTable),
-		entities.EntityTypeSensor:                       fmt.Sprintf("%s%s", schema, sensorTable),
-		entities.EntityTypeObservedProperty:             fmt.Sprintf("%s%s", schema, observedPropertyTable),
-		entities.EntityTypeDatastream:                   fmt.Sprintf("%s%s", schema, datastreamTable),
-		entities.EntityTypeObservation:                  fmt.Sprintf("%s%s", schema, observationTable),
-		entities.EntityTypeFeatureOfInterest:            fmt.Sprintf("%s%s", schema, featureOfInterestTable),
-		entities.EntityTypeThingToLocation:              fmt.Sprintf("%s%s", schema, thingToLocationTable),
-		entities.EntityTypeLocationToHistoricalLocation: fmt.Sprintf("%s%s", schema, locationToHistoricalLocationTable),
+		entities.EntityTypeThing:                             fmt.Sprintf("%s%s", schema, thingTable),
+		entities.EntityTypeLocation:                          fmt.Sprintf("%s%s", schema, locationTable),
+		entities.EntityTypeHistoricalLocation:                fmt.Sprintf("%s%s", schema, historicalLocationTable),
+		entities.EntityTypeSensor:                            fmt.Sprintf("%s%s", schema, sensorTable),
+		entities.EntityTypeObservedProperty:                  fmt.Sprintf("%s%s", schema, observedPropertyTable),
+		entities.EntityTypeDatastream:                        fmt.Sprintf("%s%s", schema, datastreamTable),
+		entities.EntityTypeMultiDatastream:                   fmt.Sprintf("%s%s", schema, multiDatastreamTable),
+		entities.EntityTypeMultiDatastreamToObservedProperty: fmt.Sprintf("%s%s", schema, multiDatastreamToObservedPropertyTable),
+		entities.EntityTypeObservation:                       fmt.Sprintf("%s%s", schema, observationTable),
+		entities.EntityTypeFeatureOfInterest:                 fmt.Sprintf("%s%s", schema, featureOfInterestTable),
+		entities.EntityTypeThingToLocation:                   fmt.Sprintf("%s%s", schema, thingToLocationTable),
+		entities.EntityTypeLocationToHistoricalLocation:      fmt.Sprintf("%s%s", schema, locationToHistoricalLocationTable),
+		entities.EntityTypeActuator:                          fmt.Sprintf("%s%s", schema, actuatorTable),
+		entities.EntityTypeTaskingCapability:                 fmt.Sprintf("%s%s", schema, taskingCapabilityTable),
+		entities.EntityTypeTask:                              fmt.Sprintf("%s%s", schema, taskTable),
 	}
 
 	return tables
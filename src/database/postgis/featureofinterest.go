@@ -1,7 +1,6 @@
 package postgis
 
 import (
-	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -90,8 +89,19 @@ func (gdb *GostDatabase) GetFeatureOfInterestByObservation(id interface{}, qo *o
 
 // GetFeatureOfInterests returns all feature of interests
 func (gdb *GostDatabase) GetFeatureOfInterests(qo *odata.QueryOptions) ([]*entities.FeatureOfInterest, int, error) {
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.FeatureOfInterest{}, qo, "", "", foiMapping)+" from %s.featureofinterest order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema)
-	countSSQL := fmt.Sprintf("select COUNT(*) FROM %s.featureofinterest", gdb.Schema)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeFeatureOfInterest, selectMappingsResolver(entities.EntityTypeFeatureOfInterest))
+	queryString, err := CreateFilterQueryString(qo, resolver, "WHERE ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.FeatureOfInterest{}, qo, "", "", foiMapping)+" from %s.featureofinterest %sorder by %s %s", gdb.Schema, queryString, orderBy, CreateTopSkipQueryString(qo))
+	countSSQL := fmt.Sprintf("select COUNT(*) FROM %s.featureofinterest %s", gdb.Schema, queryString)
 	return processFeatureOfInterests(gdb.Db, sql, qo, countSSQL)
 }
 
@@ -100,8 +110,8 @@ func (gdb *GostDatabase) PostFeatureOfInterest(f *entities.FeatureOfInterest) (*
 	var fID int
 	locationBytes, _ := json.Marshal(f.Feature)
 	encoding, _ := entities.CreateEncodingType(f.EncodingType)
-	sql := fmt.Sprintf("INSERT INTO %s.featureofinterest (name, description, encodingtype, feature, original_location_id) VALUES ($1, $2, $3, ST_SetSRID(public.ST_GeomFromGeoJSON('%s'),4326), $4) RETURNING id", gdb.Schema, string(locationBytes[:]))
-	err := gdb.Db.QueryRow(sql, f.Name, f.Description, encoding.Code, f.OriginalLocationID).Scan(&fID)
+	sql := fmt.Sprintf("INSERT INTO %s.featureofinterest (name, description, encodingtype, feature, original_location_id) VALUES ($1, $2, $3, ST_SetSRID(public.ST_GeomFromGeoJSON($4::text),4326), $5) RETURNING id", gdb.Schema)
+	err := gdb.Db.QueryRow(sql, f.Name, f.Description, encoding.Code, string(locationBytes[:]), f.OriginalLocationID).Scan(&fID)
 	if err != nil {
 		return nil, err
 	}
@@ -110,29 +120,18 @@ func (gdb *GostDatabase) PostFeatureOfInterest(f *entities.FeatureOfInterest) (*
 	return f, nil
 }
 
-// PutFeatureOfInterest inserts a new FeatureOfInterest into the database
+// PutFeatureOfInterest replaces a FeatureOfInterest in the database, all properties of a FeatureOfInterest
+// are mandatory
 func (gdb *GostDatabase) PutFeatureOfInterest(id interface{}, f *entities.FeatureOfInterest) (*entities.FeatureOfInterest, error) {
-	return gdb.PatchFeatureOfInterest(id, f)
-	/*
-		locationBytes, _ := json.Marshal(f.Feature)
-		intID, _ := ToIntID(id)
-		encoding, _ := entities.CreateEncodingType(f.EncodingType)
-
-		if !gdb.FeatureOfInterestExists(intID) {
-			return nil, gostErrors.NewRequestNotFound(errors.New("FeatureOfInterest does not exist"))
-		}
-
-		sql := fmt.Sprintf("update %s.featureofinterest set name=$1, description=$2, encodingtype=$3, feature= ST_SetSRID(public.ST_GeomFromGeoJSON('%s'),4326), original_location_id=$4 where id=$5", gdb.Schema, string(locationBytes[:]))
-		_, err := gdb.Db.Exec(sql, f.Name, f.Description, encoding.Code, f.OriginalLocationID, intID)
-		if err != nil {
-			return nil, err
-		}
+	intID, err := gdb.putEntity(entities.EntityTypeFeatureOfInterest, id, nil, nil)
+	if err != nil {
+		return nil, err
+	}
 
-		f.ID = intID
-		return f, nil*/
+	return gdb.PatchFeatureOfInterest(intID, f)
 }
 
-func processFeatureOfInterest(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.FeatureOfInterest, error) {
+func processFeatureOfInterest(db Executor, sql string, qo *odata.QueryOptions) (*entities.FeatureOfInterest, error) {
 	locations, _, err := processFeatureOfInterests(db, sql, qo, "")
 	if err != nil {
 		return nil, err
@@ -145,13 +144,12 @@ func processFeatureOfInterest(db *sql.DB, sql string, qo *odata.QueryOptions) (*
 	return locations[0], nil
 }
 
-func processFeatureOfInterests(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.FeatureOfInterest, int, error) {
+func processFeatureOfInterests(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.FeatureOfInterest, int, error) {
 	rows, err := db.Query(sql)
-	defer rows.Close()
-
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, filterQueryError(err)
 	}
+	defer rows.Close()
 
 	var featureOfInterests = []*entities.FeatureOfInterest{}
 	for rows.Next() {
@@ -160,13 +158,7 @@ func processFeatureOfInterests(db *sql.DB, sql string, qo *odata.QueryOptions, c
 		var name, description, feature string
 
 		var params []interface{}
-		var qp []string
-		if qo == nil || qo.QuerySelect == nil || len(qo.QuerySelect.Params) == 0 {
-			f := &entities.FeatureOfInterest{}
-			qp = f.GetPropertyNames()
-		} else {
-			qp = qo.QuerySelect.Params
-		}
+		qp := selectedProperties(&entities.FeatureOfInterest{}, qo)
 
 		for _, p := range qp {
 			p = strings.ToLower(p)
@@ -206,9 +198,13 @@ func processFeatureOfInterests(db *sql.DB, sql string, qo *odata.QueryOptions, c
 		featureOfInterests = append(featureOfInterests, &foi)
 	}
 
-	var count int
-	if len(countSQL) > 0 {
-		db.QueryRow(countSQL).Scan(&count)
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
 	}
 
 	return featureOfInterests, count, nil
@@ -239,8 +235,7 @@ func (gdb *GostDatabase) PatchFeatureOfInterest(id interface{}, foi *entities.Fe
 	}
 
 	if len(foi.Feature) > 0 {
-		locationBytes, _ := json.Marshal(foi.Feature)
-		updates["feature"] = fmt.Sprintf("ST_SetSRID(public.ST_GeomFromGeoJSON('%s'),4326)", string(locationBytes[:]))
+		updates["feature"] = newGeoJSON(foi.Feature)
 	}
 
 	if err = gdb.updateEntityColumns("featureofinterest", updates, intID); err != nil {
@@ -0,0 +1,435 @@
+package postgis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// FilterColumn describes the SQL expression a property inside a $filter is mapped to
+type FilterColumn struct {
+	SQL  string
+	JSON bool            // true when SQL results in a jsonb value, for example data -> 'result'
+	Type odata.ValueType // type of the column when known, for example ValueTypeGeometry for location.location
+	// Exists holds the subqueries needed to reach a property of a related entity, the
+	// condition using the property is placed inside nested EXISTS (subquery condition)
+	Exists []string
+	// Scalar holds the same navigation steps as Exists as FROM clauses when every step references a
+	// single related entity, the property can then be selected in nested (SELECT property FROM clause)
+	Scalar []string
+	// ObservationType is set for an observation result and holds the SQL returning the observation
+	// type code of the Datastream the observation belongs to, used to compare results by their type
+	ObservationType string
+}
+
+// FilterPropertyResolver maps a property path used inside a $filter onto a FilterColumn,
+// returns an error when the property is unknown for the queried entity
+type FilterPropertyResolver func(path []string) (*FilterColumn, error)
+
+// filterSQL is the translated form of a (sub) expression
+type filterSQL struct {
+	sql             string
+	json            bool
+	literal         *odata.LiteralExpression
+	exists          []string
+	valueType       odata.ValueType
+	observationType string
+}
+
+// filterBuilder translates a parsed odata filter expression into a PostgreSQL condition
+type filterBuilder struct {
+	resolver FilterPropertyResolver
+}
+
+// BuildFilter translates the given filter expression into a PostgreSQL condition, properties
+// are mapped to columns using the given resolver
+func BuildFilter(e odata.Expression, resolver FilterPropertyResolver) (string, error) {
+	fb := &filterBuilder{resolver: resolver}
+	f, err := fb.translate(e)
+	if err != nil {
+		return "", err
+	}
+
+	return fb.condition(f), nil
+}
+
+func (fb *filterBuilder) translate(e odata.Expression) (*filterSQL, error) {
+	switch t := e.(type) {
+	case *odata.LiteralExpression:
+		return &filterSQL{literal: t, valueType: odata.ExpressionType(t)}, nil
+	case *odata.PropertyExpression:
+		c, err := fb.resolver(t.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		return &filterSQL{sql: c.SQL, json: c.JSON, exists: c.Exists, valueType: c.Type, observationType: c.ObservationType}, nil
+	case *odata.UnaryExpression:
+		return fb.translateUnary(t)
+	case *odata.BinaryExpression:
+		return fb.translateBinary(t)
+	case *odata.FunctionExpression:
+		return fb.translateFunction(t)
+	}
+
+	return nil, fmt.Errorf("Unable to translate %v", e)
+}
+
+func (fb *filterBuilder) translateUnary(u *odata.UnaryExpression) (*filterSQL, error) {
+	operand, err := fb.translate(u.Operand)
+	if err != nil {
+		return nil, err
+	}
+
+	operator, err := OdataOperatorToPostgreSQL(u.Operator)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filterSQL{sql: fmt.Sprintf("%s (%s)", operator, fb.condition(operand))}, nil
+}
+
+func (fb *filterBuilder) translateBinary(b *odata.BinaryExpression) (*filterSQL, error) {
+	left, err := fb.translate(b.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := fb.translate(b.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.Operator.IsLogical() {
+		operator, _ := OdataOperatorToPostgreSQL(b.Operator)
+		return &filterSQL{sql: fmt.Sprintf("(%s %s %s)", fb.condition(left), operator, fb.condition(right))}, nil
+	}
+
+	if b.Operator.IsArithmetic() {
+		operator, err := OdataOperatorToPostgreSQL(b.Operator)
+		if err != nil {
+			return nil, err
+		}
+
+		return &filterSQL{
+			sql:       fmt.Sprintf("(%s %s %s)", fb.renderAs(left, odata.ValueTypeNumber), operator, fb.renderAs(right, odata.ValueTypeNumber)),
+			exists:    mergeExists(left.exists, right.exists),
+			valueType: odata.ValueTypeNumber,
+		}, nil
+	}
+
+	if b.Operator == odata.Equals || b.Operator == odata.NotEquals {
+		if isNullLiteral(right) || isNullLiteral(left) {
+			subject := left
+			if isNullLiteral(left) {
+				subject = right
+			}
+
+			if b.Operator == odata.Equals {
+				return &filterSQL{sql: fmt.Sprintf("%s IS NULL", fb.render(subject)), exists: subject.exists}, nil
+			}
+			return &filterSQL{sql: fmt.Sprintf("%s IS NOT NULL", fb.render(subject)), exists: subject.exists}, nil
+		}
+	}
+
+	operator, err := OdataOperatorToPostgreSQL(b.Operator)
+	if err != nil {
+		return nil, err
+	}
+
+	l, r := fb.renderOperands(left, right)
+	sql := fmt.Sprintf("%s %s %s", l, operator, r)
+	if b.Operator != odata.Equals && b.Operator != odata.NotEquals {
+		sql = jsonTypeGuard(left, right, sql)
+	}
+
+	sql = typedResultComparison(left, right, operator, sql)
+	return &filterSQL{sql: sql, exists: mergeExists(left.exists, right.exists)}, nil
+}
+
+// typedResultComparison compares an observation result with a literal using the observationType of
+// the Datastream: OM_Measurement as double, OM_CountObservation as integer, OM_TruthObservation
+// as boolean and OM_CategoryObservation as text. Results of other types such as OM_Observation
+// are compared as json using the given fallback comparison
+func typedResultComparison(left, right *filterSQL, operator string, fallback string) string {
+	result, literal := left, right
+	if right.observationType != "" {
+		result, literal = right, left
+	}
+
+	if result.observationType == "" || literal.literal == nil {
+		return fallback
+	}
+
+	var casts map[entities.ObservationType]string
+	switch literal.literal.Type {
+	case odata.LiteralNumber:
+		casts = map[entities.ObservationType]string{entities.OMMeasurement: "double precision", entities.OMCountObservation: "bigint"}
+	case odata.LiteralBoolean:
+		casts = map[entities.ObservationType]string{entities.OMTruthObservation: "boolean"}
+	case odata.LiteralString:
+		casts = map[entities.ObservationType]string{entities.OMCategoryObservation: "text"}
+	default:
+		return fallback
+	}
+
+	sql := fmt.Sprintf("CASE %s", result.observationType)
+	for _, ot := range entities.ObservationTypes {
+		cast, ok := casts[ot]
+		if !ok {
+			continue
+		}
+
+		value := fmt.Sprintf("(%s #>> '{}')::%s", result.sql, cast)
+		if result == left {
+			sql = fmt.Sprintf("%s WHEN %v THEN %s %s %s", sql, ot.Code, value, operator, renderLiteral(literal.literal))
+		} else {
+			sql = fmt.Sprintf("%s WHEN %v THEN %s %s %s", sql, ot.Code, renderLiteral(literal.literal), operator, value)
+		}
+	}
+
+	return fmt.Sprintf("%s ELSE %s END", sql, fallback)
+}
+
+// jsonTypeGuard makes an ordering comparison between a jsonb value and a literal type aware, jsonb
+// orders values of different types by type so 'a' gt 10 would be true, the guard only
+// compares values which have the same type as the literal
+func jsonTypeGuard(left, right *filterSQL, comparison string) string {
+	value, literal := left, right
+	if right.json {
+		value, literal = right, left
+	}
+
+	if !value.json || literal.literal == nil {
+		return comparison
+	}
+
+	jsonType := ""
+	switch literal.literal.Type {
+	case odata.LiteralNumber:
+		jsonType = "number"
+	case odata.LiteralString:
+		jsonType = "string"
+	case odata.LiteralBoolean:
+		jsonType = "boolean"
+	default:
+		return comparison
+	}
+
+	return fmt.Sprintf("(jsonb_typeof(%s) = '%s' AND %s)", value.sql, jsonType, comparison)
+}
+
+// mergeExists combines the subqueries of two operands, a subquery used by both operands
+// is added only once so both sides refer to the same related entity
+func mergeExists(left, right []string) []string {
+	merged := append([]string{}, left...)
+	for _, r := range right {
+		found := false
+		for _, l := range left {
+			if l == r {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			merged = append(merged, r)
+		}
+	}
+
+	return merged
+}
+
+// renderOperands renders both sides of a comparison, a jsonb value compared to a date time or
+// a calculated number is converted to a timestamp or numeric, a literal compared to a jsonb
+// value is converted to jsonb and a jsonb value compared to any other expression is converted to text
+func (fb *filterBuilder) renderOperands(left, right *filterSQL) (string, string) {
+	if left.json != right.json {
+		other := right
+		if right.json {
+			other = left
+		}
+
+		if other.valueType == odata.ValueTypeDateTime {
+			return fb.renderAs(left, odata.ValueTypeDateTime), fb.renderAs(right, odata.ValueTypeDateTime)
+		}
+
+		if other.valueType == odata.ValueTypeNumber && other.literal == nil {
+			return fb.renderAs(left, odata.ValueTypeNumber), fb.renderAs(right, odata.ValueTypeNumber)
+		}
+
+		if left.json && right.literal != nil {
+			return left.sql, renderJSONLiteral(right.literal)
+		}
+
+		if right.json && left.literal != nil {
+			return renderJSONLiteral(left.literal), right.sql
+		}
+
+		return fb.renderText(left), fb.renderText(right)
+	}
+
+	return fb.render(left), fb.render(right)
+}
+
+func (fb *filterBuilder) render(f *filterSQL) string {
+	if f.literal != nil {
+		return renderLiteral(f.literal)
+	}
+
+	return f.sql
+}
+
+// condition renders a boolean expression, placing it inside the EXISTS subqueries
+// needed for properties of related entities
+func (fb *filterBuilder) condition(f *filterSQL) string {
+	c := fb.render(f)
+	for i := len(f.exists) - 1; i >= 0; i-- {
+		c = fmt.Sprintf("EXISTS (%s %s)", f.exists[i], c)
+	}
+
+	return c
+}
+
+// renderText renders the given expression, jsonb values are converted to text
+func (fb *filterBuilder) renderText(f *filterSQL) string {
+	return fb.renderAs(f, odata.ValueTypeString)
+}
+
+// renderAs renders the given expression, jsonb values are converted to the given type. Time
+// intervals such as 2016-01-01T00:00:00Z/2016-01-02T00:00:00Z are converted to their start time
+func (fb *filterBuilder) renderAs(f *filterSQL, t odata.ValueType) string {
+	if !f.json {
+		return fb.render(f)
+	}
+
+	switch t {
+	case odata.ValueTypeDateTime:
+		return fmt.Sprintf("split_part(%s #>> '{}', '/', 1)::timestamptz", f.sql)
+	case odata.ValueTypeNumber:
+		return fmt.Sprintf("(%s #>> '{}')::numeric", f.sql)
+	}
+
+	return fmt.Sprintf("(%s #>> '{}')", f.sql)
+}
+
+func isNullLiteral(f *filterSQL) bool {
+	return f.literal != nil && f.literal.Type == odata.LiteralNull
+}
+
+// renderLiteral converts a literal to its PostgreSQL representation
+func renderLiteral(l *odata.LiteralExpression) string {
+	switch l.Type {
+	case odata.LiteralString:
+		return quoteLiteral(l.Raw)
+	case odata.LiteralBoolean:
+		return strings.ToUpper(l.Raw)
+	case odata.LiteralNull:
+		return "NULL"
+	case odata.LiteralDateTime:
+		return fmt.Sprintf("%s::timestamptz", quoteLiteral(l.Raw))
+	case odata.LiteralGeometry:
+		return fmt.Sprintf("public.ST_GeomFromEWKT(%s)", quoteLiteral(ewkt(l.Raw)))
+	}
+
+	return l.Raw
+}
+
+// ewkt returns the given well known text with an SRID, geometries without SRID are
+// assumed to use WGS84 like the geometries stored by GOST
+func ewkt(wkt string) string {
+	if strings.HasPrefix(strings.ToUpper(wkt), "SRID=") {
+		return wkt
+	}
+
+	return "SRID=4326;" + wkt
+}
+
+// renderJSONLiteral converts a literal to a jsonb value so it can be compared to a jsonb column,
+// date times are stored as strings inside json
+func renderJSONLiteral(l *odata.LiteralExpression) string {
+	switch l.Type {
+	case odata.LiteralString, odata.LiteralDateTime:
+		return fmt.Sprintf("to_jsonb(%s::text)", quoteLiteral(l.Raw))
+	case odata.LiteralNull:
+		return "'null'::jsonb"
+	}
+
+	return fmt.Sprintf("to_jsonb(%s)", renderLiteral(l))
+}
+
+// quoteLiteral returns the given value as a quoted PostgreSQL string, quotes inside the value are escaped
+func quoteLiteral(value string) string {
+	return fmt.Sprintf("'%s'", strings.Replace(value, "'", "''", -1))
+}
+
+// jsonFields lists the entity properties which are stored as jsonb, keys inside these
+// properties can be addressed with a path such as properties/owner
+var jsonFields = map[entities.EntityType][]string{
+	entities.EntityTypeThing:      {thingProperties},
+	entities.EntityTypeDatastream: {datastreamUnitOfMeasurement},
+	entities.EntityTypeObservation: {
+		observationPhenomenonTime,
+		observationResultTime,
+		observationResult,
+		observationValidTime,
+		observationResultQuality,
+		observationParameters,
+	},
+}
+
+// jsonPath returns the SQL to get the value found at the given keys inside a jsonb column
+func jsonPath(column string, keys []string) string {
+	quoted := make([]string, len(keys))
+	for i, k := range keys {
+		quoted[i] = quoteLiteral(k)
+	}
+
+	return fmt.Sprintf("%s #> ARRAY[%s]", column, strings.Join(quoted, ", "))
+}
+
+func isJSONField(et entities.EntityType, field string) bool {
+	for _, f := range jsonFields[et] {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// geometryFields maps the entity properties which are stored as PostGIS geometry onto their
+// column, selectMappings converts these columns to GeoJSON which can not be used in spatial functions
+var geometryFields = map[entities.EntityType]map[string]string{
+	entities.EntityTypeLocation:          {locationLocation: fmt.Sprintf("%s.%s", locationTable, locationLocation)},
+	entities.EntityTypeFeatureOfInterest: {foiFeature: fmt.Sprintf("%s.%s", featureOfInterestTable, foiFeature)},
+	entities.EntityTypeDatastream:        {datastreamObservedArea: fmt.Sprintf("%s.%s", datastreamTable, datastreamObservedArea)},
+}
+
+// selectMappingsResolver creates a FilterPropertyResolver which maps properties of the given
+// entity type onto the fields defined in selectMappings
+func selectMappingsResolver(et entities.EntityType) FilterPropertyResolver {
+	return func(path []string) (*FilterColumn, error) {
+		field := strings.ToLower(path[0])
+		if column, ok := geometryFields[et][field]; ok && len(path) == 1 {
+			return &FilterColumn{SQL: column, Type: odata.ValueTypeGeometry}, nil
+		}
+
+		column, ok := selectMappings[et][field]
+		if !ok {
+			return nil, fmt.Errorf("Property %s not supported for %s", path[0], et.ToString())
+		}
+
+		if len(path) > 1 {
+			if !isJSONField(et, field) {
+				return nil, fmt.Errorf("Property %s of %s has no keys, unable to use %s", path[0], et.ToString(), strings.Join(path, "/"))
+			}
+
+			return &FilterColumn{SQL: jsonPath(column, path[1:]), JSON: true}, nil
+		}
+
+		return &FilterColumn{SQL: column, JSON: isJSONField(et, field)}, nil
+	}
+}
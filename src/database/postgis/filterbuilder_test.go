@@ -0,0 +1,357 @@
+package postgis
+
+import (
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/odata"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCreateFilterQueryStringForObservations(t *testing.T) {
+	//arrange
+	qf := &odata.QueryFilter{}
+	qf.Parse("result gt 10 and (phenomenonTime ge 2016-01-01T00:00:00Z or not (id eq 1))")
+	qo := &odata.QueryOptions{QueryFilter: qf}
+
+	//act
+	sql, err := CreateFilterQueryString(qo, observationParamFactoryWhere, "WHERE ")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "WHERE ((jsonb_typeof(observation.data -> 'result') = 'number' AND observation.data -> 'result' > to_jsonb(10)) AND (split_part(observation.data -> 'phenomenonTime' #>> '{}', '/', 1)::timestamptz >= '2016-01-01T00:00:00Z'::timestamptz OR NOT (observation.id = 1))) ", sql)
+}
+
+func TestCreateFilterQueryStringShouldEscapeStrings(t *testing.T) {
+	//arrange
+	qf := &odata.QueryFilter{}
+	qf.Parse("name eq 'it''s' or description eq null")
+	qo := &odata.QueryOptions{QueryFilter: qf}
+
+	//act
+	sql, err := BuildFilter(qo.QueryFilter.Expression, selectMappingsResolver(entities.EntityTypeThing))
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "(thing.name = 'it''s' OR thing.description IS NULL)", sql)
+}
+
+func TestCreateFilterQueryStringWithUnknownPropertyShouldFail(t *testing.T) {
+	//arrange
+	qf := &odata.QueryFilter{}
+	qf.Parse("unknown eq 1")
+	qo := &odata.QueryOptions{QueryFilter: qf}
+
+	//act
+	_, err := CreateFilterQueryString(qo, observationParamFactoryWhere, "WHERE ")
+
+	//assert
+	assert.NotNil(t, err)
+}
+
+func TestFilterWithNavigationPathShouldCreateExists(t *testing.T) {
+	//arrange
+	qb := CreateQueryBuilder("v1", 1)
+	qf := &odata.QueryFilter{}
+	qf.Parse("Datastream/Thing/name eq 'a' and Datastream/name ne Datastream/Sensor/name")
+
+	//act
+	sql, err := BuildFilter(qf.Expression, qb.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere))
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "(EXISTS (SELECT 1 FROM v1.datastream WHERE datastream.id = observation.stream_id AND "+
+		"EXISTS (SELECT 1 FROM v1.thing WHERE thing.id = datastream.thing_id AND thing.name = 'a')) AND "+
+		"EXISTS (SELECT 1 FROM v1.datastream WHERE datastream.id = observation.stream_id AND "+
+		"EXISTS (SELECT 1 FROM v1.sensor WHERE sensor.id = datastream.sensor_id AND datastream.name != sensor.name)))", sql)
+}
+
+func TestFilterWithManyToManyNavigationPathShouldCreateExists(t *testing.T) {
+	//arrange
+	qb := CreateQueryBuilder("v1", 1)
+	qf := &odata.QueryFilter{}
+	qf.Parse("Locations/name eq 'home'")
+
+	//act
+	sql, err := BuildFilter(qf.Expression, qb.filterPropertyResolver(entities.EntityTypeThing, selectMappingsResolver(entities.EntityTypeThing)))
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "EXISTS (SELECT 1 FROM v1.location INNER JOIN v1.thing_to_location ON thing_to_location.location_id = location.id AND thing_to_location.thing_id = thing.id WHERE location.name = 'home')", sql)
+}
+
+func TestFilterWithUnknownNavigationPathShouldFail(t *testing.T) {
+	//arrange
+	qb := CreateQueryBuilder("v1", 1)
+	filters := []string{"Sensor/name eq 'a'", "Unknown/name eq 'a'", "Datastream/Observations/id eq 1", "Datastream/unknown eq 1"}
+
+	for _, filter := range filters {
+		qf := &odata.QueryFilter{}
+		qf.Parse(filter)
+
+		//act
+		_, err := BuildFilter(qf.Expression, qb.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere))
+
+		//assert
+		assert.NotNil(t, err, "Filter %s should give an error", filter)
+	}
+}
+
+func TestFilterWithStringFunctions(t *testing.T) {
+	//arrange
+	filters := map[string]string{
+		"startswith(name, 'a_b')":                  `thing.name LIKE 'a\_b%'`,
+		"endswith(name, 'it''s')":                  `thing.name LIKE '%it''s'`,
+		"substringof('room', name)":                `thing.name LIKE '%room%'`,
+		"contains(description, name)":              `strpos(thing.description, thing.name) > 0`,
+		"length(trim(name)) gt 3":                  `length(trim(thing.name)) > 3`,
+		"indexof(tolower(name), 'a') eq 0":         `(strpos(lower(thing.name), 'a') - 1) = 0`,
+		"substring(name, 1, 2) eq toupper('ab')":   `substr(thing.name, 1 + 1, 2) = upper('ab')`,
+		"concat(name, description) ne 'x'":         `(thing.name || thing.description) != 'x'`,
+		"not startswith(Datastreams/name, 'temp')": `NOT (EXISTS (SELECT 1 FROM v1.datastream WHERE datastream.thing_id = thing.id AND datastream.name LIKE 'temp%'))`,
+	}
+	qb := CreateQueryBuilder("v1", 1)
+
+	for filter, expected := range filters {
+		qf := &odata.QueryFilter{}
+		qf.Parse(filter)
+
+		//act
+		sql, err := BuildFilter(qf.Expression, qb.filterPropertyResolver(entities.EntityTypeThing, selectMappingsResolver(entities.EntityTypeThing)))
+
+		//assert
+		assert.Nil(t, err, "Filter %s should not give an error", filter)
+		assert.Equal(t, expected, sql)
+	}
+}
+
+func TestFilterWithStringFunctionOnJSONShouldUseText(t *testing.T) {
+	//arrange
+	qf := &odata.QueryFilter{}
+	qf.Parse("startswith(result, 'a')")
+
+	//act
+	sql, err := BuildFilter(qf.Expression, observationParamFactoryWhere)
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "(observation.data -> 'result' #>> '{}') LIKE 'a%'", sql)
+}
+
+func TestFilterWithDateFunctions(t *testing.T) {
+	//arrange
+	filters := map[string]string{
+		"hour(phenomenonTime) ge 8":        `extract(hour FROM split_part(observation.data -> 'phenomenonTime' #>> '{}', '/', 1)::timestamptz AT TIME ZONE 'UTC') >= 8`,
+		"year(resultTime) eq 2026":         `extract(year FROM split_part(observation.data -> 'resultTime' #>> '{}', '/', 1)::timestamptz AT TIME ZONE 'UTC') = 2026`,
+		"second(resultTime) eq 1":          `floor(extract(second FROM split_part(observation.data -> 'resultTime' #>> '{}', '/', 1)::timestamptz AT TIME ZONE 'UTC')) = 1`,
+		"date(resultTime) eq date(now())":  `(split_part(observation.data -> 'resultTime' #>> '{}', '/', 1)::timestamptz AT TIME ZONE 'UTC')::date = (now() AT TIME ZONE 'UTC')::date`,
+		"time(resultTime) lt '08:00:00'":   `(split_part(observation.data -> 'resultTime' #>> '{}', '/', 1)::timestamptz AT TIME ZONE 'UTC')::time < '08:00:00'`,
+		"resultTime lt now()":              `split_part(observation.data -> 'resultTime' #>> '{}', '/', 1)::timestamptz < now()`,
+		"resultTime gt mindatetime()":      `split_part(observation.data -> 'resultTime' #>> '{}', '/', 1)::timestamptz > '0001-01-01T00:00:00Z'::timestamptz`,
+		"month(2016-05-01T00:00:00Z) eq 5": `extract(month FROM '2016-05-01T00:00:00Z'::timestamptz AT TIME ZONE 'UTC') = 5`,
+	}
+
+	for filter, expected := range filters {
+		qf := &odata.QueryFilter{}
+		qf.Parse(filter)
+
+		//act
+		sql, err := BuildFilter(qf.Expression, observationParamFactoryWhere)
+
+		//assert
+		assert.Nil(t, err, "Filter %s should not give an error", filter)
+		assert.Equal(t, expected, sql)
+	}
+}
+
+func TestFilterWithArithmetic(t *testing.T) {
+	//arrange
+	filters := map[string]string{
+		"result mul 1.8 add 32 gt 90":       `(((observation.data -> 'result' #>> '{}')::numeric * 1.8) + 32) > 90`,
+		"round(result) eq 21":               `round((observation.data -> 'result' #>> '{}')::numeric) = 21`,
+		"result eq floor(result)":           `(observation.data -> 'result' #>> '{}')::numeric = floor((observation.data -> 'result' #>> '{}')::numeric)`,
+		"ceiling(result div 2) le id mod 3": `ceil(((observation.data -> 'result' #>> '{}')::numeric / 2)) <= (observation.id % 3)`,
+		"result sub 1 ne 0":                 `((observation.data -> 'result' #>> '{}')::numeric - 1) != 0`,
+	}
+
+	for filter, expected := range filters {
+		qf := &odata.QueryFilter{}
+		qf.Parse(filter)
+
+		//act
+		sql, err := BuildFilter(qf.Expression, observationParamFactoryWhere)
+
+		//assert
+		assert.Nil(t, err, "Filter %s should not give an error", filter)
+		assert.Equal(t, expected, sql)
+	}
+}
+
+func TestFilterWithGeospatialFunctions(t *testing.T) {
+	//arrange
+	filters := map[entities.EntityType]map[string]string{
+		entities.EntityTypeLocation: {
+			"geo.intersects(location, geography'POLYGON((0 0, 0 10, 10 10, 0 0))')": `public.ST_Intersects(location.location, public.ST_GeomFromEWKT('SRID=4326;POLYGON((0 0, 0 10, 10 10, 0 0))'))`,
+			"geo.distance(location, geography'POINT(5 52)') lt 1000":                `public.ST_Distance(location.location::geography, public.ST_GeomFromEWKT('SRID=4326;POINT(5 52)')::geography) < 1000`,
+			"st_within(location, geometry'SRID=28992;POINT(1 2)')":                  `public.ST_Within(location.location, public.ST_GeomFromEWKT('SRID=28992;POINT(1 2)'))`,
+			"st_relate(location, geography'POINT(5 52)', 'T********')":              `public.ST_Relate(location.location, public.ST_GeomFromEWKT('SRID=4326;POINT(5 52)'), 'T********')`,
+		},
+		entities.EntityTypeFeatureOfInterest: {
+			"st_contains(feature, geography'POINT(5 52)')": `public.ST_Contains(featureofinterest.feature, public.ST_GeomFromEWKT('SRID=4326;POINT(5 52)'))`,
+		},
+		entities.EntityTypeDatastream: {
+			"geo.length(observedArea) gt 10": `public.ST_Length(datastream.observedarea::geography) > 10`,
+		},
+		entities.EntityTypeObservation: {
+			"st_disjoint(FeatureOfInterest/feature, geography'POINT(5 52)')": `EXISTS (SELECT 1 FROM v1.featureofinterest WHERE featureofinterest.id = observation.featureofinterest_id AND public.ST_Disjoint(featureofinterest.feature, public.ST_GeomFromEWKT('SRID=4326;POINT(5 52)')))`,
+		},
+	}
+	qb := CreateQueryBuilder("v1", 1)
+
+	for et, f := range filters {
+		for filter, expected := range f {
+			qf := &odata.QueryFilter{}
+			qf.Parse(filter)
+
+			//act
+			sql, err := BuildFilter(qf.Expression, qb.filterPropertyResolver(et, selectMappingsResolver(et)))
+
+			//assert
+			assert.Nil(t, err, "Filter %s should not give an error", filter)
+			assert.Equal(t, expected, sql)
+		}
+	}
+}
+
+func TestFilterOnJSONKeys(t *testing.T) {
+	//arrange
+	filters := map[entities.EntityType]map[string]string{
+		entities.EntityTypeThing: {
+			"properties/owner eq 'Bert'":                   `thing.properties #> ARRAY['owner'] = to_jsonb('Bert'::text)`,
+			"properties/building/floor gt 3":               `(jsonb_typeof(thing.properties #> ARRAY['building', 'floor']) = 'number' AND thing.properties #> ARRAY['building', 'floor'] > to_jsonb(3))`,
+			"tolower(properties/owner) eq 'bert'":          `lower((thing.properties #> ARRAY['owner'] #>> '{}')) = 'bert'`,
+			"Datastreams/unitOfMeasurement/symbol eq '°C'": `EXISTS (SELECT 1 FROM v1.datastream WHERE datastream.thing_id = thing.id AND datastream.unitofmeasurement #> ARRAY['symbol'] = to_jsonb('°C'::text))`,
+		},
+		entities.EntityTypeObservation: {
+			"parameters/x ne null":                     `observation.data -> 'parameters' #> ARRAY['x'] IS NOT NULL`,
+			"parameters/x_y eq true":                   `observation.data -> 'parameters' #> ARRAY['x_y'] = to_jsonb(TRUE)`,
+			"Datastream/Thing/properties/owner le 'B'": `EXISTS (SELECT 1 FROM v1.datastream WHERE datastream.id = observation.stream_id AND EXISTS (SELECT 1 FROM v1.thing WHERE thing.id = datastream.thing_id AND (jsonb_typeof(thing.properties #> ARRAY['owner']) = 'string' AND thing.properties #> ARRAY['owner'] <= to_jsonb('B'::text))))`,
+		},
+	}
+	qb := CreateQueryBuilder("v1", 1)
+	resolvers := map[entities.EntityType]FilterPropertyResolver{
+		entities.EntityTypeThing:       qb.filterPropertyResolver(entities.EntityTypeThing, selectMappingsResolver(entities.EntityTypeThing)),
+		entities.EntityTypeObservation: qb.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere),
+	}
+
+	for et, f := range filters {
+		for filter, expected := range f {
+			qf := &odata.QueryFilter{}
+			err := qf.Parse(filter)
+			assert.Nil(t, err, "Filter %s should be parsed", filter)
+
+			//act
+			sql, err := BuildFilter(qf.Expression, resolvers[et])
+
+			//assert
+			assert.Nil(t, err, "Filter %s should not give an error", filter)
+			assert.Equal(t, expected, sql)
+		}
+	}
+}
+
+func TestFilterOnResultShouldUseObservationType(t *testing.T) {
+	//arrange
+	observationType := "(SELECT datastream.observationtype FROM v1.datastream WHERE datastream.id = observation.stream_id)"
+	filters := map[string]string{
+		"result gt 20": "CASE " + observationType + " WHEN 2 THEN (observation.data -> 'result' #>> '{}')::bigint > 20 " +
+			"WHEN 3 THEN (observation.data -> 'result' #>> '{}')::double precision > 20 " +
+			"ELSE (jsonb_typeof(observation.data -> 'result') = 'number' AND observation.data -> 'result' > to_jsonb(20)) END",
+		"true eq result": "CASE " + observationType + " WHEN 5 THEN TRUE = (observation.data -> 'result' #>> '{}')::boolean " +
+			"ELSE to_jsonb(TRUE) = observation.data -> 'result' END",
+		"result eq 'http://example.org/red'": "CASE " + observationType + " WHEN 1 THEN (observation.data -> 'result' #>> '{}')::text = 'http://example.org/red' " +
+			"ELSE observation.data -> 'result' = to_jsonb('http://example.org/red'::text) END",
+		"result/temperature gt 20": `(jsonb_typeof(observation.data -> 'result' #> ARRAY['temperature']) = 'number' AND observation.data -> 'result' #> ARRAY['temperature'] > to_jsonb(20))`,
+		"result eq null":           `observation.data -> 'result' IS NULL`,
+	}
+	qb := CreateQueryBuilder("v1", 1)
+	resolver := qb.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere)
+
+	for filter, expected := range filters {
+		qf := &odata.QueryFilter{}
+		err := qf.Parse(filter)
+		assert.Nil(t, err, "Filter %s should be parsed", filter)
+
+		//act
+		sql, err := BuildFilter(qf.Expression, resolver)
+
+		//assert
+		assert.Nil(t, err, "Filter %s should not give an error", filter)
+		assert.Equal(t, expected, sql)
+	}
+}
+
+func TestFilterOnKeysOfNonJSONPropertyShouldFail(t *testing.T) {
+	//arrange
+	qf := &odata.QueryFilter{}
+	qf.Parse("name/first eq 'a'")
+
+	//act
+	_, err := BuildFilter(qf.Expression, selectMappingsResolver(entities.EntityTypeThing))
+
+	//assert
+	assert.NotNil(t, err)
+}
+
+func TestCreateOrderByQueryString(t *testing.T) {
+	//arrange
+	qb := CreateQueryBuilder("v1", 1)
+	resolver := qb.filterPropertyResolver(entities.EntityTypeThing, selectMappingsResolver(entities.EntityTypeThing))
+	orderBy := &odata.QueryOrderBy{}
+	orderBy.Parse("properties/owner desc")
+
+	//act
+	defaultSQL, err1 := CreateOrderByQueryString(&odata.QueryOptions{}, resolver, "id DESC")
+	sql, err2 := CreateOrderByQueryString(&odata.QueryOptions{QueryOrderBy: orderBy}, resolver, "id DESC")
+
+	//assert
+	assert.Nil(t, err1)
+	assert.Nil(t, err2)
+	assert.Equal(t, "id DESC", defaultSQL)
+	assert.Equal(t, "thing.properties #> ARRAY['owner'] DESC", sql)
+}
+
+func TestCreateOrderByQueryStringWithMultipleKeysAndNavigation(t *testing.T) {
+	//arrange
+	qb := CreateQueryBuilder("v1", 1)
+	resolver := qb.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere)
+	orderBy := &odata.QueryOrderBy{}
+	orderBy.Parse("Datastream/Thing/name,phenomenonTime desc,id")
+	manyOrderBy := &odata.QueryOrderBy{}
+	manyOrderBy.Parse("Datastream/Observations/id")
+
+	//act
+	sql, err := CreateOrderByQueryString(&odata.QueryOptions{QueryOrderBy: orderBy}, resolver, "id DESC")
+	_, err2 := CreateOrderByQueryString(&odata.QueryOptions{QueryOrderBy: manyOrderBy}, resolver, "id DESC")
+
+	//assert
+	assert.Nil(t, err)
+	assert.NotNil(t, err2, "Ordering through a navigation path leading to multiple entities should fail")
+	assert.Equal(t, "(SELECT (SELECT thing.name FROM v1.thing WHERE thing.id = datastream.thing_id) FROM v1.datastream WHERE datastream.id = observation.stream_id) ASC, "+
+		"observation.data -> 'phenomenonTime' DESC, observation.id ASC", sql)
+}
+
+func TestCreateNavigationQuery(t *testing.T) {
+	//arrange
+	qb := CreateQueryBuilder("v1", 1)
+
+	//act
+	sql, err := qb.CreateNavigationQuery(entities.EntityTypeThing, 1, entities.EntityTypeDatastream, 99)
+	single, _ := qb.CreateNavigationQuery(entities.EntityTypeDatastream, 99, entities.EntityTypeThing, nil)
+	_, err2 := qb.CreateNavigationQuery(entities.EntityTypeThing, 1, entities.EntityTypeSensor, nil)
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "SELECT datastream.id FROM v1.datastream WHERE datastream.id = 99 AND EXISTS (SELECT 1 FROM v1.thing WHERE thing.id = datastream.thing_id AND thing.id = 1) LIMIT 1", sql)
+	assert.Equal(t, "SELECT thing.id FROM v1.thing WHERE EXISTS (SELECT 1 FROM v1.datastream WHERE datastream.thing_id = thing.id AND datastream.id = 99) LIMIT 1", single)
+	assert.NotNil(t, err2)
+}
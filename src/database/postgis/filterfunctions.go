@@ -0,0 +1,181 @@
+package postgis
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// functionTranslator converts the rendered arguments of an OData function into a PostgreSQL expression
+type functionTranslator func(fb *filterBuilder, args []*filterSQL) (string, error)
+
+// filterFunctions maps the supported OData functions to their PostgreSQL translation
+var filterFunctions = map[string]functionTranslator{
+	odata.OSSubstringOf.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fb.containsSQL(args[1], args[0]), nil
+	},
+	odata.OSContains.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fb.containsSQL(args[0], args[1]), nil
+	},
+	odata.OSStartsWith.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		if isStringLiteral(args[1]) {
+			return fmt.Sprintf("%s LIKE %s", fb.renderText(args[0]), quoteLiteral(escapeLike(args[1].literal.Raw)+"%")), nil
+		}
+		return fmt.Sprintf("strpos(%s, %s) = 1", fb.renderText(args[0]), fb.renderText(args[1])), nil
+	},
+	odata.OSEndsWith.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		if isStringLiteral(args[1]) {
+			return fmt.Sprintf("%s LIKE %s", fb.renderText(args[0]), quoteLiteral("%"+escapeLike(args[1].literal.Raw))), nil
+		}
+		return fmt.Sprintf("right(%s, length(%s)) = %s", fb.renderText(args[0]), fb.renderText(args[1]), fb.renderText(args[1])), nil
+	},
+	odata.OSLength.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("length(%s)", fb.renderText(args[0])), nil
+	},
+	odata.OSIndexOf.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		// OData indexes are zero based, strpos returns 0 when not found so indexof results in -1
+		return fmt.Sprintf("(strpos(%s, %s) - 1)", fb.renderText(args[0]), fb.renderText(args[1])), nil
+	},
+	odata.OSSubstring.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		if len(args) == 3 {
+			return fmt.Sprintf("substr(%s, %s + 1, %s)", fb.renderText(args[0]), fb.renderText(args[1]), fb.renderText(args[2])), nil
+		}
+		return fmt.Sprintf("substr(%s, %s + 1)", fb.renderText(args[0]), fb.renderText(args[1])), nil
+	},
+	odata.OSToLower.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("lower(%s)", fb.renderText(args[0])), nil
+	},
+	odata.OSToUpper.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("upper(%s)", fb.renderText(args[0])), nil
+	},
+	odata.OSTrim.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("trim(%s)", fb.renderText(args[0])), nil
+	},
+	odata.OSConcat.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("(%s || %s)", fb.renderText(args[0]), fb.renderText(args[1])), nil
+	},
+
+	odata.ODYear.ToString():   datePartTranslator("year"),
+	odata.ODMonth.ToString():  datePartTranslator("month"),
+	odata.ODDay.ToString():    datePartTranslator("day"),
+	odata.ODHour.ToString():   datePartTranslator("hour"),
+	odata.ODMinute.ToString(): datePartTranslator("minute"),
+	odata.ODSecond.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("floor(extract(second FROM %s))", fb.renderUTC(args[0])), nil
+	},
+	odata.ODFractionalSeconds.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		second := fmt.Sprintf("extract(second FROM %s)", fb.renderUTC(args[0]))
+		return fmt.Sprintf("(%s - floor(%s))", second, second), nil
+	},
+	odata.ODDate.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("(%s)::date", fb.renderUTC(args[0])), nil
+	},
+	odata.ODTime.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("(%s)::time", fb.renderUTC(args[0])), nil
+	},
+	odata.ODNow.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return "now()", nil
+	},
+	odata.ODMinDateTime.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return "'0001-01-01T00:00:00Z'::timestamptz", nil
+	},
+	odata.ODMaxDateTime.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return "'9999-12-31T23:59:59.999Z'::timestamptz", nil
+	},
+	odata.OMRound.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("round(%s)", fb.renderAs(args[0], odata.ValueTypeNumber)), nil
+	},
+	odata.OMFloor.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("floor(%s)", fb.renderAs(args[0], odata.ValueTypeNumber)), nil
+	},
+	odata.OMCeiling.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("ceil(%s)", fb.renderAs(args[0], odata.ValueTypeNumber)), nil
+	},
+	// distances and lengths are calculated on the spheroid and returned in meters
+	odata.OGSDistance.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("public.ST_Distance(%s::geography, %s::geography)", fb.render(args[0]), fb.render(args[1])), nil
+	},
+	odata.OGSLength.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("public.ST_Length(%s::geography)", fb.render(args[0])), nil
+	},
+	odata.OGSIntersects.ToString(): spatialTranslator("ST_Intersects"),
+	odata.OSREquals.ToString():     spatialTranslator("ST_Equals"),
+	odata.OSRDisjoint.ToString():   spatialTranslator("ST_Disjoint"),
+	odata.OSRTouches.ToString():    spatialTranslator("ST_Touches"),
+	odata.OSRWithin.ToString():     spatialTranslator("ST_Within"),
+	odata.OSROverlaps.ToString():   spatialTranslator("ST_Overlaps"),
+	odata.OSRCrosses.ToString():    spatialTranslator("ST_Crosses"),
+	odata.OSRIntersects.ToString(): spatialTranslator("ST_Intersects"),
+	odata.OSRContains.ToString():   spatialTranslator("ST_Contains"),
+	odata.OSRRelate.ToString(): func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("public.ST_Relate(%s, %s, %s)", fb.render(args[0]), fb.render(args[1]), fb.renderText(args[2])), nil
+	},
+}
+
+// spatialTranslator creates a functionTranslator calling the given PostGIS spatial relationship function
+func spatialTranslator(function string) functionTranslator {
+	return func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("public.%s(%s, %s)", function, fb.render(args[0]), fb.render(args[1])), nil
+	}
+}
+
+// datePartTranslator creates a functionTranslator extracting the given field from a date time
+func datePartTranslator(field string) functionTranslator {
+	return func(fb *filterBuilder, args []*filterSQL) (string, error) {
+		return fmt.Sprintf("extract(%s FROM %s)", field, fb.renderUTC(args[0])), nil
+	}
+}
+
+func (fb *filterBuilder) translateFunction(f *odata.FunctionExpression) (*filterSQL, error) {
+	translator, ok := filterFunctions[f.Name]
+	if !ok {
+		return nil, fmt.Errorf("Function %s is not supported in $filter", f.Name)
+	}
+
+	args := make([]*filterSQL, len(f.Arguments))
+	exists := make([]string, 0)
+	for i, a := range f.Arguments {
+		arg, err := fb.translate(a)
+		if err != nil {
+			return nil, err
+		}
+
+		args[i] = arg
+		exists = mergeExists(exists, arg.exists)
+	}
+
+	sql, err := translator(fb, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &filterSQL{sql: sql, exists: exists, valueType: odata.ExpressionType(f)}, nil
+}
+
+// renderUTC renders a date time argument as timestamp in UTC so date parts do not depend
+// on the time zone of the database session
+func (fb *filterBuilder) renderUTC(f *filterSQL) string {
+	return fmt.Sprintf("%s AT TIME ZONE 'UTC'", fb.renderAs(f, odata.ValueTypeDateTime))
+}
+
+// containsSQL returns a condition checking if value contains search, a literal search
+// string is translated into a LIKE pattern
+func (fb *filterBuilder) containsSQL(value, search *filterSQL) string {
+	if isStringLiteral(search) {
+		return fmt.Sprintf("%s LIKE %s", fb.renderText(value), quoteLiteral("%"+escapeLike(search.literal.Raw)+"%"))
+	}
+
+	return fmt.Sprintf("strpos(%s, %s) > 0", fb.renderText(value), fb.renderText(search))
+}
+
+func isStringLiteral(f *filterSQL) bool {
+	return f.literal != nil && f.literal.Type == odata.LiteralString
+}
+
+// escapeLike escapes the characters with a special meaning inside a LIKE pattern
+func escapeLike(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, "%", `\%`, -1)
+	return strings.Replace(value, "_", `\_`, -1)
+}
@@ -1,7 +1,6 @@
 package postgis
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 	"time"
@@ -68,8 +67,20 @@ func (gdb *GostDatabase) GetHistoricalLocationsByLocation(locationID interface{}
 	if !ok {
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Location does not exist"))
 	}
-	query := fmt.Sprintf("select "+CreateSelectString(&entities.HistoricalLocation{}, qo, "", "", hlMapping)+" FROM %s.historicallocation inner join %s.location_to_historicallocation on location_to_historicallocation.historicallocation_id = historicallocation.id where location_to_historicallocation.location_id = %v order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema, gdb.Schema, intID)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.historicallocation inner join %s.location_to_historicallocation on location_to_historicallocation.historicallocation_id = historicallocation.id where location_to_historicallocation.location_id = %v", gdb.Schema, gdb.Schema, intID)
+
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeHistoricalLocation, selectMappingsResolver(entities.EntityTypeHistoricalLocation))
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "historicallocation.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	query := fmt.Sprintf("select "+CreateSelectString(&entities.HistoricalLocation{}, qo, "", "", hlMapping)+" FROM %s.historicallocation inner join %s.location_to_historicallocation on location_to_historicallocation.historicallocation_id = historicallocation.id where location_to_historicallocation.location_id = %v %sorder by %s"+CreateTopSkipQueryString(qo), gdb.Schema, gdb.Schema, intID, queryString, orderBy)
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.historicallocation inner join %s.location_to_historicallocation on location_to_historicallocation.historicallocation_id = historicallocation.id where location_to_historicallocation.location_id = %v %s", gdb.Schema, gdb.Schema, intID, queryString)
 	return processHistoricalLocations(gdb.Db, query, qo, countSQL)
 }
 
@@ -79,12 +90,24 @@ func (gdb *GostDatabase) GetHistoricalLocationsByThing(thingID interface{}, qo *
 	if !ok {
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Thing does not exist"))
 	}
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.HistoricalLocation{}, qo, "", "", hlMapping)+" FROM %s.historicallocation where thing_id = %v order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema, intID)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.historicallocation where thing_id = %v", gdb.Schema, intID)
+
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeHistoricalLocation, selectMappingsResolver(entities.EntityTypeHistoricalLocation))
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "historicallocation.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.HistoricalLocation{}, qo, "", "", hlMapping)+" FROM %s.historicallocation where thing_id = %v %sorder by %s"+CreateTopSkipQueryString(qo), gdb.Schema, intID, queryString, orderBy)
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.historicallocation where thing_id = %v %s", gdb.Schema, intID, queryString)
 	return processHistoricalLocations(gdb.Db, sql, qo, countSQL)
 }
 
-func processHistoricalLocation(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.HistoricalLocation, error) {
+func processHistoricalLocation(db Executor, sql string, qo *odata.QueryOptions) (*entities.HistoricalLocation, error) {
 	hls, _, err := processHistoricalLocations(db, sql, qo, "")
 	if err != nil {
 		return nil, err
@@ -97,13 +120,12 @@ func processHistoricalLocation(db *sql.DB, sql string, qo *odata.QueryOptions) (
 	return hls[0], nil
 }
 
-func processHistoricalLocations(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.HistoricalLocation, int, error) {
+func processHistoricalLocations(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.HistoricalLocation, int, error) {
 	rows, err := db.Query(sql)
-	defer rows.Close()
-
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, filterQueryError(err)
 	}
+	defer rows.Close()
 
 	var hls = []*entities.HistoricalLocation{}
 	for rows.Next() {
@@ -111,13 +133,7 @@ func processHistoricalLocations(db *sql.DB, sql string, qo *odata.QueryOptions,
 		var time string
 
 		var params []interface{}
-		var qp []string
-		if qo == nil || qo.QuerySelect == nil || len(qo.QuerySelect.Params) == 0 {
-			s := &entities.HistoricalLocation{}
-			qp = s.GetPropertyNames()
-		} else {
-			qp = qo.QuerySelect.Params
-		}
+		qp := selectedProperties(&entities.HistoricalLocation{}, qo)
 
 		for _, p := range qp {
 			p = strings.ToLower(p)
@@ -129,7 +145,9 @@ func processHistoricalLocations(db *sql.DB, sql string, qo *odata.QueryOptions,
 			}
 		}
 
-		err = rows.Scan(params...)
+		if err = rows.Scan(params...); err != nil {
+			return nil, 0, err
+		}
 
 		datastream := entities.HistoricalLocation{}
 		datastream.ID = id
@@ -138,9 +156,13 @@ func processHistoricalLocations(db *sql.DB, sql string, qo *odata.QueryOptions,
 		hls = append(hls, &datastream)
 	}
 
-	var count int
-	if len(countSQL) > 0 {
-		db.QueryRow(countSQL).Scan(&count)
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
 	}
 
 	return hls, count, nil
@@ -186,67 +208,27 @@ func (gdb *GostDatabase) HistoricalLocationExists(id interface{}) bool {
 	return EntityExists(gdb, id, "historicallocation")
 }
 
-// PutHistoricalLocation updates a HistoricalLocation in the database
+// PutHistoricalLocation replaces a HistoricalLocation in the database, the given Locations replace the
+// Locations linked to the HistoricalLocation
 func (gdb *GostDatabase) PutHistoricalLocation(id interface{}, hl *entities.HistoricalLocation) (*entities.HistoricalLocation, error) {
-	return gdb.PatchHistoricalLocation(id, hl)
-	/*
-		var ok bool
-		var intID int
-		if intID, ok = ToIntID(id); !ok || !gdb.HistoricalLocationExists(intID) {
-			return nil, gostErrors.NewRequestNotFound(errors.New("HistoricalLocation does not exist"))
-		}
-
-		if hl.Thing != nil {
-			tid, ok := ToIntID(hl.Thing.ID)
-			if !ok || !gdb.ThingExists(tid) {
-				return nil, gostErrors.NewRequestNotFound(errors.New("Thing does not exist"))
-			}
-
-			query := fmt.Sprintf("UPDATE %s.historicallocation set thing_id=$1 where id = $2", gdb.Schema)
-			_, err := gdb.Db.Exec(query, intID)
-			if err != nil {
-				return nil, err
-			}
-		}
-
-		if len(hl.Locations) > 0 {
-			for _, l := range hl.Locations {
-				lid, ok := ToIntID(l.ID)
-				if !ok || !gdb.LocationExists(lid) {
-					return nil, gostErrors.NewRequestNotFound(errors.New("Location does not exist"))
-				}
-			}
-
-			query := fmt.Sprintf("DELETE FROM %s.location_to_historicallocation WHERE historicallocation_id = $1", gdb.Schema)
-			_, err := gdb.Db.Exec(query, intID)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, l := range hl.Locations {
-				query = fmt.Sprintf("INSERT INTO %s.location_to_historicallocation (location_id, historicallocation_id) VALUES ($1, $2)", gdb.Schema)
-				_, err = gdb.Db.Exec(query, l.ID, intID)
-				if err != nil {
-					return nil, err
-				}
-			}
-		}
+	relations := map[entities.EntityType]interface{}{}
+	if hl.Thing != nil {
+		relations[entities.EntityTypeThing] = hl.Thing.ID
+	}
 
-		if len(hl.Time) > 0 {
-			t, _ := time.Parse(time.RFC3339Nano, hl.Time)
-			utcT := t.UTC().Format("2006-01-02T15:04:05.000Z")
+	intID, err := gdb.putEntity(entities.EntityTypeHistoricalLocation, id, nil, relations)
+	if err != nil {
+		return nil, err
+	}
 
-			query := fmt.Sprintf("UPDATE %s.historicallocation set time=$1 where id = $2", gdb.Schema)
-			_, err := gdb.Db.Exec(query, utcT, intID)
-			if err != nil {
-				return nil, err
-			}
+	if len(hl.Locations) > 0 {
+		query := fmt.Sprintf("DELETE FROM %s.location_to_historicallocation WHERE historicallocation_id = $1", gdb.Schema)
+		if _, err = gdb.Db.Exec(query, intID); err != nil {
+			return nil, err
 		}
+	}
 
-		hl.ID = intID
-
-		return hl, nil
-	*/
+	return gdb.PatchHistoricalLocation(intID, &entities.HistoricalLocation{Time: hl.Time, Locations: hl.Locations})
 }
 
 // PatchHistoricalLocation updates a HistoricalLocation in the database
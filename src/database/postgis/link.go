@@ -0,0 +1,135 @@
+package postgis
+
+import (
+	"fmt"
+	"strings"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+)
+
+// linkColumns holds for each entity the columns referencing a related entity, the relation is changed
+// by updating the column of the entity holding the reference
+var linkColumns = map[entities.EntityType]map[entities.EntityType]string{
+	entities.EntityTypeDatastream: {
+		entities.EntityTypeThing:            datastreamThingID,
+		entities.EntityTypeSensor:           datastreamSensorID,
+		entities.EntityTypeObservedProperty: datastreamObservedPropertyID,
+	},
+	entities.EntityTypeMultiDatastream: {
+		entities.EntityTypeThing:  multiDatastreamThingID,
+		entities.EntityTypeSensor: multiDatastreamSensorID,
+	},
+	entities.EntityTypeObservation: {
+		entities.EntityTypeDatastream:        observationStreamID,
+		entities.EntityTypeMultiDatastream:   observationMultiDatastreamID,
+		entities.EntityTypeFeatureOfInterest: observationFeatureOfInterestID,
+	},
+	entities.EntityTypeHistoricalLocation: {
+		entities.EntityTypeThing: historicalLocationThingID,
+	},
+	entities.EntityTypeTaskingCapability: {
+		entities.EntityTypeThing:    taskingCapabilityThingID,
+		entities.EntityTypeActuator: taskingCapabilityActuatorID,
+	},
+	entities.EntityTypeTask: {
+		entities.EntityTypeTaskingCapability: taskTaskingCapabilityID,
+	},
+}
+
+// linkTables holds the tables linking entities in a many to many relation with the column of each entity,
+// the relation between a MultiDatastream and its ObservedProperties is not listed since it depends on the rank
+var linkTables = map[entities.EntityType]map[entities.EntityType]string{
+	entities.EntityTypeThingToLocation: {
+		entities.EntityTypeThing:    thingToLocationThingID,
+		entities.EntityTypeLocation: thingToLocationLocationID,
+	},
+	entities.EntityTypeLocationToHistoricalLocation: {
+		entities.EntityTypeLocation:           strings.TrimSpace(locationToHistoricalLocationLocationID),
+		entities.EntityTypeHistoricalLocation: strings.TrimSpace(locationToHistoricalLocationHistoricalLocationID),
+	},
+}
+
+// getLinkTable returns the table linking the given entity types and the columns holding their ids
+func getLinkTable(et1, et2 entities.EntityType) (string, string, string, bool) {
+	for t, columns := range linkTables {
+		c1, ok1 := columns[et1]
+		c2, ok2 := columns[et2]
+		if ok1 && ok2 {
+			return tableMappings[t], c1, c2, true
+		}
+	}
+
+	return "", "", "", false
+}
+
+// checkLinkedEntities converts the given ids and checks if both entities exist
+func (gdb *GostDatabase) checkLinkedEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (int, int, error) {
+	pID, ok := ToIntID(parentID)
+	if !ok || !EntityExists(gdb, pID, tableMappings[parent]) {
+		return 0, 0, gostErrors.NewRequestNotFound(fmt.Errorf("%s does not exist", parent.ToString()))
+	}
+
+	cID, ok := ToIntID(childID)
+	if !ok || !EntityExists(gdb, cID, tableMappings[child]) {
+		return 0, 0, gostErrors.NewRequestNotFound(fmt.Errorf("%s does not exist", child.ToString()))
+	}
+
+	return pID, cID, nil
+}
+
+// LinkEntities links the child entity to the parent entity, a single valued relation is replaced by updating
+// the referencing column, for many to many relations a record is added to the link table if not yet present.
+// linked is false when no record was added because the entities were already linked
+func (gdb *GostDatabase) LinkEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (bool, error) {
+	pID, cID, err := gdb.checkLinkedEntities(parent, parentID, child, childID)
+	if err != nil {
+		return false, err
+	}
+
+	var query string
+	if column, ok := linkColumns[parent][child]; ok {
+		query = fmt.Sprintf("UPDATE %s.%s SET %s = %v WHERE id = %v", gdb.Schema, tableMappings[parent], column, cID, pID)
+	} else if column, ok := linkColumns[child][parent]; ok {
+		query = fmt.Sprintf("UPDATE %s.%s SET %s = %v WHERE id = %v", gdb.Schema, tableMappings[child], column, pID, cID)
+	} else if table, pColumn, cColumn, ok := getLinkTable(parent, child); ok {
+		query = fmt.Sprintf("INSERT INTO %s.%s (%s, %s) SELECT %v, %v WHERE NOT EXISTS (SELECT 1 FROM %s.%s WHERE %s = %v AND %s = %v)",
+			gdb.Schema, table, pColumn, cColumn, pID, cID, gdb.Schema, table, pColumn, pID, cColumn, cID)
+	} else {
+		return false, gostErrors.NewBadRequestError(fmt.Errorf("Unable to link %s to %s", child.ToString(), parent.ToString()))
+	}
+
+	r, err := gdb.Db.Exec(query)
+	if err != nil {
+		return false, err
+	}
+
+	c, _ := r.RowsAffected()
+	return c > 0, nil
+}
+
+// UnlinkEntities removes the link between the parent and child entity, only links of many to many relations
+// can be removed, other relations are mandatory and can only be replaced
+func (gdb *GostDatabase) UnlinkEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) error {
+	table, pColumn, cColumn, ok := getLinkTable(parent, child)
+	if !ok {
+		return gostErrors.NewBadRequestError(fmt.Errorf("Unable to remove the mandatory link between %s and %s", parent.ToString(), child.ToString()))
+	}
+
+	pID, cID, err := gdb.checkLinkedEntities(parent, parentID, child, childID)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s.%s WHERE %s = $1 AND %s = $2", gdb.Schema, table, pColumn, cColumn)
+	r, err := gdb.Db.Exec(query, pID, cID)
+	if err != nil {
+		return err
+	}
+
+	if c, _ := r.RowsAffected(); c == 0 {
+		return gostErrors.NewRequestNotFound(fmt.Errorf("%s(%v) is not linked to %s(%v)", child.ToString(), cID, parent.ToString(), pID))
+	}
+
+	return nil
+}
@@ -6,7 +6,6 @@ import (
 
 	"github.com/geodan/gost/src/sensorthings/entities"
 
-	"database/sql"
 	"errors"
 
 	gostErrors "github.com/geodan/gost/src/errors"
@@ -61,8 +60,19 @@ func (gdb *GostDatabase) GetLocation(id interface{}, qo *odata.QueryOptions) (*e
 
 // GetLocations retrieves all locations
 func (gdb *GostDatabase) GetLocations(qo *odata.QueryOptions) ([]*entities.Location, int, error) {
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Location{}, qo, "", "", lMapping)+" AS location from %s.location order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.location", gdb.Schema)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeLocation, selectMappingsResolver(entities.EntityTypeLocation))
+	queryString, err := CreateFilterQueryString(qo, resolver, "WHERE ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Location{}, qo, "", "", lMapping)+" AS location from %s.location %sorder by %s %s", gdb.Schema, queryString, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.location %s", gdb.Schema, queryString)
 	return processLocations(gdb.Db, sql, qo, countSQL)
 }
 
@@ -73,8 +83,19 @@ func (gdb *GostDatabase) GetLocationsByHistoricalLocation(hlID interface{}, qo *
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("HistoricaLocation does not exist"))
 	}
 
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Location{}, qo, "location.", "", lMapping)+" AS location from %s.location inner join %s.location_to_historicallocation on location_to_historicallocation.location_id = location.id where location_to_historicallocation.historicallocation_id = %v", gdb.Schema, gdb.Schema, intID)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.location inner join %s.location_to_historicallocation on location_to_historicallocation.location_id = location.id where location_to_historicallocation.historicallocation_id = %v", gdb.Schema, gdb.Schema, intID)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeLocation, selectMappingsResolver(entities.EntityTypeLocation))
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "location.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Location{}, qo, "location.", "", lMapping)+" AS location from %s.location inner join %s.location_to_historicallocation on location_to_historicallocation.location_id = location.id where location_to_historicallocation.historicallocation_id = %v %sorder by %s"+CreateTopSkipQueryString(qo), gdb.Schema, gdb.Schema, intID, queryString, orderBy)
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.location inner join %s.location_to_historicallocation on location_to_historicallocation.location_id = location.id where location_to_historicallocation.historicallocation_id = %v %s", gdb.Schema, gdb.Schema, intID, queryString)
 	return processLocations(gdb.Db, sql, qo, countSQL)
 }
 
@@ -89,6 +110,17 @@ func (gdb *GostDatabase) GetLocationByDatastreamID(datastreamID interface{}) (*e
 	return processLocation(gdb.Db, sql, nil)
 }
 
+// GetLocationByMultiDatastreamID returns the last location of the thing linked to a MultiDatastream
+func (gdb *GostDatabase) GetLocationByMultiDatastreamID(multiDatastreamID interface{}) (*entities.Location, error) {
+	intID, ok := ToIntID(multiDatastreamID)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("MultiDatastream does not exist"))
+	}
+
+	sql := fmt.Sprintf("SELECT "+CreateSelectString(&entities.Location{}, nil, "location.", "", lMapping)+" FROM %s.location INNER JOIN %s.thing_to_location on location.id = thing_to_location.location_id INNER JOIN %s.multidatastream on thing_to_location.thing_id = multidatastream.thing_id WHERE multidatastream.id = %v ORDER BY location.id DESC LIMIT 1", gdb.Schema, gdb.Schema, gdb.Schema, intID)
+	return processLocation(gdb.Db, sql, nil)
+}
+
 // GetLocationsByThing retrieves all locations linked to the given thing
 func (gdb *GostDatabase) GetLocationsByThing(thingID interface{}, qo *odata.QueryOptions) ([]*entities.Location, int, error) {
 	intID, ok := ToIntID(thingID)
@@ -96,12 +128,19 @@ func (gdb *GostDatabase) GetLocationsByThing(thingID interface{}, qo *odata.Quer
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Thing does not exist"))
 	}
 
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Location{}, qo, "location.", "", lMapping)+" AS location from %s.location inner join %s.thing_to_location on thing_to_location.location_id = location.id where thing_to_location.thing_id = %v order by id desc limit 1", gdb.Schema, gdb.Schema, intID)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.location inner join %s.thing_to_location on thing_to_location.location_id = location.id where thing_to_location.thing_id = %v", gdb.Schema, gdb.Schema, intID)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeLocation, selectMappingsResolver(entities.EntityTypeLocation))
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	// only the last linked location is the current location of the thing
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Location{}, qo, "location.", "", lMapping)+" AS location from %s.location inner join %s.thing_to_location on thing_to_location.location_id = location.id where thing_to_location.thing_id = %v %sorder by location.id desc limit 1", gdb.Schema, gdb.Schema, intID, queryString)
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.location inner join %s.thing_to_location on thing_to_location.location_id = location.id where thing_to_location.thing_id = %v %s", gdb.Schema, gdb.Schema, intID, queryString)
 	return processLocations(gdb.Db, sql, qo, countSQL)
 }
 
-func processLocation(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.Location, error) {
+func processLocation(db Executor, sql string, qo *odata.QueryOptions) (*entities.Location, error) {
 	locations, _, err := processLocations(db, sql, qo, "")
 	if err != nil {
 		return nil, err
@@ -114,12 +153,12 @@ func processLocation(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.
 	return locations[0], nil
 }
 
-func processLocations(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Location, int, error) {
+func processLocations(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Location, int, error) {
 	rows, err := db.Query(sql)
-	defer rows.Close()
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, filterQueryError(err)
 	}
+	defer rows.Close()
 
 	var locations = []*entities.Location{}
 	for rows.Next() {
@@ -128,13 +167,7 @@ func processLocations(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL s
 		var name, description, location string
 
 		var params []interface{}
-		var qp []string
-		if qo == nil || qo.QuerySelect == nil || len(qo.QuerySelect.Params) == 0 {
-			s := &entities.Location{}
-			qp = s.GetPropertyNames()
-		} else {
-			qp = qo.QuerySelect.Params
-		}
+		qp := selectedProperties(&entities.Location{}, qo)
 
 		for _, p := range qp {
 			p = strings.ToLower(p)
@@ -177,9 +210,13 @@ func processLocations(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL s
 		locations = append(locations, &l)
 	}
 
-	var count int
-	if len(countSQL) > 0 {
-		db.QueryRow(countSQL).Scan(&count)
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
 	}
 
 	return locations, count, nil
@@ -192,8 +229,8 @@ func (gdb *GostDatabase) PostLocation(location *entities.Location) (*entities.Lo
 	locationBytes, _ := json.Marshal(location.Location)
 	encoding, _ := entities.CreateEncodingType(location.EncodingType)
 
-	sql := fmt.Sprintf("INSERT INTO %s.location (name, description, encodingtype, location) VALUES ($1, $2, $3, ST_SetSRID(ST_GeomFromGeoJSON('%s'),4326)) RETURNING id", gdb.Schema, string(locationBytes[:]))
-	err := gdb.Db.QueryRow(sql, location.Name, location.Description, encoding.Code).Scan(&locationID)
+	sql := fmt.Sprintf("INSERT INTO %s.location (name, description, encodingtype, location) VALUES ($1, $2, $3, ST_SetSRID(ST_GeomFromGeoJSON($4::text),4326)) RETURNING id", gdb.Schema)
+	err := gdb.Db.QueryRow(sql, location.Name, location.Description, encoding.Code, string(locationBytes[:])).Scan(&locationID)
 	if err != nil {
 		return nil, err
 	}
@@ -227,8 +264,7 @@ func (gdb *GostDatabase) PatchLocation(id interface{}, l *entities.Location) (*e
 	}
 
 	if len(l.Location) > 0 {
-		locationBytes, _ := json.Marshal(l.Location)
-		updates["location"] = fmt.Sprintf("ST_SetSRID(ST_GeomFromGeoJSON('%s'),4326)", string(locationBytes[:]))
+		updates["location"] = newGeoJSON(l.Location)
 	}
 
 	if len(l.EncodingType) > 0 {
@@ -249,27 +285,14 @@ func (gdb *GostDatabase) DeleteLocation(id interface{}) error {
 	return DeleteEntity(gdb, id, "location")
 }
 
-// PutLocation receives a Location entity and changes it in the database
-// returns the adapted Location
+// PutLocation replaces a Location in the database, all properties of a Location are mandatory
 func (gdb *GostDatabase) PutLocation(id interface{}, location *entities.Location) (*entities.Location, error) {
-	return gdb.PatchLocation(id, location)
-	/*var intID int
-	var ok bool
-	if intID, ok = ToIntID(id); !ok || !gdb.LocationExists(intID) {
-		return nil, gostErrors.NewRequestNotFound(errors.New("Location does not exist"))
-	}
-
-	locationBytes, _ := json.Marshal(location.Location)
-	encoding, _ := entities.CreateEncodingType(location.EncodingType)
-
-	sql := fmt.Sprintf("update %s.location set name=$1, description=$2, encodingtype=$3, location=ST_SetSRID(ST_GeomFromGeoJSON('%s'),4326) where id = $4", gdb.Schema, string(locationBytes[:]))
-	_, err := gdb.Db.Exec(sql, location.Name, location.Description, encoding.Code, intID)
+	intID, err := gdb.putEntity(entities.EntityTypeLocation, id, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	nt, _ := gdb.GetLocation(intID, nil)
-	return nt, nil*/
+	return gdb.PatchLocation(intID, location)
 }
 
 // LinkLocation links a thing with a location
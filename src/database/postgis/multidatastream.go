@@ -0,0 +1,429 @@
+package postgis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+var mdsMapping = map[string]string{"observedArea": "public.ST_AsGeoJSON(multidatastream.observedarea) AS observedarea"}
+
+func multiDatastreamParamFactory(values map[string]interface{}) (entities.Entity, error) {
+	ds := &entities.MultiDatastream{}
+	for as, value := range values {
+		if value == nil {
+			continue
+		}
+
+		if as == asMappings[entities.EntityTypeMultiDatastream][multiDatastreamID] {
+			ds.ID = value
+		} else if as == asMappings[entities.EntityTypeMultiDatastream][multiDatastreamObservedArea] {
+			t := value.(string)
+			observedAreaMap, err := JSONToMap(&t)
+			if err != nil {
+				return nil, err
+			}
+			ds.ObservedArea = observedAreaMap
+		} else if as == asMappings[entities.EntityTypeMultiDatastream][multiDatastreamName] {
+			ds.Name = value.(string)
+		} else if as == asMappings[entities.EntityTypeMultiDatastream][multiDatastreamDescription] {
+			ds.Description = value.(string)
+		} else if as == asMappings[entities.EntityTypeMultiDatastream][multiDatastreamResultTime] {
+			ds.ResultTime = value.(string)
+		} else if as == asMappings[entities.EntityTypeMultiDatastream][multiDatastreamObservationType] {
+			ds.ObservationType = entities.OMComplexObservation.Value
+		} else if as == asMappings[entities.EntityTypeMultiDatastream][multiDatastreamPhenomenonTime] {
+			ds.PhenomenonTime = value.(string)
+		} else if as == asMappings[entities.EntityTypeMultiDatastream][multiDatastreamUnitOfMeasurements] {
+			if err := json.Unmarshal([]byte(value.(string)), &ds.UnitOfMeasurements); err != nil {
+				return nil, err
+			}
+		} else if as == asMappings[entities.EntityTypeMultiDatastream][multiDatastreamMultiObservationDataTypes] {
+			if err := json.Unmarshal([]byte(value.(string)), &ds.MultiObservationDataTypes); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ds, nil
+}
+
+// GetMultiDatastream retrieves a MultiDatastream by id
+func (gdb *GostDatabase) GetMultiDatastream(id interface{}, qo *odata.QueryOptions) (*entities.MultiDatastream, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("MultiDatastream does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.MultiDatastream{}, qo, "", "", mdsMapping)+" FROM %s.multidatastream where id = %v", gdb.Schema, intID)
+	return processMultiDatastream(gdb.Db, sql, qo)
+}
+
+// GetMultiDatastreams retrieves all MultiDatastreams
+func (gdb *GostDatabase) GetMultiDatastreams(qo *odata.QueryOptions) ([]*entities.MultiDatastream, int, error) {
+	return gdb.getMultiDatastreams("", qo)
+}
+
+// GetMultiDatastreamByObservation retrieves the MultiDatastream linked to the given observation
+func (gdb *GostDatabase) GetMultiDatastreamByObservation(observationID interface{}, qo *odata.QueryOptions) (*entities.MultiDatastream, error) {
+	intID, ok := ToIntID(observationID)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("MultiDatastream does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.MultiDatastream{}, qo, "multidatastream.", "", mdsMapping)+" FROM %s.multidatastream inner join %s.observation on multidatastream.id = observation.multidatastream_id where observation.id = %v", gdb.Schema, gdb.Schema, intID)
+	return processMultiDatastream(gdb.Db, sql, qo)
+}
+
+// GetMultiDatastreamsByThing retrieves all MultiDatastreams linked to the given thing
+func (gdb *GostDatabase) GetMultiDatastreamsByThing(thingID interface{}, qo *odata.QueryOptions) ([]*entities.MultiDatastream, int, error) {
+	intID, ok := ToIntID(thingID)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Thing does not exist"))
+	}
+
+	return gdb.getMultiDatastreams(fmt.Sprintf("multidatastream.thing_id = %v", intID), qo)
+}
+
+// GetMultiDatastreamsBySensor retrieves all MultiDatastreams linked to the given sensor
+func (gdb *GostDatabase) GetMultiDatastreamsBySensor(sensorID interface{}, qo *odata.QueryOptions) ([]*entities.MultiDatastream, int, error) {
+	intID, ok := ToIntID(sensorID)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Sensor does not exist"))
+	}
+
+	return gdb.getMultiDatastreams(fmt.Sprintf("multidatastream.sensor_id = %v", intID), qo)
+}
+
+// GetMultiDatastreamsByObservedProperty retrieves all MultiDatastreams which observe the given ObservedProperty
+func (gdb *GostDatabase) GetMultiDatastreamsByObservedProperty(oID interface{}, qo *odata.QueryOptions) ([]*entities.MultiDatastream, int, error) {
+	intID, ok := ToIntID(oID)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("ObservedProperty does not exist"))
+	}
+
+	return gdb.getMultiDatastreams(fmt.Sprintf("multidatastream.id in (select multidatastream_id from %s.multidatastream_to_observedproperty where observedproperty_id = %v)", gdb.Schema, intID), qo)
+}
+
+// getMultiDatastreams retrieves the MultiDatastreams matching the given condition and $filter
+func (gdb *GostDatabase) getMultiDatastreams(condition string, qo *odata.QueryOptions) ([]*entities.MultiDatastream, int, error) {
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeMultiDatastream, selectMappingsResolver(entities.EntityTypeMultiDatastream))
+	prefix := "WHERE "
+	if len(condition) > 0 {
+		condition = "WHERE " + condition + " "
+		prefix = " AND "
+	}
+
+	queryString, err := CreateFilterQueryString(qo, resolver, prefix)
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "multidatastream.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.MultiDatastream{}, qo, "multidatastream.", "", mdsMapping)+" FROM %s.multidatastream %s%sorder by %s%s", gdb.Schema, condition, queryString, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.multidatastream %s%s", gdb.Schema, condition, queryString)
+	return processMultiDatastreams(gdb.Db, sql, qo, countSQL)
+}
+
+func processMultiDatastream(db Executor, sql string, qo *odata.QueryOptions) (*entities.MultiDatastream, error) {
+	datastreams, _, err := processMultiDatastreams(db, sql, qo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(datastreams) == 0 {
+		return nil, gostErrors.NewRequestNotFound(errors.New("MultiDatastream does not exist"))
+	}
+
+	return datastreams[0], nil
+}
+
+func processMultiDatastreams(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.MultiDatastream, int, error) {
+	rows, err := db.Query(sql)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+	defer rows.Close()
+
+	var datastreams = []*entities.MultiDatastream{}
+	for rows.Next() {
+		var id interface{}
+		var name, description, phenomenonTime, resultTime string
+		var unitOfMeasurements, dataTypes, observedArea *string
+		var ot int64
+
+		var params []interface{}
+		for _, p := range selectedProperties(&entities.MultiDatastream{}, qo) {
+			switch strings.ToLower(p) {
+			case "id":
+				params = append(params, &id)
+			case "name":
+				params = append(params, &name)
+			case "description":
+				params = append(params, &description)
+			case "unitofmeasurements":
+				params = append(params, &unitOfMeasurements)
+			case "observationtype":
+				params = append(params, &ot)
+			case "multiobservationdatatypes":
+				params = append(params, &dataTypes)
+			case "observedarea":
+				params = append(params, &observedArea)
+			case "phenomenontime":
+				params = append(params, &phenomenonTime)
+			case "resulttime":
+				params = append(params, &resultTime)
+			}
+		}
+
+		if err = rows.Scan(params...); err != nil {
+			return nil, 0, err
+		}
+
+		observedAreaMap, err := JSONToMap(observedArea)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		datastream := entities.MultiDatastream{}
+		datastream.ID = id
+		datastream.Name = name
+		datastream.Description = description
+		datastream.PhenomenonTime = phenomenonTime
+		datastream.ResultTime = resultTime
+		datastream.ObservedArea = observedAreaMap
+		if ot != 0 {
+			datastream.ObservationType = entities.OMComplexObservation.Value
+		}
+
+		if unitOfMeasurements != nil {
+			if err = json.Unmarshal([]byte(*unitOfMeasurements), &datastream.UnitOfMeasurements); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		if dataTypes != nil {
+			if err = json.Unmarshal([]byte(*dataTypes), &datastream.MultiObservationDataTypes); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		datastreams = append(datastreams, &datastream)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	return datastreams, count, nil
+}
+
+// CheckMultiDatastreamRelationsExist checks if the Thing, Sensor and ObservedProperties of the MultiDatastream exist
+func CheckMultiDatastreamRelationsExist(gdb *GostDatabase, d *entities.MultiDatastream) error {
+	if tID, ok := ToIntID(d.Thing.ID); !ok || !gdb.ThingExists(tID) {
+		return gostErrors.NewBadRequestError(errors.New("Thing does not exist"))
+	}
+
+	if sID, ok := ToIntID(d.Sensor.ID); !ok || !gdb.SensorExists(sID) {
+		return gostErrors.NewBadRequestError(errors.New("Sensor does not exist"))
+	}
+
+	for _, op := range d.ObservedProperties {
+		if oID, ok := ToIntID(op.ID); !ok || !gdb.ObservedPropertyExists(oID) {
+			return gostErrors.NewBadRequestError(errors.New("ObservedProperty does not exist"))
+		}
+	}
+
+	return nil
+}
+
+// PostMultiDatastream adds a MultiDatastream and the links to its ObservedProperties in one transaction,
+// the rank of a link is the position of the ObservedProperty in the MultiDatastream
+func (gdb *GostDatabase) PostMultiDatastream(d *entities.MultiDatastream) (*entities.MultiDatastream, error) {
+	err := CheckMultiDatastreamRelationsExist(gdb, d)
+	if err != nil {
+		return nil, err
+	}
+
+	tID, _ := ToIntID(d.Thing.ID)
+	sID, _ := ToIntID(d.Sensor.ID)
+	unitOfMeasurements, _ := json.Marshal(d.UnitOfMeasurements)
+	dataTypes, _ := json.Marshal(d.MultiObservationDataTypes)
+	var observedArea interface{}
+	if len(d.ObservedArea) != 0 {
+		observedAreaBytes, _ := json.Marshal(d.ObservedArea)
+		observedArea = string(observedAreaBytes[:])
+	}
+
+	phenomenonTime := "NULL"
+	if len(d.PhenomenonTime) != 0 {
+		period := ParseTMPeriod(d.PhenomenonTime)
+		phenomenonTime = "'" + ToPostgresPeriodFormat(period) + "'"
+	}
+
+	err = gdb.WithTransaction(func(db models.Database) error {
+		tx := db.(*GostDatabase)
+		var dsID int
+		sql := fmt.Sprintf("INSERT INTO %s.multidatastream (name, description, unitofmeasurements, observationtype, multiobservationdatatypes, observedarea, thing_id, sensor_id, phenomenonTime) VALUES ($1, $2, $3, $4, $5, ST_SetSRID(ST_GeomFromGeoJSON($6::text),4326), $7, $8, %s) RETURNING id", tx.Schema, phenomenonTime)
+		if err := tx.Db.QueryRow(sql, d.Name, d.Description, string(unitOfMeasurements), entities.OMComplexObservation.Code, string(dataTypes), observedArea, tID, sID).Scan(&dsID); err != nil {
+			return err
+		}
+
+		for i, op := range d.ObservedProperties {
+			oID, _ := ToIntID(op.ID)
+			sql = fmt.Sprintf("INSERT INTO %s.multidatastream_to_observedproperty (multidatastream_id, observedproperty_id, rank) VALUES ($1, $2, $3)", tx.Schema)
+			if _, err := tx.Db.Exec(sql, dsID, oID, i); err != nil {
+				return err
+			}
+		}
+
+		d.ID = dsID
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	// clear inner entities to serves links upon response
+	d.Thing = nil
+	d.Sensor = nil
+	d.ObservedProperties = nil
+
+	return d, nil
+}
+
+// PatchMultiDatastream updates a MultiDatastream in the database, the number of unitOfMeasurements and
+// multiObservationDataTypes cannot be changed since it is bound to the linked ObservedProperties
+func (gdb *GostDatabase) PatchMultiDatastream(id interface{}, ds *entities.MultiDatastream) (*entities.MultiDatastream, error) {
+	var intID int
+	var ok bool
+	updates := make(map[string]interface{})
+
+	if intID, ok = ToIntID(id); !ok || !gdb.MultiDatastreamExists(intID) {
+		return nil, gostErrors.NewRequestNotFound(errors.New("MultiDatastream does not exist"))
+	}
+
+	if len(ds.Name) > 0 {
+		updates["name"] = ds.Name
+	}
+
+	if len(ds.Description) > 0 {
+		updates["description"] = ds.Description
+	}
+
+	if len(ds.ObservationType) > 0 && ds.ObservationType != entities.OMComplexObservation.Value {
+		return nil, gostErrors.NewBadRequestError(fmt.Errorf("The observationType of a MultiDatastream has to be %s", entities.OMComplexObservation.Value))
+	}
+
+	if len(ds.UnitOfMeasurements) > 0 || len(ds.MultiObservationDataTypes) > 0 {
+		current, err := gdb.GetMultiDatastream(intID, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ds.UnitOfMeasurements) > 0 {
+			if len(ds.UnitOfMeasurements) != len(current.MultiObservationDataTypes) {
+				return nil, gostErrors.NewBadRequestError(fmt.Errorf("The MultiDatastream has %v unitOfMeasurements", len(current.MultiObservationDataTypes)))
+			}
+
+			j, _ := json.Marshal(ds.UnitOfMeasurements)
+			updates["unitofmeasurements"] = string(j[:])
+		}
+
+		if len(ds.MultiObservationDataTypes) > 0 {
+			if len(ds.MultiObservationDataTypes) != len(current.MultiObservationDataTypes) {
+				return nil, gostErrors.NewBadRequestError(fmt.Errorf("The MultiDatastream has %v multiObservationDataTypes", len(current.MultiObservationDataTypes)))
+			}
+
+			for _, t := range ds.MultiObservationDataTypes {
+				if ot, err := entities.GetObservationTypeByValue(t); err != nil || ot.Code == entities.OMCategoryUnknown.Code {
+					return nil, gostErrors.NewBadRequestError(fmt.Errorf("MultiObservationDataType %s not supported", t))
+				}
+			}
+
+			j, _ := json.Marshal(ds.MultiObservationDataTypes)
+			updates["multiobservationdatatypes"] = string(j[:])
+		}
+	}
+
+	if len(ds.ObservedArea) > 0 {
+		updates["observedarea"] = newGeoJSON(ds.ObservedArea)
+	}
+
+	if err := gdb.updateEntityColumns("multidatastream", updates, intID); err != nil {
+		return nil, err
+	}
+
+	return gdb.GetMultiDatastream(intID, nil)
+}
+
+// PutMultiDatastream replaces a MultiDatastream in the database, the observedArea is cleared when omitted and
+// the MultiDatastream is linked to the given Thing and Sensor, the given ObservedProperties replace the linked
+// ObservedProperties in the given order
+func (gdb *GostDatabase) PutMultiDatastream(id interface{}, datastream *entities.MultiDatastream) (*entities.MultiDatastream, error) {
+	var cleared []string
+	if len(datastream.ObservedArea) == 0 {
+		cleared = append(cleared, multiDatastreamObservedArea)
+	}
+
+	relations := map[entities.EntityType]interface{}{}
+	if datastream.Thing != nil {
+		relations[entities.EntityTypeThing] = datastream.Thing.ID
+	}
+
+	if datastream.Sensor != nil {
+		relations[entities.EntityTypeSensor] = datastream.Sensor.ID
+	}
+
+	intID, err := gdb.putEntity(entities.EntityTypeMultiDatastream, id, cleared, relations)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(datastream.ObservedProperties) > 0 {
+		query := fmt.Sprintf("DELETE FROM %s.multidatastream_to_observedproperty WHERE multidatastream_id = $1", gdb.Schema)
+		if _, err = gdb.Db.Exec(query, intID); err != nil {
+			return nil, err
+		}
+
+		for i, op := range datastream.ObservedProperties {
+			opID, ok := ToIntID(op.ID)
+			if !ok || !EntityExists(gdb, opID, observedPropertyTable) {
+				return nil, gostErrors.NewRequestNotFound(errors.New("ObservedProperty does not exist"))
+			}
+
+			query = fmt.Sprintf("INSERT INTO %s.multidatastream_to_observedproperty (multidatastream_id, observedproperty_id, rank) VALUES ($1, $2, $3)", gdb.Schema)
+			if _, err = gdb.Db.Exec(query, intID, opID, i); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return gdb.PatchMultiDatastream(intID, datastream)
+}
+
+// DeleteMultiDatastream tries to delete a MultiDatastream by the given id
+func (gdb *GostDatabase) DeleteMultiDatastream(id interface{}) error {
+	return DeleteEntity(gdb, id, "multidatastream")
+}
+
+// MultiDatastreamExists checks if a MultiDatastream is present in the database based on a given id
+func (gdb *GostDatabase) MultiDatastreamExists(id int) bool {
+	return EntityExists(gdb, id, "multidatastream")
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"strings"
 
-	"database/sql"
-
 	gostErrors "github.com/geodan/gost/src/errors"
 	"github.com/geodan/gost/src/sensorthings/entities"
 	"github.com/geodan/gost/src/sensorthings/odata"
@@ -51,40 +49,37 @@ func observationParamFactory(values map[string]interface{}) (entities.Entity, er
 	return o, nil
 }
 
-// observationParamFactory is used to construct a WHERE clause from an ODATA $select string
-func observationParamFactoryWhere(key string, value interface{}) (string, string, error) {
-	val := fmt.Sprintf("%v", value)
-	jsonVal := convertSelectValueForJSON(val)
-	switch key {
+// observationParamFactoryWhere is used to map a property inside an ODATA $filter or $orderby onto a field of
+// the observation table, keys inside json values can be used as well, for example parameters/parameterName
+func observationParamFactoryWhere(path []string) (*FilterColumn, error) {
+	var column string
+	switch strings.ToLower(path[0]) {
 	case "id":
-		return "id", val, nil
-		break
-	case "phenomenonTime":
-		return "data -> 'phenomenonTime'", jsonVal, nil
-		break
-	case "resultTime":
-		return "data -> 'resultTime'", jsonVal, nil
-		break
+		if len(path) > 1 {
+			return nil, fmt.Errorf("Parameter %s not implemented", strings.Join(path, "/"))
+		}
+		return &FilterColumn{SQL: "observation.id"}, nil
+	case "phenomenontime":
+		column = "observation.data -> 'phenomenonTime'"
+	case "resulttime":
+		column = "observation.data -> 'resultTime'"
 	case "result":
-		return "data -> 'result'", jsonVal, nil
-		break
-	case "resultQuality":
-		return "data -> 'resultQuality'", jsonVal, nil
-		break
-	case "parameters": //implement parameters/parameterName
-		return "data -> 'parameters'", jsonVal, nil
-		break
+		column = "observation.data -> 'result'"
+	case "validtime":
+		column = "observation.data -> 'validTime'"
+	case "resultquality":
+		column = "observation.data -> 'resultQuality'"
+	case "parameters":
+		column = "observation.data -> 'parameters'"
+	default:
+		return nil, fmt.Errorf("Parameter %s not implemented", path[0])
 	}
 
-	return "", "", fmt.Errorf("Parameter %s not implemented", key)
-}
-
-func convertSelectValueForJSON(value string) string {
-	if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") {
-		return value
+	if len(path) > 1 {
+		column = jsonPath(column, path[1:])
 	}
 
-	return fmt.Sprintf("'%v'", value)
+	return &FilterColumn{SQL: column, JSON: true}, nil
 }
 
 // GetObservation retrieves an observation by id from the database
@@ -94,7 +89,7 @@ func (gdb *GostDatabase) GetObservation(id interface{}, qo *odata.QueryOptions)
 		return nil, gostErrors.NewRequestNotFound(errors.New("Observation does not exist"))
 	}
 
-	sql := fmt.Sprintf("select id, data FROM %s.observation where id = %v ", gdb.Schema, intID)
+	sql := fmt.Sprintf("select id, data, stream_id, multidatastream_id FROM %s.observation where id = %v ", gdb.Schema, intID)
 	observation, err := processObservation(gdb.Db, sql, qo)
 	if err != nil {
 		return nil, err
@@ -107,13 +102,24 @@ func (gdb *GostDatabase) GetObservation(id interface{}, qo *odata.QueryOptions)
 func (gdb *GostDatabase) GetObservations(qo *odata.QueryOptions) ([]*entities.Observation, int, error) {
 	var queryString string
 	var err error
-	if queryString, err = CreateFilterQueryString(qo, observationParamFactoryWhere, "WHERE "); err != nil {
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere)
+	if queryString, err = CreateFilterQueryString(qo, resolver, "WHERE "); err != nil {
 		return nil, 0, gostErrors.NewBadRequestError(err)
 	}
 
-	sql := fmt.Sprintf("select id, data FROM %s.observation "+queryString+"order by id desc"+CreateTopSkipQueryString(qo), gdb.Schema)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.observation", gdb.Schema)
-	return processObservations(gdb.Db, sql, qo, countSQL)
+	keysetPrefix := " AND "
+	if len(queryString) == 0 {
+		keysetPrefix = "WHERE "
+	}
+
+	orderBy, keyset, keys, args, err := CreateKeysetQueryString(qo, resolver, "observation.id", keysetPrefix)
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select id, data, stream_id, multidatastream_id, %s FROM %s.observation %s%sorder by %s%s", keys, gdb.Schema, queryString, keyset, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.observation %s", gdb.Schema, queryString)
+	return processObservations(gdb.Db, sql, qo, countSQL, args...)
 }
 
 // GetObservationsByFeatureOfInterest retrieves all observations by the given FeatureOfInterest id
@@ -123,9 +129,20 @@ func (gdb *GostDatabase) GetObservationsByFeatureOfInterest(foiID interface{}, q
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("FeatureOfInterest does not exist"))
 	}
 
-	sql := fmt.Sprintf("select id, data FROM %s.observation where featureofinterest_id = %v order by id desc"+CreateTopSkipQueryString(qo), gdb.Schema, intID)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.observation where featureofinterest_id = %v", gdb.Schema, intID)
-	return processObservations(gdb.Db, sql, qo, countSQL)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere)
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, keyset, keys, args, err := CreateKeysetQueryString(qo, resolver, "observation.id", " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select id, data, stream_id, multidatastream_id, %s FROM %s.observation where featureofinterest_id = %v %s%sorder by %s%s", keys, gdb.Schema, intID, queryString, keyset, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.observation where featureofinterest_id = %v %s", gdb.Schema, intID, queryString)
+	return processObservations(gdb.Db, sql, qo, countSQL, args...)
 }
 
 // GetObservationsByDatastream retrieves all observations by the given datastream id
@@ -139,16 +156,45 @@ func (gdb *GostDatabase) GetObservationsByDatastream(dataStreamID interface{}, q
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Datastream does not exist"))
 	}
 
-	if queryString, err = CreateFilterQueryString(qo, observationParamFactoryWhere, " AND "); err != nil {
-		return nil, 0, gostErrors.NewBadRequestError(errors.New("Datastream does not exist"))
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere)
+	if queryString, err = CreateFilterQueryString(qo, resolver, " AND "); err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, keyset, keys, args, err := CreateKeysetQueryString(qo, resolver, "observation.id", " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select id, data, stream_id, multidatastream_id, %s FROM %s.observation where stream_id = %v %s%sorder by %s%s", keys, gdb.Schema, intID, queryString, keyset, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.observation where stream_id = %v %s", gdb.Schema, intID, queryString)
+	return processObservations(gdb.Db, sql, qo, countSQL, args...)
+}
+
+// GetObservationsByMultiDatastream retrieves all observations by the given MultiDatastream id
+func (gdb *GostDatabase) GetObservationsByMultiDatastream(multiDatastreamID interface{}, qo *odata.QueryOptions) ([]*entities.Observation, int, error) {
+	intID, ok := ToIntID(multiDatastreamID)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("MultiDatastream does not exist"))
+	}
+
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeObservation, observationParamFactoryWhere)
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
 	}
 
-	sql := fmt.Sprintf("select id, data FROM %s.observation where stream_id = %v "+queryString+"order by id desc"+CreateTopSkipQueryString(qo), gdb.Schema, intID)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.observation where stream_id = %v", gdb.Schema, intID)
-	return processObservations(gdb.Db, sql, qo, countSQL)
+	orderBy, keyset, keys, args, err := CreateKeysetQueryString(qo, resolver, "observation.id", " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select id, data, stream_id, multidatastream_id, %s FROM %s.observation where multidatastream_id = %v %s%sorder by %s%s", keys, gdb.Schema, intID, queryString, keyset, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.observation where multidatastream_id = %v %s", gdb.Schema, intID, queryString)
+	return processObservations(gdb.Db, sql, qo, countSQL, args...)
 }
 
-func processObservation(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.Observation, error) {
+func processObservation(db Executor, sql string, qo *odata.QueryOptions) (*entities.Observation, error) {
 	observations, _, err := processObservations(db, sql, qo, "")
 	if err != nil {
 		return nil, err
@@ -161,10 +207,19 @@ func processObservation(db *sql.DB, sql string, qo *odata.QueryOptions) (*entiti
 	return observations[0], nil
 }
 
-func processObservations(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Observation, int, error) {
-	rows, err := db.Query(sql)
+// processObservations runs the query and parses the observations, the Datastream of an observation is only
+// set, containing the id, when $resultFormat=dataArray is requested to group the observations per Datastream.
+// An observation belongs to either a Datastream or a MultiDatastream, the other id is NULL.
+// When the query selects the keyset of the rows as fifth column it is stored as SkipToken of the observation,
+// args are the parameters of the query
+func processObservations(db Executor, sql string, qo *odata.QueryOptions, countSQL string, args ...interface{}) ([]*entities.Observation, int, error) {
+	rows, err := db.Query(sql, args...)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
 	defer rows.Close()
 
+	columns, err := rows.Columns()
 	if err != nil {
 		return nil, 0, err
 	}
@@ -172,32 +227,44 @@ func processObservations(db *sql.DB, sql string, qo *odata.QueryOptions, countSQ
 	var observations = []*entities.Observation{}
 	for rows.Next() {
 		var id int
-		var data string
+		var streamID, multiDatastreamID *int
+		var data, keys string
 
-		err := rows.Scan(&id, &data)
+		dest := []interface{}{&id, &data, &streamID, &multiDatastreamID}
+		if len(columns) > 4 {
+			dest = append(dest, &keys)
+		}
+
+		err := rows.Scan(dest...)
 		if err != nil {
 			return nil, 0, err
 		}
 
 		observation := entities.Observation{}
 		observation.ID = id
+		if len(keys) > 0 {
+			observation.SkipToken = odata.CreateSkipToken(keys)
+		}
 		err = observation.ParseEntity([]byte(data))
 
 		if err != nil {
 			return nil, 0, err
 		}
 
+		observation.InMultiDatastream = multiDatastreamID != nil
+		if qo != nil && qo.QueryResultFormat.IsDataArray() && streamID != nil {
+			datastream := &entities.Datastream{}
+			datastream.ID = *streamID
+			observation.Datastream = datastream
+		}
+
 		if qo != nil && qo.QuerySelect != nil && len(qo.QuerySelect.Params) > 0 {
 			set := make(map[string]bool)
 			for _, v := range qo.QuerySelect.Params {
 				set[strings.ToLower(v)] = true
 			}
 
-			_, ok := set["id"]
-			if !ok {
-				observation.ID = nil
-			}
-			_, ok = set["phenomenontime"]
+			_, ok := set["phenomenontime"]
 			if !ok {
 				observation.PhenomenonTime = ""
 			}
@@ -226,54 +293,65 @@ func processObservations(db *sql.DB, sql string, qo *odata.QueryOptions, countSQ
 		observations = append(observations, &observation)
 	}
 
-	var count int
-	if len(countSQL) > 0 {
-		db.QueryRow(countSQL).Scan(&count)
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
 	}
 
 	return observations, count, nil
 }
 
-// PutObservation replaces an observation to the database
+// PutObservation replaces an Observation in the database, the optional properties which are omitted are
+// cleared and the Observation is linked to the given Datastream or MultiDatastream and FeatureOfInterest
 func (gdb *GostDatabase) PutObservation(id interface{}, o *entities.Observation) (*entities.Observation, error) {
-	return gdb.PatchObservation(id, o)
-	/*var err error
-	var ok bool
-	var intID, dID, fID int
+	var cleared []string
+	relations := map[entities.EntityType]interface{}{}
+	if o.Datastream != nil {
+		relations[entities.EntityTypeDatastream] = o.Datastream.ID
+		cleared = append(cleared, observationMultiDatastreamID)
+	}
 
-	if intID, ok = ToIntID(id); !ok || !gdb.ObservationExists(intID) {
-		return nil, gostErrors.NewRequestNotFound(errors.New("Observation does not exist"))
+	if o.MultiDatastream != nil {
+		relations[entities.EntityTypeMultiDatastream] = o.MultiDatastream.ID
+		cleared = append(cleared, observationStreamID)
 	}
 
-	if dID, ok = ToIntID(o.Datastream.ID); !ok || !gdb.ObservationExists(dID) {
-		return nil, gostErrors.NewRequestNotFound(errors.New("Datastream does not exist"))
+	if o.FeatureOfInterest != nil {
+		relations[entities.EntityTypeFeatureOfInterest] = o.FeatureOfInterest.ID
 	}
 
-	if fID, ok = ToIntID(o.FeatureOfInterest.ID); !ok || !gdb.FeatureOfInterestExists(fID) {
-		return nil, gostErrors.NewRequestNotFound(errors.New("FeatureOfInterest does not exist"))
+	intID, err := gdb.putEntity(entities.EntityTypeObservation, id, cleared, relations)
+	if err != nil {
+		return nil, err
 	}
 
 	json, _ := o.MarshalPostgresJSON()
-	obs := fmt.Sprintf("'%s'", string(json[:]))
-	sql := fmt.Sprintf("update %s.observation set data=%v, stream_id=%v, featureofinterest_id=%v where id=%v", gdb.Schema, obs, dID, fID, intID)
-
-	_, err2 := gdb.Db.Exec(sql)
-	if err2 != nil {
+	if err = gdb.updateEntityColumns(observationTable, map[string]interface{}{observationData: string(json[:])}, intID); err != nil {
 		return nil, err
 	}
 
-	o.ID = intID
-	return o, nil
-	*/
+	return gdb.GetObservation(intID, nil)
 }
 
 // PostObservation adds an observation to the database
 func (gdb *GostDatabase) PostObservation(o *entities.Observation) (*entities.Observation, error) {
 	var oID int
 
-	dID, ok := ToIntID(o.Datastream.ID)
+	// an observation of a MultiDatastream has no Datastream
+	streamColumn, entityName, streamID := "stream_id", "Datastream", interface{}(nil)
+	if o.MultiDatastream != nil {
+		streamColumn, entityName, streamID = "multidatastream_id", "MultiDatastream", o.MultiDatastream.ID
+	} else if o.Datastream != nil {
+		streamID = o.Datastream.ID
+	}
+
+	dID, ok := ToIntID(streamID)
 	if !ok {
-		return nil, gostErrors.NewBadRequestError(errors.New("Datastream does not exist"))
+		return nil, gostErrors.NewBadRequestError(fmt.Errorf("%s does not exist", entityName))
 	}
 
 	if o.FeatureOfInterest == nil || len(fmt.Sprintf("%v", o.FeatureOfInterest.ID)) == 0 {
@@ -284,7 +362,7 @@ func (gdb *GostDatabase) PostObservation(o *entities.Observation) (*entities.Obs
 
 	json, _ := o.MarshalPostgresJSON()
 	obs := fmt.Sprintf("'%s'", string(json[:]))
-	sql := fmt.Sprintf("INSERT INTO %s.observation (data, stream_id, featureofinterest_id) VALUES (%v, %v, %v) RETURNING id", gdb.Schema, obs, dID, fID)
+	sql := fmt.Sprintf("INSERT INTO %s.observation (data, %s, featureofinterest_id) VALUES (%v, %v, %v) RETURNING id", gdb.Schema, streamColumn, obs, dID, fID)
 
 	err := gdb.Db.QueryRow(sql).Scan(&oID)
 	if err != nil {
@@ -292,6 +370,9 @@ func (gdb *GostDatabase) PostObservation(o *entities.Observation) (*entities.Obs
 		if strings.Contains(errString, "violates foreign key constraint \"fk_datastream\"") {
 			return nil, gostErrors.NewBadRequestError(errors.New("Datastream does not exist"))
 		}
+		if strings.Contains(errString, "violates foreign key constraint \"fk_multidatastream\"") {
+			return nil, gostErrors.NewBadRequestError(errors.New("MultiDatastream does not exist"))
+		}
 		if strings.Contains(errString, "violates foreign key constraint \"fk_featureofinterest\"") {
 			return nil, gostErrors.NewBadRequestError(errors.New("FeatureOfInterest does not exist"))
 		}
@@ -305,12 +386,113 @@ func (gdb *GostDatabase) PostObservation(o *entities.Observation) (*entities.Obs
 	}
 
 	// clear inner entities to serves links upon response
+	o.InMultiDatastream = o.MultiDatastream != nil
 	o.Datastream = nil
+	o.MultiDatastream = nil
 	o.FeatureOfInterest = nil
 
 	return o, nil
 }
 
+// PostObservations adds multiple observations to the database using one insert, observations of which the Datastream
+// or FeatureOfInterest does not exist are not inserted. For every given observation either the created observation
+// or an error is returned, both slices are in the order of the given observations
+func (gdb *GostDatabase) PostObservations(observations []*entities.Observation) ([]*entities.Observation, []error) {
+	created := make([]*entities.Observation, len(observations))
+	errs := make([]error, len(observations))
+	dIDs := make([]int, len(observations))
+	fIDs := make([]int, len(observations))
+	for i, o := range observations {
+		var dOk, fOk bool
+		dIDs[i], dOk = ToIntID(o.Datastream.ID)
+		if o.FeatureOfInterest != nil {
+			fIDs[i], fOk = ToIntID(o.FeatureOfInterest.ID)
+		}
+
+		if !dOk {
+			errs[i] = gostErrors.NewBadRequestError(errors.New("Datastream does not exist"))
+		} else if !fOk {
+			errs[i] = gostErrors.NewBadRequestError(errors.New("No FeatureOfInterest supplied or Location found on linked thing"))
+		}
+	}
+
+	datastreams, err := ExistingEntities(gdb, dIDs, "datastream")
+	if err == nil {
+		var fois map[int]bool
+		if fois, err = ExistingEntities(gdb, fIDs, "featureofinterest"); err == nil {
+			for i := range observations {
+				if errs[i] == nil && !datastreams[dIDs[i]] {
+					errs[i] = gostErrors.NewBadRequestError(errors.New("Datastream does not exist"))
+				} else if errs[i] == nil && !fois[fIDs[i]] {
+					errs[i] = gostErrors.NewBadRequestError(errors.New("FeatureOfInterest does not exist"))
+				}
+			}
+		}
+	}
+
+	values := []string{}
+	args := []interface{}{}
+	inserted := []int{}
+	for i, o := range observations {
+		if err != nil {
+			errs[i] = err
+		}
+
+		if errs[i] != nil {
+			continue
+		}
+
+		json, _ := o.MarshalPostgresJSON()
+		n := len(args)
+		values = append(values, fmt.Sprintf("($%v, $%v, $%v)", n+1, n+2, n+3))
+		args = append(args, string(json), dIDs[i], fIDs[i])
+		inserted = append(inserted, i)
+	}
+
+	if len(values) == 0 {
+		return created, errs
+	}
+
+	sql := fmt.Sprintf("INSERT INTO %s.observation (data, stream_id, featureofinterest_id) VALUES %s RETURNING id", gdb.Schema, strings.Join(values, ", "))
+	ids, err := insertedIDs(gdb.Db, sql, args...)
+	for n, i := range inserted {
+		if err != nil || n >= len(ids) {
+			errs[i] = fmt.Errorf("Unable to insert observation: %v", err)
+			continue
+		}
+
+		o := observations[i]
+		o.ID = ids[n]
+		o.Datastream = nil
+		o.FeatureOfInterest = nil
+		created[i] = o
+	}
+
+	return created, errs
+}
+
+// insertedIDs runs an insert returning id with the given arguments and returns the ids of the inserted rows in
+// order of insertion
+func insertedIDs(db Executor, sql string, args ...interface{}) ([]int, error) {
+	rows, err := db.Query(sql, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
 // ObservationExists checks if an Observation is present in the database based on a given id.
 func (gdb *GostDatabase) ObservationExists(id interface{}) bool {
 	return EntityExists(gdb, id, "observation")
@@ -5,7 +5,6 @@ import (
 
 	"github.com/geodan/gost/src/sensorthings/entities"
 
-	"database/sql"
 	"errors"
 
 	gostErrors "github.com/geodan/gost/src/errors"
@@ -74,6 +73,18 @@ func (gdb *GostDatabase) GetObservedPropertyByDatastream(id interface{}, qo *oda
 	return observedProperty, nil
 }
 
+// GetObservedPropertiesByMultiDatastream returns the ObservedProperties of a MultiDatastream in the order of its results
+func (gdb *GostDatabase) GetObservedPropertiesByMultiDatastream(id interface{}, qo *odata.QueryOptions) ([]*entities.ObservedProperty, int, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("MultiDatastream does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.ObservedProperty{}, qo, "observedproperty.", "", nil)+" FROM %s.observedproperty inner join %s.multidatastream_to_observedproperty on multidatastream_to_observedproperty.observedproperty_id = observedproperty.id where multidatastream_to_observedproperty.multidatastream_id = %v order by multidatastream_to_observedproperty.rank "+CreateTopSkipQueryString(qo), gdb.Schema, gdb.Schema, intID)
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.multidatastream_to_observedproperty where multidatastream_id = %v", gdb.Schema, intID)
+	return processObservedProperties(gdb.Db, sql, qo, countSQL)
+}
+
 // GetObservedProperties returns all observed properties
 func (gdb *GostDatabase) GetObservedProperties(qo *odata.QueryOptions) ([]*entities.ObservedProperty, int, error) {
 	sql := fmt.Sprintf("select "+CreateSelectString(&entities.ObservedProperty{}, qo, "", "", nil)+" FROM %s.observedproperty order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema)
@@ -81,7 +92,7 @@ func (gdb *GostDatabase) GetObservedProperties(qo *odata.QueryOptions) ([]*entit
 	return processObservedProperties(gdb.Db, sql, qo, countSQL)
 }
 
-func processObservedProperty(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.ObservedProperty, error) {
+func processObservedProperty(db Executor, sql string, qo *odata.QueryOptions) (*entities.ObservedProperty, error) {
 	observedProperties, _, err := processObservedProperties(db, sql, qo, "")
 	if err != nil {
 		return nil, err
@@ -94,13 +105,13 @@ func processObservedProperty(db *sql.DB, sql string, qo *odata.QueryOptions) (*e
 	return observedProperties[0], nil
 }
 
-func processObservedProperties(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.ObservedProperty, int, error) {
+func processObservedProperties(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.ObservedProperty, int, error) {
 	rows, err := db.Query(sql)
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, filterQueryError(err)
 	}
-
 	defer rows.Close()
+
 	var observedProperties = []*entities.ObservedProperty{}
 
 	for rows.Next() {
@@ -110,13 +121,7 @@ func processObservedProperties(db *sql.DB, sql string, qo *odata.QueryOptions, c
 		var description string
 
 		var params []interface{}
-		var qp []string
-		if qo == nil || qo.QuerySelect == nil || len(qo.QuerySelect.Params) == 0 {
-			op := &entities.ObservedProperty{}
-			qp = op.GetPropertyNames()
-		} else {
-			qp = qo.QuerySelect.Params
-		}
+		qp := selectedProperties(&entities.ObservedProperty{}, qo)
 
 		for _, p := range qp {
 			p := strings.ToLower(p)
@@ -148,9 +153,13 @@ func processObservedProperties(db *sql.DB, sql string, qo *odata.QueryOptions, c
 		observedProperties = append(observedProperties, &op)
 	}
 
-	var count int
-	if len(countSQL) > 0 {
-		db.QueryRow(countSQL).Scan(&count)
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
 	}
 
 	return observedProperties, count, nil
@@ -169,22 +178,15 @@ func (gdb *GostDatabase) PostObservedProperty(op *entities.ObservedProperty) (*e
 	return op, nil
 }
 
-// PutObservedProperty updates a ObservedProperty in the database
+// PutObservedProperty replaces an ObservedProperty in the database, all properties of an ObservedProperty
+// are mandatory
 func (gdb *GostDatabase) PutObservedProperty(id interface{}, op *entities.ObservedProperty) (*entities.ObservedProperty, error) {
-	return gdb.PatchObservedProperty(id, op)
-	/*intID, _ := ToIntID(id)
-	if !gdb.ObservedPropertyExists(intID) {
-		return nil, gostErrors.NewRequestNotFound(errors.New("FeatureOfInterest does not exist"))
-	}
-
-	sql := fmt.Sprintf("update %s.observedproperty set name=$1, definition=$2, description=$3 where id=$4", gdb.Schema)
-	_, err := gdb.Db.Exec(sql, op.Name, op.Definition, op.Description, intID)
+	intID, err := gdb.putEntity(entities.EntityTypeObservedProperty, id, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	op.ID = intID
-	return op, nil*/
+	return gdb.PatchObservedProperty(intID, op)
 }
 
 // ObservedPropertyExists checks if a ObservedProperty is present in the database based on a given id.
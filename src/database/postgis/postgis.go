@@ -8,6 +8,7 @@ import (
 	"log"
 
 	"encoding/json"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -15,7 +16,7 @@ import (
 	"github.com/geodan/gost/src/sensorthings/entities"
 	"github.com/geodan/gost/src/sensorthings/models"
 	"github.com/geodan/gost/src/sensorthings/odata"
-	_ "github.com/lib/pq" // postgres driver
+	"github.com/lib/pq" // postgres driver
 )
 
 const (
@@ -34,10 +35,18 @@ type GostDatabase struct {
 	Ssl          bool
 	MaxIdeConns  int
 	MaxOpenConns int
-	Db           *sql.DB
+	Db           Executor
 	QueryBuilder *QueryBuilder
 }
 
+// Executor runs the queries of the database, implemented by *sql.DB and by *sql.Tx
+// when the queries are part of a transaction
+type Executor interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 // NewDatabase initialises the PostgreSQL database
 //	host = TCP host:port or Unix socket depending on Network.
 //	user = database user
@@ -80,6 +89,38 @@ func (gdb *GostDatabase) Start() {
 	log.Printf("Connected to database, host: \"%v\", port: \"%v\" user: \"%v\", database: \"%v\", schema: \"%v\" ssl: \"%v\"", gdb.Host, gdb.Port, gdb.User, gdb.Database, gdb.Schema, gdb.Ssl)
 }
 
+// WithTransaction runs fn with a copy of the database of which all queries are part of one transaction, the
+// transaction is committed when fn succeeds and rolled back when fn returns an error. When the database is
+// already part of a transaction fn joins the existing transaction. The transaction is rolled back as well when
+// fn panics, after which the panic is passed on
+func (gdb *GostDatabase) WithTransaction(fn func(db models.Database) error) error {
+	conn, ok := gdb.Db.(*sql.DB)
+	if !ok {
+		return fn(gdb)
+	}
+
+	tx, err := conn.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+
+	txDB := *gdb
+	txDB.Db = tx
+	if err = fn(&txDB); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
 // CreateSchema creates the needed schema in the database
 func (gdb *GostDatabase) CreateSchema(location string) error {
 	create, err := GetCreateDatabaseQuery(location, gdb.Schema)
@@ -140,6 +181,27 @@ func EntityExists(gdb *GostDatabase, id interface{}, entityName string) bool {
 	return result
 }
 
+// ExistingEntities returns which of the given ids are present in the table of the entity
+func ExistingEntities(gdb *GostDatabase, ids []int, entityName string) (map[int]bool, error) {
+	existing := map[int]bool{}
+	rows, err := gdb.Db.Query(fmt.Sprintf("SELECT id FROM %s.%s WHERE id = ANY($1)", gdb.Schema, entityName), pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		if err = rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		existing[id] = true
+	}
+
+	return existing, rows.Err()
+}
+
 // DeleteEntity deletes a record from database for entity
 func DeleteEntity(gdb *GostDatabase, id interface{}, entityName string) error {
 	intID, ok := ToIntID(id)
@@ -160,6 +222,57 @@ func DeleteEntity(gdb *GostDatabase, id interface{}, entityName string) error {
 	return nil
 }
 
+// LockEntity locks the row of the entity until the end of the transaction, a concurrent change of the entity
+// waits until the transaction is committed or rolled back. A not found error is returned when the entity does
+// not exist
+func (gdb *GostDatabase) LockEntity(entityType entities.EntityType, id interface{}) error {
+	intID, ok := ToIntID(id)
+	table, known := tableMappings[entityType]
+	if !ok || !known {
+		return gostErrors.NewRequestNotFound(fmt.Errorf("%s does not exist", entityType.ToString()))
+	}
+
+	var lockedID int
+	err := gdb.Db.QueryRow(fmt.Sprintf("SELECT id FROM %s.%s WHERE id = $1 FOR UPDATE", gdb.Schema, table), intID).Scan(&lockedID)
+	if err == sql.ErrNoRows {
+		return gostErrors.NewRequestNotFound(fmt.Errorf("%s does not exist", entityType.ToString()))
+	}
+
+	return err
+}
+
+// ResolveNavigation returns the id of the entity of type child which is related to the parent entity, when
+// childID is given it is only returned when that child belongs to the parent. A not found error is returned
+// when the parent has no such related entity
+func (gdb *GostDatabase) ResolveNavigation(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (interface{}, error) {
+	pID, ok := ToIntID(parentID)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(fmt.Errorf("%s does not exist", parent.ToString()))
+	}
+
+	var cID interface{}
+	if childID != nil {
+		if cID, ok = ToIntID(childID); !ok {
+			return nil, gostErrors.NewRequestNotFound(fmt.Errorf("%s does not exist", child.ToString()))
+		}
+	}
+
+	query, err := gdb.QueryBuilder.CreateNavigationQuery(parent, pID, child, cID)
+	if err != nil {
+		return nil, gostErrors.NewRequestNotFound(err)
+	}
+
+	var id int
+	err = gdb.Db.QueryRow(query).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, gostErrors.NewRequestNotFound(fmt.Errorf("%s(%v) has no related %s", parent.ToString(), pID, child.ToString()))
+	} else if err != nil {
+		return nil, err
+	}
+
+	return id, nil
+}
+
 // JSONToMap converts a string of json into a map
 func JSONToMap(data *string) (map[string]interface{}, error) {
 	var p map[string]interface{}
@@ -195,55 +308,97 @@ func ToIntID(id interface{}) (int, bool) {
 	return intID, true
 }
 
+// geoJSON is the GeoJSON of a geometry column which is updated by updateEntityColumns, the GeoJSON is passed as
+// a parameter to ST_GeomFromGeoJSON
+type geoJSON string
+
+// newGeoJSON marshals the geometry of an entity for updateEntityColumns
+func newGeoJSON(geometry map[string]interface{}) geoJSON {
+	b, _ := json.Marshal(geometry)
+	return geoJSON(b)
+}
+
 func (gdb *GostDatabase) updateEntityColumns(table string, updates map[string]interface{}, entityID int) error {
 	if len(updates) == 0 {
 		return nil
 	}
 
+	sql, args := createUpdateQuery(gdb.Schema, table, updates, entityID)
+	_, err := gdb.Db.Exec(sql, args...)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// createUpdateQuery creates the query updating the given columns of an entity, all values are passed as parameters,
+// a nil value sets the column to NULL and a geoJSON value is converted to a geometry
+func createUpdateQuery(schema, table string, updates map[string]interface{}, entityID int) (string, []interface{}) {
+	keys := make([]string, 0, len(updates))
+	for k := range updates {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
 	columns := ""
 	prefix := ""
-	for k, v := range updates {
-		switch t := v.(type) {
-		case string:
-			// do not format when value contains ST_ at the start
-			if !strings.HasPrefix(t, "ST_") {
-				v = fmt.Sprintf("'%s'", t)
-			}
+	args := []interface{}{}
+	for _, k := range keys {
+		value := "NULL"
+		switch t := updates[k].(type) {
+		case nil:
+		case geoJSON:
+			args = append(args, string(t))
+			value = fmt.Sprintf("ST_SetSRID(public.ST_GeomFromGeoJSON($%v::text),4326)", len(args))
+		default:
+			args = append(args, t)
+			value = fmt.Sprintf("$%v", len(args))
 		}
 
-		columns += fmt.Sprintf("%s%s=%v", prefix, k, v)
-		if prefix != ", " {
-			prefix = ", "
-		}
+		columns += fmt.Sprintf("%s%s=%s", prefix, k, value)
+		prefix = ", "
 	}
 
-	sql := fmt.Sprintf("update %s.%s set %s where id = $1", gdb.Schema, table, columns)
-	_, err := gdb.Db.Exec(sql, entityID)
-	if err != nil {
-		return err
+	args = append(args, entityID)
+	return fmt.Sprintf("update %s.%s set %s where id = $%v", schema, table, columns, len(args)), args
+}
+
+// putEntity prepares the replacement of an entity by a PUT, the optional columns which are omitted are cleared and
+// the entity is linked to the given related entities, the remaining properties are set by the patch of the entity
+func (gdb *GostDatabase) putEntity(et entities.EntityType, id interface{}, cleared []string, relations map[entities.EntityType]interface{}) (int, error) {
+	intID, ok := ToIntID(id)
+	if !ok || !EntityExists(gdb, intID, tableMappings[et]) {
+		return 0, gostErrors.NewRequestNotFound(fmt.Errorf("%s does not exist", et.ToString()))
 	}
 
-	return nil
-}
+	updates := make(map[string]interface{})
+	for _, c := range cleared {
+		updates[c] = nil
+	}
 
-// CreateSelectString creates a select string based on available parameters and or QuerySelect option
-func CreateSelectString(e entities.Entity, qo *odata.QueryOptions, prefix string, trail string, mapping map[string]string) string {
-	var properties []string
+	if err := gdb.updateEntityColumns(tableMappings[et], updates, intID); err != nil {
+		return 0, err
+	}
 
-	if qo == nil || qo.QuerySelect == nil || len(qo.QuerySelect.Params) == 0 {
-		properties = e.GetPropertyNames()
-	} else {
-		for _, p := range qo.QuerySelect.Params {
-			for _, pn := range e.GetPropertyNames() {
-				if strings.ToLower(p) == strings.ToLower(pn) {
-					properties = append(properties, pn)
-				}
-			}
+	for child, childID := range relations {
+		if childID == nil {
+			return 0, gostErrors.NewBadRequestError(fmt.Errorf("Unable to deep insert %s in a PUT, use the @iot.id of an existing %s", child.ToString(), child.ToString()))
+		}
+
+		if _, err := gdb.LinkEntities(et, intID, child, childID); err != nil {
+			return 0, err
 		}
 	}
 
+	return intID, nil
+}
+
+// CreateSelectString creates a select string based on available parameters and or QuerySelect option
+func CreateSelectString(e entities.Entity, qo *odata.QueryOptions, prefix string, trail string, mapping map[string]string) string {
 	s := ""
-	for _, p := range properties {
+	for _, p := range selectedProperties(e, qo) {
 		skip := false
 		for _, e := range entities.EntityTypeList {
 			if p == e.ToString() {
@@ -284,6 +439,26 @@ func CreateSelectString(e entities.Entity, qo *odata.QueryOptions, prefix string
 	return s
 }
 
+// selectedProperties returns the properties of the entity to retrieve from the database in the order of $select,
+// names are matched case-insensitive and returned as defined by the entity. The id is always retrieved since
+// it is needed to create the links of the entity and to expand it, the api removes the id when it is not selected
+func selectedProperties(e entities.Entity, qo *odata.QueryOptions) []string {
+	if qo == nil || qo.QuerySelect.IsNil() {
+		return e.GetPropertyNames()
+	}
+
+	properties := []string{"id"}
+	for _, p := range qo.QuerySelect.Params {
+		for _, pn := range e.GetPropertyNames() {
+			if strings.ToLower(p) == strings.ToLower(pn) && !ContainsToLower(properties, pn) {
+				properties = append(properties, pn)
+			}
+		}
+	}
+
+	return properties
+}
+
 // CreateTopSkipQueryString creates a LIMIT and OFFSET query string
 func CreateTopSkipQueryString(qo *odata.QueryOptions) string {
 	q := ""
@@ -297,36 +472,271 @@ func CreateTopSkipQueryString(qo *odata.QueryOptions) string {
 	return q
 }
 
-// CreateFilterQueryString converts an OData query string found in odata.QueryOptions.QueryFilter to a PostgreSQL query string
-// ParamFactory is used for converting SensorThings parameter names to postgres field names
-// Convert receives a name such as phenomenonTime and returns "data ->> 'id'" true, returns
-// false if parameter cannot be converted
-func CreateFilterQueryString(qo *odata.QueryOptions, paramFactory func(string, interface{}) (string, string, error), prefix string) (string, error) {
+// CreateFilterQueryString converts the parsed filter found in odata.QueryOptions.QueryFilter to a PostgreSQL query string
+// resolver is used for converting SensorThings parameter names to postgres field names, it receives
+// a path such as [phenomenonTime] and returns data -> 'phenomenonTime', returns an error
+// if the parameter cannot be converted
+func CreateFilterQueryString(qo *odata.QueryOptions, resolver FilterPropertyResolver, prefix string) (string, error) {
 	q := ""
 	if qo != nil && !qo.QueryFilter.IsNil() {
-		q += prefix
-		ps, ops := qo.QueryFilter.Predicate.Split()
-		for i, p := range ps {
-			var left, right, operator string
-			var err error
-
-			if left, right, err = paramFactory(fmt.Sprintf("%v", p.Left), p.Right); err != nil {
-				return "", err
-			}
+		condition, err := BuildFilter(qo.QueryFilter.Expression, resolver)
+		if err != nil {
+			return "", err
+		}
+
+		q += prefix + condition + " "
+	}
+
+	return q, nil
+}
+
+// CreateOrderByQueryString converts the $orderby found in odata.QueryOptions to a PostgreSQL ORDER BY
+// expression, properties are mapped to columns using resolver, keys inside json properties are
+// ordered by their json value and properties of a single related entity such as Datastream/name
+// are selected with a subquery. Returns defaultOrderBy when no $orderby is given
+func CreateOrderByQueryString(qo *odata.QueryOptions, resolver FilterPropertyResolver, defaultOrderBy string) (string, error) {
+	if qo == nil || qo.QueryOrderBy.IsNil() || len(qo.QueryOrderBy.Params) == 0 {
+		return defaultOrderBy, nil
+	}
+
+	orderBy := make([]string, 0)
+	for _, p := range qo.QueryOrderBy.Params {
+		sql, _, err := orderByColumn(p, resolver)
+		if err != nil {
+			return "", err
+		}
+
+		orderBy = append(orderBy, fmt.Sprintf("%s %s", sql, strings.ToUpper(p.Suffix)))
+	}
+
+	return strings.Join(orderBy, ", "), nil
+}
+
+// orderByColumn returns the SQL of an $orderby property, a navigation path is turned into sub selects.
+// json is true when the SQL results in a jsonb value
+func orderByColumn(p odata.OrderByParam, resolver FilterPropertyResolver) (sql string, json bool, err error) {
+	column, err := resolver(strings.Split(p.Property, "/"))
+	if err != nil {
+		return "", false, err
+	}
+
+	if len(column.Scalar) != len(column.Exists) {
+		return "", false, fmt.Errorf("Unable to order by %s, navigation path leads to multiple entities", p.Property)
+	}
+
+	if column.Type == odata.ValueTypeGeometry {
+		return "", false, fmt.Errorf("Unable to order by geometry %s", p.Property)
+	}
+
+	sql = column.SQL
+	for i := len(column.Scalar) - 1; i >= 0; i-- {
+		sql = fmt.Sprintf("(SELECT %s %s)", sql, column.Scalar[i])
+	}
+
+	return sql, column.JSON, nil
+}
 
-			if operator, err = OdataOperatorToPostgreSQL(p.Operator); err != nil {
-				return "", err
+// keysetKey is a column used to order a keyset paginated query
+type keysetKey struct {
+	sql       string
+	direction string
+	json      bool
+	isID      bool
+}
+
+// CreateKeysetQueryString creates the ORDER BY for keyset pagination, the select of the keys of a row and, when
+// a $skiptoken is given, the condition prefixed by prefix which selects the rows following the row the token was
+// created for. The keys are the $orderby properties followed by the id, they are compared on the columns used in
+// the ORDER BY so an index on the columns can be used. The values of the token are returned as args and are
+// referenced by the condition as $1..$n, NULL keys are ordered as PostgreSQL does: last when ascending and first
+// when descending
+func CreateKeysetQueryString(qo *odata.QueryOptions, resolver FilterPropertyResolver, idColumn string, prefix string) (orderBy string, condition string, keys string, args []interface{}, err error) {
+	var keyset []keysetKey
+	direction := "DESC"
+	hasID := false
+	if qo != nil && !qo.QueryOrderBy.IsNil() {
+		for _, p := range qo.QueryOrderBy.Params {
+			sql, json, err := orderByColumn(p, resolver)
+			if err != nil {
+				return "", "", "", nil, err
 			}
 
-			q += fmt.Sprintf("%v %v %v", left, operator, right)
-			if len(ops)-1 >= i {
-				q += fmt.Sprintf(" %v ", ops[i])
+			direction = strings.ToUpper(p.Suffix)
+			if sql == idColumn {
+				hasID = true
+				keyset = append(keyset, keysetKey{idColumn, direction, false, true})
+				continue
 			}
+
+			keyset = append(keyset, keysetKey{sql, direction, json, false})
 		}
-		q += " "
 	}
 
-	return q, nil
+	// the id makes the order unique when the values of the other keys are equal
+	if !hasID {
+		keyset = append(keyset, keysetKey{idColumn, direction, false, true})
+	}
+
+	orderBys := make([]string, len(keyset))
+	selects := make([]string, len(keyset))
+	sameDirection := true
+	for i, k := range keyset {
+		orderBys[i] = fmt.Sprintf("%s %s", k.sql, k.direction)
+		selects[i] = k.sql
+		if k.json {
+			// a jsonb key is wrapped in an array to tell a missing key (NULL) apart from a json null
+			selects[i] = fmt.Sprintf("CASE WHEN %s IS NULL THEN NULL ELSE jsonb_build_array(%s) END", k.sql, k.sql)
+		}
+
+		sameDirection = sameDirection && k.direction == keyset[0].direction
+	}
+
+	orderBy = strings.Join(orderBys, ", ")
+	keys = fmt.Sprintf("jsonb_build_array(%s)::text", strings.Join(selects, ", "))
+	if qo == nil || qo.QuerySkipToken.IsNil() {
+		return orderBy, "", keys, nil, nil
+	}
+
+	if len(qo.QuerySkipToken.Values) != len(keyset) {
+		return "", "", "", nil, fmt.Errorf("The $skiptoken does not match the requested $orderby")
+	}
+
+	columns := make([]string, len(keyset))
+	params := make([]string, len(keyset))
+	hasNull := false
+	for i, k := range keyset {
+		value, err := keysetValue(k, qo.QuerySkipToken.Values[i])
+		if err != nil {
+			return "", "", "", nil, err
+		}
+
+		columns[i] = k.sql
+		if value == nil {
+			hasNull = true
+			continue
+		}
+
+		args = append(args, value)
+		params[i] = fmt.Sprintf("$%v", len(args))
+		if k.json {
+			params[i] += "::jsonb"
+		}
+	}
+
+	if sameDirection && !hasNull {
+		condition = keysetRowCondition(keyset, columns, params)
+	} else {
+		condition = keysetCondition(keyset, params)
+	}
+
+	return orderBy, fmt.Sprintf("%s(%s) ", prefix, condition), keys, args, nil
+}
+
+// keysetRowCondition compares the keys as a row (k1, k2) > ($1, $2), which is used when all keys are ordered
+// in the same direction and the token holds no NULL values. A row with a NULL key is not selected by the row
+// comparison, when ascending these rows follow the rows with a value and are selected separately
+func keysetRowCondition(keyset []keysetKey, columns []string, params []string) string {
+	if keyset[0].direction == "DESC" {
+		return fmt.Sprintf("(%s) < (%s)", strings.Join(columns, ", "), strings.Join(params, ", "))
+	}
+
+	conditions := []string{fmt.Sprintf("(%s) > (%s)", strings.Join(columns, ", "), strings.Join(params, ", "))}
+	var equals []string
+	for i, k := range keyset {
+		if k.isID {
+			break
+		}
+
+		conditions = append(conditions, strings.Join(append(equals, fmt.Sprintf("%s IS NULL", k.sql)), " AND "))
+		equals = append(equals, fmt.Sprintf("%s = %s", k.sql, params[i]))
+	}
+
+	return "(" + strings.Join(conditions, ") OR (") + ")"
+}
+
+// keysetCondition selects the rows following the token as (k1 > $1) OR (k1 = $1 AND k2 > $2) OR ..., < is used
+// for keys ordered descending. A NULL value of the token has no parameter
+func keysetCondition(keyset []keysetKey, params []string) string {
+	var equals, conditions []string
+	for i, k := range keyset {
+		var after, equal string
+		switch {
+		case params[i] == "" && k.direction == "DESC":
+			after, equal = fmt.Sprintf("%s IS NOT NULL", k.sql), fmt.Sprintf("%s IS NULL", k.sql)
+		case params[i] == "":
+			after, equal = "FALSE", fmt.Sprintf("%s IS NULL", k.sql)
+		case k.direction == "DESC":
+			after, equal = fmt.Sprintf("%s < %s", k.sql, params[i]), fmt.Sprintf("%s = %s", k.sql, params[i])
+		default:
+			after, equal = fmt.Sprintf("(%s > %s OR %s IS NULL)", k.sql, params[i], k.sql), fmt.Sprintf("%s = %s", k.sql, params[i])
+		}
+
+		conditions = append(conditions, strings.Join(append(equals, after), " AND "))
+		equals = append(equals, equal)
+	}
+
+	return "(" + strings.Join(conditions, ") OR (") + ")"
+}
+
+// keysetValue converts a value of a $skiptoken into the query parameter for the given key, nil is returned for
+// a NULL key. A jsonb key is passed as json text, other values are passed as text and converted by PostgreSQL
+// into the type of the column
+func keysetValue(k keysetKey, value json.RawMessage) (interface{}, error) {
+	if k.isID {
+		var id int64
+		if err := json.Unmarshal(value, &id); err != nil {
+			return nil, fmt.Errorf("The $skiptoken contains an invalid id")
+		}
+
+		return id, nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(value, &v); err != nil {
+		return nil, fmt.Errorf("The $skiptoken contains an invalid value")
+	}
+
+	if v == nil {
+		return nil, nil
+	}
+
+	if k.json {
+		var wrapped []json.RawMessage
+		if err := json.Unmarshal(value, &wrapped); err != nil || len(wrapped) != 1 {
+			return nil, fmt.Errorf("The $skiptoken contains an invalid value")
+		}
+
+		return string(wrapped[0]), nil
+	}
+
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+
+	return string(value), nil
+}
+
+// countIfRequested runs countSQL when $count=true is requested, when the count is not requested
+// the count query is not executed and 0 is returned
+func countIfRequested(db Executor, countSQL string, qo *odata.QueryOptions) (int, error) {
+	if len(countSQL) == 0 || qo == nil || !qo.QueryCount.IsTrue() {
+		return 0, nil
+	}
+
+	return ExecuteSelectCount(db, countSQL)
+}
+
+// filterQueryError converts errors raised by PostgreSQL while evaluating a $filter into a bad request,
+// for example when a numeric operation is used on an observation result which is not a number
+func filterQueryError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "22P02", "22007", "22008", "22003", "22012": // invalid input, invalid or out of range date time, out of range number, division by zero
+			return gostErrors.NewBadRequestError(fmt.Errorf("Unable to apply $filter, a value is not of the expected type: %s", pqErr.Message))
+		}
+	}
+
+	return err
 }
 
 // OdataOperatorToPostgreSQL converts an odata.OdataOperator to a PostgreSQL string representation
@@ -352,6 +762,16 @@ func OdataOperatorToPostgreSQL(o odata.Operator) (string, error) {
 		return "<=", nil
 	case odata.IsNull:
 		return "IS NULL", nil
+	case odata.Addition:
+		return "+", nil
+	case odata.Subtraction:
+		return "-", nil
+	case odata.Multiplication:
+		return "*", nil
+	case odata.Division:
+		return "/", nil
+	case odata.Modulo:
+		return "%", nil
 	}
 
 	return "", fmt.Errorf("Operator %v not implemented", o.ToString())
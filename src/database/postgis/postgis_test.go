@@ -0,0 +1,175 @@
+package postgis
+
+import (
+	"database/sql"
+	"net/http"
+	"strings"
+	"testing"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/odata"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateSelectStringShouldAlwaysSelectID(t *testing.T) {
+	//arrange
+	qo, _ := odata.CreateQueryOptions(map[string]string{"$select": "UnitOfMeasurement,name,Thing,@iot.selfLink,NAME"})
+
+	//act
+	properties := selectedProperties(&entities.Datastream{}, qo)
+	all := selectedProperties(&entities.Datastream{}, nil)
+	selectString := CreateSelectString(&entities.Datastream{}, qo, "", "", nil)
+
+	//assert
+	assert.Equal(t, []string{"id", "unitOfMeasurement", "name"}, properties)
+	assert.Equal(t, (&entities.Datastream{}).GetPropertyNames(), all)
+	assert.Equal(t, "id, unitOfMeasurement, name", selectString)
+}
+
+func TestCreateKeysetQueryStringShouldContinueAfterSkipToken(t *testing.T) {
+	//arrange
+	qo, _ := odata.CreateQueryOptions(map[string]string{
+		"$orderby":   "phenomenonTime asc",
+		"$skiptoken": odata.CreateSkipToken(`[["2016-01-01T00:00:00Z"], 12]`),
+	})
+
+	//act
+	orderBy, condition, keys, args, err := CreateKeysetQueryString(qo, observationParamFactoryWhere, "observation.id", " AND ")
+
+	//assert
+	key := "observation.data -> 'phenomenonTime'"
+	assert.Nil(t, err)
+	assert.Equal(t, key+" ASC, observation.id ASC", orderBy)
+	assert.Equal(t, "jsonb_build_array(CASE WHEN "+key+" IS NULL THEN NULL ELSE jsonb_build_array("+key+") END, observation.id)::text", keys)
+	assert.Equal(t, " AND ((("+key+", observation.id) > ($1::jsonb, $2)) OR ("+key+" IS NULL)) ", condition)
+	assert.Equal(t, []interface{}{`"2016-01-01T00:00:00Z"`, int64(12)}, args)
+}
+
+func TestCreateKeysetQueryStringShouldCompareKeysSeparately(t *testing.T) {
+	//arrange
+	qoDirection, _ := odata.CreateQueryOptions(map[string]string{
+		"$orderby":   "phenomenonTime asc,id desc",
+		"$skiptoken": odata.CreateSkipToken(`[["2016-01-01T00:00:00Z"], 12]`),
+	})
+	qoNull, _ := odata.CreateQueryOptions(map[string]string{
+		"$orderby":   "resultTime desc",
+		"$skiptoken": odata.CreateSkipToken(`[null, 12]`),
+	})
+
+	//act
+	_, conditionDirection, _, _, errDirection := CreateKeysetQueryString(qoDirection, observationParamFactoryWhere, "observation.id", "WHERE ")
+	_, conditionNull, _, argsNull, errNull := CreateKeysetQueryString(qoNull, observationParamFactoryWhere, "observation.id", "WHERE ")
+
+	//assert
+	phenomenonTime := "observation.data -> 'phenomenonTime'"
+	resultTime := "observation.data -> 'resultTime'"
+	assert.Nil(t, errDirection)
+	assert.Equal(t, "WHERE ((("+phenomenonTime+" > $1::jsonb OR "+phenomenonTime+" IS NULL)) OR ("+phenomenonTime+" = $1::jsonb AND observation.id < $2)) ", conditionDirection)
+	assert.Nil(t, errNull)
+	assert.Equal(t, "WHERE (("+resultTime+" IS NOT NULL) OR ("+resultTime+" IS NULL AND observation.id < $1)) ", conditionNull)
+	assert.Equal(t, []interface{}{int64(12)}, argsNull)
+}
+
+func TestCreateKeysetQueryStringShouldFailOnMismatchingSkipToken(t *testing.T) {
+	//arrange
+	qo, _ := odata.CreateQueryOptions(map[string]string{"$skiptoken": odata.CreateSkipToken(`[1, 2]`)})
+	qoID, _ := odata.CreateQueryOptions(map[string]string{"$skiptoken": odata.CreateSkipToken(`["1'; drop table"]`)})
+	qoValue, _ := odata.CreateQueryOptions(map[string]string{"$orderby": "result", "$skiptoken": odata.CreateSkipToken(`[1, 2]`)})
+
+	//act
+	orderBy, _, _, _, err := CreateKeysetQueryString(nil, observationParamFactoryWhere, "observation.id", "WHERE ")
+	_, _, _, _, errCount := CreateKeysetQueryString(qo, observationParamFactoryWhere, "observation.id", "WHERE ")
+	_, _, _, _, errID := CreateKeysetQueryString(qoID, observationParamFactoryWhere, "observation.id", "WHERE ")
+	_, _, _, _, errValue := CreateKeysetQueryString(qoValue, observationParamFactoryWhere, "observation.id", "WHERE ")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "observation.id DESC", orderBy)
+	assert.NotNil(t, errCount)
+	assert.NotNil(t, errID)
+	assert.NotNil(t, errValue, "value of a jsonb key should be wrapped in an array")
+}
+
+func TestCreateApplyQueryString(t *testing.T) {
+	//arrange
+	qo, _ := odata.CreateQueryOptions(map[string]string{
+		"$apply": "filter(result gt 0)/groupby((bucket(phenomenonTime,'P1D') as day),aggregate(result with max as max))",
+		"$top":   "10",
+	})
+
+	//act
+	sql, countSQL, err := CreateApplyQueryString(qo, observationParamFactoryWhere, "v1.observation", "observation.stream_id = 1")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Contains(t, sql, "SELECT row_to_json(a)::text FROM (SELECT to_char(to_timestamp(floor(extract(epoch from split_part(observation.data -> 'phenomenonTime' #>> '{}', '/', 1)::timestamptz) / 86400) * 86400)")
+	assert.Contains(t, sql, "max(CASE WHEN jsonb_typeof(observation.data -> 'result') = 'number' THEN (observation.data -> 'result' #>> '{}')::double precision END) AS \"max\"")
+	assert.Contains(t, sql, "WHERE observation.stream_id = 1 AND ")
+	assert.Contains(t, sql, "GROUP BY 1) a ORDER BY a.\"day\" ASC LIMIT 10")
+	assert.True(t, strings.HasPrefix(countSQL, "SELECT COUNT(*) FROM (SELECT "))
+}
+
+func TestCreateApplyQueryStringShouldFailOnInvalidAggregate(t *testing.T) {
+	//arrange
+	qoBucket, _ := odata.CreateQueryOptions(map[string]string{"$apply": "groupby((bucket(result,'PT1H') as hour),aggregate($count as n))"})
+	qoAverage, _ := odata.CreateQueryOptions(map[string]string{"$apply": "aggregate(phenomenonTime with average as avg)"})
+
+	//act
+	_, _, errBucket := CreateApplyQueryString(qoBucket, observationParamFactoryWhere, "v1.observation", "")
+	_, _, errAverage := CreateApplyQueryString(qoAverage, observationParamFactoryWhere, "v1.observation", "")
+
+	//assert
+	assert.NotNil(t, errBucket)
+	assert.NotNil(t, errAverage)
+}
+
+func TestCreateUpdateQueryShouldPassValuesAsParameters(t *testing.T) {
+	//arrange
+	updates := map[string]interface{}{
+		"name":         "O'Brien",
+		"encodingtype": 1,
+		"observedarea": nil,
+		"location":     geoJSON(`{"type":"Point","coordinates":[5,52]}`),
+	}
+
+	//act
+	sql, args := createUpdateQuery("v1", "thing", updates, 3)
+
+	//assert
+	assert.Equal(t, "update v1.thing set encodingtype=$1, location=ST_SetSRID(public.ST_GeomFromGeoJSON($2::text),4326), name=$3, observedarea=NULL where id = $4", sql)
+	assert.Equal(t, []interface{}{1, `{"type":"Point","coordinates":[5,52]}`, "O'Brien", 3}, args)
+}
+
+// failingExecutor fails every query with a division by zero as raised when evaluating a $filter
+type failingExecutor struct{}
+
+func (e failingExecutor) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return nil, &pq.Error{Code: "22012"}
+}
+
+func (e failingExecutor) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return nil, &pq.Error{Code: "22012"}
+}
+
+func (e failingExecutor) QueryRow(query string, args ...interface{}) *sql.Row {
+	return nil
+}
+
+func TestProcessShouldReturnBadRequestOnFailingFilter(t *testing.T) {
+	//arrange
+	db := failingExecutor{}
+
+	//act
+	_, _, errHistoricalLocations := processHistoricalLocations(db, "", nil, "")
+	_, _, errObservedProperties := processObservedProperties(db, "", nil, "")
+	_, errSelect := ExecuteSelect(db, nil, "")
+
+	//assert
+	for _, err := range []error{errHistoricalLocations, errObservedProperties, errSelect} {
+		apiErr, ok := err.(gostErrors.APIError)
+		assert.True(t, ok)
+		assert.Equal(t, http.StatusBadRequest, apiErr.GetHTTPErrorStatusCode())
+	}
+}
@@ -58,12 +58,8 @@ func (qb *QueryBuilder) getOffset(qo *odata.QueryOptions) string {
 
 // getOrderBy returns the string that needs to be placed after ORDER BY, this is set using
 // ODATA's $orderby if not given use the default ORDER BY "table".id DESC
-func (qb *QueryBuilder) getOrderBy(et entities.EntityType, qo *odata.QueryOptions) string {
-	if qo != nil && !qo.QueryOrderBy.IsNil() {
-		return fmt.Sprintf("%v %v", selectMappings[et][strings.ToLower(qo.QueryOrderBy.Property)], strings.ToUpper(qo.QueryOrderBy.Suffix))
-	}
-
-	return fmt.Sprintf("%s DESC", selectMappings[et]["id"])
+func (qb *QueryBuilder) getOrderBy(et entities.EntityType, qo *odata.QueryOptions) (string, error) {
+	return CreateOrderByQueryString(qo, qb.filterPropertyResolver(et, selectMappingsResolver(et)), fmt.Sprintf("%s DESC", selectMappings[et]["id"]))
 }
 
 // getSelect return the select string that needs to be placed after SELECT in the query
@@ -121,7 +117,7 @@ func (qb *QueryBuilder) getSelect(et entities.Entity, qo *odata.QueryOptions, ad
 	return selectString
 }
 
-func (qb *QueryBuilder) createLateralJoin(e1 entities.Entity, e2 entities.Entity, isExpand bool, qo *odata.QueryOptions, qpi *QueryParseInfo, joinString string) string {
+func (qb *QueryBuilder) createLateralJoin(e1 entities.Entity, e2 entities.Entity, isExpand bool, qo *odata.QueryOptions, qpi *QueryParseInfo, joinString string) (string, error) {
 	if e2 != nil {
 		nqo := qo
 		et2 := e2.GetEntityType()
@@ -130,6 +126,19 @@ func (qb *QueryBuilder) createLateralJoin(e1 entities.Entity, e2 entities.Entity
 			nqo = &odata.QueryOptions{
 				QuerySelect: &odata.QuerySelect{Params: []string{"id"}},
 			}
+		}
+
+		filter, err := qb.getFilterQueryString(et2, nqo, true)
+		if err != nil {
+			return "", err
+		}
+
+		orderBy, err := qb.getOrderBy(et2, nqo)
+		if err != nil {
+			return "", err
+		}
+
+		if !isExpand {
 			joinString = fmt.Sprintf("%s"+
 				"INNER JOIN LATERAL ("+
 				"SELECT %s FROM %s %s "+
@@ -138,7 +147,7 @@ func (qb *QueryBuilder) createLateralJoin(e1 entities.Entity, e2 entities.Entity
 				qb.getSelect(e2, nqo, true, true, false, ""),
 				qb.tables[et2],
 				qb.joins[et2][e1.GetEntityType()],
-				qb.getFilterQueryString(et2, nqo, true),
+				filter,
 				qb.removeSchema(qb.tables[et2]))
 		} else {
 			joinString = fmt.Sprintf("%s"+
@@ -150,8 +159,8 @@ func (qb *QueryBuilder) createLateralJoin(e1 entities.Entity, e2 entities.Entity
 				qb.getSelect(e2, nqo, true, true, false, ""),
 				qb.tables[et2],
 				qb.joins[et2][e1.GetEntityType()],
-				qb.getFilterQueryString(et2, nqo, true),
-				qb.getOrderBy(et2, nqo),
+				filter,
+				orderBy,
 				qb.getLimit(nqo),
 				qb.getOffset(nqo),
 				qb.removeSchema(qb.tables[et2]))
@@ -167,63 +176,123 @@ func (qb *QueryBuilder) createLateralJoin(e1 entities.Entity, e2 entities.Entity
 			nqpi.Init(qe.Entity.GetEntityType(), qpi.GetNextQueryIndex())
 			qpi.SubEntities = append(qpi.SubEntities, nqpi)
 
-			joinString = qb.createLateralJoin(e1, qe.Entity, true, qe.QueryOptions, nqpi, joinString)
+			var err error
+			if joinString, err = qb.createLateralJoin(e1, qe.Entity, true, qe.QueryOptions, nqpi, joinString); err != nil {
+				return "", err
+			}
 		}
 	}
 
-	return joinString
+	return joinString, nil
 }
 
-// createFilterQueryString converts an OData query string found in odata.QueryOptions.QueryFilter to a PostgreSQL query string
-// ParamFactory is used for converting SensorThings parameter names to postgres field names
-// Convert receives a name such as phenomenonTime and returns "data ->> 'id'" true, returns
-// false if parameter cannot be converted
-func (qb *QueryBuilder) getFilterQueryString(et entities.EntityType, qo *odata.QueryOptions, addWhere bool) string {
+// getFilterQueryString converts the parsed filter found in odata.QueryOptions.QueryFilter to a PostgreSQL query string,
+// properties are mapped onto the fields defined in selectMappings for the given entity type
+func (qb *QueryBuilder) getFilterQueryString(et entities.EntityType, qo *odata.QueryOptions, addWhere bool) (string, error) {
 	q := ""
 	if qo != nil && !qo.QueryFilter.IsNil() {
+		condition, err := BuildFilter(qo.QueryFilter.Expression, qb.filterPropertyResolver(et, selectMappingsResolver(et)))
+		if err != nil {
+			return "", err
+		}
+
 		if addWhere {
 			q += " WHERE "
 		}
-		ps, ops := qo.QueryFilter.Predicate.Split()
-		for i, p := range ps {
-			operator, _ := qb.odataOperatorToPostgreSQL(p.Operator)
-			q += fmt.Sprintf("%v %v %v", selectMappings[et][strings.ToLower(fmt.Sprintf("%v", p.Left))], operator, p.Right)
-			if len(ops)-1 >= i {
-				q += fmt.Sprintf(" %v ", ops[i])
-			}
-		}
-		q += " "
+		q += condition + " "
 	}
 
-	return q
+	return q, nil
+}
+
+// singleNavigations lists per entity the related entities which can be reached as a single entity,
+// for example an Observation belongs to one Datastream while a Datastream has many Observations
+var singleNavigations = map[entities.EntityType][]entities.EntityType{
+	entities.EntityTypeObservation:        {entities.EntityTypeDatastream, entities.EntityTypeMultiDatastream, entities.EntityTypeFeatureOfInterest},
+	entities.EntityTypeDatastream:         {entities.EntityTypeThing, entities.EntityTypeSensor, entities.EntityTypeObservedProperty},
+	entities.EntityTypeMultiDatastream:    {entities.EntityTypeThing, entities.EntityTypeSensor},
+	entities.EntityTypeHistoricalLocation: {entities.EntityTypeThing},
+	entities.EntityTypeTaskingCapability:  {entities.EntityTypeThing, entities.EntityTypeActuator},
+	entities.EntityTypeTask:               {entities.EntityTypeTaskingCapability},
 }
 
-// OdataOperatorToPostgreSQL converts an odata.OdataOperator to a PostgreSQL string representation
-func (qb *QueryBuilder) odataOperatorToPostgreSQL(o odata.Operator) (string, error) {
-	switch o {
-	case odata.And:
-		return "AND", nil
-	case odata.Or:
-		return "OR", nil
-	case odata.Not:
-		return "NOT", nil
-	case odata.Equals:
-		return "=", nil
-	case odata.NotEquals:
-		return "!=", nil
-	case odata.GreaterThan:
-		return ">", nil
-	case odata.GreaterThanOrEquals:
-		return ">=", nil
-	case odata.LessThan:
-		return "<", nil
-	case odata.LessThanOrEquals:
-		return "<=", nil
-	case odata.IsNull:
-		return "IS NULL", nil
+func isSingleNavigation(from, to entities.EntityType) bool {
+	for _, et := range singleNavigations[from] {
+		if et == to {
+			return true
+		}
 	}
 
-	return "", fmt.Errorf("Operator %v not implemented", o.ToString())
+	return false
+}
+
+// filterPropertyResolver creates a FilterPropertyResolver for the given entity type, properties of the entity
+// itself are resolved by resolver, navigation paths such as Datastream/Thing/name are resolved through the
+// relations defined in joins, every step in the path results in an EXISTS subquery on the related table.
+// The path after the navigation steps can address keys inside a json property: Thing/properties/owner
+func (qb *QueryBuilder) filterPropertyResolver(et entities.EntityType, resolver FilterPropertyResolver) FilterPropertyResolver {
+	return func(path []string) (*FilterColumn, error) {
+		current := et
+		visited := map[entities.EntityType]bool{et: true}
+		exists := make([]string, 0)
+		scalar := make([]string, 0)
+		single := true
+		i := 0
+		for ; i < len(path)-1; i++ {
+			next, err := entities.EntityTypeFromString(path[i])
+			if err != nil {
+				// not an entity, the remaining path addresses a property or keys inside a json property
+				break
+			}
+
+			join, ok := qb.joins[next][current]
+			if !ok {
+				return nil, fmt.Errorf("%s has no navigation property %s", current.ToString(), path[i])
+			}
+
+			if visited[next] {
+				return nil, fmt.Errorf("Navigation path %s contains %s more than once", strings.Join(path, "/"), next.ToString())
+			}
+
+			connector := "WHERE"
+			if strings.HasPrefix(join, "WHERE") {
+				connector = "AND"
+			}
+
+			exists = append(exists, fmt.Sprintf("SELECT 1 FROM %s %s %s", qb.tables[next], join, connector))
+			scalar = append(scalar, fmt.Sprintf("FROM %s %s", qb.tables[next], join))
+			single = single && isSingleNavigation(current, next)
+			visited[next] = true
+			current = next
+		}
+
+		var column *FilterColumn
+		var err error
+		if current == et {
+			column, err = resolver(path)
+		} else {
+			column, err = selectMappingsResolver(current)(path[i:])
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if current == entities.EntityTypeObservation && i == len(path)-1 && strings.ToLower(path[i]) == observationResult {
+			column.ObservationType = fmt.Sprintf("(SELECT %s FROM %s WHERE %s = %s)",
+				selectMappings[entities.EntityTypeDatastream][datastreamObservationType],
+				qb.tables[entities.EntityTypeDatastream],
+				selectMappings[entities.EntityTypeDatastream][datastreamID],
+				selectMappings[entities.EntityTypeObservation][observationStreamID])
+		}
+
+		column.Exists = exists
+		if single {
+			column.Scalar = scalar
+		}
+
+		return column, nil
+	}
 }
 
 // CreateQuery creates a new query based on given input
@@ -241,20 +310,35 @@ func (qb *QueryBuilder) CreateQuery(e1 entities.Entity, e2 entities.Entity, id i
 	qpi := &QueryParseInfo{}
 	qpi.Init(et1, 0)
 
-	queryString := fmt.Sprintf("SELECT %s FROM %s %s", qb.getSelect(e1, qo, true, true, false, ""), qb.tables[et1], qb.createLateralJoin(e1, e2, false, qo, qpi, ""))
+	join, err := qb.createLateralJoin(e1, e2, false, qo, qpi, "")
+	if err != nil {
+		return "", nil, err
+	}
+
+	queryString := fmt.Sprintf("SELECT %s FROM %s %s", qb.getSelect(e1, qo, true, true, false, ""), qb.tables[et1], join)
 	if id != nil {
 		queryString = fmt.Sprintf("%s WHERE %s.%s = %v", queryString, tableMappings[et2], asMappings[et2][idField], id)
 	}
 
-	if qo != nil && !qo.QueryFilter.IsNil() {
+	filter, err := qb.getFilterQueryString(et1, qo, id == nil)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(filter) > 0 {
 		if id != nil {
-			queryString = fmt.Sprintf("%s AND %s", queryString, qb.getFilterQueryString(et1, qo, false))
+			queryString = fmt.Sprintf("%s AND %s", queryString, filter)
 		} else {
-			queryString = fmt.Sprintf("%s %s", queryString, qb.getFilterQueryString(et1, qo, true))
+			queryString = fmt.Sprintf("%s %s", queryString, filter)
 		}
 	}
 
-	queryString = fmt.Sprintf("%s ORDER BY %s", queryString, qb.getOrderBy(et1, qo))
+	orderBy, err := qb.getOrderBy(et1, qo)
+	if err != nil {
+		return "", nil, err
+	}
+
+	queryString = fmt.Sprintf("%s ORDER BY %s", queryString, orderBy)
 	queryString = fmt.Sprintf("%s LIMIT %s OFFSET %s", queryString, qb.getLimit(qo), qb.getOffset(qo))
 
 	return queryString, qpi, nil
@@ -267,22 +351,56 @@ func (qb *QueryBuilder) CreateCountQuery(e1 entities.Entity, e2 entities.Entity,
 		et2 = e2.GetEntityType()
 	}
 
-	queryString := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", qb.tables[et1], qb.createLateralJoin(e1, e2, false, nil, nil, ""))
+	join, err := qb.createLateralJoin(e1, e2, false, nil, nil, "")
+	if err != nil {
+		return "", err
+	}
+
+	queryString := fmt.Sprintf("SELECT COUNT(*) FROM %s %s", qb.tables[et1], join)
 	if id != nil {
 		queryString = fmt.Sprintf("%s WHERE %s.%s = %v", queryString, tableMappings[et2], asMappings[et2][idField], id)
 	}
 
-	if qo != nil && !qo.QueryFilter.IsNil() {
+	filter, err := qb.getFilterQueryString(et1, qo, id == nil)
+	if err != nil {
+		return "", err
+	}
+
+	if len(filter) > 0 {
 		if id != nil {
-			queryString = fmt.Sprintf("%s AND %s", queryString, qb.getFilterQueryString(et1, qo, false))
+			queryString = fmt.Sprintf("%s AND %s", queryString, filter)
 		} else {
-			queryString = fmt.Sprintf("%s %s", queryString, qb.getFilterQueryString(et1, qo, true))
+			queryString = fmt.Sprintf("%s %s", queryString, filter)
 		}
 	}
 
 	return queryString, nil
 }
 
+// CreateNavigationQuery creates a query selecting the id of the entity of type child which is related to the
+// parent entity with the given id, when childID is not nil only the child with that id is selected. The relation
+// is taken from the joins, an error is returned when child is not a navigation property of parent
+func (qb *QueryBuilder) CreateNavigationQuery(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (string, error) {
+	join, ok := qb.joins[parent][child]
+	if !ok {
+		return "", fmt.Errorf("%s has no navigation property %s", parent.ToString(), child.ToString())
+	}
+
+	connector := "WHERE"
+	if strings.HasPrefix(join, "WHERE") {
+		connector = "AND"
+	}
+
+	condition := ""
+	if childID != nil {
+		condition = fmt.Sprintf("%s = %v AND ", selectMappings[child][idField], childID)
+	}
+
+	return fmt.Sprintf("SELECT %s FROM %s WHERE %sEXISTS (SELECT 1 FROM %s %s %s %s = %v) LIMIT 1",
+		selectMappings[child][idField], qb.tables[child], condition,
+		qb.tables[parent], join, connector, selectMappings[parent][idField], parentID), nil
+}
+
 // Test is a temporarily test function while developing
 func (qb *QueryBuilder) Test() {
 	fmt.Println("------------GET THINGS------------")
@@ -1,7 +1,6 @@
 package postgis
 
 import (
-	"database/sql"
 	"fmt"
 	"github.com/geodan/gost/src/sensorthings/entities"
 
@@ -11,21 +10,20 @@ import (
 var idAsSuffix = fmt.Sprintf("%s%s", asSeparator, idField)
 
 // ExecuteSelectCount runs a given count query and returns the value
-func ExecuteSelectCount(db *sql.DB, sql string) (int, error) {
+func ExecuteSelectCount(db Executor, sql string) (int, error) {
 	var count int
-	db.QueryRow(sql).Scan(&count)
+	err := db.QueryRow(sql).Scan(&count)
 
-	return count, nil
+	return count, err
 }
 
 // ExecuteSelect executes the select query and creates the retrieved entities
-func ExecuteSelect(db *sql.DB, q *QueryParseInfo, sql string) ([]interface{}, error) {
+func ExecuteSelect(db Executor, q *QueryParseInfo, sql string) ([]interface{}, error) {
 	rows, err := db.Query(sql)
-	defer rows.Close()
-
 	if err != nil {
-		return nil, err
+		return nil, filterQueryError(err)
 	}
+	defer rows.Close()
 
 	columns, _ := rows.Columns()
 	count := len(columns)
@@ -55,7 +53,10 @@ func ExecuteSelect(db *sql.DB, q *QueryParseInfo, sql string) ([]interface{}, er
 			valueP[i] = &values[i]
 		}
 
-		rows.Scan(valueP...)
+		if err = rows.Scan(valueP...); err != nil {
+			return nil, err
+		}
+
 		sortEntities := make(map[int]map[string]interface{})
 
 		// split a row into the desired entities (row can contain multiple entities due to join queries)
@@ -132,6 +133,10 @@ func ExecuteSelect(db *sql.DB, q *QueryParseInfo, sql string) ([]interface{}, er
 		}
 	}
 
+	if err = rows.Err(); err != nil {
+		return nil, filterQueryError(err)
+	}
+
 	for _, entity := range parentEntities {
 		parseResults(entity, 0, relationMap, subEntities)
 	}
@@ -1,7 +1,6 @@
 package postgis
 
 import (
-	"database/sql"
 	"errors"
 	"fmt"
 
@@ -77,14 +76,36 @@ func (gdb *GostDatabase) GetSensorByDatastream(id interface{}, qo *odata.QueryOp
 	return sensor, nil
 }
 
+// GetSensorByMultiDatastream retrieves a sensor by given MultiDatastream
+func (gdb *GostDatabase) GetSensorByMultiDatastream(id interface{}, qo *odata.QueryOptions) (*entities.Sensor, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("MultiDatastream does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Sensor{}, qo, "sensor.", "", nil)+" from %s.sensor inner join %s.multidatastream on multidatastream.sensor_id = sensor.id where multidatastream.id = %v", gdb.Schema, gdb.Schema, intID)
+	return processSensor(gdb.Db, sql, qo)
+}
+
 // GetSensors retrieves all sensors based on the QueryOptions
 func (gdb *GostDatabase) GetSensors(qo *odata.QueryOptions) ([]*entities.Sensor, int, error) {
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Sensor{}, qo, "", "", nil)+" FROM %s.sensor order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema)
-	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.sensor", gdb.Schema)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeSensor, selectMappingsResolver(entities.EntityTypeSensor))
+	queryString, err := CreateFilterQueryString(qo, resolver, "WHERE ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Sensor{}, qo, "", "", nil)+" FROM %s.sensor %sorder by %s %s", gdb.Schema, queryString, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.sensor %s", gdb.Schema, queryString)
 	return processSensors(gdb.Db, sql, qo, countSQL)
 }
 
-func processSensor(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.Sensor, error) {
+func processSensor(db Executor, sql string, qo *odata.QueryOptions) (*entities.Sensor, error) {
 	sensors, _, err := processSensors(db, sql, qo, "")
 	if err != nil {
 		return nil, err
@@ -97,15 +118,13 @@ func processSensor(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.Se
 	return sensors[0], nil
 }
 
-func processSensors(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Sensor, int, error) {
+func processSensors(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Sensor, int, error) {
 	rows, err := db.Query(sql)
-	defer rows.Close()
-
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, filterQueryError(err)
 	}
-
 	defer rows.Close()
+
 	var sensors = []*entities.Sensor{}
 
 	for rows.Next() {
@@ -114,13 +133,7 @@ func processSensors(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL str
 		var name, description, metadata string
 
 		var params []interface{}
-		var qp []string
-		if qo == nil || qo.QuerySelect == nil || len(qo.QuerySelect.Params) == 0 {
-			s := &entities.Sensor{}
-			qp = s.GetPropertyNames()
-		} else {
-			qp = qo.QuerySelect.Params
-		}
+		qp := selectedProperties(&entities.Sensor{}, qo)
 
 		for _, p := range qp {
 			p = strings.ToLower(p)
@@ -158,9 +171,13 @@ func processSensors(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL str
 		sensors = append(sensors, &sensor)
 	}
 
-	var count int
-	if len(countSQL) > 0 {
-		db.QueryRow(countSQL).Scan(&count)
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
 	}
 
 	return sensors, count, nil
@@ -225,30 +242,14 @@ func (gdb *GostDatabase) PatchSensor(id interface{}, s *entities.Sensor) (*entit
 	return ns, nil
 }
 
-// PutSensor receives a Sensor entity and changes it in the database
-// returns the Sensor
+// PutSensor replaces a Sensor in the database, all properties of a Sensor are mandatory
 func (gdb *GostDatabase) PutSensor(id interface{}, sensor *entities.Sensor) (*entities.Sensor, error) {
-	return gdb.PatchSensor(id, sensor)
-	/*var intID int
-	var ok bool
-
-	if intID, ok = ToIntID(id); !ok || !gdb.SensorExists(intID) {
-		return nil, gostErrors.NewRequestNotFound(errors.New("Sensor does not exist"))
-	}
-	encoding, err1 := entities.CreateEncodingType(sensor.EncodingType)
-	if err1 != nil {
-		return nil, err1
-	}
-
-	// INSERT INTO %s.sensor (name, description, encodingtype, metadata) VALUES ($1, $2, $3, $4)
-	sql := fmt.Sprintf("update %s.sensor set name=$1, description=$2,encodingtype=$3, metadata=$4 where id=$5", gdb.Schema)
-	_, err := gdb.Db.Exec(sql, sensor.Name, sensor.Description, encoding.Code, sensor.Metadata, intID)
+	intID, err := gdb.putEntity(entities.EntityTypeSensor, id, nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	ns, _ := gdb.GetSensor(intID, nil)
-	return ns, nil*/
+	return gdb.PatchSensor(intID, sensor)
 }
 
 // DeleteSensor tries to delete a Sensor by the given id
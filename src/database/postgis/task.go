@@ -0,0 +1,227 @@
+package postgis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+var taskMapping = map[string]string{"creationTime": fmt.Sprintf("to_char(task.creationtime at time zone 'UTC', '%s') as creationtime", TimeFormat)}
+
+func taskParamFactory(values map[string]interface{}) (entities.Entity, error) {
+	t := &entities.Task{}
+	for as, value := range values {
+		if value == nil {
+			continue
+		}
+
+		if as == asMappings[entities.EntityTypeTask][taskID] {
+			t.ID = value
+		} else if as == asMappings[entities.EntityTypeTask][taskCreationTime] {
+			t.CreationTime = value.(string)
+		} else if as == asMappings[entities.EntityTypeTask][taskTaskingParameters] {
+			p := value.(string)
+			parametersMap, err := JSONToMap(&p)
+			if err != nil {
+				return nil, err
+			}
+			t.TaskingParameters = parametersMap
+		}
+	}
+
+	return t, nil
+}
+
+// GetTask retrieves a Task by id
+func (gdb *GostDatabase) GetTask(id interface{}, qo *odata.QueryOptions) (*entities.Task, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("Task does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Task{}, qo, "", "", taskMapping)+" FROM %s.task where id = %v", gdb.Schema, intID)
+	return processTask(gdb.Db, sql, qo)
+}
+
+// GetTasks retrieves all Tasks
+func (gdb *GostDatabase) GetTasks(qo *odata.QueryOptions) ([]*entities.Task, int, error) {
+	return gdb.getTasks("", qo)
+}
+
+// GetTasksByTaskingCapability retrieves all Tasks created for the given TaskingCapability
+func (gdb *GostDatabase) GetTasksByTaskingCapability(taskingCapabilityID interface{}, qo *odata.QueryOptions) ([]*entities.Task, int, error) {
+	intID, ok := ToIntID(taskingCapabilityID)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("TaskingCapability does not exist"))
+	}
+
+	return gdb.getTasks(fmt.Sprintf("task.taskingcapability_id = %v", intID), qo)
+}
+
+// getTasks retrieves the Tasks matching the given condition and $filter
+func (gdb *GostDatabase) getTasks(condition string, qo *odata.QueryOptions) ([]*entities.Task, int, error) {
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeTask, selectMappingsResolver(entities.EntityTypeTask))
+	prefix := "WHERE "
+	if len(condition) > 0 {
+		condition = "WHERE " + condition + " "
+		prefix = " AND "
+	}
+
+	queryString, err := CreateFilterQueryString(qo, resolver, prefix)
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "task.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Task{}, qo, "task.", "", taskMapping)+" FROM %s.task %s%sorder by %s%s", gdb.Schema, condition, queryString, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.task %s%s", gdb.Schema, condition, queryString)
+	return processTasks(gdb.Db, sql, qo, countSQL)
+}
+
+func processTask(db Executor, sql string, qo *odata.QueryOptions) (*entities.Task, error) {
+	tasks, _, err := processTasks(db, sql, qo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tasks) == 0 {
+		return nil, gostErrors.NewRequestNotFound(errors.New("Task does not exist"))
+	}
+
+	return tasks[0], nil
+}
+
+func processTasks(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Task, int, error) {
+	rows, err := db.Query(sql)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+	defer rows.Close()
+
+	var tasks = []*entities.Task{}
+	for rows.Next() {
+		var id interface{}
+		var creationTime string
+		var taskingParameters *string
+
+		var params []interface{}
+		for _, p := range selectedProperties(&entities.Task{}, qo) {
+			switch strings.ToLower(p) {
+			case "id":
+				params = append(params, &id)
+			case "creationtime":
+				params = append(params, &creationTime)
+			case "taskingparameters":
+				params = append(params, &taskingParameters)
+			}
+		}
+
+		if err = rows.Scan(params...); err != nil {
+			return nil, 0, err
+		}
+
+		parametersMap, err := JSONToMap(taskingParameters)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		task := entities.Task{}
+		task.ID = id
+		task.CreationTime = creationTime
+		task.TaskingParameters = parametersMap
+		tasks = append(tasks, &task)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	return tasks, count, nil
+}
+
+// PostTask adds a Task to the database, the creationTime of the Task is set to the current time
+func (gdb *GostDatabase) PostTask(t *entities.Task) (*entities.Task, error) {
+	tcID, ok := ToIntID(t.TaskingCapability.ID)
+	if !ok || !gdb.TaskingCapabilityExists(tcID) {
+		return nil, gostErrors.NewBadRequestError(errors.New("TaskingCapability does not exist"))
+	}
+
+	creationTime := time.Now().UTC()
+	taskingParameters, _ := json.Marshal(t.TaskingParameters)
+
+	var taskID int
+	sql := fmt.Sprintf("INSERT INTO %s.task (creationtime, taskingparameters, taskingcapability_id) VALUES ($1, $2, $3) RETURNING id", gdb.Schema)
+	if err := gdb.Db.QueryRow(sql, creationTime, string(taskingParameters), tcID).Scan(&taskID); err != nil {
+		return nil, err
+	}
+
+	t.ID = taskID
+	t.CreationTime = creationTime.Format(time.RFC3339Nano)
+
+	// clear inner entities to serves links upon response
+	t.TaskingCapability = nil
+	return t, nil
+}
+
+// TaskExists checks if a Task is present in the database based on a given id
+func (gdb *GostDatabase) TaskExists(id int) bool {
+	return EntityExists(gdb, id, "task")
+}
+
+// PatchTask updates the taskingParameters of a Task in the database, the creationTime cannot be changed
+func (gdb *GostDatabase) PatchTask(id interface{}, t *entities.Task) (*entities.Task, error) {
+	var intID int
+	var ok bool
+	updates := make(map[string]interface{})
+
+	if intID, ok = ToIntID(id); !ok || !gdb.TaskExists(intID) {
+		return nil, gostErrors.NewRequestNotFound(errors.New("Task does not exist"))
+	}
+
+	if len(t.TaskingParameters) > 0 {
+		taskingParameters, _ := json.Marshal(t.TaskingParameters)
+		updates["taskingparameters"] = string(taskingParameters[:])
+	}
+
+	if err := gdb.updateEntityColumns("task", updates, intID); err != nil {
+		return nil, err
+	}
+
+	return gdb.GetTask(intID, nil)
+}
+
+// PutTask replaces a Task in the database and links it to the given TaskingCapability, the creationTime
+// is set by the server and kept
+func (gdb *GostDatabase) PutTask(id interface{}, t *entities.Task) (*entities.Task, error) {
+	relations := map[entities.EntityType]interface{}{}
+	if t.TaskingCapability != nil {
+		relations[entities.EntityTypeTaskingCapability] = t.TaskingCapability.ID
+	}
+
+	intID, err := gdb.putEntity(entities.EntityTypeTask, id, nil, relations)
+	if err != nil {
+		return nil, err
+	}
+
+	return gdb.PatchTask(intID, t)
+}
+
+// DeleteTask tries to delete a Task by the given id
+func (gdb *GostDatabase) DeleteTask(id interface{}) error {
+	return DeleteEntity(gdb, id, "task")
+}
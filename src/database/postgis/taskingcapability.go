@@ -0,0 +1,292 @@
+package postgis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+func taskingCapabilityParamFactory(values map[string]interface{}) (entities.Entity, error) {
+	tc := &entities.TaskingCapability{}
+	for as, value := range values {
+		if value == nil {
+			continue
+		}
+
+		if as == asMappings[entities.EntityTypeTaskingCapability][taskingCapabilityID] {
+			tc.ID = value
+		} else if as == asMappings[entities.EntityTypeTaskingCapability][taskingCapabilityName] {
+			tc.Name = value.(string)
+		} else if as == asMappings[entities.EntityTypeTaskingCapability][taskingCapabilityDescription] {
+			tc.Description = value.(string)
+		} else if as == asMappings[entities.EntityTypeTaskingCapability][taskingCapabilityProperties] {
+			p := value.(string)
+			propertiesMap, err := JSONToMap(&p)
+			if err != nil {
+				return nil, err
+			}
+			tc.Properties = propertiesMap
+		} else if as == asMappings[entities.EntityTypeTaskingCapability][taskingCapabilityTaskingParameters] {
+			p := value.(string)
+			parametersMap, err := JSONToMap(&p)
+			if err != nil {
+				return nil, err
+			}
+			tc.TaskingParameters = parametersMap
+		}
+	}
+
+	return tc, nil
+}
+
+// GetTaskingCapability retrieves a TaskingCapability by id
+func (gdb *GostDatabase) GetTaskingCapability(id interface{}, qo *odata.QueryOptions) (*entities.TaskingCapability, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("TaskingCapability does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.TaskingCapability{}, qo, "", "", nil)+" FROM %s.taskingcapability where id = %v", gdb.Schema, intID)
+	return processTaskingCapability(gdb.Db, sql, qo)
+}
+
+// GetTaskingCapabilities retrieves all TaskingCapabilities
+func (gdb *GostDatabase) GetTaskingCapabilities(qo *odata.QueryOptions) ([]*entities.TaskingCapability, int, error) {
+	return gdb.getTaskingCapabilities("", qo)
+}
+
+// GetTaskingCapabilitiesByThing retrieves all TaskingCapabilities of the given Thing
+func (gdb *GostDatabase) GetTaskingCapabilitiesByThing(thingID interface{}, qo *odata.QueryOptions) ([]*entities.TaskingCapability, int, error) {
+	intID, ok := ToIntID(thingID)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Thing does not exist"))
+	}
+
+	return gdb.getTaskingCapabilities(fmt.Sprintf("taskingcapability.thing_id = %v", intID), qo)
+}
+
+// GetTaskingCapabilitiesByActuator retrieves all TaskingCapabilities using the given Actuator
+func (gdb *GostDatabase) GetTaskingCapabilitiesByActuator(actuatorID interface{}, qo *odata.QueryOptions) ([]*entities.TaskingCapability, int, error) {
+	intID, ok := ToIntID(actuatorID)
+	if !ok {
+		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Actuator does not exist"))
+	}
+
+	return gdb.getTaskingCapabilities(fmt.Sprintf("taskingcapability.actuator_id = %v", intID), qo)
+}
+
+// GetTaskingCapabilityByTask retrieves the TaskingCapability of the given Task
+func (gdb *GostDatabase) GetTaskingCapabilityByTask(taskID interface{}, qo *odata.QueryOptions) (*entities.TaskingCapability, error) {
+	intID, ok := ToIntID(taskID)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("Task does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.TaskingCapability{}, qo, "taskingcapability.", "", nil)+" FROM %s.taskingcapability inner join %s.task on task.taskingcapability_id = taskingcapability.id where task.id = %v", gdb.Schema, gdb.Schema, intID)
+	return processTaskingCapability(gdb.Db, sql, qo)
+}
+
+// getTaskingCapabilities retrieves the TaskingCapabilities matching the given condition and $filter
+func (gdb *GostDatabase) getTaskingCapabilities(condition string, qo *odata.QueryOptions) ([]*entities.TaskingCapability, int, error) {
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeTaskingCapability, selectMappingsResolver(entities.EntityTypeTaskingCapability))
+	prefix := "WHERE "
+	if len(condition) > 0 {
+		condition = "WHERE " + condition + " "
+		prefix = " AND "
+	}
+
+	queryString, err := CreateFilterQueryString(qo, resolver, prefix)
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "taskingcapability.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.TaskingCapability{}, qo, "taskingcapability.", "", nil)+" FROM %s.taskingcapability %s%sorder by %s%s", gdb.Schema, condition, queryString, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) FROM %s.taskingcapability %s%s", gdb.Schema, condition, queryString)
+	return processTaskingCapabilities(gdb.Db, sql, qo, countSQL)
+}
+
+func processTaskingCapability(db Executor, sql string, qo *odata.QueryOptions) (*entities.TaskingCapability, error) {
+	capabilities, _, err := processTaskingCapabilities(db, sql, qo, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(capabilities) == 0 {
+		return nil, gostErrors.NewRequestNotFound(errors.New("TaskingCapability does not exist"))
+	}
+
+	return capabilities[0], nil
+}
+
+func processTaskingCapabilities(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.TaskingCapability, int, error) {
+	rows, err := db.Query(sql)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+	defer rows.Close()
+
+	var capabilities = []*entities.TaskingCapability{}
+	for rows.Next() {
+		var id interface{}
+		var name, description string
+		var properties, taskingParameters *string
+
+		var params []interface{}
+		for _, p := range selectedProperties(&entities.TaskingCapability{}, qo) {
+			switch strings.ToLower(p) {
+			case "id":
+				params = append(params, &id)
+			case "name":
+				params = append(params, &name)
+			case "description":
+				params = append(params, &description)
+			case "properties":
+				params = append(params, &properties)
+			case "taskingparameters":
+				params = append(params, &taskingParameters)
+			}
+		}
+
+		if err = rows.Scan(params...); err != nil {
+			return nil, 0, err
+		}
+
+		propertiesMap, err := JSONToMap(properties)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		parametersMap, err := JSONToMap(taskingParameters)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		capability := entities.TaskingCapability{}
+		capability.ID = id
+		capability.Name = name
+		capability.Description = description
+		capability.Properties = propertiesMap
+		capability.TaskingParameters = parametersMap
+		capabilities = append(capabilities, &capability)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	return capabilities, count, nil
+}
+
+// PostTaskingCapability adds a TaskingCapability to the database, the linked Thing and Actuator have to exist
+func (gdb *GostDatabase) PostTaskingCapability(tc *entities.TaskingCapability) (*entities.TaskingCapability, error) {
+	tID, ok := ToIntID(tc.Thing.ID)
+	if !ok || !gdb.ThingExists(tID) {
+		return nil, gostErrors.NewBadRequestError(errors.New("Thing does not exist"))
+	}
+
+	aID, ok := ToIntID(tc.Actuator.ID)
+	if !ok || !gdb.ActuatorExists(aID) {
+		return nil, gostErrors.NewBadRequestError(errors.New("Actuator does not exist"))
+	}
+
+	properties, _ := json.Marshal(tc.Properties)
+	taskingParameters, _ := json.Marshal(tc.TaskingParameters)
+
+	var tcID int
+	sql := fmt.Sprintf("INSERT INTO %s.taskingcapability (name, description, properties, taskingparameters, thing_id, actuator_id) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id", gdb.Schema)
+	if err := gdb.Db.QueryRow(sql, tc.Name, tc.Description, string(properties), string(taskingParameters), tID, aID).Scan(&tcID); err != nil {
+		return nil, err
+	}
+
+	tc.ID = tcID
+
+	// clear inner entities to serves links upon response
+	tc.Thing = nil
+	tc.Actuator = nil
+	return tc, nil
+}
+
+// TaskingCapabilityExists checks if a TaskingCapability is present in the database based on a given id
+func (gdb *GostDatabase) TaskingCapabilityExists(id int) bool {
+	return EntityExists(gdb, id, "taskingcapability")
+}
+
+// PatchTaskingCapability updates a TaskingCapability in the database
+func (gdb *GostDatabase) PatchTaskingCapability(id interface{}, tc *entities.TaskingCapability) (*entities.TaskingCapability, error) {
+	var intID int
+	var ok bool
+	updates := make(map[string]interface{})
+
+	if intID, ok = ToIntID(id); !ok || !gdb.TaskingCapabilityExists(intID) {
+		return nil, gostErrors.NewRequestNotFound(errors.New("TaskingCapability does not exist"))
+	}
+
+	if len(tc.Name) > 0 {
+		updates["name"] = tc.Name
+	}
+
+	if len(tc.Description) > 0 {
+		updates["description"] = tc.Description
+	}
+
+	if len(tc.Properties) > 0 {
+		properties, _ := json.Marshal(tc.Properties)
+		updates["properties"] = string(properties[:])
+	}
+
+	if len(tc.TaskingParameters) > 0 {
+		taskingParameters, _ := json.Marshal(tc.TaskingParameters)
+		updates["taskingparameters"] = string(taskingParameters[:])
+	}
+
+	if err := gdb.updateEntityColumns("taskingcapability", updates, intID); err != nil {
+		return nil, err
+	}
+
+	return gdb.GetTaskingCapability(intID, nil)
+}
+
+// PutTaskingCapability replaces a TaskingCapability in the database, the properties are cleared when omitted
+// and the TaskingCapability is linked to the given Thing and Actuator
+func (gdb *GostDatabase) PutTaskingCapability(id interface{}, tc *entities.TaskingCapability) (*entities.TaskingCapability, error) {
+	var cleared []string
+	if len(tc.Properties) == 0 {
+		cleared = append(cleared, taskingCapabilityProperties)
+	}
+
+	relations := map[entities.EntityType]interface{}{}
+	if tc.Thing != nil {
+		relations[entities.EntityTypeThing] = tc.Thing.ID
+	}
+
+	if tc.Actuator != nil {
+		relations[entities.EntityTypeActuator] = tc.Actuator.ID
+	}
+
+	intID, err := gdb.putEntity(entities.EntityTypeTaskingCapability, id, cleared, relations)
+	if err != nil {
+		return nil, err
+	}
+
+	return gdb.PatchTaskingCapability(intID, tc)
+}
+
+// DeleteTaskingCapability tries to delete a TaskingCapability by the given id
+func (gdb *GostDatabase) DeleteTaskingCapability(id interface{}) error {
+	return DeleteEntity(gdb, id, "taskingcapability")
+}
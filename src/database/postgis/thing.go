@@ -6,7 +6,6 @@ import (
 
 	"github.com/geodan/gost/src/sensorthings/entities"
 
-	"database/sql"
 	"errors"
 
 	gostErrors "github.com/geodan/gost/src/errors"
@@ -71,6 +70,28 @@ func (gdb *GostDatabase) GetThingByDatastream(id interface{}, qo *odata.QueryOpt
 	return processThing(gdb.Db, sql, qo)
 }
 
+//GetThingByMultiDatastream retrieves the thing linked to a MultiDatastream
+func (gdb *GostDatabase) GetThingByMultiDatastream(id interface{}, qo *odata.QueryOptions) (*entities.Thing, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("MultiDatastream does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Thing{}, qo, "thing.", "", nil)+" from %s.thing INNER JOIN %s.multidatastream ON multidatastream.thing_id = thing.id WHERE multidatastream.id = %v;", gdb.Schema, gdb.Schema, intID)
+	return processThing(gdb.Db, sql, qo)
+}
+
+//GetThingByTaskingCapability retrieves the thing linked to a TaskingCapability
+func (gdb *GostDatabase) GetThingByTaskingCapability(id interface{}, qo *odata.QueryOptions) (*entities.Thing, error) {
+	intID, ok := ToIntID(id)
+	if !ok {
+		return nil, gostErrors.NewRequestNotFound(errors.New("TaskingCapability does not exist"))
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Thing{}, qo, "thing.", "", nil)+" from %s.thing INNER JOIN %s.taskingcapability ON taskingcapability.thing_id = thing.id WHERE taskingcapability.id = %v;", gdb.Schema, gdb.Schema, intID)
+	return processThing(gdb.Db, sql, qo)
+}
+
 //GetThingsByLocation retrieves the thing linked to a location
 func (gdb *GostDatabase) GetThingsByLocation(id interface{}, qo *odata.QueryOptions) ([]*entities.Thing, int, error) {
 	intID, ok := ToIntID(id)
@@ -78,8 +99,19 @@ func (gdb *GostDatabase) GetThingsByLocation(id interface{}, qo *odata.QueryOpti
 		return nil, 0, gostErrors.NewRequestNotFound(errors.New("Location does not exist"))
 	}
 
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Thing{}, qo, "thing.", "", nil)+" from %s.thing INNER JOIN %s.thing_to_location ON thing.id = thing_to_location.thing_id INNER JOIN %s.location ON thing_to_location.location_id = location.id WHERE location.id = %v order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema, gdb.Schema, gdb.Schema, intID)
-	countSQL := fmt.Sprintf("SELECT Count(*) from %s.thing INNER JOIN %s.historicallocation ON historicallocation.thing_id = thing.id WHERE historicallocation.id = %v;", gdb.Schema, gdb.Schema, intID)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeThing, selectMappingsResolver(entities.EntityTypeThing))
+	queryString, err := CreateFilterQueryString(qo, resolver, " AND ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "thing.id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Thing{}, qo, "thing.", "", nil)+" from %s.thing INNER JOIN %s.thing_to_location ON thing.id = thing_to_location.thing_id WHERE thing_to_location.location_id = %v %sorder by %s"+CreateTopSkipQueryString(qo), gdb.Schema, gdb.Schema, intID, queryString, orderBy)
+	countSQL := fmt.Sprintf("SELECT Count(*) from %s.thing INNER JOIN %s.thing_to_location ON thing.id = thing_to_location.thing_id WHERE thing_to_location.location_id = %v %s", gdb.Schema, gdb.Schema, intID, queryString)
 	return processThings(gdb.Db, sql, qo, countSQL)
 }
 
@@ -96,12 +128,23 @@ func (gdb *GostDatabase) GetThingByHistoricalLocation(id interface{}, qo *odata.
 
 // GetThings returns an array of things
 func (gdb *GostDatabase) GetThings(qo *odata.QueryOptions) ([]*entities.Thing, int, error) {
-	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Thing{}, qo, "", "", nil)+" FROM %s.thing order by id desc "+CreateTopSkipQueryString(qo), gdb.Schema)
-	countSQL := fmt.Sprintf("select COUNT(*) as count FROM %s.thing", gdb.Schema)
+	resolver := gdb.QueryBuilder.filterPropertyResolver(entities.EntityTypeThing, selectMappingsResolver(entities.EntityTypeThing))
+	queryString, err := CreateFilterQueryString(qo, resolver, "WHERE ")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	orderBy, err := CreateOrderByQueryString(qo, resolver, "id DESC")
+	if err != nil {
+		return nil, 0, gostErrors.NewBadRequestError(err)
+	}
+
+	sql := fmt.Sprintf("select "+CreateSelectString(&entities.Thing{}, qo, "", "", nil)+" FROM %s.thing %sorder by %s %s", gdb.Schema, queryString, orderBy, CreateTopSkipQueryString(qo))
+	countSQL := fmt.Sprintf("select COUNT(*) as count FROM %s.thing %s", gdb.Schema, queryString)
 	return processThings(gdb.Db, sql, qo, countSQL)
 }
 
-func processThing(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.Thing, error) {
+func processThing(db Executor, sql string, qo *odata.QueryOptions) (*entities.Thing, error) {
 	observations, _, err := processThings(db, sql, qo, "")
 	if err != nil {
 		return nil, err
@@ -114,13 +157,12 @@ func processThing(db *sql.DB, sql string, qo *odata.QueryOptions) (*entities.Thi
 	return observations[0], nil
 }
 
-func processThings(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Thing, int, error) {
+func processThings(db Executor, sql string, qo *odata.QueryOptions, countSQL string) ([]*entities.Thing, int, error) {
 	rows, err := db.Query(sql)
-	defer rows.Close()
-
 	if err != nil {
-		return nil, 0, err
+		return nil, 0, filterQueryError(err)
 	}
+	defer rows.Close()
 
 	var things = []*entities.Thing{}
 	for rows.Next() {
@@ -129,13 +171,7 @@ func processThings(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL stri
 		var properties *string
 
 		var params []interface{}
-		var qp []string
-		if qo == nil || qo.QuerySelect == nil || len(qo.QuerySelect.Params) == 0 {
-			t := &entities.Thing{}
-			qp = t.GetPropertyNames()
-		} else {
-			qp = qo.QuerySelect.Params
-		}
+		qp := selectedProperties(&entities.Thing{}, qo)
 
 		for _, p := range qp {
 			p = strings.ToLower(p)
@@ -172,9 +208,13 @@ func processThings(db *sql.DB, sql string, qo *odata.QueryOptions, countSQL stri
 		things = append(things, &thing)
 	}
 
-	var count int
-	if len(countSQL) > 0 {
-		db.QueryRow(countSQL).Scan(&count)
+	if err = rows.Err(); err != nil {
+		return nil, 0, filterQueryError(err)
+	}
+
+	count, err := countIfRequested(db, countSQL, qo)
+	if err != nil {
+		return nil, 0, filterQueryError(err)
 	}
 
 	return things, count, nil
@@ -195,25 +235,19 @@ func (gdb *GostDatabase) PostThing(thing *entities.Thing) (*entities.Thing, erro
 	return thing, nil
 }
 
-// PutThing receives a Thing entity and changes it in the database
-// returns the adapted Thing
+// PutThing replaces a Thing in the database, the properties are cleared when omitted
 func (gdb *GostDatabase) PutThing(id interface{}, thing *entities.Thing) (*entities.Thing, error) {
-	return gdb.PatchThing(id, thing)
-	/*var intID int
-	var ok bool
-	jsonProperties, _ := json.Marshal(thing.Properties)
-	if intID, ok = ToIntID(id); !ok || !gdb.ThingExists(intID) {
-		return nil, gostErrors.NewRequestNotFound(errors.New("Thing does not exist"))
+	var cleared []string
+	if len(thing.Properties) == 0 {
+		cleared = append(cleared, thingProperties)
 	}
 
-	sql := fmt.Sprintf("update %s.thing set name=$1, description=$2, properties = $3 where id = $4", gdb.Schema)
-	_, err := gdb.Db.Exec(sql, thing.Name, thing.Description, jsonProperties, intID)
+	intID, err := gdb.putEntity(entities.EntityTypeThing, id, cleared, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	nt, _ := gdb.GetThing(intID, nil)
-	return nt, nil*/
+	return gdb.PatchThing(intID, thing)
 }
 
 // PatchThing receives a to be patched Thing entity and changes it in the database
@@ -241,27 +275,9 @@ func (gdb *GostDatabase) PatchThing(id interface{}, thing *entities.Thing) (*ent
 		updates["properties"] = string(jsonProperties[:])
 	}
 
-	if thing.Locations != nil {
-		if len(thing.Locations) > 0 {
-			for _, l := range thing.Locations {
-				location, _ := gdb.GetLocation(l.ID, nil)
-
-				// todo: check if location exist
-				if location != nil {
-					sql := fmt.Sprintf("update %s.thing_to_location set location_id  = $1 where thing_id= $2", gdb.Schema)
-					res, err := gdb.Db.Exec(sql, location.ID, intID)
-					if err != nil {
-						return nil, err
-					}
-					if c, _ := res.RowsAffected(); c == 0 {
-						sqlInsert := fmt.Sprintf("insert into %s.thing_to_location (location_id,thing_id) values ($1, $2)", gdb.Schema)
-						_, err := gdb.Db.Exec(sqlInsert, location.ID, intID)
-						if err != nil {
-							return nil, err
-						}
-					}
-				}
-			}
+	if len(thing.Locations) > 0 {
+		if err = gdb.replaceThingLocations(intID, thing.Locations); err != nil {
+			return nil, err
 		}
 	}
 
@@ -273,6 +289,50 @@ func (gdb *GostDatabase) PatchThing(id interface{}, thing *entities.Thing) (*ent
 	return nt, nil
 }
 
+// replaceThingLocations replaces the Locations of the Thing by the given Locations, a not found error is returned
+// when one of the Locations does not exist
+func (gdb *GostDatabase) replaceThingLocations(thingID int, locations []*entities.Location) error {
+	var ids []int
+	for _, l := range locations {
+		id, ok := ToIntID(l.ID)
+		if !ok {
+			return gostErrors.NewRequestNotFound(fmt.Errorf("Location %v does not exist", l.ID))
+		}
+
+		ids = append(ids, id)
+	}
+
+	existing, err := ExistingEntities(gdb, ids, locationTable)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if !existing[id] {
+			return gostErrors.NewRequestNotFound(fmt.Errorf("Location %v does not exist", id))
+		}
+	}
+
+	if _, err = gdb.Db.Exec(fmt.Sprintf("DELETE FROM %s.thing_to_location WHERE thing_id = $1", gdb.Schema), thingID); err != nil {
+		return err
+	}
+
+	inserted := map[int]bool{}
+	for _, id := range ids {
+		if inserted[id] {
+			continue
+		}
+
+		if _, err = gdb.Db.Exec(fmt.Sprintf("INSERT INTO %s.thing_to_location (thing_id, location_id) VALUES ($1, $2)", gdb.Schema), thingID, id); err != nil {
+			return err
+		}
+
+		inserted[id] = true
+	}
+
+	return nil
+}
+
 // ThingExists checks if a thing is present in the database based on a given id
 func (gdb *GostDatabase) ThingExists(id interface{}) bool {
 	return EntityExists(gdb, id, "thing")
@@ -0,0 +1,592 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/geodan/gost/src/sensorthings/models"
+)
+
+// BatchPath is the path of the endpoint accepting batch requests
+const BatchPath = "/" + models.APIPrefix + "/$batch"
+
+// errChangesetFailed is returned inside a changeset transaction to roll back all changes of the changeset
+var errChangesetFailed = errors.New("Changeset failed")
+
+// batchRequest is a single request inside a batch, requests with the same AtomicityGroup form a changeset
+// which is executed in one transaction. The ID can be used by following requests to reference the
+// entity created by this request, for example $1/Datastreams or {"@iot.id": "$1"}
+type batchRequest struct {
+	ID             string            `json:"id"`
+	AtomicityGroup string            `json:"atomicityGroup,omitempty"`
+	Method         string            `json:"method"`
+	URL            string            `json:"url"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           json.RawMessage   `json:"body,omitempty"`
+}
+
+// batchResponse is the response of a single request inside a batch
+type batchResponse struct {
+	ID             string            `json:"id,omitempty"`
+	AtomicityGroup string            `json:"atomicityGroup,omitempty"`
+	Status         int               `json:"status"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	Body           json.RawMessage   `json:"body,omitempty"`
+}
+
+// jsonBatchRequest is the body of a batch request in the JSON format
+type jsonBatchRequest struct {
+	Requests []*batchRequest `json:"requests"`
+}
+
+// jsonBatchResponse is the body of a batch response in the JSON format
+type jsonBatchResponse struct {
+	Responses []*batchResponse `json:"responses"`
+}
+
+// batchReference holds the location and id of an entity created by a request inside a batch
+type batchReference struct {
+	path string
+	id   interface{}
+}
+
+// HandleBatch handles a POST on the $batch endpoint, the batch is read as JSON or as multipart/mixed depending
+// on the Content-Type and answered in the same format. Requests outside a changeset are executed on their own,
+// all requests of a changeset are executed in one transaction, when one of them fails the changeset is rolled
+// back and only the response of the failed request is returned for the changeset
+func HandleBatch(w http.ResponseWriter, r *http.Request, api *models.API) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		sendError(w, http.StatusBadRequest, fmt.Errorf("Missing or wrong Content-Type, accepting: application/json, multipart/mixed"))
+		return
+	}
+
+	var requests []*batchRequest
+	switch mediaType {
+	case "application/json":
+		requests, err = readJSONBatch(r.Body)
+	case "multipart/mixed":
+		requests, err = readMultipartBatch(r.Body, params["boundary"])
+	default:
+		err = fmt.Errorf("Missing or wrong Content-Type, accepting: application/json, multipart/mixed")
+	}
+
+	if err != nil {
+		sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	responses := processBatch(api, requests)
+	if mediaType == "multipart/mixed" {
+		var b bytes.Buffer
+		contentType, err := writeMultipartBatch(&b, responses)
+		if err != nil {
+			sendError(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(http.StatusOK)
+		w.Write(b.Bytes())
+		return
+	}
+
+	b, err := json.Marshal(jsonBatchResponse{Responses: responses})
+	if err != nil {
+		sendError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write(b)
+}
+
+// processBatch executes the requests in order, consecutive requests with the same atomicity group are
+// executed as one changeset
+func processBatch(api *models.API, requests []*batchRequest) []*batchResponse {
+	router := createEndpointRouter(api)
+	references := map[string]batchReference{}
+	responses := []*batchResponse{}
+	for i := 0; i < len(requests); {
+		group := requests[i].AtomicityGroup
+		j := i + 1
+		for len(group) > 0 && j < len(requests) && requests[j].AtomicityGroup == group {
+			j++
+		}
+
+		if len(group) == 0 {
+			responses = append(responses, executeBatchRequest(router, requests[i], references))
+		} else {
+			responses = append(responses, processChangeset(api, requests[i:j], references)...)
+		}
+
+		i = j
+	}
+
+	return responses
+}
+
+// processChangeset executes the requests of a changeset in one transaction, references to entities
+// created inside the changeset are only kept when the changeset is committed
+func processChangeset(api *models.API, requests []*batchRequest, references map[string]batchReference) []*batchResponse {
+	changesetReferences := map[string]batchReference{}
+	for k, v := range references {
+		changesetReferences[k] = v
+	}
+
+	var responses []*batchResponse
+	var failed *batchResponse
+	a := *api
+	err := a.WithTransaction(func(tx models.API) error {
+		router := createEndpointRouter(&tx)
+		for _, r := range requests {
+			response := executeBatchRequest(router, r, changesetReferences)
+			if response.Status >= http.StatusBadRequest {
+				failed = response
+				return errChangesetFailed
+			}
+
+			responses = append(responses, response)
+		}
+
+		return nil
+	})
+
+	if failed != nil {
+		return []*batchResponse{failed}
+	}
+
+	if err != nil {
+		return []*batchResponse{errorBatchResponse(requests[0], http.StatusInternalServerError, err)}
+	}
+
+	for k, v := range changesetReferences {
+		references[k] = v
+	}
+
+	return responses
+}
+
+// executeBatchRequest resolves the references inside the url and body of the request and sends it to
+// the handler, the entity created by a request with an id is added to the references
+func executeBatchRequest(handler http.Handler, br *batchRequest, references map[string]batchReference) *batchResponse {
+	path, err := resolveBatchURL(br.URL, references)
+	if err != nil {
+		return errorBatchResponse(br, http.StatusBadRequest, err)
+	}
+
+	body, err := resolveBatchBody(br.Body, references)
+	if err != nil {
+		return errorBatchResponse(br, http.StatusBadRequest, err)
+	}
+
+	r, err := http.NewRequest(strings.ToUpper(br.Method), path, bytes.NewReader(body))
+	if err != nil {
+		return errorBatchResponse(br, http.StatusBadRequest, err)
+	}
+
+	for k, v := range br.Headers {
+		r.Header.Set(k, v)
+	}
+
+	if len(body) > 0 && len(r.Header.Get("Content-Type")) == 0 {
+		r.Header.Set("Content-Type", "application/json")
+	}
+
+	// paths are handled in lower case, the same as requests on the server
+	r.URL.Path = strings.ToLower(r.URL.Path)
+
+	w := &batchResponseWriter{header: http.Header{}}
+	handler.ServeHTTP(w, r)
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	response := &batchResponse{ID: br.ID, AtomicityGroup: br.AtomicityGroup, Status: w.status, Headers: map[string]string{}}
+	for k := range w.header {
+		response.Headers[k] = w.header.Get(k)
+	}
+
+	if w.body.Len() > 0 {
+		if json.Valid(w.body.Bytes()) {
+			response.Body = w.body.Bytes()
+		} else {
+			response.Body, _ = json.Marshal(w.body.String())
+		}
+	}
+
+	if len(br.ID) > 0 && w.status == http.StatusCreated {
+		if reference, ok := createdReference(w); ok {
+			references[br.ID] = reference
+		}
+	}
+
+	return response
+}
+
+// createdReference returns the location and id of the entity created by a request
+func createdReference(w *batchResponseWriter) (batchReference, bool) {
+	var entity map[string]interface{}
+	if err := json.Unmarshal(w.body.Bytes(), &entity); err != nil {
+		return batchReference{}, false
+	}
+
+	id, ok := entity["@iot.id"]
+	if !ok {
+		return batchReference{}, false
+	}
+
+	location, err := url.Parse(w.header.Get("Location"))
+	if err != nil || len(location.Path) == 0 {
+		return batchReference{}, false
+	}
+
+	return batchReference{path: location.Path, id: id}, true
+}
+
+// resolveBatchURL returns the path and query of the url of a request inside a batch, urls can be absolute,
+// relative to the service root such as Things(1) or start with a reference such as $1/Datastreams
+func resolveBatchURL(rawURL string, references map[string]batchReference) (string, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if strings.HasPrefix(rawURL, "$") {
+		end := strings.IndexAny(rawURL, "/?")
+		if end == -1 {
+			end = len(rawURL)
+		}
+
+		reference, ok := references[rawURL[1:end]]
+		if !ok {
+			return "", fmt.Errorf("Unknown reference %s in url %s", rawURL[:end], rawURL)
+		}
+
+		rawURL = reference.path + rawURL[end:]
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+
+	path := u.Path
+	if !strings.HasPrefix(path, "/") {
+		path = fmt.Sprintf("/%s/%s", models.APIPrefix, path)
+	}
+
+	if len(u.RawQuery) > 0 {
+		path = fmt.Sprintf("%s?%s", path, u.RawQuery)
+	}
+
+	return path, nil
+}
+
+// resolveBatchBody replaces the references in the body by the id of the referenced entity,
+// for example {"Thing": {"@iot.id": "$1"}}
+func resolveBatchBody(body json.RawMessage, references map[string]batchReference) ([]byte, error) {
+	if len(body) == 0 || len(references) == 0 {
+		return body, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(replaceReferences(value, references))
+}
+
+// replaceReferences replaces the @iot.id of the entity and of its navigation objects when it references a
+// created entity, for example {"Thing": {"@iot.id": "$1"}} or the {"@iot.id": "$1"} of a $ref. Navigation
+// properties start with an upper case letter, other values such as properties are left unchanged
+func replaceReferences(value interface{}, references map[string]batchReference) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, e := range v {
+			if k == "@iot.id" {
+				if id, ok := e.(string); ok && strings.HasPrefix(id, "$") {
+					if reference, ok := references[id[1:]]; ok {
+						v[k] = reference.id
+					}
+				}
+			} else if len(k) > 0 && unicode.IsUpper(rune(k[0])) {
+				v[k] = replaceReferences(e, references)
+			}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = replaceReferences(e, references)
+		}
+	}
+
+	return value
+}
+
+// errorBatchResponse creates the response for a request which could not be executed
+func errorBatchResponse(br *batchRequest, status int, err error) *batchResponse {
+	body, _ := json.Marshal(createErrorResponse(status, err))
+	return &batchResponse{
+		ID:             br.ID,
+		AtomicityGroup: br.AtomicityGroup,
+		Status:         status,
+		Headers:        map[string]string{"Content-Type": "application/json; charset=UTF-8"},
+		Body:           body,
+	}
+}
+
+// createErrorResponse creates the default error response of the sensorthings api
+func createErrorResponse(status int, err error) models.ErrorResponse {
+	return models.ErrorResponse{
+		Error: models.ErrorContent{
+			StatusText: http.StatusText(status),
+			StatusCode: status,
+			Messages:   []string{err.Error()},
+		},
+	}
+}
+
+// sendError sends the default error response of the sensorthings api with the given status
+func sendError(w http.ResponseWriter, status int, err error) {
+	b, _ := json.Marshal(createErrorResponse(status, err))
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+// readJSONBatch reads a batch in the JSON format: {"requests": [{"id": "1", "method": "post", "url": "Things", "body": {}}]}
+func readJSONBatch(body io.Reader) ([]*batchRequest, error) {
+	batch := jsonBatchRequest{}
+	if err := json.NewDecoder(body).Decode(&batch); err != nil {
+		return nil, fmt.Errorf("Unable to read batch: %v", err)
+	}
+
+	for _, r := range batch.Requests {
+		if len(r.Method) == 0 || len(r.URL) == 0 {
+			return nil, fmt.Errorf("Unable to read batch, method and url are mandatory for request %s", r.ID)
+		}
+	}
+
+	return batch.Requests, nil
+}
+
+// readMultipartBatch reads a batch in the multipart/mixed format, every part is a request with Content-Type
+// application/http or a changeset with Content-Type multipart/mixed containing the requests of the changeset
+func readMultipartBatch(body io.Reader, boundary string) ([]*batchRequest, error) {
+	if len(boundary) == 0 {
+		return nil, fmt.Errorf("Unable to read batch, missing boundary")
+	}
+
+	requests := []*batchRequest{}
+	reader := multipart.NewReader(body, boundary)
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read batch: %v", err)
+		}
+
+		mediaType, params, err := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/mixed" {
+			r, err := readHTTPPart(part, "")
+			if err != nil {
+				return nil, err
+			}
+
+			requests = append(requests, r)
+			continue
+		}
+
+		changeset := multipart.NewReader(part, params["boundary"])
+		for {
+			changesetPart, err := changeset.NextPart()
+			if err == io.EOF {
+				break
+			}
+
+			if err != nil {
+				return nil, fmt.Errorf("Unable to read changeset: %v", err)
+			}
+
+			r, err := readHTTPPart(changesetPart, params["boundary"])
+			if err != nil {
+				return nil, err
+			}
+
+			requests = append(requests, r)
+		}
+	}
+
+	return requests, nil
+}
+
+// readHTTPPart reads a request inside a multipart batch, the part contains the request line followed
+// by the headers and the body: POST Things HTTP/1.1
+func readHTTPPart(part *multipart.Part, atomicityGroup string) (*batchRequest, error) {
+	reader := textproto.NewReader(bufio.NewReader(part))
+	line, err := reader.ReadLine()
+	for err == nil && len(strings.TrimSpace(line)) == 0 {
+		line, err = reader.ReadLine()
+	}
+
+	requestLine := strings.Fields(line)
+	if err != nil || len(requestLine) < 2 {
+		return nil, fmt.Errorf("Unable to read batch, invalid request line %s", line)
+	}
+
+	header, err := reader.ReadMIMEHeader()
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("Unable to read batch: %v", err)
+	}
+
+	body, err := ioutil.ReadAll(reader.R)
+	if err != nil {
+		return nil, fmt.Errorf("Unable to read batch: %v", err)
+	}
+
+	r := &batchRequest{
+		ID:             part.Header.Get("Content-ID"),
+		AtomicityGroup: atomicityGroup,
+		Method:         requestLine[0],
+		URL:            requestLine[1],
+		Headers:        map[string]string{},
+	}
+
+	for k := range header {
+		r.Headers[k] = header.Get(k)
+	}
+
+	if body = bytes.TrimSpace(body); len(body) > 0 {
+		r.Body = body
+	}
+
+	return r, nil
+}
+
+// writeMultipartBatch writes the responses as multipart/mixed and returns the Content-Type of the response,
+// responses of a changeset are written inside a nested multipart/mixed part
+func writeMultipartBatch(w io.Writer, responses []*batchResponse) (string, error) {
+	writer := multipart.NewWriter(w)
+	for i := 0; i < len(responses); {
+		group := responses[i].AtomicityGroup
+		j := i + 1
+		for len(group) > 0 && j < len(responses) && responses[j].AtomicityGroup == group {
+			j++
+		}
+
+		if len(group) == 0 {
+			if err := writeHTTPPart(writer, responses[i]); err != nil {
+				return "", err
+			}
+
+			i = j
+			continue
+		}
+
+		boundary := multipart.NewWriter(ioutil.Discard).Boundary()
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Type", fmt.Sprintf("multipart/mixed; boundary=%s", boundary))
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return "", err
+		}
+
+		changeset := multipart.NewWriter(part)
+		if err = changeset.SetBoundary(boundary); err != nil {
+			return "", err
+		}
+
+		for _, r := range responses[i:j] {
+			if err = writeHTTPPart(changeset, r); err != nil {
+				return "", err
+			}
+		}
+
+		if err = changeset.Close(); err != nil {
+			return "", err
+		}
+
+		i = j
+	}
+
+	if err := writer.Close(); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("multipart/mixed; boundary=%s", writer.Boundary()), nil
+}
+
+// writeHTTPPart writes a response as application/http part: the status line followed by the headers and the body
+func writeHTTPPart(writer *multipart.Writer, r *batchResponse) error {
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Type", "application/http")
+	header.Set("Content-Transfer-Encoding", "binary")
+	if len(r.ID) > 0 {
+		header.Set("Content-ID", r.ID)
+	}
+
+	part, err := writer.CreatePart(header)
+	if err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "HTTP/1.1 %d %s\r\n", r.Status, http.StatusText(r.Status))
+	keys := make([]string, 0, len(r.Headers))
+	for k := range r.Headers {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s: %s\r\n", k, r.Headers[k])
+	}
+
+	b.WriteString("\r\n")
+	b.Write(r.Body)
+	_, err = part.Write(b.Bytes())
+	return err
+}
+
+// batchResponseWriter collects the response of a request inside a batch
+type batchResponseWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+// Header returns the headers of the response
+func (w *batchResponseWriter) Header() http.Header {
+	return w.header
+}
+
+// Write adds b to the body of the response, the status is set to 200 when not set yet
+func (w *batchResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	return w.body.Write(b)
+}
+
+// WriteHeader sets the status of the response
+func (w *batchResponseWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadJSONBatch(t *testing.T) {
+	// arrange
+	body := `{"requests": [
+		{"id": "1", "atomicityGroup": "g1", "method": "post", "url": "Things", "body": {"name": "thing"}},
+		{"id": "2", "atomicityGroup": "g1", "method": "post", "url": "$1/Locations", "body": {"name": "location"}},
+		{"id": "3", "method": "get", "url": "Things?$top=1"}
+	]}`
+
+	// act
+	requests, err := readJSONBatch(strings.NewReader(body))
+	_, errMissingURL := readJSONBatch(strings.NewReader(`{"requests": [{"id": "1", "method": "get"}]}`))
+
+	// assert
+	assert.Nil(t, err)
+	assert.Len(t, requests, 3)
+	assert.Equal(t, "g1", requests[1].AtomicityGroup)
+	assert.Equal(t, "$1/Locations", requests[1].URL)
+	assert.Equal(t, `{"name": "thing"}`, string(requests[0].Body))
+	assert.NotNil(t, errMissingURL)
+}
+
+func TestReadMultipartBatch(t *testing.T) {
+	// arrange
+	body := "--batch_1\r\n" +
+		"Content-Type: multipart/mixed; boundary=changeset_1\r\n\r\n" +
+		"--changeset_1\r\n" +
+		"Content-Type: application/http\r\n" +
+		"Content-ID: 1\r\n\r\n" +
+		"POST /v1.0/Things HTTP/1.1\r\n" +
+		"Content-Type: application/json\r\n\r\n" +
+		"{\"name\": \"thing\"}\r\n" +
+		"--changeset_1--\r\n" +
+		"--batch_1\r\n" +
+		"Content-Type: application/http\r\n\r\n" +
+		"GET Things(1) HTTP/1.1\r\n\r\n\r\n" +
+		"--batch_1--\r\n"
+
+	// act
+	requests, err := readMultipartBatch(strings.NewReader(body), "batch_1")
+
+	// assert
+	assert.Nil(t, err)
+	assert.Len(t, requests, 2)
+	assert.Equal(t, "1", requests[0].ID)
+	assert.Equal(t, "changeset_1", requests[0].AtomicityGroup)
+	assert.Equal(t, "POST", requests[0].Method)
+	assert.Equal(t, "/v1.0/Things", requests[0].URL)
+	assert.Equal(t, "application/json", requests[0].Headers["Content-Type"])
+	assert.Equal(t, `{"name": "thing"}`, string(requests[0].Body))
+	assert.Equal(t, "", requests[1].AtomicityGroup)
+	assert.Equal(t, "Things(1)", requests[1].URL)
+	assert.Nil(t, requests[1].Body)
+}
+
+func TestResolveBatchURL(t *testing.T) {
+	// arrange
+	references := map[string]batchReference{"1": {path: "/v1.0/Things(5)", id: 5}}
+
+	// act
+	relative, _ := resolveBatchURL("Things?$top=1", references)
+	absolute, _ := resolveBatchURL("http://localhost:8080/v1.0/Things(1)", references)
+	referenced, _ := resolveBatchURL("$1/Locations", references)
+	_, err := resolveBatchURL("$2/Locations", references)
+
+	// assert
+	assert.Equal(t, "/v1.0/Things?$top=1", relative)
+	assert.Equal(t, "/v1.0/Things(1)", absolute)
+	assert.Equal(t, "/v1.0/Things(5)/Locations", referenced)
+	assert.NotNil(t, err)
+}
+
+func TestExecuteBatchRequestShouldResolveReferences(t *testing.T) {
+	// arrange
+	var received []byte
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = ioutil.ReadAll(r.Body)
+		w.Header().Set("Location", "http://localhost:8080/v1.0/Things(5)")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"@iot.id": 5}`))
+	})
+	references := map[string]batchReference{}
+
+	// act
+	created := executeBatchRequest(handler, &batchRequest{ID: "1", Method: "post", URL: "Things", Body: json.RawMessage(`{"name": "thing"}`)}, references)
+	linked := executeBatchRequest(handler, &batchRequest{ID: "2", Method: "post", URL: "Datastreams", Body: json.RawMessage(`{"Thing": {"@iot.id": "$1"}}`)}, references)
+
+	// assert
+	assert.Equal(t, http.StatusCreated, created.Status)
+	assert.Equal(t, http.StatusCreated, linked.Status)
+	assert.Equal(t, "/v1.0/Things(5)", references["1"].path)
+	assert.Equal(t, `{"Thing":{"@iot.id":5}}`, string(received))
+}
+
+func TestResolveBatchBodyShouldOnlyReplaceNavigationIDs(t *testing.T) {
+	// arrange
+	references := map[string]batchReference{"1": {path: "/v1.0/Things(5)", id: 5}}
+	body := json.RawMessage(`{"name":"$1","properties":{"a":"$1","@iot.id":"$1"},"Datastreams":[{"description":"$1","Thing":{"@iot.id":"$1"}}]}`)
+
+	// act
+	resolved, err := resolveBatchBody(body, references)
+	reference, _ := resolveBatchBody(json.RawMessage(`{"@iot.id":"$1"}`), references)
+
+	// assert
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"$1","properties":{"a":"$1","@iot.id":"$1"},"Datastreams":[{"description":"$1","Thing":{"@iot.id":5}}]}`, string(resolved))
+	assert.JSONEq(t, `{"@iot.id":5}`, string(reference))
+}
+
+func TestWriteMultipartBatch(t *testing.T) {
+	// arrange
+	responses := []*batchResponse{
+		{ID: "1", AtomicityGroup: "g1", Status: http.StatusCreated, Body: json.RawMessage(`{"@iot.id":1}`)},
+		{ID: "2", Status: http.StatusOK, Headers: map[string]string{"Content-Type": "application/json"}},
+	}
+	var b bytes.Buffer
+
+	// act
+	contentType, err := writeMultipartBatch(&b, responses)
+
+	// assert
+	assert.Nil(t, err)
+	_, params, _ := mime.ParseMediaType(contentType)
+	assert.NotEmpty(t, params["boundary"])
+	assert.Contains(t, b.String(), "HTTP/1.1 201 Created\r\n\r\n{\"@iot.id\":1}")
+	assert.Contains(t, b.String(), "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n")
+	assert.Contains(t, b.String(), "Content-Type: multipart/mixed; boundary=")
+}
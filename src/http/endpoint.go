@@ -27,14 +27,6 @@ func (a Endpoints) Less(i, j int) bool {
 	firstDynamic := isDynamic(a[i].Operation.Path)
 	secondDynamic := isDynamic(a[j].Operation.Path)
 
-	if strings.Contains(a[i].Operation.Path, "{c:.*}") && !strings.Contains(a[j].Operation.Path, "{c:.*}") {
-		return false
-	}
-
-	if !strings.Contains(a[i].Operation.Path, "{c:.*}") && strings.Contains(a[j].Operation.Path, "{c:.*}") {
-		return true
-	}
-
 	if firstDynamic && !secondDynamic {
 		return false
 	}
@@ -49,41 +49,17 @@ func (s *GostServer) Stop() {
 
 }
 
-// LowerCaseURI is a middleware function that lower cases the url path
+// LowerCaseURI is a middleware function that lower cases the url path, the resource path is checked
+// and resolved by resourcePathHandler before the request is passed on to h
 func (s *GostServer) LowerCaseURI(h http.Handler) http.Handler {
+	h = resourcePathHandler(h, s.api)
 	fn := func(w http.ResponseWriter, r *http.Request) {
 		if strings.Contains(strings.ToLower(r.URL.Path), "dashboard") {
 			h.ServeHTTP(w, r)
 			return
 		}
 
-		lowerCasePath := strings.ToLower(r.URL.Path)
-		// temporarily disabled checking on paths due to problems in serving with /$value
-		/*
-			api := *s.api
-			split := strings.Split(lowerCasePath, "/")
-			for i, s := range split {
-				if len(s) == 0 || i+1 == len(split) {
-					continue
-				}
-
-				found := false
-				for _, a := range api.GetAcceptedPaths() {
-					if strings.HasPrefix(s, a) {
-						found = true
-					}
-				}
-
-				if !found {
-					w.Header().Set("Content-Type", "application/json; charset=UTF-8")
-					w.WriteHeader(http.StatusNotFound)
-					w.Write([]byte(""))
-					return
-				}
-			}
-		*/
-
-		r.URL.Path = lowerCasePath
+		r.URL.Path = strings.ToLower(r.URL.Path)
 		h.ServeHTTP(w, r)
 	}
 
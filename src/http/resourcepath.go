@@ -0,0 +1,213 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/rest"
+)
+
+// resourceSegmentRegex matches a resource path segment with an optional key, for example Things or Things(1)
+var resourceSegmentRegex = regexp.MustCompile(`^([A-Za-z]+)(?:\(([^()]+)\))?$`)
+
+// resourcePathSegment is a segment of a resource path addressing an entity set, an entity or a navigation property
+type resourcePathSegment struct {
+	name       string
+	entityType entities.EntityType
+	id         string // key between the parentheses, empty when no key is given
+	single     bool   // segment is a single valued navigation property such as Thing
+}
+
+// addressesEntity returns true when the segment addresses a single entity instead of a collection
+func (s *resourcePathSegment) addressesEntity() bool {
+	return len(s.id) > 0 || s.single
+}
+
+// resourcePath is a parsed resource path such as Things(1)/Datastreams(2)/Observations, the segments following
+// the last entity segment are kept in suffix, for example name/$value or $ref
+type resourcePath struct {
+	segments []*resourcePathSegment
+	suffix   []string
+}
+
+// parseResourcePath parses a path relative to the service root, every segment after the entity set has to be a
+// navigation property of the entity before it, the navigation properties of an entity are the supported expand
+// params of its endpoint. After the last entity segment the path can only contain a property, optionally followed
+// by $value, or $ref. Nil is returned when the path does not start with an entity set, for example $batch.
+// endpoints holds the endpoint of each entity type as returned by rest.EntityEndpoints
+func parseResourcePath(path string, endpoints map[entities.EntityType]models.Endpoint) (*resourcePath, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	first, ok := parseResourceSegment(parts[0])
+	if !ok || first.single || endpoints[first.entityType] == nil {
+		return nil, nil
+	}
+
+	rp := &resourcePath{segments: []*resourcePathSegment{first}}
+	for i := 1; i < len(parts); i++ {
+		current := rp.segments[len(rp.segments)-1]
+		part := parts[i]
+
+		if part == "$ref" {
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("$ref has to be the last segment of the path")
+			}
+
+			rp.suffix = []string{part}
+			break
+		}
+
+		if !current.addressesEntity() {
+			return nil, fmt.Errorf("%s is a collection, expected a key or $ref", current.name)
+		}
+
+		endpoint := endpoints[current.entityType]
+		if segment, ok := parseResourceSegment(part); ok && endpoint != nil && containsIgnoreCase(endpoint.GetSupportedExpandParams(), segment.name) {
+			if segment.single && len(segment.id) > 0 {
+				return nil, fmt.Errorf("%s is a single valued navigation property and cannot have a key", segment.name)
+			}
+
+			rp.segments = append(rp.segments, segment)
+			continue
+		}
+
+		if !containsIgnoreCase(entities.EntityFromType(current.entityType).GetPropertyNames(), part) {
+			return nil, fmt.Errorf("%s has no property or navigation property %s", current.entityType.ToString(), part)
+		}
+
+		if i < len(parts)-2 || (i == len(parts)-2 && parts[i+1] != "$value") {
+			return nil, fmt.Errorf("Only $value can follow the property %s", part)
+		}
+
+		rp.suffix = parts[i:]
+		break
+	}
+
+	return rp, nil
+}
+
+// parseResourceSegment parses a segment of a resource path which addresses an entity, false is returned when
+// the segment is not an entity set or navigation property
+func parseResourceSegment(part string) (*resourcePathSegment, bool) {
+	match := resourceSegmentRegex.FindStringSubmatch(part)
+	if match == nil {
+		return nil, false
+	}
+
+	et, ok := entities.StringEntityMap[strings.ToLower(match[1])]
+	if !ok {
+		return nil, false
+	}
+
+	return &resourcePathSegment{
+		name:       match[1],
+		entityType: et,
+		id:         match[2],
+		single:     strings.ToLower(match[1]) == strings.ToLower(et.ToString()),
+	}, true
+}
+
+func containsIgnoreCase(values []string, value string) bool {
+	for _, v := range values {
+		if strings.ToLower(v) == strings.ToLower(value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// resolveResourcePath checks if every entity in the path belongs to the entity before it and resolves the single
+// valued navigation properties in between. The path is rewritten to the last entity and its navigation so it can
+// be handled by the endpoints, for example Things(1)/Datastreams(2)/Observations becomes Datastreams(2)/Observations
+// and Observations(1)/Datastream/Thing becomes Datastreams(7)/Thing. When unlink is true a path ending on an entity
+// with $ref keeps the parent since the link between both is removed: Things(1)/Locations(3)/$ref
+func resolveResourcePath(rp *resourcePath, api models.API, endpoints map[entities.EntityType]models.Endpoint, unlink bool) (string, error) {
+	setName := func(et entities.EntityType) string {
+		return strings.ToLower(endpoints[et].GetName())
+	}
+
+	var grandParentID, parentID interface{} = nil, rp.segments[0].id
+	for i := 1; i < len(rp.segments); i++ {
+		parent, segment := rp.segments[i-1], rp.segments[i]
+		if len(segment.id) == 0 && (!segment.single || i == len(rp.segments)-1) {
+			break
+		}
+
+		var childID interface{}
+		if len(segment.id) > 0 {
+			childID = segment.id
+		}
+
+		id, err := api.ResolveNavigation(parent.entityType, parentID, segment.entityType, childID)
+		if err != nil {
+			return "", err
+		}
+
+		grandParentID, parentID = parentID, id
+	}
+
+	last := rp.segments[len(rp.segments)-1]
+	var path string
+	if unlink && len(last.id) > 0 && len(rp.segments) > 1 && len(rp.suffix) > 0 && rp.suffix[0] == "$ref" {
+		path = fmt.Sprintf("%s(%v)/%s(%s)", setName(rp.segments[len(rp.segments)-2].entityType), grandParentID, strings.ToLower(last.name), last.id)
+	} else if len(last.id) > 0 {
+		path = fmt.Sprintf("%s(%s)", setName(last.entityType), last.id)
+	} else if len(rp.segments) == 1 {
+		path = setName(last.entityType)
+	} else {
+		path = fmt.Sprintf("%s(%v)/%s", setName(rp.segments[len(rp.segments)-2].entityType), parentID, strings.ToLower(last.name))
+	}
+
+	for _, s := range rp.suffix {
+		path = fmt.Sprintf("%s/%s", path, s)
+	}
+
+	return fmt.Sprintf("/%s/%s", models.APIPrefix, path), nil
+}
+
+// resourcePathHandler is a middleware which parses the resource path of requests on the SensorThings API, invalid
+// paths and paths in which an entity does not belong to the entity before it are answered with 404, valid paths
+// are rewritten by resolveResourcePath before they are passed on to h
+func resourcePathHandler(h http.Handler, api *models.API) http.Handler {
+	endpoints := rest.EntityEndpoints(*(*api).GetEndpoints())
+	fn := func(w http.ResponseWriter, r *http.Request) {
+		a := *api
+		prefix := fmt.Sprintf("/%s/", models.APIPrefix)
+		if !strings.HasPrefix(r.URL.Path, prefix) {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		rp, err := parseResourcePath(strings.TrimPrefix(r.URL.Path, prefix), endpoints)
+		if err != nil {
+			sendError(w, http.StatusNotFound, err)
+			return
+		}
+
+		if rp == nil {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		path, err := resolveResourcePath(rp, a, endpoints, r.Method == "DELETE")
+		if err != nil {
+			status := http.StatusInternalServerError
+			if apiErr, ok := err.(gostErrors.APIError); ok {
+				status = apiErr.GetHTTPErrorStatusCode()
+			}
+
+			sendError(w, status, err)
+			return
+		}
+
+		r.URL.Path = path
+		h.ServeHTTP(w, r)
+	}
+
+	return http.HandlerFunc(fn)
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/geodan/gost/src/configuration"
+	"github.com/geodan/gost/src/database/postgis"
+	"github.com/geodan/gost/src/mqtt"
+	"github.com/geodan/gost/src/sensorthings/api"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/rest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResourcePath(t *testing.T) {
+	// arrange
+	endpoints := rest.EntityEndpoints(rest.CreateEndPoints(""))
+
+	// act
+	rp, err := parseResourcePath("things(1)/datastreams(2)/thing/locations/$ref", endpoints)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Len(t, rp.segments, 4)
+	assert.Equal(t, entities.EntityTypeThing, rp.segments[0].entityType)
+	assert.Equal(t, "1", rp.segments[0].id)
+	assert.Equal(t, "2", rp.segments[1].id)
+	assert.True(t, rp.segments[2].single)
+	assert.False(t, rp.segments[3].single)
+	assert.Equal(t, []string{"$ref"}, rp.suffix)
+}
+
+func TestParseResourcePathWithProperty(t *testing.T) {
+	// arrange
+	endpoints := rest.EntityEndpoints(rest.CreateEndPoints(""))
+
+	// act
+	rp, err := parseResourcePath("locations(1)/location/$value", endpoints)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Len(t, rp.segments, 1)
+	assert.Equal(t, []string{"location", "$value"}, rp.suffix)
+}
+
+func TestParseResourcePathShouldIgnoreNonEntityPaths(t *testing.T) {
+	// arrange
+	endpoints := rest.EntityEndpoints(rest.CreateEndPoints(""))
+
+	for _, path := range []string{"", "$batch", "createobservations"} {
+		// act
+		rp, err := parseResourcePath(path, endpoints)
+
+		// assert
+		assert.Nil(t, err, "Path %s should not give an error", path)
+		assert.Nil(t, rp, "Path %s should not be parsed", path)
+	}
+}
+
+func TestParseResourcePathShouldFailOnInvalidPaths(t *testing.T) {
+	// arrange
+	endpoints := rest.EntityEndpoints(rest.CreateEndPoints(""))
+	paths := []string{
+		"things(1)/sensors",
+		"things/datastreams",
+		"things(1)/datastream",
+		"datastreams(1)/thing(2)",
+		"datastreams(1)/unknown",
+		"datastreams(1)/name/description",
+		"datastreams(1)/$ref/name",
+		"datastreams(1)/$value",
+	}
+
+	for _, path := range paths {
+		// act
+		_, err := parseResourcePath(path, endpoints)
+
+		// assert
+		assert.NotNil(t, err, "Path %s should give an error", path)
+	}
+}
+
+func TestResourcePathHandlerShouldReturnNotFoundOnInvalidPath(t *testing.T) {
+	// arrange
+	cfg := configuration.Config{}
+	mqttServer := mqtt.CreateMQTTClient(configuration.MQTTConfig{})
+	database := postgis.NewDatabase("", 123, "", "", "", "", false, 50, 100, 200)
+	a := api.NewAPI(database, cfg, mqttServer)
+	handler := resourcePathHandler(CreateRouter(&a), &a)
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest("GET", "/v1.0/things(1)/sensors", nil)
+
+	// act
+	handler.ServeHTTP(w, r)
+
+	// assert
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -15,6 +15,29 @@ func CreateRouter(api *models.API) *mux.Router {
 	// problems with interfering endpoints cause of the wildcard used for the (id) in requests
 	a := *api
 
+	router := mux.NewRouter().StrictSlash(true)
+	router.PathPrefix("/Dashboard/").Handler(http.StripPrefix("/Dashboard/", http.FileServer(http.Dir(a.GetConfig().Server.ClientContent))))
+	setDashboardRedirects(router)
+	router.Methods("POST").Path(BatchPath).HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		HandleBatch(w, r, api)
+	})
+
+	addEndpoints(router, api)
+	return router
+}
+
+// createEndpointRouter creates a router which only contains the endpoints of the sensorthings api,
+// the resource paths of the requests are resolved by resourcePathHandler
+func createEndpointRouter(api *models.API) http.Handler {
+	router := mux.NewRouter().StrictSlash(true)
+	addEndpoints(router, api)
+	return resourcePathHandler(router, api)
+}
+
+// addEndpoints adds the operations of all endpoints of the api to the router
+func addEndpoints(router *mux.Router, api *models.API) {
+	a := *api
+
 	// get all endpoints into HttpEndpoints to be able to sort them so they can be added
 	// to the routes in the right order else requests will be picked up by the wrong handlers
 	eps := Endpoints{}
@@ -26,10 +49,6 @@ func CreateRouter(api *models.API) *mux.Router {
 	}
 	sort.Sort(eps)
 
-	router := mux.NewRouter().StrictSlash(true)
-	router.PathPrefix("/Dashboard/").Handler(http.StripPrefix("/Dashboard/", http.FileServer(http.Dir(a.GetConfig().Server.ClientContent))))
-	setDashboardRedirects(router)
-
 	for _, e := range eps {
 		op := e
 		operation := op.Operation
@@ -44,8 +63,6 @@ func CreateRouter(api *models.API) *mux.Router {
 				operation.Handler(w, r, &op.Endpoint, api)
 			})
 	}
-
-	return router
 }
 
 func setDashboardRedirects(router *mux.Router) {
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"errors"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// GetActuator retrieves an actuator by id and given query
+func (a *APIv1) GetActuator(id interface{}, qo *odata.QueryOptions, path string) (*entities.Actuator, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Actuator{})
+	if err != nil {
+		return nil, err
+	}
+
+	actuator, err := a.db.GetActuator(id, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(actuator, qo); err != nil {
+		return nil, err
+	}
+	return actuator, nil
+}
+
+// GetActuatorByTaskingCapability retrieves the actuator used by a TaskingCapability
+func (a *APIv1) GetActuatorByTaskingCapability(id interface{}, qo *odata.QueryOptions, path string) (*entities.Actuator, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Actuator{})
+	if err != nil {
+		return nil, err
+	}
+
+	actuator, err := a.db.GetActuatorByTaskingCapability(id, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(actuator, qo); err != nil {
+		return nil, err
+	}
+	return actuator, nil
+}
+
+// GetActuators retrieves an array of actuators based on the given query
+func (a *APIv1) GetActuators(qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Actuator{})
+	if err != nil {
+		return nil, err
+	}
+
+	actuators, count, err := a.db.GetActuators(qo)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, item := range actuators {
+		i := *item
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
+		actuators[idx] = &i
+	}
+
+	var data interface{} = actuators
+	return &models.ArrayResponse{
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(actuators), path, qo),
+		Data:     &data,
+	}, nil
+}
+
+// PostActuator adds a new actuator to the database
+func (a *APIv1) PostActuator(actuator *entities.Actuator) (*entities.Actuator, []error) {
+	_, err := actuator.ContainsMandatoryParams()
+	if err != nil {
+		return nil, err
+	}
+
+	supported, err2 := entities.CheckEncodingSupported(actuator, actuator.EncodingType)
+	if !supported || err2 != nil {
+		return nil, []error{err2}
+	}
+
+	na, err2 := a.db.PostActuator(actuator)
+	if err2 != nil {
+		return nil, []error{err2}
+	}
+
+	na.SetAllLinks(a.config.GetExternalServerURI())
+	return na, nil
+}
+
+// PatchActuator updates an actuator in the database
+func (a *APIv1) PatchActuator(id interface{}, actuator *entities.Actuator) (*entities.Actuator, error) {
+	if actuator.TaskingCapabilities != nil {
+		return nil, gostErrors.NewBadRequestError(errors.New("Unable to deep patch Actuator"))
+	}
+
+	if len(actuator.EncodingType) != 0 {
+		supported, err := entities.CheckEncodingSupported(actuator, actuator.EncodingType)
+		if !supported || err != nil {
+			return nil, err
+		}
+	}
+
+	return a.db.PatchActuator(id, actuator)
+}
+
+// PutActuator replaces the given actuator in the database
+func (a *APIv1) PutActuator(id interface{}, actuator *entities.Actuator) (*entities.Actuator, []error) {
+	if _, err := actuator.ContainsMandatoryParams(); len(err) > 0 {
+		return nil, err
+	}
+
+	putActuator, err := a.db.PutActuator(id, actuator)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	putActuator.SetAllLinks(a.config.GetExternalServerURI())
+	return putActuator, nil
+}
+
+// DeleteActuator deletes an actuator from the database by given actuator id
+func (a *APIv1) DeleteActuator(id interface{}) error {
+	return a.db.DeleteActuator(id)
+}
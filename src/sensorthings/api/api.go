@@ -36,6 +36,8 @@ func NewAPI(database models.Database, config configuration.Config, mqtt models.M
 			"things",
 			"datastream",
 			"datastreams",
+			"multidatastream",
+			"multidatastreams",
 			"location",
 			"locations",
 			"historicallocation",
@@ -48,6 +50,12 @@ func NewAPI(database models.Database, config configuration.Config, mqtt models.M
 			"observedproperties",
 			"featureofinterest",
 			"featurseofinterest",
+			"actuator",
+			"actuators",
+			"taskingcapability",
+			"taskingcapabilities",
+			"task",
+			"tasks",
 			"$value",
 			"dashboard",
 		},
@@ -63,6 +71,88 @@ func (a *APIv1) GetConfig() *configuration.Config {
 	return &a.config
 }
 
+// WithTransaction runs fn with a copy of the api which uses a database transaction, all changes made
+// by fn are committed when fn returns no error and rolled back otherwise. MQTT messages published by fn
+// are sent after the commit and dropped on a rollback
+func (a *APIv1) WithTransaction(fn func(models.API) error) error {
+	publisher, joined := a.mqtt.(*transactionPublisher)
+	if !joined {
+		publisher = &transactionPublisher{client: a.mqtt}
+	}
+
+	err := a.db.WithTransaction(func(db models.Database) error {
+		txAPI := *a
+		txAPI.db = db
+		txAPI.mqtt = publisher
+		return fn(&txAPI)
+	})
+
+	if err == nil && !joined {
+		publisher.flush()
+	}
+
+	return err
+}
+
+// transactionPublisher queues the messages published during a transaction until the transaction is committed
+type transactionPublisher struct {
+	client   models.MQTTClient
+	messages []transactionMessage
+}
+
+type transactionMessage struct {
+	topic   string
+	message string
+	qos     byte
+}
+
+// Start is not supported during a transaction
+func (p *transactionPublisher) Start(api *models.API) {}
+
+// Stop is not supported during a transaction
+func (p *transactionPublisher) Stop() {}
+
+// Publish queues the message until the transaction is committed
+func (p *transactionPublisher) Publish(topic string, message string, qos byte) {
+	p.messages = append(p.messages, transactionMessage{topic: topic, message: message, qos: qos})
+}
+
+// flush publishes the queued messages with the client of the api
+func (p *transactionPublisher) flush() {
+	if p.client == nil {
+		return
+	}
+
+	for _, m := range p.messages {
+		p.client.Publish(m.topic, m.message, m.qos)
+	}
+
+	p.messages = nil
+}
+
+// ResolveNavigation returns the id of the entity of type child related to the parent entity, when childID
+// is given the id is only returned when the child belongs to the parent
+func (a *APIv1) ResolveNavigation(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (interface{}, error) {
+	return a.db.ResolveNavigation(parent, parentID, child, childID)
+}
+
+// LockEntity locks the entity until the end of the transaction the api is part of
+func (a *APIv1) LockEntity(entityType entities.EntityType, id interface{}) error {
+	return a.db.LockEntity(entityType, id)
+}
+
+// keepRelation sets the id of the entity of type child which is currently related to the parent entity, a PUT
+// replaces all properties of an entity but keeps the relations which are omitted
+func (a *APIv1) keepRelation(parent entities.EntityType, parentID interface{}, child entities.EntityType, base *entities.BaseEntity) error {
+	id, err := a.db.ResolveNavigation(parent, parentID, child, nil)
+	if err != nil {
+		return err
+	}
+
+	base.ID = id
+	return nil
+}
+
 // GetAcceptedPaths returns an array of accepted endpoint paths
 func (a *APIv1) GetAcceptedPaths() []string {
 	return a.acceptedPaths
@@ -123,36 +213,92 @@ func (a *APIv1) QueryOptionsSupported(qo *odata.QueryOptions, entity entities.En
 	return true, nil
 }
 
-// ProcessGetRequest processes the entities by setting the necessary links before sending back
-func (a *APIv1) ProcessGetRequest(entity entities.Entity, qo *odata.QueryOptions) {
+// ProcessGetRequest processes the entities by adding the entities requested by $expand and
+// setting the necessary links before sending back
+func (a *APIv1) ProcessGetRequest(entity entities.Entity, qo *odata.QueryOptions) error {
+	if qo != nil && !qo.QueryOptionRef && !qo.QueryExpand.IsNil() {
+		if err := a.expand(entity, qo.QueryExpand); err != nil {
+			return err
+		}
+	}
+
 	// a $ref request, id's are selected to create selfLink, remove after setting self url
 	if qo != nil && qo.QueryOptionRef {
 		entity.SetSelfLink(a.config.GetExternalServerURI())
 		entity.SetID(nil)
 
-	} else if qo == nil || qo.QuerySelect.IsNil() || len(qo.QuerySelect.Params) == 0 { //no query options, set all links
+	} else if qo == nil || qo.QuerySelect.IsNil() { //no query options, set all links
 		entity.SetAllLinks(a.config.GetExternalServerURI())
+	} else {
+		// the id is always retrieved to create the links, remove it when it is not selected
+		entity.SetSelectedLinks(a.config.GetExternalServerURI(), qo.QuerySelect.Params)
+		if !qo.QuerySelect.Contains("id") {
+			entity.SetID(nil)
+		}
 	}
+
+	return nil
 }
 
 // CreateNextLink creates the link to the next page with results
-//  count is the number of total entities in the current query
+//  count is the number of total entities in the current query, only known when $count=true is requested
+//  resultCount is the number of entities in the current page, used when the total count is unknown
 //  incomingUrl is the url of the request excluding oData query params
-func (a *APIv1) CreateNextLink(count int, incomingURL string, qo *odata.QueryOptions) string {
-	// do not create a nextLink when there is no top and skip given
-	if qo == nil && qo.QueryTop.Limit == 0 && qo.QuerySkip.Index == 0 {
+func (a *APIv1) CreateNextLink(count int, resultCount int, incomingURL string, qo *odata.QueryOptions) string {
+	// do not create a nextLink when there is no top given
+	if qo == nil || qo.QueryTop.IsNil() || qo.QueryTop.Limit == 0 {
 		return ""
 	}
 
-	// do not create a nextLink when the current page has no following one
-	if qo.QueryTop.Limit+qo.QuerySkip.Index >= count || count < qo.QueryTop.Limit {
+	skip := 0
+	if !qo.QuerySkip.IsNil() {
+		skip = qo.QuerySkip.Index
+	}
+
+	// do not create a nextLink when the current page has no following one, without a total count
+	// a following page is assumed when the current page is full
+	if qo.QueryCount.IsTrue() && (qo.QueryTop.Limit+skip >= count || count < qo.QueryTop.Limit) {
 		return ""
 	}
 
+	if !qo.QueryCount.IsTrue() && resultCount < qo.QueryTop.Limit {
+		return ""
+	}
+
+	queryString := appendQueryPart(nextLinkQuery(qo), fmt.Sprintf("%v=%v", odata.QueryOptionSkip.String(), skip+qo.QueryTop.Limit))
+	return fmt.Sprintf("%s/%s", incomingURL, queryString)
+}
+
+// CreateSkipTokenLink creates the link to the next page using the given $skiptoken, the token holds the keys
+// of the last entity in the current page. No link is created when the current page is not full
+func (a *APIv1) CreateSkipTokenLink(resultCount int, incomingURL string, qo *odata.QueryOptions, skipToken string) string {
+	if qo == nil || qo.QueryTop.IsNil() || qo.QueryTop.Limit == 0 || resultCount < qo.QueryTop.Limit {
+		return ""
+	}
+
+	queryString := appendQueryPart(nextLinkQuery(qo), fmt.Sprintf("%v=%v", odata.QueryOptionSkipToken.String(), skipToken))
+	return fmt.Sprintf("%s/%s", incomingURL, queryString)
+}
+
+// createNextLink creates the nextLink of a page, a $skiptoken is used when a token is given and
+// no $skip is requested, otherwise the next page is requested using $skip
+func (a *APIv1) createNextLink(count int, resultCount int, incomingURL string, qo *odata.QueryOptions, skipToken string) string {
+	if len(skipToken) > 0 && (qo == nil || qo.QuerySkip.IsNil()) {
+		return a.CreateSkipTokenLink(resultCount, incomingURL, qo, skipToken)
+	}
+
+	return a.CreateNextLink(count, resultCount, incomingURL, qo)
+}
+
+// nextLinkQuery returns the query options of the current request which are passed on to the next page
+func nextLinkQuery(qo *odata.QueryOptions) string {
 	queryString := ""
 	if !qo.QueryFilter.IsNil() {
 		queryString = appendQueryPart(queryString, fmt.Sprintf("%v=%v", odata.QueryOptionFilter.String(), qo.QueryFilter.RawQuery))
 	}
+	if !qo.QueryApply.IsNil() {
+		queryString = appendQueryPart(queryString, fmt.Sprintf("%v=%v", odata.QueryOptionApply.String(), qo.QueryApply.RawQuery))
+	}
 	if !qo.QueryCount.IsNil() {
 		queryString = appendQueryPart(queryString, fmt.Sprintf("%v=%v", odata.QueryOptionCount.String(), qo.QueryCount.RawQuery))
 	}
@@ -168,11 +314,18 @@ func (a *APIv1) CreateNextLink(count int, incomingURL string, qo *odata.QueryOpt
 	if !qo.QueryTop.IsNil() {
 		queryString = appendQueryPart(queryString, fmt.Sprintf("%v=%v", odata.QueryOptionTop.String(), qo.QueryTop.RawQuery))
 	}
-	if !qo.QuerySkip.IsNil() {
-		queryString = appendQueryPart(queryString, fmt.Sprintf("%v=%v", odata.QueryOptionSkip.String(), qo.QuerySkip.Index+qo.QueryTop.Limit))
+
+	return queryString
+}
+
+// responseCount returns the count to set in an ArrayResponse, @iot.count is only
+// returned when $count=true is requested
+func responseCount(count int, qo *odata.QueryOptions) *int {
+	if qo == nil || !qo.QueryCount.IsTrue() {
+		return nil
 	}
 
-	return fmt.Sprintf("%s/%s", incomingURL, queryString)
+	return &count
 }
 
 func appendQueryPart(base string, q string) string {
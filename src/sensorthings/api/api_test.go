@@ -4,6 +4,8 @@ import (
 	"github.com/geodan/gost/src/configuration"
 	"github.com/geodan/gost/src/database/postgis"
 	"github.com/geodan/gost/src/mqtt"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/odata"
 
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -24,3 +26,80 @@ func TestCreateApi(t *testing.T) {
 	assert.NotNil(t, ep)
 	assert.NotEqual(t, len(endpoints), 0, "Endpoints empty")
 }
+
+func TestCreateNextLinkWithAndWithoutCount(t *testing.T) {
+	// arrange
+	stAPI := APIv1{}
+	qo, _ := odata.CreateQueryOptions(map[string]string{"$top": "2"})
+	qoCount, _ := odata.CreateQueryOptions(map[string]string{"$top": "2", "$count": "true"})
+
+	// act
+	fullPage := stAPI.CreateNextLink(0, 2, "http://localhost/v1.0/Things", qo)
+	lastPage := stAPI.CreateNextLink(0, 1, "http://localhost/v1.0/Things", qo)
+	countedPage := stAPI.CreateNextLink(3, 2, "http://localhost/v1.0/Things", qoCount)
+	countedLastPage := stAPI.CreateNextLink(2, 2, "http://localhost/v1.0/Things", qoCount)
+
+	// assert
+	assert.Equal(t, "http://localhost/v1.0/Things/?$top=2&$skip=2", fullPage)
+	assert.Equal(t, "", lastPage)
+	assert.Equal(t, "http://localhost/v1.0/Things/?$count=true&$top=2&$skip=2", countedPage)
+	assert.Equal(t, "", countedLastPage)
+}
+
+func TestCreateNextLinkShouldUseSkipTokenWithoutSkip(t *testing.T) {
+	// arrange
+	stAPI := APIv1{}
+	qo, _ := odata.CreateQueryOptions(map[string]string{"$top": "2", "$orderby": "phenomenonTime asc"})
+	qoSkip, _ := odata.CreateQueryOptions(map[string]string{"$top": "2", "$skip": "2"})
+
+	// act
+	tokenPage := stAPI.createNextLink(0, 2, "http://localhost/v1.0/Observations", qo, "abc")
+	tokenLastPage := stAPI.createNextLink(0, 1, "http://localhost/v1.0/Observations", qo, "abc")
+	skipPage := stAPI.createNextLink(0, 2, "http://localhost/v1.0/Observations", qoSkip, "abc")
+
+	// assert
+	assert.Equal(t, "http://localhost/v1.0/Observations/?$orderby=phenomenonTime asc&$top=2&$skiptoken=abc", tokenPage)
+	assert.Equal(t, "", tokenLastPage)
+	assert.Equal(t, "http://localhost/v1.0/Observations/?$top=2&$skip=4", skipPage)
+}
+
+func TestResponseCountOnlyWhenRequested(t *testing.T) {
+	// arrange
+	qo, _ := odata.CreateQueryOptions(map[string]string{"$count": "false"})
+	qoCount, _ := odata.CreateQueryOptions(map[string]string{"$count": "true"})
+
+	// act
+	notRequested := responseCount(10, qo)
+	requested := responseCount(0, qoCount)
+
+	// assert
+	assert.Nil(t, notRequested)
+	assert.NotNil(t, requested)
+	assert.Equal(t, 0, *requested)
+}
+
+func TestExpandedCollectionInfoShouldLinkToNavigation(t *testing.T) {
+	// arrange
+	stAPI := APIv1{}
+	qo, _ := odata.CreateQueryOptions(map[string]string{"$top": "2", "$count": "true"})
+
+	// act
+	count, nextLink := stAPI.expandedCollectionInfo(5, 2, "", entities.EntityLinkThings, entities.EntityLinkDatastreams, 1, qo)
+
+	// assert
+	assert.Equal(t, 5, *count)
+	assert.Equal(t, "/v1.0/Things(1)/Datastreams/?$count=true&$top=2&$skip=2", nextLink)
+}
+
+func TestPutShouldRequireMandatoryParams(t *testing.T) {
+	// arrange
+	stAPI := APIv1{}
+
+	// act
+	_, sensorErrors := stAPI.PutSensor(1, &entities.Sensor{Name: "sensor"})
+	_, thingErrors := stAPI.PutThing(1, &entities.Thing{Description: "thing"})
+
+	// assert
+	assert.Len(t, sensorErrors, 3, "description, encodingType and metadata should be missing")
+	assert.Len(t, thingErrors, 1, "name should be missing")
+}
@@ -21,16 +21,9 @@ func (a *APIv1) GetDatastream(id interface{}, qo *odata.QueryOptions, path strin
 		return nil, err
 	}
 
-	// as a start read only first expand parameter named
-	// ObservedProperty and add it to datastream
-	if !qo.QueryExpand.IsNil() {
-		if qo.QueryExpand.Params[0] == "ObservedProperty" {
-			observedproperty, _ := a.db.GetObservedPropertyByDatastream(id, nil)
-			ds.ObservedProperty = observedproperty
-		}
+	if err := a.ProcessGetRequest(ds, qo); err != nil {
+		return nil, err
 	}
-
-	a.ProcessGetRequest(ds, qo)
 	return ds, nil
 }
 
@@ -69,7 +62,9 @@ func (a *APIv1) GetDatastreamByObservation(observationID interface{}, qo *odata.
 		return nil, err
 	}
 
-	a.ProcessGetRequest(ds, qo)
+	if err := a.ProcessGetRequest(ds, qo); err != nil {
+		return nil, err
+	}
 	return ds, nil
 }
 
@@ -102,97 +97,91 @@ func processDatastreams(a *APIv1, datastreams []*entities.Datastream, qo *odata.
 
 	for idx, item := range datastreams {
 		i := *item
-		a.ProcessGetRequest(&i, qo)
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
 		datastreams[idx] = &i
 	}
 
 	var data interface{} = datastreams
 	return &models.ArrayResponse{
-		Count:    count,
-		NextLink: a.CreateNextLink(count, path, qo),
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(datastreams), path, qo),
 		Data:     &data,
 	}, nil
 }
 
-// PostDatastream adds a new datastream to the database
+// PostDatastream adds a new datastream to the database, deep inserted ObservedProperty, Sensor and Observations
+// are created in the same transaction as the datastream
 func (a *APIv1) PostDatastream(datastream *entities.Datastream) (*entities.Datastream, []error) {
-	var errors []error
-	var err error
-	var postedObservedProperty *entities.ObservedProperty
-	var postedSensor *entities.Sensor
-	var postedObservations []*entities.Observation
+	if _, errs := datastream.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	var ns *entities.Datastream
+	var errs []error
+	err := a.WithTransaction(func(txAPI models.API) error {
+		tx := txAPI.(*APIv1)
+		ns, errs = tx.postDatastream(datastream)
+		if len(errs) > 0 {
+			return errs[0]
+		}
+
+		return nil
+	})
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
 
-	_, errors = datastream.ContainsMandatoryParams()
-	if len(errors) > 0 {
-		a.revertPostDatastream(postedObservedProperty, postedSensor, postedObservations)
-		return nil, errors
+	if err != nil {
+		return nil, []error{err}
 	}
 
+	ns.SetAllLinks(a.config.GetExternalServerURI())
+	return ns, nil
+}
+
+func (a *APIv1) postDatastream(datastream *entities.Datastream) (*entities.Datastream, []error) {
 	// Check if ObservedProperty is deep inserted
 	if datastream.ObservedProperty != nil && datastream.ObservedProperty.ID == nil {
-		var op *entities.ObservedProperty
-		if op, err = a.db.PostObservedProperty(datastream.ObservedProperty); err != nil {
-			a.revertPostDatastream(postedObservedProperty, postedSensor, postedObservations)
+		op, err := a.db.PostObservedProperty(datastream.ObservedProperty)
+		if err != nil {
 			return nil, []error{err}
 		}
 
 		datastream.ObservedProperty = op
-		postedObservedProperty = op
 	}
 
 	// Check if Sensor is deep inserted
 	if datastream.Sensor != nil && datastream.Sensor.ID == nil {
-		var s *entities.Sensor
-		if s, err = a.db.PostSensor(datastream.Sensor); err != nil {
-			a.revertPostDatastream(postedObservedProperty, postedSensor, postedObservations)
+		s, err := a.db.PostSensor(datastream.Sensor)
+		if err != nil {
 			return nil, []error{err}
 		}
 
 		datastream.Sensor = s
-		postedSensor = s
 	}
 
 	ns, err := a.db.PostDatastream(datastream)
 	if err != nil {
-		a.revertPostDatastream(postedObservedProperty, postedSensor, postedObservations)
 		return nil, []error{err}
 	}
 
 	// Check if Observations are deep inserted
-	if datastream.Observations != nil {
-		for _, observation := range datastream.Observations {
-			ds := &entities.Datastream{}
-			ds.ID = datastream.ID
-			observation.Datastream = ds
-
-			var o *entities.Observation
-			if o, errors = a.PostObservation(observation); len(errors) > 0 {
-				a.revertPostDatastream(postedObservedProperty, postedSensor, postedObservations)
-				return nil, errors
-			}
-
-			postedObservations = append(postedObservations, o)
+	for _, observation := range datastream.Observations {
+		ds := &entities.Datastream{}
+		ds.ID = ns.ID
+		observation.Datastream = ds
+
+		if _, errs := a.PostObservation(observation); len(errs) > 0 {
+			return nil, errs
 		}
 	}
 
-	ns.SetAllLinks(a.config.GetExternalServerURI())
 	return ns, nil
 }
 
-func (a *APIv1) revertPostDatastream(op *entities.ObservedProperty, sensor *entities.Sensor, observations []*entities.Observation) {
-	if op != nil {
-		a.DeleteObservedProperty(op.ID)
-	}
-
-	if sensor != nil {
-		a.DeleteSensor(sensor.ID)
-	}
-
-	for _, observation := range observations {
-		a.DeleteObservation(observation.ID)
-	}
-}
-
 // PostDatastreamByThing adds a new datastream by given thing ID
 func (a *APIv1) PostDatastreamByThing(thingID interface{}, datastream *entities.Datastream) (*entities.Datastream, []error) {
 	t := &entities.Thing{}
@@ -210,15 +199,47 @@ func (a *APIv1) PatchDatastream(id interface{}, datastream *entities.Datastream)
 	return a.db.PatchDatastream(id, datastream)
 }
 
-// PutDatastream updates the given thing in the database
+// PutDatastream replaces the given datastream in the database, the Thing, Sensor and ObservedProperty are
+// kept when omitted
 func (a *APIv1) PutDatastream(id interface{}, datastream *entities.Datastream) (*entities.Datastream, []error) {
-	var err2 error
-	putdatastream, err2 := a.db.PutDatastream(id, datastream)
-	if err2 != nil {
-		return nil, []error{err2}
+	et := entities.EntityTypeDatastream
+	if datastream.Thing == nil {
+		datastream.Thing = &entities.Thing{}
+		if err := a.keepRelation(et, id, entities.EntityTypeThing, &datastream.Thing.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if datastream.Sensor == nil {
+		datastream.Sensor = &entities.Sensor{}
+		if err := a.keepRelation(et, id, entities.EntityTypeSensor, &datastream.Sensor.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if datastream.ObservedProperty == nil {
+		datastream.ObservedProperty = &entities.ObservedProperty{}
+		if err := a.keepRelation(et, id, entities.EntityTypeObservedProperty, &datastream.ObservedProperty.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if _, errs := datastream.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	var putdatastream *entities.Datastream
+	err := a.WithTransaction(func(tx models.API) error {
+		var err error
+		putdatastream, err = tx.(*APIv1).db.PutDatastream(id, datastream)
+		return err
+	})
+
+	if err != nil {
+		return nil, []error{err}
 	}
 
-	// putdatastream.SetAllLinks(a.config.GetExternalServerURI())
+	putdatastream.SetAllLinks(a.config.GetExternalServerURI())
 	return putdatastream, nil
 }
 
@@ -0,0 +1,450 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// defaultExpandTop is the $top of an expanded collection when no maxEntityResponse is configured, it equals
+// the $top used for a request without $top
+const defaultExpandTop = 200
+
+// expand adds the entities requested by the expand operations to the given entity, the query options
+// of an operation are used to retrieve the expanded entities which can be expanded again by a nested $expand.
+// An expanded collection is paged, see expandQueryOptions, and gets its own @iot.count and @iot.nextLink based on
+// the query options of the operation
+func (a *APIv1) expand(entity entities.Entity, qe *odata.QueryExpand) error {
+	// entities without id, for instance when id is not selected, cannot be expanded
+	if entity.GetID() == nil {
+		return nil
+	}
+
+	for _, o := range qe.Operations {
+		if err := a.expandOperation(entity, o); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// expandOperation retrieves the entities linked to the given entity by the navigation property of the operation
+func (a *APIv1) expandOperation(entity entities.Entity, o *odata.ExpandOperation) error {
+	qo := a.expandQueryOptions(o)
+	nav := o.Entity.GetEntityType()
+
+	switch e := entity.(type) {
+	case *entities.Thing:
+		switch nav {
+		case entities.EntityTypeLocation:
+			locations, count, err := a.db.GetLocationsByThing(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Locations = locations
+			e.CountLocations, e.NextLinkLocations = a.expandedCollectionInfo(count, len(locations), "", entities.EntityLinkThings, entities.EntityLinkLocations, e.ID, qo)
+			for _, l := range locations {
+				if err = a.ProcessGetRequest(l, qo); err != nil {
+					return err
+				}
+			}
+		case entities.EntityTypeDatastream:
+			datastreams, count, err := a.db.GetDatastreamsByThing(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Datastreams = datastreams
+			e.CountDatastreams, e.NextLinkDatastreams = a.expandedCollectionInfo(count, len(datastreams), "", entities.EntityLinkThings, entities.EntityLinkDatastreams, e.ID, qo)
+			for _, d := range datastreams {
+				if err = a.ProcessGetRequest(d, qo); err != nil {
+					return err
+				}
+			}
+		case entities.EntityTypeMultiDatastream:
+			multiDatastreams, count, err := a.db.GetMultiDatastreamsByThing(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.MultiDatastreams = multiDatastreams
+			e.CountMultiDatastreams, e.NextLinkMultiDatastreams = a.expandedCollectionInfo(count, len(multiDatastreams), "", entities.EntityLinkThings, entities.EntityLinkMultiDatastreams, e.ID, qo)
+			for _, d := range multiDatastreams {
+				if err = a.ProcessGetRequest(d, qo); err != nil {
+					return err
+				}
+			}
+		case entities.EntityTypeHistoricalLocation:
+			historicalLocations, count, err := a.db.GetHistoricalLocationsByThing(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.HistoricalLocations = historicalLocations
+			e.CountHistoricalLocations, e.NextLinkHistoricalLocations = a.expandedCollectionInfo(count, len(historicalLocations), "", entities.EntityLinkThings, entities.EntityLinkHistoricalLocations, e.ID, qo)
+			for _, hl := range historicalLocations {
+				if err = a.ProcessGetRequest(hl, qo); err != nil {
+					return err
+				}
+			}
+		case entities.EntityTypeTaskingCapability:
+			capabilities, count, err := a.db.GetTaskingCapabilitiesByThing(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.TaskingCapabilities = capabilities
+			e.CountTaskingCapabilities, e.NextLinkTaskingCapabilities = a.expandedCollectionInfo(count, len(capabilities), "", entities.EntityLinkThings, entities.EntityLinkTaskingCapabilities, e.ID, qo)
+			for _, tc := range capabilities {
+				if err = a.ProcessGetRequest(tc, qo); err != nil {
+					return err
+				}
+			}
+		}
+	case *entities.Location:
+		switch nav {
+		case entities.EntityTypeThing:
+			things, count, err := a.db.GetThingsByLocation(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Things = things
+			e.CountThings, e.NextLinkThings = a.expandedCollectionInfo(count, len(things), "", entities.EntityLinkLocations, entities.EntityLinkThings, e.ID, qo)
+			for _, t := range things {
+				if err = a.ProcessGetRequest(t, qo); err != nil {
+					return err
+				}
+			}
+		case entities.EntityTypeHistoricalLocation:
+			historicalLocations, count, err := a.db.GetHistoricalLocationsByLocation(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.HistoricalLocations = historicalLocations
+			e.CountHistoricalLocations, e.NextLinkHistoricalLocations = a.expandedCollectionInfo(count, len(historicalLocations), "", entities.EntityLinkLocations, entities.EntityLinkHistoricalLocations, e.ID, qo)
+			for _, hl := range historicalLocations {
+				if err = a.ProcessGetRequest(hl, qo); err != nil {
+					return err
+				}
+			}
+		}
+	case *entities.HistoricalLocation:
+		switch nav {
+		case entities.EntityTypeThing:
+			thing, err := a.db.GetThingByHistoricalLocation(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Thing = thing
+			return a.ProcessGetRequest(thing, qo)
+		case entities.EntityTypeLocation:
+			locations, count, err := a.db.GetLocationsByHistoricalLocation(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Locations = locations
+			e.CountLocations, e.NextLinkLocations = a.expandedCollectionInfo(count, len(locations), "", entities.EntityLinkHistoricalLocations, entities.EntityLinkLocations, e.ID, qo)
+			for _, l := range locations {
+				if err = a.ProcessGetRequest(l, qo); err != nil {
+					return err
+				}
+			}
+		}
+	case *entities.Datastream:
+		switch nav {
+		case entities.EntityTypeThing:
+			thing, err := a.db.GetThingByDatastream(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Thing = thing
+			return a.ProcessGetRequest(thing, qo)
+		case entities.EntityTypeSensor:
+			sensor, err := a.db.GetSensorByDatastream(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Sensor = sensor
+			return a.ProcessGetRequest(sensor, qo)
+		case entities.EntityTypeObservedProperty:
+			observedProperty, err := a.db.GetObservedPropertyByDatastream(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.ObservedProperty = observedProperty
+			return a.ProcessGetRequest(observedProperty, qo)
+		case entities.EntityTypeObservation:
+			observations, count, err := a.db.GetObservationsByDatastream(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Observations = observations
+			e.CountObservations, e.NextLinkObservations = a.expandedCollectionInfo(count, len(observations), lastSkipToken(observations), entities.EntityLinkDatastreams, entities.EntityLinkObservations, e.ID, qo)
+			for _, o := range observations {
+				if err = a.ProcessGetRequest(o, qo); err != nil {
+					return err
+				}
+			}
+		}
+	case *entities.MultiDatastream:
+		switch nav {
+		case entities.EntityTypeThing:
+			thing, err := a.db.GetThingByMultiDatastream(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Thing = thing
+			return a.ProcessGetRequest(thing, qo)
+		case entities.EntityTypeSensor:
+			sensor, err := a.db.GetSensorByMultiDatastream(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Sensor = sensor
+			return a.ProcessGetRequest(sensor, qo)
+		case entities.EntityTypeObservedProperty:
+			observedProperties, count, err := a.db.GetObservedPropertiesByMultiDatastream(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.ObservedProperties = observedProperties
+			e.CountObservedProperties, e.NextLinkObservedProperties = a.expandedCollectionInfo(count, len(observedProperties), "", entities.EntityLinkMultiDatastreams, entities.EntityLinkObservedProperties, e.ID, qo)
+			for _, op := range observedProperties {
+				if err = a.ProcessGetRequest(op, qo); err != nil {
+					return err
+				}
+			}
+		case entities.EntityTypeObservation:
+			observations, count, err := a.db.GetObservationsByMultiDatastream(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Observations = observations
+			e.CountObservations, e.NextLinkObservations = a.expandedCollectionInfo(count, len(observations), lastSkipToken(observations), entities.EntityLinkMultiDatastreams, entities.EntityLinkObservations, e.ID, qo)
+			for _, o := range observations {
+				if err = a.ProcessGetRequest(o, qo); err != nil {
+					return err
+				}
+			}
+		}
+	case *entities.Sensor:
+		switch nav {
+		case entities.EntityTypeDatastream:
+			datastreams, count, err := a.db.GetDatastreamsBySensor(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Datastreams = datastreams
+			e.CountDatastreams, e.NextLinkDatastreams = a.expandedCollectionInfo(count, len(datastreams), "", entities.EntityLinkSensors, entities.EntityLinkDatastreams, e.ID, qo)
+			for _, d := range datastreams {
+				if err = a.ProcessGetRequest(d, qo); err != nil {
+					return err
+				}
+			}
+		case entities.EntityTypeMultiDatastream:
+			multiDatastreams, count, err := a.db.GetMultiDatastreamsBySensor(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.MultiDatastreams = multiDatastreams
+			e.CountMultiDatastreams, e.NextLinkMultiDatastreams = a.expandedCollectionInfo(count, len(multiDatastreams), "", entities.EntityLinkSensors, entities.EntityLinkMultiDatastreams, e.ID, qo)
+			for _, d := range multiDatastreams {
+				if err = a.ProcessGetRequest(d, qo); err != nil {
+					return err
+				}
+			}
+		}
+	case *entities.ObservedProperty:
+		switch nav {
+		case entities.EntityTypeDatastream:
+			datastreams, count, err := a.db.GetDatastreamsByObservedProperty(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Datastreams = datastreams
+			e.CountDatastreams, e.NextLinkDatastreams = a.expandedCollectionInfo(count, len(datastreams), "", entities.EntityLinkObservedProperties, entities.EntityLinkDatastreams, e.ID, qo)
+			for _, d := range datastreams {
+				if err = a.ProcessGetRequest(d, qo); err != nil {
+					return err
+				}
+			}
+		case entities.EntityTypeMultiDatastream:
+			multiDatastreams, count, err := a.db.GetMultiDatastreamsByObservedProperty(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.MultiDatastreams = multiDatastreams
+			e.CountMultiDatastreams, e.NextLinkMultiDatastreams = a.expandedCollectionInfo(count, len(multiDatastreams), "", entities.EntityLinkObservedProperties, entities.EntityLinkMultiDatastreams, e.ID, qo)
+			for _, d := range multiDatastreams {
+				if err = a.ProcessGetRequest(d, qo); err != nil {
+					return err
+				}
+			}
+		}
+	case *entities.Observation:
+		switch nav {
+		case entities.EntityTypeDatastream:
+			// an observation of a MultiDatastream has no Datastream to expand
+			if e.InMultiDatastream {
+				return nil
+			}
+
+			datastream, err := a.db.GetDatastreamByObservation(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Datastream = datastream
+			return a.ProcessGetRequest(datastream, qo)
+		case entities.EntityTypeMultiDatastream:
+			// an observation of a Datastream has no MultiDatastream to expand
+			if !e.InMultiDatastream {
+				return nil
+			}
+
+			multiDatastream, err := a.db.GetMultiDatastreamByObservation(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.MultiDatastream = multiDatastream
+			return a.ProcessGetRequest(multiDatastream, qo)
+		case entities.EntityTypeFeatureOfInterest:
+			foi, err := a.db.GetFeatureOfInterestByObservation(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.FeatureOfInterest = foi
+			return a.ProcessGetRequest(foi, qo)
+		}
+	case *entities.Actuator:
+		if nav == entities.EntityTypeTaskingCapability {
+			capabilities, count, err := a.db.GetTaskingCapabilitiesByActuator(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.TaskingCapabilities = capabilities
+			e.CountTaskingCapabilities, e.NextLinkTaskingCapabilities = a.expandedCollectionInfo(count, len(capabilities), "", entities.EntityLinkActuators, entities.EntityLinkTaskingCapabilities, e.ID, qo)
+			for _, tc := range capabilities {
+				if err = a.ProcessGetRequest(tc, qo); err != nil {
+					return err
+				}
+			}
+		}
+	case *entities.TaskingCapability:
+		switch nav {
+		case entities.EntityTypeThing:
+			thing, err := a.db.GetThingByTaskingCapability(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Thing = thing
+			return a.ProcessGetRequest(thing, qo)
+		case entities.EntityTypeActuator:
+			actuator, err := a.db.GetActuatorByTaskingCapability(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Actuator = actuator
+			return a.ProcessGetRequest(actuator, qo)
+		case entities.EntityTypeTask:
+			tasks, count, err := a.db.GetTasksByTaskingCapability(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Tasks = tasks
+			e.CountTasks, e.NextLinkTasks = a.expandedCollectionInfo(count, len(tasks), "", entities.EntityLinkTaskingCapabilities, entities.EntityLinkTasks, e.ID, qo)
+			for _, t := range tasks {
+				if err = a.ProcessGetRequest(t, qo); err != nil {
+					return err
+				}
+			}
+		}
+	case *entities.Task:
+		if nav == entities.EntityTypeTaskingCapability {
+			capability, err := a.db.GetTaskingCapabilityByTask(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.TaskingCapability = capability
+			return a.ProcessGetRequest(capability, qo)
+		}
+	case *entities.FeatureOfInterest:
+		if nav == entities.EntityTypeObservation {
+			observations, count, err := a.db.GetObservationsByFeatureOfInterest(e.ID, qo)
+			if err != nil {
+				return err
+			}
+
+			e.Observations = observations
+			e.CountObservations, e.NextLinkObservations = a.expandedCollectionInfo(count, len(observations), lastSkipToken(observations), entities.EntityLinkFeatureOfInterests, entities.EntityLinkObservations, e.ID, qo)
+			for _, o := range observations {
+				if err = a.ProcessGetRequest(o, qo); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// expandedCollectionInfo returns the @iot.count and @iot.nextLink of an expanded collection, the nextLink
+// points to the navigation link of the collection, for example Things(1)/Datastreams, and uses the
+// skipToken of the last expanded entity when given
+func (a *APIv1) expandedCollectionInfo(count int, resultCount int, skipToken string, parent entities.EntityLink, navigation entities.EntityLink, id interface{}, qo *odata.QueryOptions) (*int, string) {
+	path := entities.CreateEntityLink(true, a.config.GetExternalServerURI(), parent.ToString(), navigation.ToString(), id)
+	return responseCount(count, qo), a.createNextLink(count, resultCount, path, qo, skipToken)
+}
+
+// expandQueryOptions returns the query options of the operation, an expanded collection is always paged: when
+// no $top is requested inside the $expand the maxEntityResponse of the server is used and a larger $top is
+// limited to the maxEntityResponse. The $top is set on the operation so it is only determined once per request
+func (a *APIv1) expandQueryOptions(o *odata.ExpandOperation) *odata.QueryOptions {
+	if strings.ToLower(o.Name) == strings.ToLower(o.Entity.GetEntityType().ToString()) {
+		return o.QueryOptions
+	}
+
+	max := a.config.Server.MaxEntityResponse
+	if max <= 0 {
+		max = defaultExpandTop
+	}
+
+	if o.QueryOptions == nil {
+		o.QueryOptions = &odata.QueryOptions{}
+	}
+
+	qo := o.QueryOptions
+	if qo.QueryTop.IsNil() || qo.QueryTop.Limit > max {
+		qo.QueryTop = &odata.QueryTop{Limit: max}
+		qo.QueryTop.RawQuery = fmt.Sprintf("%v", max)
+	}
+
+	return qo
+}
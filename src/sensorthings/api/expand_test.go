@@ -0,0 +1,74 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/geodan/gost/src/configuration"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+	"github.com/stretchr/testify/assert"
+)
+
+// expandDatabase returns as many Datastreams and Observations as requested by $top and records the
+// requested $top per entity type
+type expandDatabase struct {
+	models.Database
+	tops map[entities.EntityType][]int
+}
+
+func (d *expandDatabase) record(et entities.EntityType, qo *odata.QueryOptions) int {
+	top := -1
+	if qo != nil && !qo.QueryTop.IsNil() {
+		top = qo.QueryTop.Limit
+	}
+
+	d.tops[et] = append(d.tops[et], top)
+	return top
+}
+
+func (d *expandDatabase) GetDatastreamsByThing(id interface{}, qo *odata.QueryOptions) ([]*entities.Datastream, int, error) {
+	var datastreams []*entities.Datastream
+	top := d.record(entities.EntityTypeDatastream, qo)
+	for i := 0; i < top; i++ {
+		ds := &entities.Datastream{}
+		ds.ID = i + 1
+		datastreams = append(datastreams, ds)
+	}
+
+	return datastreams, 0, nil
+}
+
+func (d *expandDatabase) GetObservationsByDatastream(id interface{}, qo *odata.QueryOptions) ([]*entities.Observation, int, error) {
+	var observations []*entities.Observation
+	top := d.record(entities.EntityTypeObservation, qo)
+	for i := 0; i < top; i++ {
+		o := &entities.Observation{}
+		o.ID = i + 1
+		observations = append(observations, o)
+	}
+
+	return observations, 0, nil
+}
+
+func TestExpandShouldPageEveryExpandedCollection(t *testing.T) {
+	// arrange
+	cfg := configuration.Config{}
+	cfg.Server.ExternalURI = "http://localhost"
+	cfg.Server.MaxEntityResponse = 2
+	db := &expandDatabase{tops: map[entities.EntityType][]int{}}
+	stAPI := &APIv1{db: db, config: cfg}
+	qo, _ := odata.CreateQueryOptions(map[string]string{"$expand": "Datastreams($top=5)/Observations"})
+	thing := &entities.Thing{}
+	thing.ID = 1
+
+	// act
+	err := stAPI.ProcessGetRequest(thing, qo)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, []int{2}, db.tops[entities.EntityTypeDatastream], "$top should be limited to maxEntityResponse")
+	assert.Equal(t, []int{2, 2}, db.tops[entities.EntityTypeObservation], "$top should default to maxEntityResponse")
+	assert.Contains(t, thing.NextLinkDatastreams, "$top=2")
+	assert.Contains(t, thing.Datastreams[0].NextLinkObservations, "Datastreams(1)/Observations")
+}
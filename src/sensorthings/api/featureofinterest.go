@@ -21,7 +21,9 @@ func (a *APIv1) GetFeatureOfInterest(id interface{}, qo *odata.QueryOptions, pat
 		return nil, err
 	}
 
-	a.ProcessGetRequest(l, qo)
+	if err := a.ProcessGetRequest(l, qo); err != nil {
+		return nil, err
+	}
 	return l, nil
 }
 
@@ -37,7 +39,9 @@ func (a *APIv1) GetFeatureOfInterestByObservation(id interface{}, qo *odata.Quer
 		return nil, err
 	}
 
-	a.ProcessGetRequest(l, qo)
+	if err := a.ProcessGetRequest(l, qo); err != nil {
+		return nil, err
+	}
 	return l, nil
 }
 
@@ -55,14 +59,16 @@ func (a *APIv1) GetFeatureOfInterests(qo *odata.QueryOptions, path string) (*mod
 func processFeatureOfInterest(a *APIv1, fois []*entities.FeatureOfInterest, qo *odata.QueryOptions, path string, count int, err error) (*models.ArrayResponse, error) {
 	for idx, item := range fois {
 		i := *item
-		a.ProcessGetRequest(&i, qo)
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
 		fois[idx] = &i
 	}
 
 	var data interface{} = fois
 	return &models.ArrayResponse{
-		Count:    count,
-		NextLink: a.CreateNextLink(count, path, qo),
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(fois), path, qo),
 		Data:     &data,
 	}, nil
 }
@@ -88,8 +94,12 @@ func (a *APIv1) PostFeatureOfInterest(foi *entities.FeatureOfInterest) (*entitie
 	return l, nil
 }
 
-// PutFeatureOfInterest adds a FeatureOfInterest to the database
+// PutFeatureOfInterest replaces a FeatureOfInterest in the database
 func (a *APIv1) PutFeatureOfInterest(id interface{}, foi *entities.FeatureOfInterest) (*entities.FeatureOfInterest, []error) {
+	if _, errs := foi.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
 	supported, err2 := entities.CheckEncodingSupported(foi, foi.EncodingType)
 	if !supported || err2 != nil {
 		return nil, []error{err2}
@@ -22,7 +22,9 @@ func (a *APIv1) GetHistoricalLocation(id interface{}, qo *odata.QueryOptions, pa
 		return nil, err
 	}
 
-	a.ProcessGetRequest(hl, qo)
+	if err := a.ProcessGetRequest(hl, qo); err != nil {
+		return nil, err
+	}
 	return hl, nil
 }
 
@@ -62,14 +64,16 @@ func (a *APIv1) GetHistoricalLocationsByThing(thingID interface{}, qo *odata.Que
 func processHistoricalLocations(a *APIv1, historicalLocations []*entities.HistoricalLocation, qo *odata.QueryOptions, path string, count int, err error) (*models.ArrayResponse, error) {
 	for idx, item := range historicalLocations {
 		i := *item
-		a.ProcessGetRequest(&i, qo)
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
 		historicalLocations[idx] = &i
 	}
 
 	var data interface{} = historicalLocations
 	return &models.ArrayResponse{
-		Count:    count,
-		NextLink: a.CreateNextLink(count, path, qo),
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(historicalLocations), path, qo),
 		Data:     &data,
 	}, nil
 }
@@ -89,12 +93,41 @@ func (a *APIv1) PostHistoricalLocation(hl *entities.HistoricalLocation) (*entiti
 	return l, nil
 }
 
-// PutHistoricalLocation adds a new HistoricalLocation to the database
+// PutHistoricalLocation replaces a HistoricalLocation in the database, the Thing and Locations are kept when omitted
 func (a *APIv1) PutHistoricalLocation(id interface{}, hl *entities.HistoricalLocation) (*entities.HistoricalLocation, []error) {
-	l, err2 := a.db.PutHistoricalLocation(id, hl)
-	if err2 != nil {
-		return nil, []error{err2}
+	if hl.Thing == nil {
+		hl.Thing = &entities.Thing{}
+		if err := a.keepRelation(entities.EntityTypeHistoricalLocation, id, entities.EntityTypeThing, &hl.Thing.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if hl.Locations == nil {
+		locations, _, err := a.db.GetLocationsByHistoricalLocation(id, nil)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		for _, l := range locations {
+			hl.Locations = append(hl.Locations, &entities.Location{BaseEntity: entities.BaseEntity{ID: l.ID}})
+		}
 	}
+
+	if _, errs := hl.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	var l *entities.HistoricalLocation
+	err := a.WithTransaction(func(tx models.API) error {
+		var err error
+		l, err = tx.(*APIv1).db.PutHistoricalLocation(id, hl)
+		return err
+	})
+
+	if err != nil {
+		return nil, []error{err}
+	}
+
 	l.SetAllLinks(a.config.GetExternalServerURI())
 	return l, nil
 }
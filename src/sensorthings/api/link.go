@@ -0,0 +1,83 @@
+package api
+
+import (
+	"time"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+)
+
+// LinkEntities links the child entity to the parent entity, when a Location is linked to a Thing a
+// HistoricalLocation holding all current Locations is created for the Thing. No HistoricalLocation is
+// created when the Location was already linked to the Thing
+func (a *APIv1) LinkEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) error {
+	return a.WithTransaction(func(tx models.API) error {
+		txAPI := tx.(*APIv1)
+		linked, err := txAPI.db.LinkEntities(parent, parentID, child, childID)
+		if err != nil {
+			return err
+		}
+
+		thingID, _ := thingLocationIDs(parent, parentID, child, childID)
+		if thingID == nil || !linked {
+			return nil
+		}
+
+		return txAPI.postCurrentLocations(thingID)
+	})
+}
+
+// UnlinkEntities removes the link between the parent and child entity, when a Location is unlinked from a
+// Thing which still has a Location a HistoricalLocation is created for the Thing
+func (a *APIv1) UnlinkEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) error {
+	return a.WithTransaction(func(tx models.API) error {
+		txAPI := tx.(*APIv1)
+		if err := txAPI.db.UnlinkEntities(parent, parentID, child, childID); err != nil {
+			return err
+		}
+
+		thingID, _ := thingLocationIDs(parent, parentID, child, childID)
+		if thingID == nil {
+			return nil
+		}
+
+		return txAPI.postCurrentLocations(thingID)
+	})
+}
+
+// thingLocationIDs returns the id of the Thing and Location when the link is between a Thing and a Location
+func thingLocationIDs(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (interface{}, interface{}) {
+	if parent == entities.EntityTypeThing && child == entities.EntityTypeLocation {
+		return parentID, childID
+	}
+
+	if parent == entities.EntityTypeLocation && child == entities.EntityTypeThing {
+		return childID, parentID
+	}
+
+	return nil, nil
+}
+
+// postCurrentLocations records the current Locations of the Thing in a HistoricalLocation, no HistoricalLocation
+// is created when the Thing has no Location
+func (a *APIv1) postCurrentLocations(thingID interface{}) error {
+	locations, _, err := a.db.GetLocationsByThing(thingID, nil)
+	if err != nil || len(locations) == 0 {
+		return err
+	}
+
+	return a.postHistoricalLocation(thingID, locations)
+}
+
+// postHistoricalLocation records the given locations as the current locations of the Thing
+func (a *APIv1) postHistoricalLocation(thingID interface{}, locations []*entities.Location) error {
+	hl := &entities.HistoricalLocation{
+		Thing:     &entities.Thing{},
+		Locations: locations,
+	}
+
+	hl.Thing.ID = thingID
+	hl.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	_, err := a.db.PostHistoricalLocation(hl)
+	return err
+}
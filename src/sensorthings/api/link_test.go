@@ -0,0 +1,69 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+	"github.com/stretchr/testify/assert"
+)
+
+// linkDatabase holds the Locations linked to a Thing, a transaction is not rolled back
+type linkDatabase struct {
+	testDatabase
+	locations []interface{}
+}
+
+func (d *linkDatabase) WithTransaction(fn func(db models.Database) error) error {
+	return fn(d)
+}
+
+func (d *linkDatabase) LinkEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (bool, error) {
+	for _, id := range d.locations {
+		if id == childID {
+			return false, nil
+		}
+	}
+
+	d.locations = append(d.locations, childID)
+	return true, nil
+}
+
+func (d *linkDatabase) GetLocationsByThing(id interface{}, qo *odata.QueryOptions) ([]*entities.Location, int, error) {
+	var locations []*entities.Location
+	for _, id := range d.locations {
+		l := &entities.Location{}
+		l.ID = id
+		locations = append(locations, l)
+	}
+
+	return locations, len(locations), nil
+}
+
+func TestLinkEntitiesShouldRecordAllCurrentLocations(t *testing.T) {
+	// arrange
+	db := &linkDatabase{locations: []interface{}{1}}
+	stAPI := &APIv1{db: db}
+
+	// act
+	err := stAPI.LinkEntities(entities.EntityTypeThing, 1, entities.EntityTypeLocation, 2)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Len(t, db.inserted, 1)
+	assert.Len(t, db.inserted[0].(*entities.HistoricalLocation).Locations, 2)
+}
+
+func TestLinkEntitiesShouldSkipExistingLink(t *testing.T) {
+	// arrange
+	db := &linkDatabase{locations: []interface{}{1}}
+	stAPI := &APIv1{db: db}
+
+	// act
+	err := stAPI.LinkEntities(entities.EntityTypeThing, 1, entities.EntityTypeLocation, 1)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Empty(t, db.inserted)
+}
@@ -2,8 +2,6 @@ package api
 
 import (
 	"errors"
-	"log"
-	"time"
 
 	gostErrors "github.com/geodan/gost/src/errors"
 	"github.com/geodan/gost/src/sensorthings/entities"
@@ -32,44 +30,33 @@ func (a *APIv1) PostLocation(location *entities.Location) (*entities.Location, [
 }
 
 // PostLocationByThing checks if the given location entity is valid and adds it to the database
-// the new location will be linked to a thing if needed
+// the new location will be linked to a thing if needed, both in the same transaction
 func (a *APIv1) PostLocationByThing(thingID interface{}, location *entities.Location) (*entities.Location, []error) {
-	var err []error
-	var err2 error
-	l, err := a.PostLocation(location)
-	if len(err) > 0 {
-		return nil, err
-	}
-
-	if thingID != nil {
-		err2 = a.LinkLocation(thingID, l.ID)
-		if err2 != nil {
-			err3 := a.DeleteLocation(l.ID)
-			if err3 != nil {
-				log.Printf("Error rolling back location %v", err2)
-			}
+	var l *entities.Location
+	var errs []error
+	err := a.WithTransaction(func(txAPI models.API) error {
+		tx := txAPI.(*APIv1)
+		if l, errs = tx.PostLocation(location); len(errs) > 0 {
+			return errs[0]
+		}
 
-			return nil, []error{err2}
+		if thingID == nil {
+			return nil
 		}
 
-		hl := &entities.HistoricalLocation{
-			Thing:     &entities.Thing{},
-			Locations: []*entities.Location{l},
+		if err := tx.LinkLocation(thingID, l.ID); err != nil {
+			return err
 		}
 
-		hl.Thing.ID = thingID
-		hl.Time = time.Now().UTC().Format(time.RFC3339Nano)
-		hl.ContainsMandatoryParams()
+		return tx.postHistoricalLocation(thingID, []*entities.Location{l})
+	})
 
-		hl, err = a.PostHistoricalLocation(hl)
-		if len(err) > 0 {
-			err2 := a.DeleteHistoricalLocation(l.ID)
-			if err2 != nil {
-				log.Printf("Error rolling back location %v", err2)
-			}
+	if len(errs) > 0 {
+		return nil, errs
+	}
 
-			return nil, []error{err2}
-		}
+	if err != nil {
+		return nil, []error{err}
 	}
 
 	l.SetAllLinks(a.config.GetExternalServerURI())
@@ -89,7 +76,9 @@ func (a *APIv1) GetLocation(id interface{}, qo *odata.QueryOptions, path string)
 		return nil, err
 	}
 
-	a.ProcessGetRequest(l, qo)
+	if err := a.ProcessGetRequest(l, qo); err != nil {
+		return nil, err
+	}
 	return l, nil
 }
 
@@ -129,14 +118,16 @@ func (a *APIv1) GetLocationsByThing(thingID interface{}, qo *odata.QueryOptions,
 func processLocations(a *APIv1, locations []*entities.Location, qo *odata.QueryOptions, path string, count int, err error) (*models.ArrayResponse, error) {
 	for idx, item := range locations {
 		i := *item
-		a.ProcessGetRequest(&i, qo)
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
 		locations[idx] = &i
 	}
 
 	var data interface{} = locations
 	return &models.ArrayResponse{
-		Count:    count,
-		NextLink: a.CreateNextLink(count, path, qo),
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(locations), path, qo),
 		Data:     &data,
 	}, nil
 }
@@ -157,12 +148,20 @@ func (a *APIv1) PatchLocation(id interface{}, location *entities.Location) (*ent
 	return a.db.PatchLocation(id, location)
 }
 
-// PutLocation updates the given thing in the database
+// PutLocation replaces the given location in the database
 func (a *APIv1) PutLocation(id interface{}, location *entities.Location) (*entities.Location, []error) {
-	var err2 error
-	putlocation, err2 := a.db.PutLocation(id, location)
-	if err2 != nil {
-		return nil, []error{err2}
+	if _, errs := location.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	supported, err := entities.CheckEncodingSupported(location, location.EncodingType)
+	if !supported || err != nil {
+		return nil, []error{err}
+	}
+
+	putlocation, err := a.db.PutLocation(id, location)
+	if err != nil {
+		return nil, []error{err}
 	}
 
 	putlocation.SetAllLinks(a.config.GetExternalServerURI())
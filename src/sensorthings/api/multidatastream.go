@@ -0,0 +1,258 @@
+package api
+
+import (
+	"errors"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// GetMultiDatastream retrieves a MultiDatastream by id and given query
+func (a *APIv1) GetMultiDatastream(id interface{}, qo *odata.QueryOptions, path string) (*entities.MultiDatastream, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.MultiDatastream{})
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := a.db.GetMultiDatastream(id, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(ds, qo); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// GetMultiDatastreams retrieves an array of MultiDatastreams based on the given query
+func (a *APIv1) GetMultiDatastreams(qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.MultiDatastream{})
+	if err != nil {
+		return nil, err
+	}
+
+	datastreams, count, err := a.db.GetMultiDatastreams(qo)
+	return processMultiDatastreams(a, datastreams, qo, path, count, err)
+}
+
+// GetMultiDatastreamsByThing returns all MultiDatastreams linked to the given thing
+func (a *APIv1) GetMultiDatastreamsByThing(thingID interface{}, qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.MultiDatastream{})
+	if err != nil {
+		return nil, err
+	}
+
+	datastreams, count, err := a.db.GetMultiDatastreamsByThing(thingID, qo)
+	return processMultiDatastreams(a, datastreams, qo, path, count, err)
+}
+
+// GetMultiDatastreamByObservation returns the MultiDatastream linked to the given observation
+func (a *APIv1) GetMultiDatastreamByObservation(observationID interface{}, qo *odata.QueryOptions, path string) (*entities.MultiDatastream, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.MultiDatastream{})
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := a.db.GetMultiDatastreamByObservation(observationID, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(ds, qo); err != nil {
+		return nil, err
+	}
+	return ds, nil
+}
+
+// GetMultiDatastreamsBySensor returns all MultiDatastreams linked to the given sensor
+func (a *APIv1) GetMultiDatastreamsBySensor(sensorID interface{}, qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.MultiDatastream{})
+	if err != nil {
+		return nil, err
+	}
+
+	datastreams, count, err := a.db.GetMultiDatastreamsBySensor(sensorID, qo)
+	return processMultiDatastreams(a, datastreams, qo, path, count, err)
+}
+
+// GetMultiDatastreamsByObservedProperty returns all MultiDatastreams which observe the given ObservedProperty
+func (a *APIv1) GetMultiDatastreamsByObservedProperty(oID interface{}, qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.MultiDatastream{})
+	if err != nil {
+		return nil, err
+	}
+
+	datastreams, count, err := a.db.GetMultiDatastreamsByObservedProperty(oID, qo)
+	return processMultiDatastreams(a, datastreams, qo, path, count, err)
+}
+
+func processMultiDatastreams(a *APIv1, datastreams []*entities.MultiDatastream, qo *odata.QueryOptions, path string, count int, err error) (*models.ArrayResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, item := range datastreams {
+		i := *item
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
+		datastreams[idx] = &i
+	}
+
+	var data interface{} = datastreams
+	return &models.ArrayResponse{
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(datastreams), path, qo),
+		Data:     &data,
+	}, nil
+}
+
+// PostMultiDatastream adds a new MultiDatastream to the database, deep inserted Sensor, ObservedProperties
+// and Observations are created in the same transaction as the MultiDatastream
+func (a *APIv1) PostMultiDatastream(datastream *entities.MultiDatastream) (*entities.MultiDatastream, []error) {
+	if _, errs := datastream.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	var ns *entities.MultiDatastream
+	var errs []error
+	err := a.WithTransaction(func(txAPI models.API) error {
+		tx := txAPI.(*APIv1)
+		ns, errs = tx.postMultiDatastream(datastream)
+		if len(errs) > 0 {
+			return errs[0]
+		}
+
+		return nil
+	})
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	ns.SetAllLinks(a.config.GetExternalServerURI())
+	return ns, nil
+}
+
+func (a *APIv1) postMultiDatastream(datastream *entities.MultiDatastream) (*entities.MultiDatastream, []error) {
+	// Check if ObservedProperties are deep inserted
+	for i, op := range datastream.ObservedProperties {
+		if op.ID != nil {
+			continue
+		}
+
+		posted, err := a.db.PostObservedProperty(op)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		datastream.ObservedProperties[i] = posted
+	}
+
+	// Check if Sensor is deep inserted
+	if datastream.Sensor != nil && datastream.Sensor.ID == nil {
+		s, err := a.db.PostSensor(datastream.Sensor)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		datastream.Sensor = s
+	}
+
+	observations := datastream.Observations
+	ns, err := a.db.PostMultiDatastream(datastream)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	// Check if Observations are deep inserted
+	for _, observation := range observations {
+		ds := &entities.MultiDatastream{}
+		ds.ID = ns.ID
+		observation.MultiDatastream = ds
+
+		if _, errs := a.PostObservation(observation); len(errs) > 0 {
+			return nil, errs
+		}
+	}
+
+	ns.Observations = nil
+	return ns, nil
+}
+
+// PostMultiDatastreamByThing adds a new MultiDatastream by given thing ID
+func (a *APIv1) PostMultiDatastreamByThing(thingID interface{}, datastream *entities.MultiDatastream) (*entities.MultiDatastream, []error) {
+	t := &entities.Thing{}
+	t.ID = thingID
+	datastream.Thing = t
+	return a.PostMultiDatastream(datastream)
+}
+
+// PatchMultiDatastream updates the given MultiDatastream in the database
+func (a *APIv1) PatchMultiDatastream(id interface{}, datastream *entities.MultiDatastream) (*entities.MultiDatastream, error) {
+	if datastream.Observations != nil || datastream.Sensor != nil || datastream.ObservedProperties != nil || datastream.Thing != nil {
+		return nil, gostErrors.NewBadRequestError(errors.New("Deep patch MultiDatastream not supported."))
+	}
+
+	return a.db.PatchMultiDatastream(id, datastream)
+}
+
+// PutMultiDatastream replaces the given MultiDatastream in the database, the Thing, Sensor and ObservedProperties
+// are kept when omitted
+func (a *APIv1) PutMultiDatastream(id interface{}, datastream *entities.MultiDatastream) (*entities.MultiDatastream, []error) {
+	et := entities.EntityTypeMultiDatastream
+	if datastream.Thing == nil {
+		datastream.Thing = &entities.Thing{}
+		if err := a.keepRelation(et, id, entities.EntityTypeThing, &datastream.Thing.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if datastream.Sensor == nil {
+		datastream.Sensor = &entities.Sensor{}
+		if err := a.keepRelation(et, id, entities.EntityTypeSensor, &datastream.Sensor.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if datastream.ObservedProperties == nil {
+		ops, _, err := a.db.GetObservedPropertiesByMultiDatastream(id, nil)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		for _, op := range ops {
+			datastream.ObservedProperties = append(datastream.ObservedProperties, &entities.ObservedProperty{BaseEntity: entities.BaseEntity{ID: op.ID}})
+		}
+	}
+
+	if _, errs := datastream.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	var putDatastream *entities.MultiDatastream
+	err := a.WithTransaction(func(tx models.API) error {
+		var err error
+		putDatastream, err = tx.(*APIv1).db.PutMultiDatastream(id, datastream)
+		return err
+	})
+
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	putDatastream.SetAllLinks(a.config.GetExternalServerURI())
+	return putDatastream, nil
+}
+
+// DeleteMultiDatastream deletes a MultiDatastream from the database
+func (a *APIv1) DeleteMultiDatastream(id interface{}) error {
+	return a.db.DeleteMultiDatastream(id)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 
 	gostErrors "github.com/geodan/gost/src/errors"
 	"github.com/geodan/gost/src/sensorthings/entities"
@@ -18,12 +19,18 @@ func (a *APIv1) GetObservation(id interface{}, qo *odata.QueryOptions, path stri
 		return nil, err
 	}
 
+	if qo != nil && !qo.QueryApply.IsNil() {
+		return nil, gostErrors.NewBadRequestError(errors.New("$apply can only be used on a collection of Observations"))
+	}
+
 	o, err := a.db.GetObservation(id, qo)
 	if err != nil {
 		return nil, err
 	}
 
-	a.ProcessGetRequest(o, qo)
+	if err := a.ProcessGetRequest(o, qo); err != nil {
+		return nil, err
+	}
 	return o, nil
 }
 
@@ -34,6 +41,11 @@ func (a *APIv1) GetObservations(qo *odata.QueryOptions, path string) (*models.Ar
 		return nil, err
 	}
 
+	if qo != nil && !qo.QueryApply.IsNil() {
+		results, count, err := a.db.AggregateObservations(qo)
+		return processAggregation(a, results, qo, path, count, err)
+	}
+
 	observations, count, err := a.db.GetObservations(qo)
 	return processObservations(a, observations, qo, path, count, err)
 }
@@ -45,6 +57,11 @@ func (a *APIv1) GetObservationsByFeatureOfInterest(foiID interface{}, qo *odata.
 		return nil, err
 	}
 
+	if qo != nil && !qo.QueryApply.IsNil() {
+		results, count, err := a.db.AggregateObservationsByFeatureOfInterest(foiID, qo)
+		return processAggregation(a, results, qo, path, count, err)
+	}
+
 	observations, count, err := a.db.GetObservationsByFeatureOfInterest(foiID, qo)
 	return processObservations(a, observations, qo, path, count, err)
 }
@@ -56,29 +73,171 @@ func (a *APIv1) GetObservationsByDatastream(datastreamID interface{}, qo *odata.
 		return nil, err
 	}
 
+	if qo != nil && !qo.QueryApply.IsNil() {
+		results, count, err := a.db.AggregateObservationsByDatastream(datastreamID, qo)
+		return processAggregation(a, results, qo, path, count, err)
+	}
+
 	observations, count, err := a.db.GetObservationsByDatastream(datastreamID, qo)
 	return processObservations(a, observations, qo, path, count, err)
 }
 
-func processObservations(a *APIv1, observations []*entities.Observation, qo *odata.QueryOptions, path string, count int, err error) (*models.ArrayResponse, error) {
+// GetObservationsByMultiDatastream returns all observations by given MultiDatastream and QueryOptions
+func (a *APIv1) GetObservationsByMultiDatastream(multiDatastreamID interface{}, qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Observation{})
 	if err != nil {
 		return nil, err
 	}
 
-	for idx, item := range observations {
-		i := *item
-		a.ProcessGetRequest(&i, qo)
-		observations[idx] = &i
+	if qo != nil && !qo.QueryApply.IsNil() {
+		return nil, gostErrors.NewBadRequestError(errors.New("$apply is not supported on the Observations of a MultiDatastream"))
+	}
+
+	observations, count, err := a.db.GetObservationsByMultiDatastream(multiDatastreamID, qo)
+	return processObservations(a, observations, qo, path, count, err)
+}
+
+func processObservations(a *APIv1, observations []*entities.Observation, qo *odata.QueryOptions, path string, count int, err error) (*models.ArrayResponse, error) {
+	if err != nil {
+		return nil, err
 	}
 
 	var data interface{} = observations
+	if qo != nil && qo.QueryResultFormat.IsDataArray() {
+		data = a.createDataArrays(observations, qo)
+	} else {
+		for idx, item := range observations {
+			i := *item
+			if err := a.ProcessGetRequest(&i, qo); err != nil {
+				return nil, err
+			}
+			observations[idx] = &i
+		}
+	}
+
+	return &models.ArrayResponse{
+		Count:    responseCount(count, qo),
+		NextLink: a.createNextLink(count, len(observations), path, qo, lastSkipToken(observations)),
+		Data:     &data,
+	}, nil
+}
+
+// processAggregation creates the response of $apply, every result is a JSON object holding the
+// group properties and aggregates of one group under their alias
+func processAggregation(a *APIv1, results []json.RawMessage, qo *odata.QueryOptions, path string, count int, err error) (*models.ArrayResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	var data interface{} = results
 	return &models.ArrayResponse{
-		Count:    count,
-		NextLink: a.CreateNextLink(count, path, qo),
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(results), path, qo),
 		Data:     &data,
 	}, nil
 }
 
+// lastSkipToken returns the $skiptoken of the last observation, which is used to request the following page
+func lastSkipToken(observations []*entities.Observation) string {
+	if len(observations) == 0 {
+		return ""
+	}
+
+	return observations[len(observations)-1].SkipToken
+}
+
+// createDataArrays groups the observations per Datastream into a DataArrayResponse, the components are
+// the properties given in $select in the requested order or all properties of an observation
+func (a *APIv1) createDataArrays(observations []*entities.Observation, qo *odata.QueryOptions) []models.DataArrayResponse {
+	components := dataArrayComponents(qo)
+	dataArrays := []models.DataArrayResponse{}
+	indexes := map[interface{}]int{}
+	for _, o := range observations {
+		var datastreamID interface{}
+		if o.Datastream != nil {
+			datastreamID = o.Datastream.ID
+		}
+
+		idx, ok := indexes[datastreamID]
+		if !ok {
+			dataArray := models.DataArrayResponse{Components: components, DataArray: [][]interface{}{}}
+			if datastreamID != nil {
+				dataArray.NavDatastream = entities.CreateEntitySelfLink(a.config.GetExternalServerURI(), entities.EntityLinkDatastreams.ToString(), datastreamID)
+			}
+
+			idx = len(dataArrays)
+			indexes[datastreamID] = idx
+			dataArrays = append(dataArrays, dataArray)
+		}
+
+		values := make([]interface{}, len(components))
+		for i, c := range components {
+			values[i] = observationValue(o, c)
+		}
+
+		dataArrays[idx].DataArray = append(dataArrays[idx].DataArray, values)
+		dataArrays[idx].Count++
+	}
+
+	return dataArrays
+}
+
+// dataArrayComponents returns the observation properties found in $select in the requested order,
+// navigation properties are not part of a data array. Returns all properties when none are selected
+func dataArrayComponents(qo *odata.QueryOptions) []string {
+	properties := (&entities.Observation{}).GetPropertyNames()
+	if qo.QuerySelect.IsNil() {
+		return properties
+	}
+
+	components := []string{}
+	for _, p := range qo.QuerySelect.Params {
+		for _, property := range properties {
+			if strings.ToLower(p) == strings.ToLower(property) {
+				components = append(components, property)
+			}
+		}
+	}
+
+	if len(components) == 0 {
+		return properties
+	}
+
+	return components
+}
+
+// observationValue returns the value of the given observation property, empty strings are returned as nil
+func observationValue(o *entities.Observation, property string) interface{} {
+	var value interface{}
+	switch property {
+	case "id":
+		value = o.ID
+	case "phenomenonTime":
+		value = o.PhenomenonTime
+	case "result":
+		value = o.Result
+	case "resultTime":
+		// a missing resultTime is stored as null
+		if o.ResultTime != "null" {
+			value = o.ResultTime
+		}
+	case "resultQuality":
+		value = o.ResultQuality
+	case "validTime":
+		value = o.ValidTime
+	case "parameters":
+		if o.Parameters != nil {
+			value = o.Parameters
+		}
+	}
+
+	if s, ok := value.(string); ok && len(s) == 0 {
+		return nil
+	}
+
+	return value
+}
+
 // ConvertLocationToFoi converts a location to FOI
 func ConvertLocationToFoi(l *entities.Location) *entities.FeatureOfInterest {
 	foi := &entities.FeatureOfInterest{}
@@ -94,14 +253,28 @@ func ConvertLocationToFoi(l *entities.Location) *entities.FeatureOfInterest {
 // exist, returns only the existing FeatureOfInterest ID
 func CopyLocationToFoi(gdb *models.Database, datastreamID interface{}) (string, error) {
 	db := *gdb
-	var result string
-	var l *entities.Location
-	var err error
-
-	if l, err = db.GetLocationByDatastreamID(datastreamID); err != nil {
+	l, err := db.GetLocationByDatastreamID(datastreamID)
+	if err != nil {
 		return "", gostErrors.NewConflictRequestError(errors.New("No location found for datastream.Thing"))
 	}
 
+	return locationToFoi(db, l)
+}
+
+// copyMultiDatastreamLocationToFoi copies the location of the thing of a MultiDatastream to the
+// FeatureOfInterest table, returns the existing FeatureOfInterest ID when already copied
+func copyMultiDatastreamLocationToFoi(db models.Database, multiDatastreamID interface{}) (string, error) {
+	l, err := db.GetLocationByMultiDatastreamID(multiDatastreamID)
+	if err != nil {
+		return "", gostErrors.NewConflictRequestError(errors.New("No location found for multidatastream.Thing"))
+	}
+
+	return locationToFoi(db, l)
+}
+
+// locationToFoi returns the ID of the FeatureOfInterest created from the given location
+func locationToFoi(db models.Database, l *entities.Location) (string, error) {
+	var result string
 	var featureOfInterest *entities.FeatureOfInterest
 
 	// now check if the locationid already exists in featureofinterest.orginal_location id
@@ -128,11 +301,34 @@ func (a *APIv1) PostObservation(observation *entities.Observation) (*entities.Ob
 		return nil, err
 	}
 
-	datastreamID := observation.Datastream.ID
+	// the result of an observation of a MultiDatastream holds a value for every ObservedProperty
+	var topic string
+	var datastreamID interface{}
+	if observation.MultiDatastream != nil {
+		datastreamID = observation.MultiDatastream.ID
+		topic = fmt.Sprintf("MultiDatastreams(%v)/Observations", datastreamID)
+		md, err := a.db.GetMultiDatastream(datastreamID, nil)
+		if err != nil {
+			return nil, []error{gostErrors.NewBadRequestError(errors.New("MultiDatastream does not exist"))}
+		}
+
+		if err = md.CheckResult(observation.Result); err != nil {
+			return nil, []error{err}
+		}
+	} else {
+		datastreamID = observation.Datastream.ID
+		topic = fmt.Sprintf("Datastreams(%v)/Observations", datastreamID)
+	}
 
 	// there is no foi posted: try to copy it from thing.location...
 	if observation.FeatureOfInterest == nil {
-		foiID, err := CopyLocationToFoi(&a.db, datastreamID)
+		var foiID string
+		var err error
+		if observation.MultiDatastream != nil {
+			foiID, err = copyMultiDatastreamLocationToFoi(a.db, datastreamID)
+		} else {
+			foiID, err = CopyLocationToFoi(&a.db, datastreamID)
+		}
 
 		if err != nil {
 			errorMessage := "Unable to copy location of thing to featureofinterest."
@@ -160,7 +356,7 @@ func (a *APIv1) PostObservation(observation *entities.Observation) (*entities.Ob
 	s := string(json)
 
 	//ToDo: MQTT TEST
-	a.mqtt.Publish(fmt.Sprintf("Datastreams(%v)/Observations", datastreamID), s, 0)
+	a.mqtt.Publish(topic, s, 0)
 	a.mqtt.Publish("Observations", s, 0)
 
 	return no, nil
@@ -178,21 +374,249 @@ func (a *APIv1) PostObservationByDatastream(datastreamID interface{}, observatio
 	return a.PostObservation(observation)
 }
 
+// PostObservationByMultiDatastream creates an Observation with a linked MultiDatastream by given MultiDatastream id and calls PostObservation on the database
+func (a *APIv1) PostObservationByMultiDatastream(multiDatastreamID interface{}, observation *entities.Observation) (*entities.Observation, []error) {
+	d := &entities.MultiDatastream{}
+	d.ID = multiDatastreamID
+	observation.MultiDatastream = d
+	return a.PostObservation(observation)
+}
+
+// CreateObservations creates the observations of the data arrays using one insert, a result is returned for every row
+// in the order of the request: the self link of the created observation or the error which prevented its creation.
+// An error is returned when the request itself is invalid, for example when an unknown component is used
+func (a *APIv1) CreateObservations(dataArrays []*models.DataArrayRequest) ([]string, []error) {
+	for _, d := range dataArrays {
+		if d.Datastream == nil || d.Datastream.ID == nil {
+			return nil, []error{gostErrors.NewBadRequestError(errors.New("Missing Datastream with @iot.id for dataArray"))}
+		}
+
+		if err := checkDataArrayComponents(d.Components); err != nil {
+			return nil, []error{gostErrors.NewBadRequestError(err)}
+		}
+	}
+
+	results := []string{}
+	observations := []*entities.Observation{}
+	indexes := []int{}
+	datastreamIDs := []interface{}{}
+	for _, d := range dataArrays {
+		var foiID string
+		for _, row := range d.DataArray {
+			observation, err := dataArrayObservation(d.Components, row, d.Datastream.ID)
+			if err == nil && observation.FeatureOfInterest == nil {
+				if len(foiID) == 0 {
+					foiID, err = CopyLocationToFoi(&a.db, d.Datastream.ID)
+				}
+
+				observation.FeatureOfInterest = &entities.FeatureOfInterest{}
+				observation.FeatureOfInterest.ID = foiID
+			}
+
+			if err == nil {
+				if _, errs := observation.ContainsMandatoryParams(); errs != nil {
+					err = errs[0]
+				}
+			}
+
+			if err != nil {
+				results = append(results, dataArrayError(err))
+				continue
+			}
+
+			indexes = append(indexes, len(results))
+			datastreamIDs = append(datastreamIDs, d.Datastream.ID)
+			observations = append(observations, observation)
+			results = append(results, "")
+		}
+	}
+
+	if len(observations) == 0 {
+		return results, nil
+	}
+
+	created, errs := a.db.PostObservations(observations)
+	for i, o := range created {
+		if errs[i] != nil {
+			results[indexes[i]] = dataArrayError(errs[i])
+			continue
+		}
+
+		o.SetAllLinks(a.config.GetExternalServerURI())
+		results[indexes[i]] = o.GetSelfLink()
+
+		b, _ := json.Marshal(o)
+		s := string(b)
+		a.mqtt.Publish(fmt.Sprintf("Datastreams(%v)/Observations", datastreamIDs[i]), s, 0)
+		a.mqtt.Publish("Observations", s, 0)
+	}
+
+	return results, nil
+}
+
+// createObservationsComponents are the components which can be used in a CreateObservations request
+var createObservationsComponents = []string{"phenomenonTime", "result", "resultTime", "resultQuality", "validTime", "parameters", "FeatureOfInterest/id"}
+
+// checkDataArrayComponents returns an error when a component is unknown or used twice
+func checkDataArrayComponents(components []string) error {
+	if len(components) == 0 {
+		return errors.New("Missing components for dataArray")
+	}
+
+	used := map[string]bool{}
+	for _, c := range components {
+		found := false
+		for _, known := range createObservationsComponents {
+			if strings.ToLower(c) == strings.ToLower(known) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("Unknown component %s, supported components are %s", c, strings.Join(createObservationsComponents, ", "))
+		}
+
+		if used[strings.ToLower(c)] {
+			return fmt.Errorf("Component %s is used more than once", c)
+		}
+
+		used[strings.ToLower(c)] = true
+	}
+
+	return nil
+}
+
+// dataArrayObservation creates the observation described by a row of a data array
+func dataArrayObservation(components []string, row []interface{}, datastreamID interface{}) (*entities.Observation, error) {
+	if len(row) != len(components) {
+		return nil, gostErrors.NewBadRequestError(fmt.Errorf("Expected %v values but got %v", len(components), len(row)))
+	}
+
+	observation := &entities.Observation{}
+	observation.Datastream = &entities.Datastream{}
+	observation.Datastream.ID = datastreamID
+	for i, c := range components {
+		value := row[i]
+		if value == nil {
+			continue
+		}
+
+		var ok = true
+		switch strings.ToLower(c) {
+		case "phenomenontime":
+			observation.PhenomenonTime, ok = value.(string)
+		case "result":
+			observation.Result = value
+		case "resulttime":
+			observation.ResultTime, ok = value.(string)
+		case "resultquality":
+			observation.ResultQuality, ok = value.(string)
+		case "validtime":
+			observation.ValidTime, ok = value.(string)
+		case "parameters":
+			observation.Parameters, ok = value.(map[string]interface{})
+		case "featureofinterest/id":
+			observation.FeatureOfInterest = &entities.FeatureOfInterest{}
+			observation.FeatureOfInterest.ID = value
+		}
+
+		if !ok {
+			return nil, gostErrors.NewBadRequestError(fmt.Errorf("Invalid value for %s", c))
+		}
+	}
+
+	return observation, nil
+}
+
+// dataArrayError returns the result of a row which could not be created
+func dataArrayError(err error) string {
+	return fmt.Sprintf("error: %v", err)
+}
+
 // PatchObservation updates the given observation in the database
 func (a *APIv1) PatchObservation(id interface{}, observation *entities.Observation) (*entities.Observation, error) {
-	if observation.Datastream != nil || observation.FeatureOfInterest != nil {
+	if observation.Datastream != nil || observation.MultiDatastream != nil || observation.FeatureOfInterest != nil {
 		return nil, gostErrors.NewBadRequestError(errors.New("Unable to deep patch Observation"))
 	}
 
+	if err := a.checkMultiDatastreamResult(id, observation); err != nil {
+		return nil, err
+	}
+
 	return a.db.PatchObservation(id, observation)
 }
 
-// PutObservation updates the given observation in the database
+// checkMultiDatastreamResult returns an error when a new result is given for an observation of a MultiDatastream
+// which does not hold a value for every ObservedProperty of the MultiDatastream
+func (a *APIv1) checkMultiDatastreamResult(id interface{}, observation *entities.Observation) error {
+	if observation.Result == nil {
+		return nil
+	}
+
+	current, err := a.db.GetObservation(id, nil)
+	if err != nil || !current.InMultiDatastream {
+		return nil
+	}
+
+	md, err := a.db.GetMultiDatastreamByObservation(id, nil)
+	if err != nil {
+		return err
+	}
+
+	return md.CheckResult(observation.Result)
+}
+
+// PutObservation replaces the given observation in the database, the Datastream or MultiDatastream and the
+// FeatureOfInterest are kept when omitted
 func (a *APIv1) PutObservation(id interface{}, observation *entities.Observation) (*entities.Observation, []error) {
-	obs, err2 := a.db.PutObservation(id, observation)
-	if err2 != nil {
-		return nil, []error{err2}
+	et := entities.EntityTypeObservation
+	if observation.Datastream == nil && observation.MultiDatastream == nil {
+		ds := &entities.Datastream{}
+		if err := a.keepRelation(et, id, entities.EntityTypeDatastream, &ds.BaseEntity); err == nil {
+			observation.Datastream = ds
+		} else {
+			observation.MultiDatastream = &entities.MultiDatastream{}
+			if err = a.keepRelation(et, id, entities.EntityTypeMultiDatastream, &observation.MultiDatastream.BaseEntity); err != nil {
+				return nil, []error{err}
+			}
+		}
+	}
+
+	if observation.FeatureOfInterest == nil {
+		observation.FeatureOfInterest = &entities.FeatureOfInterest{}
+		if err := a.keepRelation(et, id, entities.EntityTypeFeatureOfInterest, &observation.FeatureOfInterest.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if _, errs := observation.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	if observation.MultiDatastream != nil {
+		md, err := a.db.GetMultiDatastream(observation.MultiDatastream.ID, nil)
+		if err != nil {
+			return nil, []error{err}
+		}
+
+		if err = md.CheckResult(observation.Result); err != nil {
+			return nil, []error{err}
+		}
 	}
+
+	var obs *entities.Observation
+	err := a.WithTransaction(func(tx models.API) error {
+		var err error
+		obs, err = tx.(*APIv1).db.PutObservation(id, observation)
+		return err
+	})
+
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	obs.SetAllLinks(a.config.GetExternalServerURI())
 	return obs, nil
 }
 
@@ -0,0 +1,174 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/geodan/gost/src/configuration"
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateDataArraysShouldGroupPerDatastream(t *testing.T) {
+	// arrange
+	cfg := configuration.Config{}
+	cfg.Server.ExternalURI = "http://localhost"
+	stAPI := APIv1{config: cfg}
+	qo, _ := odata.CreateQueryOptions(map[string]string{"$resultFormat": "dataArray", "$select": "result,ID,Datastream,phenomenonTime"})
+	observations := []*entities.Observation{
+		newDataArrayObservation(1, 10, 20.5),
+		newDataArrayObservation(2, 11, 1),
+		newDataArrayObservation(3, 10, 21),
+	}
+
+	// act
+	dataArrays := stAPI.createDataArrays(observations, qo)
+
+	// assert
+	assert.Equal(t, 2, len(dataArrays))
+	assert.Equal(t, []string{"result", "id", "phenomenonTime"}, dataArrays[0].Components)
+	assert.Equal(t, "http://localhost/v1.0/Datastreams(10)", dataArrays[0].NavDatastream)
+	assert.Equal(t, 2, dataArrays[0].Count)
+	assert.Equal(t, []interface{}{20.5, 1, "2016-01-01T00:00:00.000Z"}, dataArrays[0].DataArray[0])
+	assert.Equal(t, []interface{}{21, 3, "2016-01-01T00:00:00.000Z"}, dataArrays[0].DataArray[1])
+	assert.Equal(t, 1, dataArrays[1].Count)
+}
+
+func TestDataArrayComponentsWithoutSelectShouldReturnAllProperties(t *testing.T) {
+	// arrange
+	qo, _ := odata.CreateQueryOptions(map[string]string{"$resultFormat": "dataArray"})
+
+	// act
+	components := dataArrayComponents(qo)
+
+	// assert
+	assert.Equal(t, (&entities.Observation{}).GetPropertyNames(), components)
+}
+
+func newDataArrayObservation(id int, datastreamID int, result interface{}) *entities.Observation {
+	o := &entities.Observation{PhenomenonTime: "2016-01-01T00:00:00.000Z", ResultTime: "null", Result: result}
+	o.ID = id
+	o.Datastream = &entities.Datastream{}
+	o.Datastream.ID = datastreamID
+	return o
+}
+
+func TestDataArrayObservationShouldMapComponents(t *testing.T) {
+	// arrange
+	components := []string{"phenomenonTime", "result", "FeatureOfInterest/id", "parameters"}
+	row := []interface{}{"2016-01-01T00:00:00Z", 20.5, float64(3), map[string]interface{}{"sensor": "a"}}
+
+	// act
+	observation, err := dataArrayObservation(components, row, 10)
+	_, errLength := dataArrayObservation(components, row[:2], 10)
+	_, errType := dataArrayObservation([]string{"phenomenonTime"}, []interface{}{20}, 10)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, "2016-01-01T00:00:00Z", observation.PhenomenonTime)
+	assert.Equal(t, 20.5, observation.Result)
+	assert.Equal(t, float64(3), observation.FeatureOfInterest.ID)
+	assert.Equal(t, 10, observation.Datastream.ID)
+	assert.Equal(t, "a", observation.Parameters["sensor"])
+	assert.NotNil(t, errLength)
+	assert.NotNil(t, errType)
+}
+
+func TestCheckDataArrayComponentsShouldRejectUnknownAndDuplicates(t *testing.T) {
+	// act
+	valid := checkDataArrayComponents([]string{"phenomenonTime", "result", "FeatureOfInterest/id"})
+	unknown := checkDataArrayComponents([]string{"phenomenonTime", "unknown"})
+	duplicate := checkDataArrayComponents([]string{"result", "Result"})
+
+	// assert
+	assert.Nil(t, valid)
+	assert.NotNil(t, unknown)
+	assert.NotNil(t, duplicate)
+}
+
+// observationsDatabase inserts the Observations of the known Datastreams and fails the others
+type observationsDatabase struct {
+	testDatabase
+	datastreams map[interface{}]bool
+}
+
+func (d *observationsDatabase) PostObservations(observations []*entities.Observation) ([]*entities.Observation, []error) {
+	created := make([]*entities.Observation, len(observations))
+	errs := make([]error, len(observations))
+	for i, o := range observations {
+		if !d.datastreams[o.Datastream.ID] {
+			errs[i] = gostErrors.NewBadRequestError(errors.New("Datastream does not exist"))
+			continue
+		}
+
+		d.insert(o)
+		created[i] = o
+	}
+
+	return created, errs
+}
+
+func newCreateObservationsAPI() (*APIv1, *observationsDatabase, *testMQTTClient) {
+	cfg := configuration.Config{}
+	cfg.Server.ExternalURI = "http://localhost"
+	db := &observationsDatabase{datastreams: map[interface{}]bool{1: true}}
+	client := &testMQTTClient{}
+	return &APIv1{db: db, mqtt: client, config: cfg}, db, client
+}
+
+func newDataArrayRequest(datastreamID interface{}, components []string, rows ...[]interface{}) *models.DataArrayRequest {
+	d := &models.DataArrayRequest{Datastream: &entities.Datastream{}, Components: components, DataArray: rows}
+	d.Datastream.ID = datastreamID
+	return d
+}
+
+func TestCreateObservationsShouldReportErrorsPerRow(t *testing.T) {
+	// arrange
+	stAPI, db, client := newCreateObservationsAPI()
+	components := []string{"phenomenonTime", "result", "FeatureOfInterest/id"}
+	dataArrays := []*models.DataArrayRequest{
+		newDataArrayRequest(1, components,
+			[]interface{}{"2016-01-01T00:00:00Z", 20, 1},
+			[]interface{}{"2016-01-01T00:00:00Z", 21},
+			[]interface{}{20, 22, 1},
+			[]interface{}{"2016-01-01T00:01:00Z", 23, 1}),
+		newDataArrayRequest(2, components,
+			[]interface{}{"2016-01-01T00:00:00Z", 24, 1}),
+	}
+
+	// act
+	results, errs := stAPI.CreateObservations(dataArrays)
+
+	// assert
+	assert.Nil(t, errs)
+	assert.Len(t, results, 5)
+	assert.Equal(t, "http://localhost/v1.0/Observations(1)", results[0])
+	assert.Contains(t, results[1], "error: Expected 3 values but got 2", "row with a wrong length should fail")
+	assert.Contains(t, results[2], "error:", "row with a bad phenomenonTime should fail")
+	assert.Equal(t, "http://localhost/v1.0/Observations(2)", results[3])
+	assert.Equal(t, "error: Datastream does not exist", results[4])
+	assert.Len(t, db.inserted, 2)
+	assert.Len(t, client.published, 4, "only the created Observations should be published")
+}
+
+func TestCreateObservationsShouldRejectUnknownComponent(t *testing.T) {
+	// arrange
+	stAPI, db, _ := newCreateObservationsAPI()
+	dataArrays := []*models.DataArrayRequest{
+		newDataArrayRequest(1, []string{"phenomenonTime", "result", "FeatureOfInterest/id"}, []interface{}{"2016-01-01T00:00:00Z", 20, 1}),
+		newDataArrayRequest(1, []string{"result", "unknown"}, []interface{}{20, 1}),
+	}
+
+	// act
+	results, errs := stAPI.CreateObservations(dataArrays)
+
+	// assert
+	assert.Nil(t, results)
+	assert.Len(t, errs, 1)
+	assert.Equal(t, http.StatusBadRequest, errs[0].(gostErrors.APIError).GetHTTPErrorStatusCode())
+	assert.Empty(t, db.inserted, "no Observation should be created when a component is unknown")
+}
@@ -21,7 +21,9 @@ func (a *APIv1) GetObservedProperty(id interface{}, qo *odata.QueryOptions, path
 		return nil, err
 	}
 
-	a.ProcessGetRequest(op, qo)
+	if err := a.ProcessGetRequest(op, qo); err != nil {
+		return nil, err
+	}
 	return op, nil
 }
 
@@ -37,10 +39,42 @@ func (a *APIv1) GetObservedPropertyByDatastream(datastreamID interface{}, qo *od
 		return nil, err
 	}
 
-	a.ProcessGetRequest(op, qo)
+	if err := a.ProcessGetRequest(op, qo); err != nil {
+		return nil, err
+	}
 	return op, nil
 }
 
+// GetObservedPropertiesByMultiDatastream returns the ObservedProperties of a MultiDatastream
+func (a *APIv1) GetObservedPropertiesByMultiDatastream(multiDatastreamID interface{}, qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.ObservedProperty{})
+	if err != nil {
+		return nil, err
+	}
+
+	ops, count, err := a.db.GetObservedPropertiesByMultiDatastream(multiDatastreamID, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, item := range ops {
+		i := *item
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
+		ops[idx] = &i
+	}
+
+	var data interface{} = ops
+	response := models.ArrayResponse{
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(ops), path, qo),
+		Data:     &data,
+	}
+
+	return &response, nil
+}
+
 // GetObservedProperties todo
 func (a *APIv1) GetObservedProperties(qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
 	_, err := a.QueryOptionsSupported(qo, &entities.ObservedProperty{})
@@ -55,14 +89,16 @@ func (a *APIv1) GetObservedProperties(qo *odata.QueryOptions, path string) (*mod
 
 	for idx, item := range ops {
 		i := *item
-		a.ProcessGetRequest(&i, qo)
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
 		ops[idx] = &i
 	}
 
 	var data interface{} = ops
 	response := models.ArrayResponse{
-		Count:    count,
-		NextLink: a.CreateNextLink(count, path, qo),
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(ops), path, qo),
 		Data:     &data,
 	}
 
@@ -95,8 +131,12 @@ func (a *APIv1) PatchObservedProperty(id interface{}, op *entities.ObservedPrope
 	return a.db.PatchObservedProperty(id, op)
 }
 
-// PutObservedProperty patches a given ObservedProperty
+// PutObservedProperty replaces a given ObservedProperty
 func (a *APIv1) PutObservedProperty(id interface{}, op *entities.ObservedProperty) (*entities.ObservedProperty, []error) {
+	if _, errs := op.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
 	nop, err2 := a.db.PutObservedProperty(id, op)
 	if err2 != nil {
 		return nil, []error{err2}
@@ -22,7 +22,9 @@ func (a *APIv1) GetSensor(id interface{}, qo *odata.QueryOptions, path string) (
 		return nil, err
 	}
 
-	a.ProcessGetRequest(s, qo)
+	if err := a.ProcessGetRequest(s, qo); err != nil {
+		return nil, err
+	}
 	return s, nil
 }
 
@@ -38,7 +40,27 @@ func (a *APIv1) GetSensorByDatastream(id interface{}, qo *odata.QueryOptions, pa
 		return nil, err
 	}
 
-	a.ProcessGetRequest(s, qo)
+	if err := a.ProcessGetRequest(s, qo); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetSensorByMultiDatastream retrieves the sensor of a MultiDatastream
+func (a *APIv1) GetSensorByMultiDatastream(id interface{}, qo *odata.QueryOptions, path string) (*entities.Sensor, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Sensor{})
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := a.db.GetSensorByMultiDatastream(id, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(s, qo); err != nil {
+		return nil, err
+	}
 	return s, nil
 }
 
@@ -56,14 +78,16 @@ func (a *APIv1) GetSensors(qo *odata.QueryOptions, path string) (*models.ArrayRe
 
 	for idx, item := range sensors {
 		i := *item
-		a.ProcessGetRequest(&i, qo)
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
 		sensors[idx] = &i
 	}
 
 	var data interface{} = sensors
 	return &models.ArrayResponse{
-		Count:    count,
-		NextLink: a.CreateNextLink(count, path, qo),
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(sensors), path, qo),
 		Data:     &data,
 	}, nil
 }
@@ -106,9 +130,12 @@ func (a *APIv1) PatchSensor(id interface{}, sensor *entities.Sensor) (*entities.
 	return a.db.PatchSensor(id, sensor)
 }
 
-// PutSensor updates the given thing in the database
+// PutSensor replaces the given sensor in the database
 func (a *APIv1) PutSensor(id interface{}, sensor *entities.Sensor) (*entities.Sensor, []error) {
-	var err error
+	if _, errs := sensor.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
 	putsensor, err := a.db.PutSensor(id, sensor)
 	if err != nil {
 		return nil, []error{err}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// GetTask retrieves a Task by id and given query
+func (a *APIv1) GetTask(id interface{}, qo *odata.QueryOptions, path string) (*entities.Task, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Task{})
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := a.db.GetTask(id, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(t, qo); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetTasks retrieves an array of Tasks based on the given query
+func (a *APIv1) GetTasks(qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Task{})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, count, err := a.db.GetTasks(qo)
+	return processTasks(a, tasks, qo, path, count, err)
+}
+
+// GetTasksByTaskingCapability returns all Tasks created for the given TaskingCapability
+func (a *APIv1) GetTasksByTaskingCapability(taskingCapabilityID interface{}, qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Task{})
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, count, err := a.db.GetTasksByTaskingCapability(taskingCapabilityID, qo)
+	return processTasks(a, tasks, qo, path, count, err)
+}
+
+func processTasks(a *APIv1, tasks []*entities.Task, qo *odata.QueryOptions, path string, count int, err error) (*models.ArrayResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, item := range tasks {
+		i := *item
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
+		tasks[idx] = &i
+	}
+
+	var data interface{} = tasks
+	return &models.ArrayResponse{
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(tasks), path, qo),
+		Data:     &data,
+	}, nil
+}
+
+// PostTask adds a new Task to the database and publishes it on the TaskingCapabilities(id)/Tasks and
+// Tasks topics so the device controlling the Actuator can pick it up
+func (a *APIv1) PostTask(task *entities.Task) (*entities.Task, []error) {
+	if _, errs := task.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	if task.TaskingCapability.ID == nil {
+		return nil, []error{gostErrors.NewBadRequestError(errors.New("A Task has to be linked to an existing TaskingCapability"))}
+	}
+
+	tcID := task.TaskingCapability.ID
+	nt, err := a.db.PostTask(task)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	nt.SetAllLinks(a.config.GetExternalServerURI())
+
+	b, _ := json.Marshal(nt)
+	s := string(b)
+	a.mqtt.Publish(fmt.Sprintf("TaskingCapabilities(%v)/Tasks", tcID), s, 0)
+	a.mqtt.Publish("Tasks", s, 0)
+
+	return nt, nil
+}
+
+// PostTaskByTaskingCapability adds a new Task to the given TaskingCapability
+func (a *APIv1) PostTaskByTaskingCapability(taskingCapabilityID interface{}, task *entities.Task) (*entities.Task, []error) {
+	tc := &entities.TaskingCapability{}
+	tc.ID = taskingCapabilityID
+	task.TaskingCapability = tc
+	return a.PostTask(task)
+}
+
+// PatchTask updates the given Task in the database
+func (a *APIv1) PatchTask(id interface{}, task *entities.Task) (*entities.Task, error) {
+	if task.TaskingCapability != nil {
+		return nil, gostErrors.NewBadRequestError(errors.New("Deep patch Task not supported."))
+	}
+
+	return a.db.PatchTask(id, task)
+}
+
+// PutTask replaces the given Task in the database, the TaskingCapability is kept when omitted
+func (a *APIv1) PutTask(id interface{}, task *entities.Task) (*entities.Task, []error) {
+	if task.TaskingCapability == nil {
+		task.TaskingCapability = &entities.TaskingCapability{}
+		if err := a.keepRelation(entities.EntityTypeTask, id, entities.EntityTypeTaskingCapability, &task.TaskingCapability.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if _, errs := task.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	var putTask *entities.Task
+	err := a.WithTransaction(func(tx models.API) error {
+		var err error
+		putTask, err = tx.(*APIv1).db.PutTask(id, task)
+		return err
+	})
+
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	putTask.SetAllLinks(a.config.GetExternalServerURI())
+	return putTask, nil
+}
+
+// DeleteTask deletes a Task from the database
+func (a *APIv1) DeleteTask(id interface{}) error {
+	return a.db.DeleteTask(id)
+}
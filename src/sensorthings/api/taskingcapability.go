@@ -0,0 +1,188 @@
+package api
+
+import (
+	"errors"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// GetTaskingCapability retrieves a TaskingCapability by id and given query
+func (a *APIv1) GetTaskingCapability(id interface{}, qo *odata.QueryOptions, path string) (*entities.TaskingCapability, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.TaskingCapability{})
+	if err != nil {
+		return nil, err
+	}
+
+	tc, err := a.db.GetTaskingCapability(id, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(tc, qo); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// GetTaskingCapabilities retrieves an array of TaskingCapabilities based on the given query
+func (a *APIv1) GetTaskingCapabilities(qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.TaskingCapability{})
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities, count, err := a.db.GetTaskingCapabilities(qo)
+	return processTaskingCapabilities(a, capabilities, qo, path, count, err)
+}
+
+// GetTaskingCapabilitiesByThing returns all TaskingCapabilities of the given thing
+func (a *APIv1) GetTaskingCapabilitiesByThing(thingID interface{}, qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.TaskingCapability{})
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities, count, err := a.db.GetTaskingCapabilitiesByThing(thingID, qo)
+	return processTaskingCapabilities(a, capabilities, qo, path, count, err)
+}
+
+// GetTaskingCapabilitiesByActuator returns all TaskingCapabilities using the given actuator
+func (a *APIv1) GetTaskingCapabilitiesByActuator(actuatorID interface{}, qo *odata.QueryOptions, path string) (*models.ArrayResponse, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.TaskingCapability{})
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities, count, err := a.db.GetTaskingCapabilitiesByActuator(actuatorID, qo)
+	return processTaskingCapabilities(a, capabilities, qo, path, count, err)
+}
+
+// GetTaskingCapabilityByTask returns the TaskingCapability of the given task
+func (a *APIv1) GetTaskingCapabilityByTask(taskID interface{}, qo *odata.QueryOptions, path string) (*entities.TaskingCapability, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.TaskingCapability{})
+	if err != nil {
+		return nil, err
+	}
+
+	tc, err := a.db.GetTaskingCapabilityByTask(taskID, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(tc, qo); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+func processTaskingCapabilities(a *APIv1, capabilities []*entities.TaskingCapability, qo *odata.QueryOptions, path string, count int, err error) (*models.ArrayResponse, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, item := range capabilities {
+		i := *item
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
+		capabilities[idx] = &i
+	}
+
+	var data interface{} = capabilities
+	return &models.ArrayResponse{
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(capabilities), path, qo),
+		Data:     &data,
+	}, nil
+}
+
+// PostTaskingCapability adds a new TaskingCapability to the database, a deep inserted Actuator is
+// created in the same transaction as the TaskingCapability
+func (a *APIv1) PostTaskingCapability(tc *entities.TaskingCapability) (*entities.TaskingCapability, []error) {
+	if _, errs := tc.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	var ntc *entities.TaskingCapability
+	err := a.WithTransaction(func(txAPI models.API) error {
+		tx := txAPI.(*APIv1)
+		if tc.Actuator.ID == nil {
+			if _, errs := tx.PostActuator(tc.Actuator); len(errs) > 0 {
+				return errs[0]
+			}
+		}
+
+		var err error
+		ntc, err = tx.db.PostTaskingCapability(tc)
+		return err
+	})
+
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	ntc.SetAllLinks(a.config.GetExternalServerURI())
+	return ntc, nil
+}
+
+// PostTaskingCapabilityByThing adds a new TaskingCapability to the given thing
+func (a *APIv1) PostTaskingCapabilityByThing(thingID interface{}, tc *entities.TaskingCapability) (*entities.TaskingCapability, []error) {
+	t := &entities.Thing{}
+	t.ID = thingID
+	tc.Thing = t
+	return a.PostTaskingCapability(tc)
+}
+
+// PatchTaskingCapability updates the given TaskingCapability in the database
+func (a *APIv1) PatchTaskingCapability(id interface{}, tc *entities.TaskingCapability) (*entities.TaskingCapability, error) {
+	if tc.Thing != nil || tc.Actuator != nil || tc.Tasks != nil {
+		return nil, gostErrors.NewBadRequestError(errors.New("Deep patch TaskingCapability not supported."))
+	}
+
+	return a.db.PatchTaskingCapability(id, tc)
+}
+
+// PutTaskingCapability replaces the given TaskingCapability in the database, the Thing and Actuator are kept
+// when omitted
+func (a *APIv1) PutTaskingCapability(id interface{}, tc *entities.TaskingCapability) (*entities.TaskingCapability, []error) {
+	et := entities.EntityTypeTaskingCapability
+	if tc.Thing == nil {
+		tc.Thing = &entities.Thing{}
+		if err := a.keepRelation(et, id, entities.EntityTypeThing, &tc.Thing.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if tc.Actuator == nil {
+		tc.Actuator = &entities.Actuator{}
+		if err := a.keepRelation(et, id, entities.EntityTypeActuator, &tc.Actuator.BaseEntity); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	if _, errs := tc.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	var putCapability *entities.TaskingCapability
+	err := a.WithTransaction(func(tx models.API) error {
+		var err error
+		putCapability, err = tx.(*APIv1).db.PutTaskingCapability(id, tc)
+		return err
+	})
+
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	putCapability.SetAllLinks(a.config.GetExternalServerURI())
+	return putCapability, nil
+}
+
+// DeleteTaskingCapability deletes a TaskingCapability from the database
+func (a *APIv1) DeleteTaskingCapability(id interface{}) error {
+	return a.db.DeleteTaskingCapability(id)
+}
@@ -24,7 +24,9 @@ func (a *APIv1) GetThing(id interface{}, qo *odata.QueryOptions, path string) (*
 		return nil, err
 	}
 
-	a.ProcessGetRequest(t, qo)
+	if err := a.ProcessGetRequest(t, qo); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
@@ -40,7 +42,45 @@ func (a *APIv1) GetThingByDatastream(id interface{}, qo *odata.QueryOptions, pat
 		return nil, err
 	}
 
-	a.ProcessGetRequest(t, qo)
+	if err := a.ProcessGetRequest(t, qo); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetThingByMultiDatastream returns a thing entity based on the given MultiDatastream id and QueryOptions
+func (a *APIv1) GetThingByMultiDatastream(id interface{}, qo *odata.QueryOptions, path string) (*entities.Thing, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Thing{})
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := a.db.GetThingByMultiDatastream(id, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(t, qo); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// GetThingByTaskingCapability returns a thing entity based on the given TaskingCapability id and QueryOptions
+func (a *APIv1) GetThingByTaskingCapability(id interface{}, qo *odata.QueryOptions, path string) (*entities.Thing, error) {
+	_, err := a.QueryOptionsSupported(qo, &entities.Thing{})
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := a.db.GetThingByTaskingCapability(id, qo)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := a.ProcessGetRequest(t, qo); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
@@ -66,7 +106,9 @@ func (a *APIv1) GetThingByHistoricalLocation(id interface{}, qo *odata.QueryOpti
 		return nil, err
 	}
 
-	a.ProcessGetRequest(t, qo)
+	if err := a.ProcessGetRequest(t, qo); err != nil {
+		return nil, err
+	}
 	return t, nil
 }
 
@@ -87,107 +129,98 @@ func processThings(a *APIv1, things []*entities.Thing, qo *odata.QueryOptions, p
 
 	for idx, item := range things {
 		i := *item
-		a.ProcessGetRequest(&i, qo)
+		if err := a.ProcessGetRequest(&i, qo); err != nil {
+			return nil, err
+		}
 		things[idx] = &i
 	}
 
 	var data interface{} = things
 	return &models.ArrayResponse{
-		Count:    count,
-		NextLink: a.CreateNextLink(count, path, qo),
+		Count:    responseCount(count, qo),
+		NextLink: a.CreateNextLink(count, len(things), path, qo),
 		Data:     &data,
 	}, nil
 }
 
-// PostThing checks if a posted thing entity is valid and adds it to the database
-// a posted thing can also contain Locations and DataStreams
+// PostThing checks if a posted thing entity is valid and adds it to the database, deep inserted Locations and
+// Datastreams are created in the same transaction as the Thing
 func (a *APIv1) PostThing(thing *entities.Thing) (*entities.Thing, []error) {
-	var err []error
-	var err2 error
-	_, err = thing.ContainsMandatoryParams()
-	if len(err) > 0 {
-		return nil, err
-	}
-
-	nt, err2 := a.db.PostThing(thing)
-	if err2 != nil {
-		return nil, []error{err2}
-	}
-
-	var postedLocations []*entities.Location
-	var postedDatastreams []*entities.Datastream
-
-	// Handle deep insert locations
-	if thing.Locations != nil {
-		for _, l := range thing.Locations {
-			// New location posted
-			if l.ID == nil { //Id is null so a new location is posted
-				var nl *entities.Location
-				if nl, err = a.PostLocationByThing(nt.ID, l); len(err) > 0 {
-					a.reverseInserts(nt, postedLocations, postedDatastreams)
-					err = append(err, gostErrors.NewConflictRequestError(errors.New("Location deep insert went wrong")))
-					return nil, err
-				}
-				postedLocations = append(postedLocations, nl)
-			} else { // posted id: link
-				if err2 = a.LinkLocation(nt.ID, l.ID); len(err) > 0 {
-					a.reverseInserts(nt, postedLocations, postedDatastreams)
-					err = append(err, gostErrors.NewConflictRequestError(errors.New("Location linking went wrong")))
-					err = append(err, err2)
-					return nil, err
-				}
-
-				hl := &entities.HistoricalLocation{
-					Thing:     nt,
-					Locations: []*entities.Location{l},
-				}
-
-				hl.ContainsMandatoryParams()
-
-				if hl, err = a.PostHistoricalLocation(hl); len(err) > 0 {
-					a.reverseInserts(nt, postedLocations, postedDatastreams)
-					err = append(err, gostErrors.NewConflictRequestError(errors.New("Creating Historical Location went wrong")))
-					return nil, err
-				}
-			}
-		}
+	if _, errs := thing.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
 	}
 
-	// Handle deep insert datastreams
-	if thing.Datastreams != nil {
-		for _, d := range thing.Datastreams {
-			// New location posted
-			if d.ID == nil { //Id is null so a new datastream is posted
-				var nd *entities.Datastream
-				if nd, err = a.PostDatastreamByThing(nt.ID, d); err != nil {
-					a.reverseInserts(nt, postedLocations, postedDatastreams)
-					err = append(err, gostErrors.NewConflictRequestError(errors.New("Creating Datastrean went wrong")))
-					return nil, err
-				}
-				postedDatastreams = append(postedDatastreams, nd)
-			} else {
-				a.reverseInserts(nt, postedLocations, postedDatastreams)
-				err = append(err, gostErrors.NewConflictRequestError(errors.New("ID found for deep inserted datastream, linking to an existing Datastream is not allowed")))
-				return nil, err
-			}
+	var nt *entities.Thing
+	var errs []error
+	err := a.WithTransaction(func(txAPI models.API) error {
+		tx := txAPI.(*APIv1)
+		nt, errs = tx.postThing(thing)
+		if len(errs) > 0 {
+			return errs[0]
 		}
+
+		return nil
+	})
+
+	if len(errs) > 0 {
+		return nil, errs
+	}
+
+	if err != nil {
+		return nil, []error{err}
 	}
 
 	nt.SetAllLinks(a.config.GetExternalServerURI())
-	//push to mqtt
 	return nt, nil
 }
 
-func (a *APIv1) reverseInserts(thing *entities.Thing, locations []*entities.Location, datastreams []*entities.Datastream) {
-	for _, datastream := range datastreams {
-		a.DeleteDatastream(datastream.ID)
+func (a *APIv1) postThing(thing *entities.Thing) (*entities.Thing, []error) {
+	nt, err := a.db.PostThing(thing)
+	if err != nil {
+		return nil, []error{err}
 	}
 
-	for _, location := range locations {
-		a.DeleteLocation(location.ID)
+	// Handle deep insert locations, a posted location without id is created and the new locations of the
+	// thing are recorded in one HistoricalLocation
+	for i, l := range thing.Locations {
+		if l.ID == nil {
+			nl, errs := a.PostLocation(l)
+			if len(errs) > 0 {
+				return nil, errs
+			}
+
+			thing.Locations[i] = nl
+		}
+
+		if err = a.LinkLocation(nt.ID, thing.Locations[i].ID); err != nil {
+			return nil, []error{err}
+		}
 	}
 
-	a.DeleteThing(thing.ID)
+	if len(thing.Locations) > 0 {
+		if err = a.postHistoricalLocation(nt.ID, thing.Locations); err != nil {
+			return nil, []error{err}
+		}
+	}
+
+	// Handle deep insert datastreams
+	for _, d := range thing.Datastreams {
+		if d.ID != nil {
+			return nil, []error{gostErrors.NewConflictRequestError(errors.New("ID found for deep inserted datastream, linking to an existing Datastream is not allowed"))}
+		}
+
+		d.Thing = &entities.Thing{}
+		d.Thing.ID = nt.ID
+		if _, errs := d.ContainsMandatoryParams(); len(errs) > 0 {
+			return nil, errs
+		}
+
+		if _, errs := a.postDatastream(d); len(errs) > 0 {
+			return nil, errs
+		}
+	}
+
+	return nt, nil
 }
 
 // DeleteThing deletes a given Thing from the database
@@ -201,13 +234,20 @@ func (a *APIv1) PatchThing(id interface{}, thing *entities.Thing) (*entities.Thi
 		return nil, gostErrors.NewBadRequestError(errors.New("Unable to deep patch Thing"))
 	}
 
-	return a.db.PatchThing(id, thing)
+	return a.updateThing(id, thing, false)
 }
 
-// PutThing updates the given thing in the database
+// PutThing replaces the given thing in the database
 func (a *APIv1) PutThing(id interface{}, thing *entities.Thing) (*entities.Thing, []error) {
-	var err error
-	putthing, err := a.db.PutThing(id, thing)
+	if _, errs := thing.ContainsMandatoryParams(); len(errs) > 0 {
+		return nil, errs
+	}
+
+	if thing.Datastreams != nil || thing.HistoricalLocations != nil || isDeepPatchLocations(thing.Locations) {
+		return nil, []error{gostErrors.NewBadRequestError(errors.New("Unable to deep insert in a PUT on a Thing"))}
+	}
+
+	putthing, err := a.updateThing(id, thing, true)
 	if err != nil {
 		return nil, []error{err}
 	}
@@ -216,6 +256,39 @@ func (a *APIv1) PutThing(id interface{}, thing *entities.Thing) (*entities.Thing
 	return putthing, nil
 }
 
+// updateThing patches or replaces the thing in the database, when the locations of the thing are changed the
+// new locations are recorded in a HistoricalLocation
+func (a *APIv1) updateThing(id interface{}, thing *entities.Thing, put bool) (*entities.Thing, error) {
+	update := func(db models.Database) (*entities.Thing, error) {
+		if put {
+			return db.PutThing(id, thing)
+		}
+
+		return db.PatchThing(id, thing)
+	}
+
+	if !put && len(thing.Locations) == 0 {
+		return update(a.db)
+	}
+
+	var t *entities.Thing
+	err := a.WithTransaction(func(tx models.API) error {
+		var err error
+		txAPI := tx.(*APIv1)
+		if t, err = update(txAPI.db); err != nil {
+			return err
+		}
+
+		if len(thing.Locations) == 0 {
+			return nil
+		}
+
+		return txAPI.postHistoricalLocation(id, thing.Locations)
+	})
+
+	return t, err
+}
+
 func isDeepPatchLocations(locations []*entities.Location) bool {
 	if locations != nil {
 		for _, l := range locations {
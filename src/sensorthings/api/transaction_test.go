@@ -0,0 +1,241 @@
+package api
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/geodan/gost/src/database/postgis"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// testDatabase keeps the inserted entities in memory, the entities inserted in a transaction are only kept when
+// the transaction is committed. An insert of the entity type named by failOn fails
+type testDatabase struct {
+	models.Database
+	inTransaction bool
+	inserted      []entities.Entity
+	nextID        int
+	failOn        string
+}
+
+func (d *testDatabase) WithTransaction(fn func(db models.Database) error) error {
+	if d.inTransaction {
+		return fn(d)
+	}
+
+	tx := &testDatabase{inTransaction: true, inserted: append([]entities.Entity{}, d.inserted...), nextID: d.nextID, failOn: d.failOn}
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	d.inserted, d.nextID = tx.inserted, tx.nextID
+	return nil
+}
+
+func (d *testDatabase) insert(e entities.Entity) error {
+	if e.GetEntityType().ToString() == d.failOn {
+		return fmt.Errorf("Unable to insert %s", d.failOn)
+	}
+
+	d.nextID++
+	e.SetID(d.nextID)
+	d.inserted = append(d.inserted, e)
+	return nil
+}
+
+func (d *testDatabase) PostThing(t *entities.Thing) (*entities.Thing, error) {
+	return t, d.insert(t)
+}
+
+func (d *testDatabase) PostLocation(l *entities.Location) (*entities.Location, error) {
+	return l, d.insert(l)
+}
+
+func (d *testDatabase) LinkLocation(id interface{}, locationID interface{}) error {
+	return nil
+}
+
+func (d *testDatabase) PostHistoricalLocation(hl *entities.HistoricalLocation) (*entities.HistoricalLocation, error) {
+	return hl, d.insert(hl)
+}
+
+func (d *testDatabase) PostSensor(s *entities.Sensor) (*entities.Sensor, error) {
+	return s, d.insert(s)
+}
+
+func (d *testDatabase) PostObservedProperty(op *entities.ObservedProperty) (*entities.ObservedProperty, error) {
+	return op, d.insert(op)
+}
+
+func (d *testDatabase) PostDatastream(ds *entities.Datastream) (*entities.Datastream, error) {
+	return ds, d.insert(ds)
+}
+
+func (d *testDatabase) PostObservation(o *entities.Observation) (*entities.Observation, error) {
+	return o, d.insert(o)
+}
+
+// testMQTTClient records the published messages by topic
+type testMQTTClient struct {
+	published []string
+}
+
+func (c *testMQTTClient) Start(api *models.API) {}
+func (c *testMQTTClient) Stop()                 {}
+func (c *testMQTTClient) Publish(topic string, message string, qos byte) {
+	c.published = append(c.published, topic)
+}
+
+func TestWithTransactionShouldPublishAfterCommit(t *testing.T) {
+	// arrange
+	client := &testMQTTClient{}
+	stAPI := &APIv1{db: &testDatabase{}, mqtt: client}
+	var publishedBeforeCommit int
+
+	// act
+	err := stAPI.WithTransaction(func(tx models.API) error {
+		return tx.WithTransaction(func(nested models.API) error {
+			nested.(*APIv1).mqtt.Publish("Observations", "{}", 0)
+			tx.(*APIv1).mqtt.Publish("Tasks", "{}", 0)
+			publishedBeforeCommit = len(client.published)
+			return nil
+		})
+	})
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, 0, publishedBeforeCommit)
+	assert.Equal(t, []string{"Observations", "Tasks"}, client.published)
+}
+
+func TestWithTransactionShouldDropMessagesOnRollback(t *testing.T) {
+	// arrange
+	client := &testMQTTClient{}
+	stAPI := &APIv1{db: &testDatabase{}, mqtt: client}
+
+	// act
+	err := stAPI.WithTransaction(func(tx models.API) error {
+		tx.(*APIv1).mqtt.Publish("Tasks", "{}", 0)
+		return errors.New("rollback")
+	})
+
+	// assert
+	assert.NotNil(t, err)
+	assert.Empty(t, client.published)
+}
+
+// deepInsertThing creates a Thing with a new Location and a new Datastream with a new Sensor, ObservedProperty
+// and Observation
+func deepInsertThing() *entities.Thing {
+	observation := &entities.Observation{Result: 0, FeatureOfInterest: &entities.FeatureOfInterest{}}
+	observation.FeatureOfInterest.ID = 1
+
+	return &entities.Thing{
+		Name:        "thing",
+		Description: "thing",
+		Locations: []*entities.Location{{
+			Name:         "location",
+			Description:  "location",
+			EncodingType: entities.EncodingGeoJSON.Value,
+			Location:     map[string]interface{}{"type": "Point", "coordinates": []interface{}{5.0, 52.0}},
+		}},
+		Datastreams: []*entities.Datastream{{
+			Name:              "datastream",
+			Description:       "datastream",
+			UnitOfMeasurement: map[string]interface{}{"name": "degree"},
+			ObservationType:   entities.OMMeasurement.Value,
+			Sensor:            &entities.Sensor{Name: "sensor", Description: "sensor", EncodingType: entities.EncodingPDF.Value, Metadata: "http://example.org"},
+			ObservedProperty:  &entities.ObservedProperty{Name: "op", Definition: "http://example.org", Description: "op"},
+			Observations:      []*entities.Observation{observation},
+		}},
+	}
+}
+
+func TestPostThingShouldInsertDeepInsertedEntities(t *testing.T) {
+	// arrange
+	db := &testDatabase{}
+	client := &testMQTTClient{}
+	stAPI := &APIv1{db: db, mqtt: client}
+
+	// act
+	_, errs := stAPI.PostThing(deepInsertThing())
+
+	// assert
+	assert.Empty(t, errs)
+	assert.Len(t, db.inserted, 7, "Thing, Location, HistoricalLocation, ObservedProperty, Sensor, Datastream and Observation should be inserted")
+	assert.Len(t, client.published, 2)
+}
+
+func TestPostThingShouldRollbackFailingDeepInsert(t *testing.T) {
+	// arrange
+	db := &testDatabase{failOn: entities.EntityTypeSensor.ToString()}
+	client := &testMQTTClient{}
+	stAPI := &APIv1{db: db, mqtt: client}
+	thing := deepInsertThing()
+	failing := deepInsertThing().Datastreams[0]
+	thing.Datastreams[0].Sensor = &entities.Sensor{}
+	thing.Datastreams[0].Sensor.ID = 1
+	thing.Datastreams = append(thing.Datastreams, failing)
+
+	// act
+	_, errs := stAPI.PostThing(thing)
+
+	// assert
+	assert.NotEmpty(t, errs)
+	assert.Empty(t, db.inserted)
+	assert.Empty(t, client.published, "Observation of the first Datastream should not be published")
+}
+
+// txDriver is a sql driver of which the connections only support transactions, the outcome of each
+// transaction is recorded
+type txDriver struct {
+	outcomes []string
+}
+
+type txConn struct{ driver *txDriver }
+type txTx struct{ driver *txDriver }
+
+func (d *txDriver) Open(name string) (driver.Conn, error) { return &txConn{driver: d}, nil }
+func (c *txConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("Queries are not supported")
+}
+func (c *txConn) Close() error              { return nil }
+func (c *txConn) Begin() (driver.Tx, error) { return &txTx{driver: c.driver}, nil }
+func (t *txTx) Commit() error {
+	t.driver.outcomes = append(t.driver.outcomes, "commit")
+	return nil
+}
+func (t *txTx) Rollback() error {
+	t.driver.outcomes = append(t.driver.outcomes, "rollback")
+	return nil
+}
+
+var transactionDriver = &txDriver{}
+
+func init() {
+	sql.Register("gosttransactiontest", transactionDriver)
+}
+
+func TestWithTransactionShouldRollbackOnPanic(t *testing.T) {
+	// arrange
+	conn, _ := sql.Open("gosttransactiontest", "")
+	defer conn.Close()
+	transactionDriver.outcomes = nil
+	stAPI := &APIv1{db: &postgis.GostDatabase{Db: conn}}
+
+	// act
+	panicking := func() {
+		stAPI.WithTransaction(func(tx models.API) error {
+			panic("insert failed")
+		})
+	}
+
+	// assert
+	assert.PanicsWithValue(t, "insert failed", panicking)
+	assert.Equal(t, []string{"rollback"}, transactionDriver.outcomes)
+}
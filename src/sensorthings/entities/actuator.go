@@ -0,0 +1,86 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+)
+
+// Actuator in SensorThings represents the device capable of changing a physical property, for instance
+// a valve or relay, it can be tasked through the TaskingCapabilities which use the Actuator
+type Actuator struct {
+	BaseEntity
+	Name                        string               `json:"name,omitempty"`
+	Description                 string               `json:"description,omitempty"`
+	EncodingType                string               `json:"encodingType,omitempty"`
+	Metadata                    string               `json:"metadata,omitempty"`
+	NavTaskingCapabilities      string               `json:"TaskingCapabilities@iot.navigationLink,omitempty"`
+	CountTaskingCapabilities    *int                 `json:"TaskingCapabilities@iot.count,omitempty"`
+	NextLinkTaskingCapabilities string               `json:"TaskingCapabilities@iot.nextLink,omitempty"`
+	TaskingCapabilities         []*TaskingCapability `json:"TaskingCapabilities,omitempty"`
+}
+
+// GetEntityType returns the EntityType for Actuator
+func (a Actuator) GetEntityType() EntityType {
+	return EntityTypeActuator
+}
+
+// GetPropertyNames returns the available properties for an Actuator
+func (a *Actuator) GetPropertyNames() []string {
+	return []string{"id", "name", "description", "encodingType", "metadata"}
+}
+
+// ParseEntity tries to parse the given json byte array into the current entity
+func (a *Actuator) ParseEntity(data []byte) error {
+	actuator := &a
+	err := json.Unmarshal(data, actuator)
+	if err != nil {
+		return gostErrors.NewBadRequestError(errors.New("Unable to parse Actuator"))
+	}
+
+	return nil
+}
+
+// ContainsMandatoryParams checks if all mandatory params for Actuator are available before posting.
+func (a *Actuator) ContainsMandatoryParams() (bool, []error) {
+	err := []error{}
+	CheckMandatoryParam(&err, a.Name, a.GetEntityType(), "name")
+	CheckMandatoryParam(&err, a.Description, a.GetEntityType(), "description")
+	CheckMandatoryParam(&err, a.EncodingType, a.GetEntityType(), "encodingType")
+	CheckMandatoryParam(&err, a.Metadata, a.GetEntityType(), "metadata")
+
+	if len(err) != 0 {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SetAllLinks sets the self link and relational links
+func (a *Actuator) SetAllLinks(externalURL string) {
+	a.SetSelfLink(externalURL)
+	a.SetLinks(externalURL)
+}
+
+// SetSelfLink sets the self link for the entity
+func (a *Actuator) SetSelfLink(externalURL string) {
+	a.NavSelf = CreateEntitySelfLink(externalURL, EntityLinkActuators.ToString(), a.ID)
+}
+
+// SetLinks sets the entity specific navigation links, empty string if linked(expanded) data is not nil
+func (a *Actuator) SetLinks(externalURL string) {
+	a.NavTaskingCapabilities = CreateEntityLink(a.TaskingCapabilities == nil, externalURL, EntityLinkActuators.ToString(), EntityLinkTaskingCapabilities.ToString(), a.ID)
+}
+
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (a *Actuator) SetSelectedLinks(externalURL string, selected []string) {
+	a.SetAllLinks(externalURL)
+	a.NavSelf = SelectedLink(a.NavSelf, selected, SelfLinkName)
+	a.NavTaskingCapabilities = SelectedLink(a.NavTaskingCapabilities, selected, EntityLinkTaskingCapabilities.ToString())
+}
+
+// GetSupportedEncoding returns the supported encoding tye for this entity
+func (a Actuator) GetSupportedEncoding() map[int]EncodingType {
+	return map[int]EncodingType{EncodingSensorML.Code: EncodingSensorML, EncodingPDF.Code: EncodingPDF, EncodingTextHTML.Code: EncodingTextHTML, EncodingTypeDescription.Code: EncodingTypeDescription}
+}
@@ -0,0 +1,56 @@
+package entities
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var jsonActuator = `{
+    "name": "Valve",
+    "description": "Motorized ball valve on the irrigation line",
+    "encodingType": "application/pdf",
+    "metadata": "https://example.org/datasheets/valve.pdf"
+}`
+
+func TestMissingMandatoryParametersActuator(t *testing.T) {
+	//arrange
+	actuator := &Actuator{}
+
+	//act
+	_, err := actuator.ContainsMandatoryParams()
+
+	//assert
+	assert.Len(t, err, 4, "Actuator without params should have returned 4 errors")
+	if len(err) > 0 {
+		assert.Contains(t, fmt.Sprintf("%v", err[0]), "name")
+	}
+}
+
+func TestParseEntityResultOkActuator(t *testing.T) {
+	//arrange
+	actuator := &Actuator{}
+
+	//act
+	err := actuator.ParseEntity([]byte(jsonActuator))
+	_, mandatoryErr := actuator.ContainsMandatoryParams()
+
+	//assert
+	assert.Nil(t, err, "Unable to parse json into actuator")
+	assert.Nil(t, mandatoryErr, "All mandatory params are filled in should not have returned an error")
+	assert.Equal(t, "Valve", actuator.Name)
+}
+
+func TestSetLinksActuator(t *testing.T) {
+	//arrange
+	actuator := &Actuator{}
+	actuator.ID = id
+
+	//act
+	actuator.SetAllLinks(externalURL)
+
+	//assert
+	assert.Equal(t, actuator.NavSelf, fmt.Sprintf("%s/v1.0/%s(%s)", externalURL, EntityLinkActuators.ToString(), id), "Actuator navself incorrect")
+	assert.Equal(t, actuator.NavTaskingCapabilities, fmt.Sprintf("%s/v1.0/%s(%s)/%s", externalURL, EntityLinkActuators.ToString(), id, EntityLinkTaskingCapabilities.ToString()), "TaskingCapabilities navigationLink incorrect")
+}
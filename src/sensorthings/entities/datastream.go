@@ -12,21 +12,23 @@ import (
 // sense one ObservedProperty.
 type Datastream struct {
 	BaseEntity
-	Name                string                 `json:"name,omitempty"`
-	Description         string                 `json:"description,omitempty"`
-	UnitOfMeasurement   map[string]interface{} `json:"unitOfMeasurement,omitempty"`
-	ObservationType     string                 `json:"observationType,omitempty"`
-	ObservedArea        map[string]interface{} `json:"observedArea,omitempty"`
-	NavThing            string                 `json:"Thing@iot.navigationLink,omitempty"`
-	NavSensor           string                 `json:"Sensor@iot.navigationLink,omitempty"`
-	NavObservations     string                 `json:"Observations@iot.navigationLink,omitempty"`
-	NavObservedProperty string                 `json:"ObservedProperty@iot.navigationLink,omitempty"`
-	Thing               *Thing                 `json:"Thing,omitempty"`
-	Sensor              *Sensor                `json:"Sensor,omitempty"`
-	Observations        []*Observation         `json:"Observations,omitempty"`
-	ObservedProperty    *ObservedProperty      `json:"ObservedProperty,omitempty"`
-	PhenomenonTime      string                 `json:"phenomenonTime,omitempty"`
-	ResultTime          string                 `json:"resultTime,omitempty"`
+	Name                 string                 `json:"name,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	UnitOfMeasurement    map[string]interface{} `json:"unitOfMeasurement,omitempty"`
+	ObservationType      string                 `json:"observationType,omitempty"`
+	ObservedArea         map[string]interface{} `json:"observedArea,omitempty"`
+	NavThing             string                 `json:"Thing@iot.navigationLink,omitempty"`
+	NavSensor            string                 `json:"Sensor@iot.navigationLink,omitempty"`
+	NavObservations      string                 `json:"Observations@iot.navigationLink,omitempty"`
+	NavObservedProperty  string                 `json:"ObservedProperty@iot.navigationLink,omitempty"`
+	Thing                *Thing                 `json:"Thing,omitempty"`
+	Sensor               *Sensor                `json:"Sensor,omitempty"`
+	CountObservations    *int                   `json:"Observations@iot.count,omitempty"`
+	NextLinkObservations string                 `json:"Observations@iot.nextLink,omitempty"`
+	Observations         []*Observation         `json:"Observations,omitempty"`
+	ObservedProperty     *ObservedProperty      `json:"ObservedProperty,omitempty"`
+	PhenomenonTime       string                 `json:"phenomenonTime,omitempty"`
+	ResultTime           string                 `json:"resultTime,omitempty"`
 }
 
 // "phenomenonTime"
@@ -89,6 +91,16 @@ func (d *Datastream) SetLinks(externalURL string) {
 	d.NavObservedProperty = CreateEntityLink(d.ObservedProperty == nil, externalURL, EntityLinkDatastreams.ToString(), EntityTypeObservedProperty.ToString(), d.ID)
 }
 
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (d *Datastream) SetSelectedLinks(externalURL string, selected []string) {
+	d.SetAllLinks(externalURL)
+	d.NavSelf = SelectedLink(d.NavSelf, selected, SelfLinkName)
+	d.NavThing = SelectedLink(d.NavThing, selected, EntityTypeThing.ToString())
+	d.NavSensor = SelectedLink(d.NavSensor, selected, EntityTypeSensor.ToString())
+	d.NavObservations = SelectedLink(d.NavObservations, selected, EntityLinkObservations.ToString())
+	d.NavObservedProperty = SelectedLink(d.NavObservedProperty, selected, EntityTypeObservedProperty.ToString())
+}
+
 // GetSupportedEncoding returns the supported encoding tye for this entity
 func (d Datastream) GetSupportedEncoding() map[int]EncodingType {
 	return map[int]EncodingType{}
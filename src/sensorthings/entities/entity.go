@@ -12,25 +12,31 @@ type EntityType string
 
 // List of all EntityTypes.
 const (
-	EntityTypeThing                        EntityType = "Thing"
-	EntityTypeLocation                     EntityType = "Location"
-	EntityTypeHistoricalLocation           EntityType = "HistoricalLocation"
-	EntityTypeDatastream                   EntityType = "Datastream"
-	EntityTypeSensor                       EntityType = "Sensor"
-	EntityTypeObservedProperty             EntityType = "ObservedProperty"
-	EntityTypeObservation                  EntityType = "Observation"
-	EntityTypeFeatureOfInterest            EntityType = "FeatureOfInterest"
-	EntityTypeThingToLocation              EntityType = "ThingToLocation"
-	EntityTypeLocationToHistoricalLocation EntityType = "LocationToHistoricalLocation"
-	EntityTypeUnknown                      EntityType = "Unknown"
+	EntityTypeThing                             EntityType = "Thing"
+	EntityTypeLocation                          EntityType = "Location"
+	EntityTypeHistoricalLocation                EntityType = "HistoricalLocation"
+	EntityTypeDatastream                        EntityType = "Datastream"
+	EntityTypeMultiDatastream                   EntityType = "MultiDatastream"
+	EntityTypeSensor                            EntityType = "Sensor"
+	EntityTypeObservedProperty                  EntityType = "ObservedProperty"
+	EntityTypeObservation                       EntityType = "Observation"
+	EntityTypeFeatureOfInterest                 EntityType = "FeatureOfInterest"
+	EntityTypeActuator                          EntityType = "Actuator"
+	EntityTypeTaskingCapability                 EntityType = "TaskingCapability"
+	EntityTypeTask                              EntityType = "Task"
+	EntityTypeThingToLocation                   EntityType = "ThingToLocation"
+	EntityTypeLocationToHistoricalLocation      EntityType = "LocationToHistoricalLocation"
+	EntityTypeMultiDatastreamToObservedProperty EntityType = "MultiDatastreamToObservedProperty"
+	EntityTypeUnknown                           EntityType = "Unknown"
 )
 
 // EntityTypeList is a list for all known entity types
 var EntityTypeList = []EntityType{EntityTypeThing,
 	EntityTypeLocation, EntityTypeHistoricalLocation,
-	EntityTypeDatastream, EntityTypeSensor,
+	EntityTypeDatastream, EntityTypeMultiDatastream, EntityTypeSensor,
 	EntityTypeObservedProperty, EntityTypeObservation,
-	EntityTypeFeatureOfInterest, EntityTypeUnknown,
+	EntityTypeFeatureOfInterest, EntityTypeActuator,
+	EntityTypeTaskingCapability, EntityTypeTask, EntityTypeUnknown,
 }
 
 // StringEntityMap is a map of strings that map a string to an EntityType
@@ -39,10 +45,14 @@ var StringEntityMap = map[string]EntityType{
 	"location": EntityTypeLocation, "locations": EntityTypeLocation,
 	"historicallocation": EntityTypeHistoricalLocation, "historicallocations": EntityTypeHistoricalLocation,
 	"datastream": EntityTypeDatastream, "datastreams": EntityTypeDatastream,
+	"multidatastream": EntityTypeMultiDatastream, "multidatastreams": EntityTypeMultiDatastream,
 	"sensor": EntityTypeSensor, "sensors": EntityTypeSensor,
 	"observedproperty": EntityTypeObservedProperty, "observedproperties": EntityTypeObservedProperty,
 	"observation": EntityTypeObservation, "observations": EntityTypeObservation,
 	"featureofinterest": EntityTypeFeatureOfInterest, "featuresofinterest": EntityTypeFeatureOfInterest,
+	"actuator": EntityTypeActuator, "actuators": EntityTypeActuator,
+	"taskingcapability": EntityTypeTaskingCapability, "taskingcapabilities": EntityTypeTaskingCapability,
+	"task": EntityTypeTask, "tasks": EntityTypeTask,
 }
 
 // ToString return the string representation of the EntityType.
@@ -62,6 +72,8 @@ func EntityFromType(e EntityType) Entity {
 		return &HistoricalLocation{}
 	case EntityTypeDatastream:
 		return &Datastream{}
+	case EntityTypeMultiDatastream:
+		return &MultiDatastream{}
 	case EntityTypeSensor:
 		return &Sensor{}
 	case EntityTypeObservedProperty:
@@ -70,6 +82,12 @@ func EntityFromType(e EntityType) Entity {
 		return &Observation{}
 	case EntityTypeFeatureOfInterest:
 		return &FeatureOfInterest{}
+	case EntityTypeActuator:
+		return &Actuator{}
+	case EntityTypeTaskingCapability:
+		return &TaskingCapability{}
+	case EntityTypeTask:
+		return &Task{}
 	}
 
 	return nil
@@ -105,12 +123,19 @@ const (
 	EntityLinkLocations           EntityLink = "Locations"
 	EntityLinkHistoricalLocations EntityLink = "HistoricalLocations"
 	EntityLinkDatastreams         EntityLink = "Datastreams"
+	EntityLinkMultiDatastreams    EntityLink = "MultiDatastreams"
 	EntityLinkSensors             EntityLink = "Sensors"
 	EntityLinkObservedProperties  EntityLink = "ObservedProperties"
 	EntityLinkObservations        EntityLink = "Observations"
 	EntityLinkFeatureOfInterests  EntityLink = "FeatureOfInterest"
+	EntityLinkActuators           EntityLink = "Actuators"
+	EntityLinkTaskingCapabilities EntityLink = "TaskingCapabilities"
+	EntityLinkTasks               EntityLink = "Tasks"
 )
 
+// SelfLinkName is the name of the self link of an entity which can be used to select it
+const SelfLinkName = "@iot.selfLink"
+
 // BaseEntity is the entry point for an entity
 type BaseEntity struct {
 	ID      interface{} `json:"@iot.id,omitempty"`
@@ -175,6 +200,7 @@ type Entity interface {
 	SetAllLinks(externalURL string)
 	SetSelfLink(externalURL string)
 	SetLinks(externalURL string)
+	SetSelectedLinks(externalURL string, selected []string)
 	GetSelfLink() string
 	GetEntityType() EntityType
 	GetPropertyNames() []string
@@ -198,6 +224,32 @@ func CheckMandatoryParam(errorList *[]error, param interface{}, entityType Entit
 				isNil = true
 			}
 			break
+		case []string:
+			if len(t) == 0 {
+				isNil = true
+			}
+			break
+		case []map[string]interface{}:
+			if len(t) == 0 {
+				isNil = true
+			}
+			break
+		case []*ObservedProperty:
+			if len(t) == 0 {
+				isNil = true
+			}
+
+			for _, op := range t {
+				var contains bool
+				if op != nil {
+					contains, _ = op.ContainsMandatoryParams()
+				}
+
+				if op == nil || (op.ID == nil && !contains) {
+					isNil = true
+				}
+			}
+			break
 		case *Thing:
 			var contains bool
 			if t != nil {
@@ -229,6 +281,36 @@ func CheckMandatoryParam(errorList *[]error, param interface{}, entityType Entit
 				isNil = true
 			}
 			break
+		case *MultiDatastream:
+			var contains bool
+			if t != nil {
+				contains, _ = t.ContainsMandatoryParams()
+			}
+
+			if t == nil || (t.ID == nil && !contains) {
+				isNil = true
+			}
+			break
+		case *Actuator:
+			var contains bool
+			if t != nil {
+				contains, _ = t.ContainsMandatoryParams()
+			}
+
+			if t == nil || (t.ID == nil && !contains) {
+				isNil = true
+			}
+			break
+		case *TaskingCapability:
+			var contains bool
+			if t != nil {
+				contains, _ = t.ContainsMandatoryParams()
+			}
+
+			if t == nil || (t.ID == nil && !contains) {
+				isNil = true
+			}
+			break
 		case *Datastream:
 			var contains bool
 			if t != nil {
@@ -270,6 +352,18 @@ func CreateEntitySelfLink(externalURI string, entityLink string, id interface{})
 	return fmt.Sprintf("%s/v1.0/%s", externalURI, entityLink)
 }
 
+// SelectedLink returns the given link when name is found in the selected properties, an empty string
+// is returned when the link is not selected. Names are compared case-insensitive
+func SelectedLink(link string, selected []string, name string) string {
+	for _, s := range selected {
+		if strings.ToLower(s) == strings.ToLower(name) {
+			return link
+		}
+	}
+
+	return ""
+}
+
 // CreateEntityLink formats the given parameters into a relative navigationlink path
 // for example: http://example.org/OGCSensorThings/v1.0/Things(27815)/Datastreams
 func CreateEntityLink(isNil bool, externalURI string, entityType1 string, entityType2 string, id interface{}) string {
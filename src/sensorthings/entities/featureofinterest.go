@@ -11,13 +11,15 @@ import (
 // can be the Location of the Sensor and therefore of the Observation. A FeatureOfInterest is linked to a single Observation
 type FeatureOfInterest struct {
 	BaseEntity
-	Name               string                 `json:"name,omitempty"`
-	Description        string                 `json:"description,omitempty"`
-	EncodingType       string                 `json:"encodingType,omitempty"`
-	Feature            map[string]interface{} `json:"feature,omitempty"`
-	NavObservations    string                 `json:"Observations@iot.navigationLink,omitempty"`
-	Observations       []*Observation         `json:"Observations,omitempty"`
-	OriginalLocationID interface{}            `json:"-"`
+	Name                 string                 `json:"name,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	EncodingType         string                 `json:"encodingType,omitempty"`
+	Feature              map[string]interface{} `json:"feature,omitempty"`
+	NavObservations      string                 `json:"Observations@iot.navigationLink,omitempty"`
+	CountObservations    *int                   `json:"Observations@iot.count,omitempty"`
+	NextLinkObservations string                 `json:"Observations@iot.nextLink,omitempty"`
+	Observations         []*Observation         `json:"Observations,omitempty"`
+	OriginalLocationID   interface{}            `json:"-"`
 }
 
 // GetEntityType returns the EntityType for FeatureOfInterest
@@ -72,6 +74,13 @@ func (f *FeatureOfInterest) SetLinks(externalURL string) {
 	f.NavObservations = CreateEntityLink(f.Observations == nil, externalURL, EntityLinkFeatureOfInterests.ToString(), EntityLinkObservations.ToString(), f.ID)
 }
 
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (f *FeatureOfInterest) SetSelectedLinks(externalURL string, selected []string) {
+	f.SetAllLinks(externalURL)
+	f.NavSelf = SelectedLink(f.NavSelf, selected, SelfLinkName)
+	f.NavObservations = SelectedLink(f.NavObservations, selected, EntityLinkObservations.ToString())
+}
+
 // GetSupportedEncoding returns the supported encoding tye for this entity
 func (f FeatureOfInterest) GetSupportedEncoding() map[int]EncodingType {
 	return map[int]EncodingType{EncodingGeoJSON.Code: EncodingGeoJSON, EncodingLocationType.Code: EncodingLocationType}
@@ -10,11 +10,13 @@ import (
 // HistoricalLocation in sensorthings represents the current and previous locations of a thing including time
 type HistoricalLocation struct {
 	BaseEntity
-	Time         string      `json:"time,omitempty"`
-	NavThing     string      `json:"Thing@iot.navigationLink,omitempty"`
-	NavLocations string      `json:"Locations@iot.navigationLink,omitempty"`
-	Thing        *Thing      `json:"Thing,omitempty"`
-	Locations    []*Location `json:"Locations,omitempty"`
+	Time              string      `json:"time,omitempty"`
+	NavThing          string      `json:"Thing@iot.navigationLink,omitempty"`
+	NavLocations      string      `json:"Locations@iot.navigationLink,omitempty"`
+	Thing             *Thing      `json:"Thing,omitempty"`
+	CountLocations    *int        `json:"Locations@iot.count,omitempty"`
+	NextLinkLocations string      `json:"Locations@iot.nextLink,omitempty"`
+	Locations         []*Location `json:"Locations,omitempty"`
 }
 
 // GetEntityType returns the EntityType for HistoricalLocation
@@ -79,6 +81,14 @@ func (h *HistoricalLocation) SetLinks(externalURL string) {
 	h.NavLocations = CreateEntityLink(h.Locations == nil, externalURL, EntityLinkHistoricalLocations.ToString(), EntityLinkLocations.ToString(), h.ID)
 }
 
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (h *HistoricalLocation) SetSelectedLinks(externalURL string, selected []string) {
+	h.SetAllLinks(externalURL)
+	h.NavSelf = SelectedLink(h.NavSelf, selected, SelfLinkName)
+	h.NavThing = SelectedLink(h.NavThing, selected, EntityTypeThing.ToString())
+	h.NavLocations = SelectedLink(h.NavLocations, selected, EntityLinkLocations.ToString())
+}
+
 // GetSupportedEncoding returns the supported encoding tye for this entity
 func (h HistoricalLocation) GetSupportedEncoding() map[int]EncodingType {
 	return map[int]EncodingType{}
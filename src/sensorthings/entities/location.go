@@ -17,14 +17,18 @@ import (
 // of the smart thermostat’s location should be the same as the content of the temperature readings’ feature of interest.
 type Location struct {
 	BaseEntity
-	Name                   string                 `json:"name,omitempty"`
-	Description            string                 `json:"description,omitempty"`
-	EncodingType           string                 `json:"encodingType,omitempty"`
-	Location               map[string]interface{} `json:"location,omitempty"`
-	NavThings              string                 `json:"Things@iot.navigationLink,omitempty"`
-	NavHistoricalLocations string                 `json:"HistoricalLocations@iot.navigationLink,omitempty"`
-	Things                 []*Thing               `json:"Things,omitempty"`
-	HistoricalLocations    []*HistoricalLocation  `json:"HistoricalLocations,omitempty"`
+	Name                        string                 `json:"name,omitempty"`
+	Description                 string                 `json:"description,omitempty"`
+	EncodingType                string                 `json:"encodingType,omitempty"`
+	Location                    map[string]interface{} `json:"location,omitempty"`
+	NavThings                   string                 `json:"Things@iot.navigationLink,omitempty"`
+	NavHistoricalLocations      string                 `json:"HistoricalLocations@iot.navigationLink,omitempty"`
+	CountThings                 *int                   `json:"Things@iot.count,omitempty"`
+	NextLinkThings              string                 `json:"Things@iot.nextLink,omitempty"`
+	Things                      []*Thing               `json:"Things,omitempty"`
+	CountHistoricalLocations    *int                   `json:"HistoricalLocations@iot.count,omitempty"`
+	NextLinkHistoricalLocations string                 `json:"HistoricalLocations@iot.nextLink,omitempty"`
+	HistoricalLocations         []*HistoricalLocation  `json:"HistoricalLocations,omitempty"`
 }
 
 // GetEntityType returns the EntityType for Location
@@ -80,6 +84,14 @@ func (l *Location) SetLinks(externalURL string) {
 	l.NavHistoricalLocations = CreateEntityLink(l.HistoricalLocations == nil, externalURL, EntityLinkLocations.ToString(), EntityLinkHistoricalLocations.ToString(), l.ID)
 }
 
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (l *Location) SetSelectedLinks(externalURL string, selected []string) {
+	l.SetAllLinks(externalURL)
+	l.NavSelf = SelectedLink(l.NavSelf, selected, SelfLinkName)
+	l.NavThings = SelectedLink(l.NavThings, selected, EntityLinkThings.ToString())
+	l.NavHistoricalLocations = SelectedLink(l.NavHistoricalLocations, selected, EntityLinkHistoricalLocations.ToString())
+}
+
 // GetSupportedEncoding returns the supported encoding tye for this entity
 func (l Location) GetSupportedEncoding() map[int]EncodingType {
 	return map[int]EncodingType{EncodingGeoJSON.Code: EncodingGeoJSON, EncodingLocationType.Code: EncodingLocationType}
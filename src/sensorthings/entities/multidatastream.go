@@ -0,0 +1,143 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+)
+
+// MultiDatastream in SensorThings represents a collection of Observations with a complex result, an array
+// holding a value for every ObservedProperty of the MultiDatastream. The unitOfMeasurements and
+// multiObservationDataTypes describe the values of the result in the same order as the ObservedProperties
+type MultiDatastream struct {
+	BaseEntity
+	Name                       string                   `json:"name,omitempty"`
+	Description                string                   `json:"description,omitempty"`
+	UnitOfMeasurements         []map[string]interface{} `json:"unitOfMeasurements,omitempty"`
+	ObservationType            string                   `json:"observationType,omitempty"`
+	MultiObservationDataTypes  []string                 `json:"multiObservationDataTypes,omitempty"`
+	ObservedArea               map[string]interface{}   `json:"observedArea,omitempty"`
+	PhenomenonTime             string                   `json:"phenomenonTime,omitempty"`
+	ResultTime                 string                   `json:"resultTime,omitempty"`
+	NavThing                   string                   `json:"Thing@iot.navigationLink,omitempty"`
+	NavSensor                  string                   `json:"Sensor@iot.navigationLink,omitempty"`
+	NavObservedProperties      string                   `json:"ObservedProperties@iot.navigationLink,omitempty"`
+	NavObservations            string                   `json:"Observations@iot.navigationLink,omitempty"`
+	Thing                      *Thing                   `json:"Thing,omitempty"`
+	Sensor                     *Sensor                  `json:"Sensor,omitempty"`
+	CountObservedProperties    *int                     `json:"ObservedProperties@iot.count,omitempty"`
+	NextLinkObservedProperties string                   `json:"ObservedProperties@iot.nextLink,omitempty"`
+	ObservedProperties         []*ObservedProperty      `json:"ObservedProperties,omitempty"`
+	CountObservations          *int                     `json:"Observations@iot.count,omitempty"`
+	NextLinkObservations       string                   `json:"Observations@iot.nextLink,omitempty"`
+	Observations               []*Observation           `json:"Observations,omitempty"`
+}
+
+// GetEntityType returns the EntityType for MultiDatastream
+func (d MultiDatastream) GetEntityType() EntityType {
+	return EntityTypeMultiDatastream
+}
+
+// GetPropertyNames returns the available properties for a MultiDatastream
+func (d *MultiDatastream) GetPropertyNames() []string {
+	return []string{"id", "name", "description", "unitOfMeasurements", "observationType", "multiObservationDataTypes", "observedArea", "phenomenonTime", "resultTime"}
+}
+
+// ParseEntity tries to parse the given json byte array into the current entity
+func (d *MultiDatastream) ParseEntity(data []byte) error {
+	datastream := &d
+	err := json.Unmarshal(data, datastream)
+	if err != nil {
+		return gostErrors.NewBadRequestError(errors.New("Unable to parse MultiDatastream"))
+	}
+
+	return nil
+}
+
+// ContainsMandatoryParams checks if all mandatory params for a MultiDatastream are available before posting,
+// the number of unitOfMeasurements, multiObservationDataTypes and ObservedProperties has to be the same.
+// The observationType of a MultiDatastream is always OM_ComplexObservation and set when not given
+func (d *MultiDatastream) ContainsMandatoryParams() (bool, []error) {
+	err := []error{}
+	CheckMandatoryParam(&err, d.Name, d.GetEntityType(), "name")
+	CheckMandatoryParam(&err, d.Description, d.GetEntityType(), "description")
+	CheckMandatoryParam(&err, d.UnitOfMeasurements, d.GetEntityType(), "unitOfMeasurements")
+	CheckMandatoryParam(&err, d.MultiObservationDataTypes, d.GetEntityType(), "multiObservationDataTypes")
+	CheckMandatoryParam(&err, d.Thing, d.GetEntityType(), "Thing")
+	CheckMandatoryParam(&err, d.Sensor, d.GetEntityType(), "Sensor")
+	CheckMandatoryParam(&err, d.ObservedProperties, d.GetEntityType(), "ObservedProperties")
+
+	if len(d.ObservationType) == 0 {
+		d.ObservationType = OMComplexObservation.Value
+	} else if d.ObservationType != OMComplexObservation.Value {
+		err = append(err, gostErrors.NewBadRequestError(fmt.Errorf("The observationType of a MultiDatastream has to be %s", OMComplexObservation.Value)))
+	}
+
+	for _, t := range d.MultiObservationDataTypes {
+		if ot, e := GetObservationTypeByValue(t); e != nil || ot.Code == OMCategoryUnknown.Code {
+			err = append(err, gostErrors.NewBadRequestError(fmt.Errorf("MultiObservationDataType %s not supported", t)))
+		}
+	}
+
+	if len(err) == 0 && (len(d.UnitOfMeasurements) != len(d.MultiObservationDataTypes) || len(d.ObservedProperties) != len(d.MultiObservationDataTypes)) {
+		err = append(err, gostErrors.NewBadRequestError(fmt.Errorf("The number of unitOfMeasurements (%v), multiObservationDataTypes (%v) and ObservedProperties (%v) of a MultiDatastream has to be the same",
+			len(d.UnitOfMeasurements), len(d.MultiObservationDataTypes), len(d.ObservedProperties))))
+	}
+
+	if len(err) != 0 {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CheckResult returns an error when the result of an observation is not an array holding a value for
+// every ObservedProperty of the MultiDatastream
+func (d *MultiDatastream) CheckResult(result interface{}) error {
+	values, ok := result.([]interface{})
+	if !ok {
+		return gostErrors.NewBadRequestError(errors.New("The result of an Observation of a MultiDatastream has to be an array"))
+	}
+
+	if len(values) != len(d.MultiObservationDataTypes) {
+		return gostErrors.NewBadRequestError(fmt.Errorf("The result of an Observation of MultiDatastream %v has to contain %v values but contains %v values", d.ID, len(d.MultiObservationDataTypes), len(values)))
+	}
+
+	return nil
+}
+
+// SetAllLinks sets the self link and relational links
+func (d *MultiDatastream) SetAllLinks(externalURL string) {
+	d.SetSelfLink(externalURL)
+	d.SetLinks(externalURL)
+}
+
+// SetSelfLink sets the self link for the entity
+func (d *MultiDatastream) SetSelfLink(externalURL string) {
+	d.NavSelf = CreateEntitySelfLink(externalURL, EntityLinkMultiDatastreams.ToString(), d.ID)
+}
+
+// SetLinks sets the entity specific navigation links, empty string if linked(expanded) data is not nil
+func (d *MultiDatastream) SetLinks(externalURL string) {
+	d.NavThing = CreateEntityLink(d.Thing == nil, externalURL, EntityLinkMultiDatastreams.ToString(), EntityTypeThing.ToString(), d.ID)
+	d.NavSensor = CreateEntityLink(d.Sensor == nil, externalURL, EntityLinkMultiDatastreams.ToString(), EntityTypeSensor.ToString(), d.ID)
+	d.NavObservedProperties = CreateEntityLink(d.ObservedProperties == nil, externalURL, EntityLinkMultiDatastreams.ToString(), EntityLinkObservedProperties.ToString(), d.ID)
+	d.NavObservations = CreateEntityLink(d.Observations == nil, externalURL, EntityLinkMultiDatastreams.ToString(), EntityLinkObservations.ToString(), d.ID)
+}
+
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (d *MultiDatastream) SetSelectedLinks(externalURL string, selected []string) {
+	d.SetAllLinks(externalURL)
+	d.NavSelf = SelectedLink(d.NavSelf, selected, SelfLinkName)
+	d.NavThing = SelectedLink(d.NavThing, selected, EntityTypeThing.ToString())
+	d.NavSensor = SelectedLink(d.NavSensor, selected, EntityTypeSensor.ToString())
+	d.NavObservedProperties = SelectedLink(d.NavObservedProperties, selected, EntityLinkObservedProperties.ToString())
+	d.NavObservations = SelectedLink(d.NavObservations, selected, EntityLinkObservations.ToString())
+}
+
+// GetSupportedEncoding returns the supported encoding tye for this entity
+func (d MultiDatastream) GetSupportedEncoding() map[int]EncodingType {
+	return map[int]EncodingType{}
+}
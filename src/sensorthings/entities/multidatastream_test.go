@@ -0,0 +1,120 @@
+package entities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMultiDatastream() *MultiDatastream {
+	thing, sensor := &Thing{}, &Sensor{}
+	thing.ID, sensor.ID = 1, 1
+	op1, op2 := &ObservedProperty{}, &ObservedProperty{}
+	op1.ID, op2.ID = 1, 2
+
+	return &MultiDatastream{
+		Name:                      "Weather",
+		Description:               "Temperature and humidity",
+		UnitOfMeasurements:        []map[string]interface{}{{"symbol": "C"}, {"symbol": "%"}},
+		MultiObservationDataTypes: []string{OMMeasurement.Value, OMMeasurement.Value},
+		Thing:                     thing,
+		Sensor:                    sensor,
+		ObservedProperties:        []*ObservedProperty{op1, op2},
+	}
+}
+
+func TestMultiDatastreamContainsMandatoryParams(t *testing.T) {
+	// arrange
+	datastream := newTestMultiDatastream()
+
+	// act
+	contains, err := datastream.ContainsMandatoryParams()
+
+	// assert
+	assert.True(t, contains)
+	assert.Nil(t, err)
+	assert.Equal(t, OMComplexObservation.Value, datastream.ObservationType, "observationType should be set to OM_ComplexObservation")
+}
+
+func TestMultiDatastreamContainsMandatoryParamsMissing(t *testing.T) {
+	// arrange
+	datastream := &MultiDatastream{}
+
+	// act
+	contains, err := datastream.ContainsMandatoryParams()
+
+	// assert
+	assert.False(t, contains)
+	assert.Equal(t, 7, len(err))
+}
+
+func TestMultiDatastreamContainsMandatoryParamsComponentCount(t *testing.T) {
+	// arrange
+	datastream := newTestMultiDatastream()
+	datastream.UnitOfMeasurements = datastream.UnitOfMeasurements[:1]
+
+	// act
+	contains, err := datastream.ContainsMandatoryParams()
+
+	// assert
+	assert.False(t, contains)
+	assert.Equal(t, 1, len(err))
+}
+
+func TestMultiDatastreamContainsMandatoryParamsWrongTypes(t *testing.T) {
+	// arrange
+	datastream := newTestMultiDatastream()
+	datastream.ObservationType = OMMeasurement.Value
+	datastream.MultiObservationDataTypes[1] = "unknown"
+
+	// act
+	contains, err := datastream.ContainsMandatoryParams()
+
+	// assert
+	assert.False(t, contains)
+	assert.Equal(t, 2, len(err))
+}
+
+func TestMultiDatastreamCheckResult(t *testing.T) {
+	// arrange
+	datastream := newTestMultiDatastream()
+
+	// act
+	errValid := datastream.CheckResult([]interface{}{20.5, 80})
+	errCount := datastream.CheckResult([]interface{}{20.5})
+	errNoArray := datastream.CheckResult(20.5)
+
+	// assert
+	assert.Nil(t, errValid)
+	assert.NotNil(t, errCount)
+	assert.NotNil(t, errNoArray)
+}
+
+func TestMultiDatastreamSetLinks(t *testing.T) {
+	// arrange
+	datastream := &MultiDatastream{}
+	datastream.ID = 1
+
+	// act
+	datastream.SetAllLinks("http://www.test.com")
+
+	// assert
+	assert.Equal(t, "http://www.test.com/v1.0/MultiDatastreams(1)", datastream.NavSelf)
+	assert.Equal(t, "http://www.test.com/v1.0/MultiDatastreams(1)/Thing", datastream.NavThing)
+	assert.Equal(t, "http://www.test.com/v1.0/MultiDatastreams(1)/Sensor", datastream.NavSensor)
+	assert.Equal(t, "http://www.test.com/v1.0/MultiDatastreams(1)/ObservedProperties", datastream.NavObservedProperties)
+	assert.Equal(t, "http://www.test.com/v1.0/MultiDatastreams(1)/Observations", datastream.NavObservations)
+}
+
+func TestObservationOfMultiDatastreamLinks(t *testing.T) {
+	// arrange
+	observation := &Observation{InMultiDatastream: true}
+	observation.ID = 1
+
+	// act
+	observation.SetAllLinks("http://www.test.com")
+
+	// assert
+	assert.Equal(t, "", observation.NavDatastream)
+	assert.Equal(t, "http://www.test.com/v1.0/Observations(1)/MultiDatastream", observation.NavMultiDatastream)
+}
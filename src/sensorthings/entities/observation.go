@@ -23,8 +23,12 @@ type Observation struct {
 	Parameters           map[string]interface{} `json:"parameters,omitempty"`
 	NavDatastream        string                 `json:"Datastream@iot.navigationLink,omitempty"`
 	NavFeatureOfInterest string                 `json:"FeatureOfInterest@iot.navigationLink,omitempty"`
+	NavMultiDatastream   string                 `json:"MultiDatastream@iot.navigationLink,omitempty"`
 	Datastream           *Datastream            `json:"Datastream,omitempty"`
+	MultiDatastream      *MultiDatastream       `json:"MultiDatastream,omitempty"`
 	FeatureOfInterest    *FeatureOfInterest     `json:"FeatureOfInterest,omitempty"`
+	SkipToken            string                 `json:"-"` // used to create the @iot.nextLink of a page ending with this observation
+	InMultiDatastream    bool                   `json:"-"` // the observation belongs to a MultiDatastream instead of a Datastream
 }
 
 // GetEntityType returns the EntityType for Observation
@@ -66,7 +70,7 @@ func (o *Observation) ContainsMandatoryParams() (bool, []error) {
 
 	// When a SensorThings service receives a POST Observations without resultTime, the service SHALL assign a
 	// null value to the resultTime.
-	if len(o.ResultTime) == 0 {
+	if len(o.ResultTime) == 0 || o.ResultTime == "null" {
 		o.ResultTime = "null"
 	} else {
 		if t, err := time.Parse(time.RFC3339Nano, o.ResultTime); err != nil {
@@ -79,7 +83,14 @@ func (o *Observation) ContainsMandatoryParams() (bool, []error) {
 	CheckMandatoryParam(&errors, o.PhenomenonTime, o.GetEntityType(), "phenomenonTime")
 	CheckMandatoryParam(&errors, o.Result, o.GetEntityType(), "result")
 	CheckMandatoryParam(&errors, o.ResultTime, o.GetEntityType(), "resultTime")
-	CheckMandatoryParam(&errors, o.Datastream, o.GetEntityType(), "Datastream")
+	if o.MultiDatastream != nil {
+		CheckMandatoryParam(&errors, o.MultiDatastream, o.GetEntityType(), "MultiDatastream")
+		if o.Datastream != nil {
+			errors = append(errors, gostErrors.NewBadRequestError(fmt.Errorf("An Observation can only be linked to a Datastream or a MultiDatastream")))
+		}
+	} else {
+		CheckMandatoryParam(&errors, o.Datastream, o.GetEntityType(), "Datastream")
+	}
 
 	if len(errors) != 0 {
 		return false, errors
@@ -99,12 +110,24 @@ func (o *Observation) SetSelfLink(externalURL string) {
 	o.NavSelf = CreateEntitySelfLink(externalURL, EntityLinkObservations.ToString(), o.ID)
 }
 
-// SetLinks sets the entity specific navigation links, empty string if linked(expanded) data is not nil
+// SetLinks sets the entity specific navigation links, empty string if linked(expanded) data is not nil.
+// An observation links to either its Datastream or its MultiDatastream
 func (o *Observation) SetLinks(externalURL string) {
-	o.NavDatastream = CreateEntityLink(o.Datastream == nil, externalURL, EntityLinkObservations.ToString(), EntityTypeDatastream.ToString(), o.ID)
+	inMultiDatastream := o.InMultiDatastream || o.MultiDatastream != nil
+	o.NavDatastream = CreateEntityLink(o.Datastream == nil && !inMultiDatastream, externalURL, EntityLinkObservations.ToString(), EntityTypeDatastream.ToString(), o.ID)
+	o.NavMultiDatastream = CreateEntityLink(o.MultiDatastream == nil && inMultiDatastream, externalURL, EntityLinkObservations.ToString(), EntityTypeMultiDatastream.ToString(), o.ID)
 	o.NavFeatureOfInterest = CreateEntityLink(o.FeatureOfInterest == nil, externalURL, EntityLinkObservations.ToString(), EntityTypeFeatureOfInterest.ToString(), o.ID)
 }
 
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (o *Observation) SetSelectedLinks(externalURL string, selected []string) {
+	o.SetAllLinks(externalURL)
+	o.NavSelf = SelectedLink(o.NavSelf, selected, SelfLinkName)
+	o.NavDatastream = SelectedLink(o.NavDatastream, selected, EntityTypeDatastream.ToString())
+	o.NavMultiDatastream = SelectedLink(o.NavMultiDatastream, selected, EntityTypeMultiDatastream.ToString())
+	o.NavFeatureOfInterest = SelectedLink(o.NavFeatureOfInterest, selected, EntityTypeFeatureOfInterest.ToString())
+}
+
 // MarshalPostgresJSON marshalls an observation entity for saving into PostgreSQL
 func (o Observation) MarshalPostgresJSON() ([]byte, error) {
 	return json.Marshal(&struct {
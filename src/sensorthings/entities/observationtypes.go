@@ -20,6 +20,10 @@ var (
 	OMTruthObservation    = ObservationType{5, "http://www.opengis.net/def/observationType/OGC-OM/2.0/OM_TruthObservation"}    // boolean
 )
 
+// OMComplexObservation is the observationType of a MultiDatastream, the result of its observations is an array of
+// values, it is not part of ObservationTypes since it cannot be used for a Datastream or inside multiObservationDataTypes
+var OMComplexObservation = ObservationType{6, "http://www.opengis.net/def/observationType/OGC-OM/2.0/OM_ComplexObservation"}
+
 // ObservationTypes is a list of names mapped to their ObservationType Value
 var ObservationTypes = []ObservationType{
 	OMCategoryUnknown,
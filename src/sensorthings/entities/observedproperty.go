@@ -11,11 +11,17 @@ import (
 // linked to a Datastream which can only have one ObserveProperty
 type ObservedProperty struct {
 	BaseEntity
-	Name           string        `json:"name,omitempty"`
-	Description    string        `json:"description,omitempty"`
-	Definition     string        `json:"definition,omitempty"`
-	NavDatastreams string        `json:"Datastreams@iot.navigationLink,omitempty"`
-	Datastreams    []*Datastream `json:"Datastreams,omitempty"`
+	Name                     string             `json:"name,omitempty"`
+	Description              string             `json:"description,omitempty"`
+	Definition               string             `json:"definition,omitempty"`
+	NavDatastreams           string             `json:"Datastreams@iot.navigationLink,omitempty"`
+	CountDatastreams         *int               `json:"Datastreams@iot.count,omitempty"`
+	NextLinkDatastreams      string             `json:"Datastreams@iot.nextLink,omitempty"`
+	Datastreams              []*Datastream      `json:"Datastreams,omitempty"`
+	NavMultiDatastreams      string             `json:"MultiDatastreams@iot.navigationLink,omitempty"`
+	CountMultiDatastreams    *int               `json:"MultiDatastreams@iot.count,omitempty"`
+	NextLinkMultiDatastreams string             `json:"MultiDatastreams@iot.nextLink,omitempty"`
+	MultiDatastreams         []*MultiDatastream `json:"MultiDatastreams,omitempty"`
 }
 
 // GetEntityType returns the EntityType for ObservedProperty
@@ -67,6 +73,15 @@ func (o *ObservedProperty) SetSelfLink(externalURL string) {
 // SetLinks sets the entity specific navigation links, empty string if linked(expanded) data is not nil
 func (o *ObservedProperty) SetLinks(externalURL string) {
 	o.NavDatastreams = CreateEntityLink(o.Datastreams == nil, externalURL, EntityLinkObservedProperties.ToString(), EntityLinkDatastreams.ToString(), o.ID)
+	o.NavMultiDatastreams = CreateEntityLink(o.MultiDatastreams == nil, externalURL, EntityLinkObservedProperties.ToString(), EntityLinkMultiDatastreams.ToString(), o.ID)
+}
+
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (o *ObservedProperty) SetSelectedLinks(externalURL string, selected []string) {
+	o.SetAllLinks(externalURL)
+	o.NavSelf = SelectedLink(o.NavSelf, selected, SelfLinkName)
+	o.NavDatastreams = SelectedLink(o.NavDatastreams, selected, EntityLinkDatastreams.ToString())
+	o.NavMultiDatastreams = SelectedLink(o.NavMultiDatastreams, selected, EntityLinkMultiDatastreams.ToString())
 }
 
 // GetSupportedEncoding returns the supported encoding tye for this entity
@@ -11,12 +11,18 @@ import (
 // it to an electrical impulse and be converted to a empirical value to represent a measurement value of the physical property
 type Sensor struct {
 	BaseEntity
-	Name           string        `json:"name,omitempty"`
-	Description    string        `json:"description,omitempty"`
-	EncodingType   string        `json:"encodingType,omitempty"`
-	Metadata       string        `json:"metadata,omitempty"`
-	NavDatastreams string        `json:"Datastreams@iot.navigationLink,omitempty"`
-	Datastreams    []*Datastream `json:"Datastreams,omitempty"`
+	Name                     string             `json:"name,omitempty"`
+	Description              string             `json:"description,omitempty"`
+	EncodingType             string             `json:"encodingType,omitempty"`
+	Metadata                 string             `json:"metadata,omitempty"`
+	NavDatastreams           string             `json:"Datastreams@iot.navigationLink,omitempty"`
+	CountDatastreams         *int               `json:"Datastreams@iot.count,omitempty"`
+	NextLinkDatastreams      string             `json:"Datastreams@iot.nextLink,omitempty"`
+	Datastreams              []*Datastream      `json:"Datastreams,omitempty"`
+	NavMultiDatastreams      string             `json:"MultiDatastreams@iot.navigationLink,omitempty"`
+	CountMultiDatastreams    *int               `json:"MultiDatastreams@iot.count,omitempty"`
+	NextLinkMultiDatastreams string             `json:"MultiDatastreams@iot.nextLink,omitempty"`
+	MultiDatastreams         []*MultiDatastream `json:"MultiDatastreams,omitempty"`
 }
 
 // GetEntityType returns the EntityType for Sensor
@@ -69,6 +75,15 @@ func (s *Sensor) SetSelfLink(externalURL string) {
 // SetLinks sets the entity specific navigation links, empty string if linked(expanded) data is not nil
 func (s *Sensor) SetLinks(externalURL string) {
 	s.NavDatastreams = CreateEntityLink(s.Datastreams == nil, externalURL, EntityLinkSensors.ToString(), EntityLinkDatastreams.ToString(), s.ID)
+	s.NavMultiDatastreams = CreateEntityLink(s.MultiDatastreams == nil, externalURL, EntityLinkSensors.ToString(), EntityLinkMultiDatastreams.ToString(), s.ID)
+}
+
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (s *Sensor) SetSelectedLinks(externalURL string, selected []string) {
+	s.SetAllLinks(externalURL)
+	s.NavSelf = SelectedLink(s.NavSelf, selected, SelfLinkName)
+	s.NavDatastreams = SelectedLink(s.NavDatastreams, selected, EntityLinkDatastreams.ToString())
+	s.NavMultiDatastreams = SelectedLink(s.NavMultiDatastreams, selected, EntityLinkMultiDatastreams.ToString())
 }
 
 // GetSupportedEncoding returns the supported encoding tye for this entity
@@ -0,0 +1,80 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+)
+
+// Task in SensorThings is a command for an Actuator, the taskingParameters hold the values for the
+// parameters described by the TaskingCapability. The creationTime is set by the server
+type Task struct {
+	BaseEntity
+	CreationTime         string                 `json:"creationTime,omitempty"`
+	TaskingParameters    map[string]interface{} `json:"taskingParameters,omitempty"`
+	NavTaskingCapability string                 `json:"TaskingCapability@iot.navigationLink,omitempty"`
+	TaskingCapability    *TaskingCapability     `json:"TaskingCapability,omitempty"`
+}
+
+// GetEntityType returns the EntityType for Task
+func (t Task) GetEntityType() EntityType {
+	return EntityTypeTask
+}
+
+// GetPropertyNames returns the available properties for a Task
+func (t *Task) GetPropertyNames() []string {
+	return []string{"id", "creationTime", "taskingParameters"}
+}
+
+// ParseEntity tries to parse the given json byte array into the current entity
+func (t *Task) ParseEntity(data []byte) error {
+	task := &t
+	err := json.Unmarshal(data, task)
+	if err != nil {
+		return gostErrors.NewBadRequestError(errors.New("Unable to parse Task"))
+	}
+
+	return nil
+}
+
+// ContainsMandatoryParams checks if all mandatory params for Task are available before posting.
+func (t *Task) ContainsMandatoryParams() (bool, []error) {
+	err := []error{}
+	CheckMandatoryParam(&err, taskingParametersParam(t.TaskingParameters), t.GetEntityType(), "taskingParameters")
+	CheckMandatoryParam(&err, t.TaskingCapability, t.GetEntityType(), "TaskingCapability")
+
+	if len(err) != 0 {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SetAllLinks sets the self link and relational links
+func (t *Task) SetAllLinks(externalURL string) {
+	t.SetSelfLink(externalURL)
+	t.SetLinks(externalURL)
+}
+
+// SetSelfLink sets the self link for the entity
+func (t *Task) SetSelfLink(externalURL string) {
+	t.NavSelf = CreateEntitySelfLink(externalURL, EntityLinkTasks.ToString(), t.ID)
+}
+
+// SetLinks sets the entity specific navigation links, empty string if linked(expanded) data is not nil
+func (t *Task) SetLinks(externalURL string) {
+	t.NavTaskingCapability = CreateEntityLink(t.TaskingCapability == nil, externalURL, EntityLinkTasks.ToString(), EntityTypeTaskingCapability.ToString(), t.ID)
+}
+
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (t *Task) SetSelectedLinks(externalURL string, selected []string) {
+	t.SetAllLinks(externalURL)
+	t.NavSelf = SelectedLink(t.NavSelf, selected, SelfLinkName)
+	t.NavTaskingCapability = SelectedLink(t.NavTaskingCapability, selected, EntityTypeTaskingCapability.ToString())
+}
+
+// GetSupportedEncoding returns the supported encoding tye for this entity
+func (t Task) GetSupportedEncoding() map[int]EncodingType {
+	return map[int]EncodingType{}
+}
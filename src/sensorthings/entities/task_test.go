@@ -0,0 +1,37 @@
+package entities
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMandatoryParametersTask(t *testing.T) {
+	//arrange
+	capability := &TaskingCapability{}
+	capability.ID = 1
+	task := &Task{TaskingParameters: map[string]interface{}{"state": "open"}, TaskingCapability: capability}
+	emptyTask := &Task{}
+
+	//act
+	_, err := task.ContainsMandatoryParams()
+	_, emptyErr := emptyTask.ContainsMandatoryParams()
+
+	//assert
+	assert.Nil(t, err, "All mandatory params are filled in should not have returned an error")
+	assert.Len(t, emptyErr, 2, "Task without params should have returned 2 errors")
+}
+
+func TestSetLinksTask(t *testing.T) {
+	//arrange
+	task := &Task{}
+	task.ID = id
+
+	//act
+	task.SetAllLinks(externalURL)
+
+	//assert
+	assert.Equal(t, task.NavSelf, fmt.Sprintf("%s/v1.0/%s(%s)", externalURL, EntityLinkTasks.ToString(), id), "Task navself incorrect")
+	assert.Equal(t, task.NavTaskingCapability, fmt.Sprintf("%s/v1.0/%s(%s)/%s", externalURL, EntityLinkTasks.ToString(), id, EntityTypeTaskingCapability.ToString()), "TaskingCapability navigationLink incorrect")
+}
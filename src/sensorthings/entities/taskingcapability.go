@@ -0,0 +1,104 @@
+package entities
+
+import (
+	"encoding/json"
+	"errors"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+)
+
+// TaskingCapability in SensorThings describes what an Actuator of a Thing can do, the taskingParameters
+// describe the parameters which can be set by a Task in SWE Common JSON encoding
+type TaskingCapability struct {
+	BaseEntity
+	Name              string                 `json:"name,omitempty"`
+	Description       string                 `json:"description,omitempty"`
+	Properties        map[string]interface{} `json:"properties,omitempty"`
+	TaskingParameters map[string]interface{} `json:"taskingParameters,omitempty"`
+	NavThing          string                 `json:"Thing@iot.navigationLink,omitempty"`
+	NavActuator       string                 `json:"Actuator@iot.navigationLink,omitempty"`
+	NavTasks          string                 `json:"Tasks@iot.navigationLink,omitempty"`
+	Thing             *Thing                 `json:"Thing,omitempty"`
+	Actuator          *Actuator              `json:"Actuator,omitempty"`
+	CountTasks        *int                   `json:"Tasks@iot.count,omitempty"`
+	NextLinkTasks     string                 `json:"Tasks@iot.nextLink,omitempty"`
+	Tasks             []*Task                `json:"Tasks,omitempty"`
+}
+
+// GetEntityType returns the EntityType for TaskingCapability
+func (t TaskingCapability) GetEntityType() EntityType {
+	return EntityTypeTaskingCapability
+}
+
+// GetPropertyNames returns the available properties for a TaskingCapability
+func (t *TaskingCapability) GetPropertyNames() []string {
+	return []string{"id", "name", "description", "properties", "taskingParameters"}
+}
+
+// ParseEntity tries to parse the given json byte array into the current entity
+func (t *TaskingCapability) ParseEntity(data []byte) error {
+	capability := &t
+	err := json.Unmarshal(data, capability)
+	if err != nil {
+		return gostErrors.NewBadRequestError(errors.New("Unable to parse TaskingCapability"))
+	}
+
+	return nil
+}
+
+// ContainsMandatoryParams checks if all mandatory params for TaskingCapability are available before posting.
+func (t *TaskingCapability) ContainsMandatoryParams() (bool, []error) {
+	err := []error{}
+	CheckMandatoryParam(&err, t.Name, t.GetEntityType(), "name")
+	CheckMandatoryParam(&err, t.Description, t.GetEntityType(), "description")
+	CheckMandatoryParam(&err, taskingParametersParam(t.TaskingParameters), t.GetEntityType(), "taskingParameters")
+	CheckMandatoryParam(&err, t.Thing, t.GetEntityType(), "Thing")
+	CheckMandatoryParam(&err, t.Actuator, t.GetEntityType(), "Actuator")
+
+	if len(err) != 0 {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// SetAllLinks sets the self link and relational links
+func (t *TaskingCapability) SetAllLinks(externalURL string) {
+	t.SetSelfLink(externalURL)
+	t.SetLinks(externalURL)
+}
+
+// SetSelfLink sets the self link for the entity
+func (t *TaskingCapability) SetSelfLink(externalURL string) {
+	t.NavSelf = CreateEntitySelfLink(externalURL, EntityLinkTaskingCapabilities.ToString(), t.ID)
+}
+
+// SetLinks sets the entity specific navigation links, empty string if linked(expanded) data is not nil
+func (t *TaskingCapability) SetLinks(externalURL string) {
+	t.NavThing = CreateEntityLink(t.Thing == nil, externalURL, EntityLinkTaskingCapabilities.ToString(), EntityTypeThing.ToString(), t.ID)
+	t.NavActuator = CreateEntityLink(t.Actuator == nil, externalURL, EntityLinkTaskingCapabilities.ToString(), EntityTypeActuator.ToString(), t.ID)
+	t.NavTasks = CreateEntityLink(t.Tasks == nil, externalURL, EntityLinkTaskingCapabilities.ToString(), EntityLinkTasks.ToString(), t.ID)
+}
+
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (t *TaskingCapability) SetSelectedLinks(externalURL string, selected []string) {
+	t.SetAllLinks(externalURL)
+	t.NavSelf = SelectedLink(t.NavSelf, selected, SelfLinkName)
+	t.NavThing = SelectedLink(t.NavThing, selected, EntityTypeThing.ToString())
+	t.NavActuator = SelectedLink(t.NavActuator, selected, EntityTypeActuator.ToString())
+	t.NavTasks = SelectedLink(t.NavTasks, selected, EntityLinkTasks.ToString())
+}
+
+// GetSupportedEncoding returns the supported encoding tye for this entity
+func (t TaskingCapability) GetSupportedEncoding() map[int]EncodingType {
+	return map[int]EncodingType{}
+}
+
+// taskingParametersParam returns nil for empty taskingParameters so CheckMandatoryParam reports them as missing
+func taskingParametersParam(params map[string]interface{}) interface{} {
+	if len(params) == 0 {
+		return nil
+	}
+
+	return params
+}
@@ -0,0 +1,62 @@
+package entities
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var jsonTaskingCapability = `{
+    "name": "Valve control",
+    "description": "Opens or closes the valve",
+    "taskingParameters": {
+        "type": "DataRecord",
+        "field": [{"name": "state", "label": "Valve state", "type": "Category", "constraint": {"type": "AllowedTokens", "value": ["open", "closed"]}}]
+    },
+    "Thing": {"@iot.id": 1},
+    "Actuator": {"@iot.id": 2}
+}`
+
+func TestMissingMandatoryParametersTaskingCapability(t *testing.T) {
+	//arrange
+	capability := &TaskingCapability{TaskingParameters: map[string]interface{}{}}
+
+	//act
+	_, err := capability.ContainsMandatoryParams()
+
+	//assert
+	assert.Len(t, err, 5, "TaskingCapability without params should have returned 5 errors")
+	if len(err) > 2 {
+		assert.Contains(t, fmt.Sprintf("%v", err[2]), "taskingParameters")
+	}
+}
+
+func TestParseEntityResultOkTaskingCapability(t *testing.T) {
+	//arrange
+	capability := &TaskingCapability{}
+
+	//act
+	err := capability.ParseEntity([]byte(jsonTaskingCapability))
+	_, mandatoryErr := capability.ContainsMandatoryParams()
+
+	//assert
+	assert.Nil(t, err, "Unable to parse json into tasking capability")
+	assert.Nil(t, mandatoryErr, "All mandatory params are filled in should not have returned an error")
+	assert.Equal(t, "DataRecord", capability.TaskingParameters["type"])
+}
+
+func TestSetLinksTaskingCapability(t *testing.T) {
+	//arrange
+	capability := &TaskingCapability{}
+	capability.ID = id
+
+	//act
+	capability.SetAllLinks(externalURL)
+
+	//assert
+	assert.Equal(t, capability.NavSelf, fmt.Sprintf("%s/v1.0/%s(%s)", externalURL, EntityLinkTaskingCapabilities.ToString(), id), "TaskingCapability navself incorrect")
+	assert.Equal(t, capability.NavThing, fmt.Sprintf("%s/v1.0/%s(%s)/%s", externalURL, EntityLinkTaskingCapabilities.ToString(), id, EntityTypeThing.ToString()), "Thing navigationLink incorrect")
+	assert.Equal(t, capability.NavActuator, fmt.Sprintf("%s/v1.0/%s(%s)/%s", externalURL, EntityLinkTaskingCapabilities.ToString(), id, EntityTypeActuator.ToString()), "Actuator navigationLink incorrect")
+	assert.Equal(t, capability.NavTasks, fmt.Sprintf("%s/v1.0/%s(%s)/%s", externalURL, EntityLinkTaskingCapabilities.ToString(), id, EntityLinkTasks.ToString()), "Tasks navigationLink incorrect")
+}
@@ -12,15 +12,29 @@ import (
 // and there are options to create a Things with a nested linked Location and Datastream.
 type Thing struct {
 	BaseEntity
-	Name                   string                 `json:"name,omitempty"`
-	Description            string                 `json:"description,omitempty"`
-	Properties             map[string]interface{} `json:"properties,omitempty"`
-	NavLocations           string                 `json:"Locations@iot.navigationLink,omitempty"`
-	NavDatastreams         string                 `json:"Datastreams@iot.navigationLink,omitempty"`
-	NavHistoricalLocations string                 `json:"HistoricalLocations@iot.navigationLink,omitempty"`
-	Locations              []*Location            `json:"Locations,omitempty"`
-	Datastreams            []*Datastream          `json:"Datastreams,omitempty"`
-	HistoricalLocations    []*HistoricalLocation  `json:"HistoricalLocations,omitempty"`
+	Name                        string                 `json:"name,omitempty"`
+	Description                 string                 `json:"description,omitempty"`
+	Properties                  map[string]interface{} `json:"properties,omitempty"`
+	NavLocations                string                 `json:"Locations@iot.navigationLink,omitempty"`
+	NavDatastreams              string                 `json:"Datastreams@iot.navigationLink,omitempty"`
+	NavHistoricalLocations      string                 `json:"HistoricalLocations@iot.navigationLink,omitempty"`
+	CountLocations              *int                   `json:"Locations@iot.count,omitempty"`
+	NextLinkLocations           string                 `json:"Locations@iot.nextLink,omitempty"`
+	Locations                   []*Location            `json:"Locations,omitempty"`
+	CountDatastreams            *int                   `json:"Datastreams@iot.count,omitempty"`
+	NextLinkDatastreams         string                 `json:"Datastreams@iot.nextLink,omitempty"`
+	Datastreams                 []*Datastream          `json:"Datastreams,omitempty"`
+	CountHistoricalLocations    *int                   `json:"HistoricalLocations@iot.count,omitempty"`
+	NextLinkHistoricalLocations string                 `json:"HistoricalLocations@iot.nextLink,omitempty"`
+	HistoricalLocations         []*HistoricalLocation  `json:"HistoricalLocations,omitempty"`
+	NavMultiDatastreams         string                 `json:"MultiDatastreams@iot.navigationLink,omitempty"`
+	CountMultiDatastreams       *int                   `json:"MultiDatastreams@iot.count,omitempty"`
+	NextLinkMultiDatastreams    string                 `json:"MultiDatastreams@iot.nextLink,omitempty"`
+	MultiDatastreams            []*MultiDatastream     `json:"MultiDatastreams,omitempty"`
+	NavTaskingCapabilities      string                 `json:"TaskingCapabilities@iot.navigationLink,omitempty"`
+	CountTaskingCapabilities    *int                   `json:"TaskingCapabilities@iot.count,omitempty"`
+	NextLinkTaskingCapabilities string                 `json:"TaskingCapabilities@iot.nextLink,omitempty"`
+	TaskingCapabilities         []*TaskingCapability   `json:"TaskingCapabilities,omitempty"`
 }
 
 // GetEntityType returns the EntityType for Thing
@@ -73,6 +87,19 @@ func (t *Thing) SetLinks(externalURL string) {
 	t.NavLocations = CreateEntityLink(t.Locations == nil, externalURL, EntityLinkThings.ToString(), EntityLinkLocations.ToString(), t.ID)
 	t.NavDatastreams = CreateEntityLink(t.Datastreams == nil, externalURL, EntityLinkThings.ToString(), EntityLinkDatastreams.ToString(), t.ID)
 	t.NavHistoricalLocations = CreateEntityLink(t.HistoricalLocations == nil, externalURL, EntityLinkThings.ToString(), EntityLinkHistoricalLocations.ToString(), t.ID)
+	t.NavMultiDatastreams = CreateEntityLink(t.MultiDatastreams == nil, externalURL, EntityLinkThings.ToString(), EntityLinkMultiDatastreams.ToString(), t.ID)
+	t.NavTaskingCapabilities = CreateEntityLink(t.TaskingCapabilities == nil, externalURL, EntityLinkThings.ToString(), EntityLinkTaskingCapabilities.ToString(), t.ID)
+}
+
+// SetSelectedLinks sets the self link and navigation links which are found in the selected properties
+func (t *Thing) SetSelectedLinks(externalURL string, selected []string) {
+	t.SetAllLinks(externalURL)
+	t.NavSelf = SelectedLink(t.NavSelf, selected, SelfLinkName)
+	t.NavLocations = SelectedLink(t.NavLocations, selected, EntityLinkLocations.ToString())
+	t.NavDatastreams = SelectedLink(t.NavDatastreams, selected, EntityLinkDatastreams.ToString())
+	t.NavHistoricalLocations = SelectedLink(t.NavHistoricalLocations, selected, EntityLinkHistoricalLocations.ToString())
+	t.NavMultiDatastreams = SelectedLink(t.NavMultiDatastreams, selected, EntityLinkMultiDatastreams.ToString())
+	t.NavTaskingCapabilities = SelectedLink(t.NavTaskingCapabilities, selected, EntityLinkTaskingCapabilities.ToString())
 }
 
 // GetSupportedEncoding returns the supported encoding tye for this entity
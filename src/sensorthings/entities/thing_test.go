@@ -80,6 +80,22 @@ func TestSetLinksThing(t *testing.T) {
 	assert.Equal(t, thing.NavHistoricalLocations, fmt.Sprintf("%s/v1.0/%s(%s)/%s", externalURL, EntityLinkThings.ToString(), id, EntityLinkHistoricalLocations.ToString()), "Thing NavHistoricalLocations incorrect")
 }
 
+func TestSetSelectedLinksThing(t *testing.T) {
+	//arrange
+	thing := &Thing{}
+	thing.ID = id
+	thing.Datastreams = []*Datastream{}
+
+	//act
+	thing.SetSelectedLinks(externalURL, []string{"name", "locations", "Datastreams", "@iot.selfLink"})
+
+	//assert
+	assert.Equal(t, fmt.Sprintf("%s/v1.0/%s(%s)", externalURL, EntityLinkThings.ToString(), id), thing.NavSelf, "Thing navself should be selected")
+	assert.Equal(t, fmt.Sprintf("%s/v1.0/%s(%s)/%s", externalURL, EntityLinkThings.ToString(), id, EntityLinkLocations.ToString()), thing.NavLocations, "Thing NavLocations should be selected")
+	assert.Equal(t, "", thing.NavDatastreams, "Thing NavDatastreams should not be set when Datastreams are expanded")
+	assert.Equal(t, "", thing.NavHistoricalLocations, "Thing NavHistoricalLocations should not be selected")
+}
+
 func TestGetSupportedEncodingThing(t *testing.T) {
 	//arrange
 	thing := &Thing{}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/geodan/gost/src/configuration"
@@ -26,6 +27,8 @@ type API interface {
 
 	GetThing(id interface{}, qo *odata.QueryOptions, path string) (*entities.Thing, error)
 	GetThingByDatastream(id interface{}, qo *odata.QueryOptions, path string) (*entities.Thing, error)
+	GetThingByMultiDatastream(id interface{}, qo *odata.QueryOptions, path string) (*entities.Thing, error)
+	GetThingByTaskingCapability(id interface{}, qo *odata.QueryOptions, path string) (*entities.Thing, error)
 	GetThingsByLocation(id interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
 	GetThingByHistoricalLocation(id interface{}, qo *odata.QueryOptions, path string) (*entities.Thing, error)
 	GetThings(qo *odata.QueryOptions, path string) (*ArrayResponse, error)
@@ -65,6 +68,18 @@ type API interface {
 	PutDatastream(id interface{}, datastream *entities.Datastream) (*entities.Datastream, []error)
 	DeleteDatastream(id interface{}) error
 
+	GetMultiDatastream(id interface{}, qo *odata.QueryOptions, path string) (*entities.MultiDatastream, error)
+	GetMultiDatastreams(qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	GetMultiDatastreamByObservation(id interface{}, qo *odata.QueryOptions, path string) (*entities.MultiDatastream, error)
+	GetMultiDatastreamsByThing(thingID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	GetMultiDatastreamsBySensor(sensorID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	GetMultiDatastreamsByObservedProperty(oID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	PostMultiDatastream(datastream *entities.MultiDatastream) (*entities.MultiDatastream, []error)
+	PostMultiDatastreamByThing(thingID interface{}, datastream *entities.MultiDatastream) (*entities.MultiDatastream, []error)
+	PatchMultiDatastream(id interface{}, datastream *entities.MultiDatastream) (*entities.MultiDatastream, error)
+	PutMultiDatastream(id interface{}, datastream *entities.MultiDatastream) (*entities.MultiDatastream, []error)
+	DeleteMultiDatastream(id interface{}) error
+
 	GetFeatureOfInterest(id interface{}, qo *odata.QueryOptions, path string) (*entities.FeatureOfInterest, error)
 	GetFeatureOfInterestByObservation(id interface{}, qo *odata.QueryOptions, path string) (*entities.FeatureOfInterest, error)
 	GetFeatureOfInterests(qo *odata.QueryOptions, path string) (*ArrayResponse, error)
@@ -76,9 +91,12 @@ type API interface {
 	GetObservation(id interface{}, qo *odata.QueryOptions, path string) (*entities.Observation, error)
 	GetObservations(qo *odata.QueryOptions, path string) (*ArrayResponse, error)
 	GetObservationsByDatastream(datastreamID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	GetObservationsByMultiDatastream(multiDatastreamID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
 	GetObservationsByFeatureOfInterest(foiID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
 	PostObservation(observation *entities.Observation) (*entities.Observation, []error)
 	PostObservationByDatastream(datastreamID interface{}, observation *entities.Observation) (*entities.Observation, []error)
+	PostObservationByMultiDatastream(multiDatastreamID interface{}, observation *entities.Observation) (*entities.Observation, []error)
+	CreateObservations(dataArrays []*DataArrayRequest) ([]string, []error)
 	PatchObservation(id interface{}, observation *entities.Observation) (*entities.Observation, error)
 	PutObservation(id interface{}, observation *entities.Observation) (*entities.Observation, []error)
 	DeleteObservation(id interface{}) error
@@ -86,6 +104,7 @@ type API interface {
 	GetObservedProperty(id interface{}, qo *odata.QueryOptions, path string) (*entities.ObservedProperty, error)
 	GetObservedProperties(qo *odata.QueryOptions, path string) (*ArrayResponse, error)
 	GetObservedPropertyByDatastream(datastreamID interface{}, qo *odata.QueryOptions, path string) (*entities.ObservedProperty, error)
+	GetObservedPropertiesByMultiDatastream(multiDatastreamID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
 	PostObservedProperty(op *entities.ObservedProperty) (*entities.ObservedProperty, []error)
 	PatchObservedProperty(id interface{}, op *entities.ObservedProperty) (*entities.ObservedProperty, error)
 	PutObservedProperty(id interface{}, op *entities.ObservedProperty) (*entities.ObservedProperty, []error)
@@ -93,22 +112,67 @@ type API interface {
 
 	GetSensor(id interface{}, qo *odata.QueryOptions, path string) (*entities.Sensor, error)
 	GetSensorByDatastream(id interface{}, qo *odata.QueryOptions, path string) (*entities.Sensor, error)
+	GetSensorByMultiDatastream(id interface{}, qo *odata.QueryOptions, path string) (*entities.Sensor, error)
 	GetSensors(qo *odata.QueryOptions, path string) (*ArrayResponse, error)
 	PostSensor(sensor *entities.Sensor) (*entities.Sensor, []error)
 	PatchSensor(id interface{}, sensor *entities.Sensor) (*entities.Sensor, error)
 	DeleteSensor(id interface{}) error
 	PutSensor(id interface{}, sensor *entities.Sensor) (*entities.Sensor, []error)
 
+	GetActuator(id interface{}, qo *odata.QueryOptions, path string) (*entities.Actuator, error)
+	GetActuatorByTaskingCapability(id interface{}, qo *odata.QueryOptions, path string) (*entities.Actuator, error)
+	GetActuators(qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	PostActuator(actuator *entities.Actuator) (*entities.Actuator, []error)
+	PatchActuator(id interface{}, actuator *entities.Actuator) (*entities.Actuator, error)
+	PutActuator(id interface{}, actuator *entities.Actuator) (*entities.Actuator, []error)
+	DeleteActuator(id interface{}) error
+
+	GetTaskingCapability(id interface{}, qo *odata.QueryOptions, path string) (*entities.TaskingCapability, error)
+	GetTaskingCapabilities(qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	GetTaskingCapabilitiesByThing(thingID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	GetTaskingCapabilitiesByActuator(actuatorID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	GetTaskingCapabilityByTask(taskID interface{}, qo *odata.QueryOptions, path string) (*entities.TaskingCapability, error)
+	PostTaskingCapability(tc *entities.TaskingCapability) (*entities.TaskingCapability, []error)
+	PostTaskingCapabilityByThing(thingID interface{}, tc *entities.TaskingCapability) (*entities.TaskingCapability, []error)
+	PatchTaskingCapability(id interface{}, tc *entities.TaskingCapability) (*entities.TaskingCapability, error)
+	PutTaskingCapability(id interface{}, tc *entities.TaskingCapability) (*entities.TaskingCapability, []error)
+	DeleteTaskingCapability(id interface{}) error
+
+	GetTask(id interface{}, qo *odata.QueryOptions, path string) (*entities.Task, error)
+	GetTasks(qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	GetTasksByTaskingCapability(taskingCapabilityID interface{}, qo *odata.QueryOptions, path string) (*ArrayResponse, error)
+	PostTask(task *entities.Task) (*entities.Task, []error)
+	PostTaskByTaskingCapability(taskingCapabilityID interface{}, task *entities.Task) (*entities.Task, []error)
+	PatchTask(id interface{}, task *entities.Task) (*entities.Task, error)
+	PutTask(id interface{}, task *entities.Task) (*entities.Task, []error)
+	DeleteTask(id interface{}) error
+
 	LinkLocation(thingID interface{}, locationID interface{}) error
+	LinkEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) error
+	UnlinkEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) error
+
+	// ResolveNavigation returns the id of the entity of type child related to the parent entity, when childID
+	// is given the id is only returned when the child belongs to the parent
+	ResolveNavigation(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (interface{}, error)
+
+	// WithTransaction runs fn with an API of which all database operations are part of one transaction,
+	// the transaction is committed when fn returns no error and rolled back otherwise
+	WithTransaction(fn func(a API) error) error
+	// LockEntity locks the entity until the end of the transaction the API is part of
+	LockEntity(entityType entities.EntityType, id interface{}) error
 }
 
 // Database specifies the operations that the database provider needs to support
 type Database interface {
 	Start()
 	CreateSchema(location string) error
+	WithTransaction(fn func(db Database) error) error
+	LockEntity(entityType entities.EntityType, id interface{}) error
 
 	GetThing(id interface{}, qo *odata.QueryOptions) (*entities.Thing, error)
 	GetThingByDatastream(id interface{}, qo *odata.QueryOptions) (t *entities.Thing, e error)
+	GetThingByMultiDatastream(id interface{}, qo *odata.QueryOptions) (t *entities.Thing, e error)
+	GetThingByTaskingCapability(id interface{}, qo *odata.QueryOptions) (t *entities.Thing, e error)
 	GetThingsByLocation(id interface{}, qo *odata.QueryOptions) (t []*entities.Thing, count int, e error)
 	GetThingByHistoricalLocation(id interface{}, qo *odata.QueryOptions) (t *entities.Thing, e error)
 	GetThings(qo *odata.QueryOptions) (t []*entities.Thing, count int, e error)
@@ -122,6 +186,7 @@ type Database interface {
 	GetLocationsByHistoricalLocation(id interface{}, qo *odata.QueryOptions) (l []*entities.Location, count int, e error)
 	GetLocationsByThing(id interface{}, qo *odata.QueryOptions) (l []*entities.Location, count int, e error)
 	GetLocationByDatastreamID(id interface{}) (*entities.Location, error)
+	GetLocationByMultiDatastreamID(id interface{}) (*entities.Location, error)
 	PostLocation(*entities.Location) (*entities.Location, error)
 	LinkLocation(id interface{}, locationID interface{}) error
 	PatchLocation(interface{}, *entities.Location) (*entities.Location, error)
@@ -130,6 +195,7 @@ type Database interface {
 
 	GetObservedProperty(id interface{}, qo *odata.QueryOptions) (*entities.ObservedProperty, error)
 	GetObservedPropertyByDatastream(id interface{}, qo *odata.QueryOptions) (*entities.ObservedProperty, error)
+	GetObservedPropertiesByMultiDatastream(id interface{}, qo *odata.QueryOptions) (o []*entities.ObservedProperty, count int, e error)
 	GetObservedProperties(qo *odata.QueryOptions) (o []*entities.ObservedProperty, count int, e error)
 	PostObservedProperty(*entities.ObservedProperty) (*entities.ObservedProperty, error)
 	PatchObservedProperty(interface{}, *entities.ObservedProperty) (*entities.ObservedProperty, error)
@@ -138,6 +204,7 @@ type Database interface {
 
 	GetSensor(id interface{}, qo *odata.QueryOptions) (*entities.Sensor, error)
 	GetSensorByDatastream(id interface{}, qo *odata.QueryOptions) (*entities.Sensor, error)
+	GetSensorByMultiDatastream(id interface{}, qo *odata.QueryOptions) (*entities.Sensor, error)
 	GetSensors(qo *odata.QueryOptions) (s []*entities.Sensor, count int, e error)
 	PostSensor(*entities.Sensor) (*entities.Sensor, error)
 	PatchSensor(interface{}, *entities.Sensor) (*entities.Sensor, error)
@@ -156,6 +223,18 @@ type Database interface {
 	DatastreamExists(int) bool
 	PutDatastream(interface{}, *entities.Datastream) (*entities.Datastream, error)
 
+	GetMultiDatastream(id interface{}, qo *odata.QueryOptions) (*entities.MultiDatastream, error)
+	GetMultiDatastreams(qo *odata.QueryOptions) (d []*entities.MultiDatastream, count int, e error)
+	GetMultiDatastreamByObservation(id interface{}, qo *odata.QueryOptions) (*entities.MultiDatastream, error)
+	GetMultiDatastreamsByThing(id interface{}, qo *odata.QueryOptions) (d []*entities.MultiDatastream, count int, e error)
+	GetMultiDatastreamsBySensor(id interface{}, qo *odata.QueryOptions) (d []*entities.MultiDatastream, count int, e error)
+	GetMultiDatastreamsByObservedProperty(id interface{}, qo *odata.QueryOptions) (d []*entities.MultiDatastream, count int, e error)
+	PostMultiDatastream(*entities.MultiDatastream) (*entities.MultiDatastream, error)
+	PatchMultiDatastream(interface{}, *entities.MultiDatastream) (*entities.MultiDatastream, error)
+	DeleteMultiDatastream(id interface{}) error
+	MultiDatastreamExists(int) bool
+	PutMultiDatastream(interface{}, *entities.MultiDatastream) (*entities.MultiDatastream, error)
+
 	GetFeatureOfInterest(id interface{}, qo *odata.QueryOptions) (*entities.FeatureOfInterest, error)
 	GetFeatureOfInterestByLocationID(id interface{}) (*entities.FeatureOfInterest, error)
 	GetFeatureOfInterestByObservation(id interface{}, qo *odata.QueryOptions) (*entities.FeatureOfInterest, error)
@@ -168,8 +247,13 @@ type Database interface {
 	GetObservation(id interface{}, qo *odata.QueryOptions) (*entities.Observation, error)
 	GetObservations(qo *odata.QueryOptions) (o []*entities.Observation, count int, e error)
 	GetObservationsByDatastream(id interface{}, qo *odata.QueryOptions) (o []*entities.Observation, count int, e error)
+	GetObservationsByMultiDatastream(id interface{}, qo *odata.QueryOptions) (o []*entities.Observation, count int, e error)
 	GetObservationsByFeatureOfInterest(id interface{}, qo *odata.QueryOptions) (o []*entities.Observation, count int, e error)
 	PostObservation(*entities.Observation) (*entities.Observation, error)
+	PostObservations([]*entities.Observation) ([]*entities.Observation, []error)
+	AggregateObservations(qo *odata.QueryOptions) (r []json.RawMessage, count int, e error)
+	AggregateObservationsByDatastream(id interface{}, qo *odata.QueryOptions) (r []json.RawMessage, count int, e error)
+	AggregateObservationsByFeatureOfInterest(id interface{}, qo *odata.QueryOptions) (r []json.RawMessage, count int, e error)
 	PatchObservation(interface{}, *entities.Observation) (*entities.Observation, error)
 	PutObservation(interface{}, *entities.Observation) (*entities.Observation, error)
 	DeleteObservation(id interface{}) error
@@ -183,8 +267,41 @@ type Database interface {
 	PatchHistoricalLocation(interface{}, *entities.HistoricalLocation) (*entities.HistoricalLocation, error)
 	DeleteHistoricalLocation(id interface{}) error
 
+	GetActuator(id interface{}, qo *odata.QueryOptions) (*entities.Actuator, error)
+	GetActuatorByTaskingCapability(id interface{}, qo *odata.QueryOptions) (*entities.Actuator, error)
+	GetActuators(qo *odata.QueryOptions) (a []*entities.Actuator, count int, e error)
+	PostActuator(*entities.Actuator) (*entities.Actuator, error)
+	PatchActuator(interface{}, *entities.Actuator) (*entities.Actuator, error)
+	PutActuator(interface{}, *entities.Actuator) (*entities.Actuator, error)
+	DeleteActuator(id interface{}) error
+	ActuatorExists(int) bool
+
+	GetTaskingCapability(id interface{}, qo *odata.QueryOptions) (*entities.TaskingCapability, error)
+	GetTaskingCapabilities(qo *odata.QueryOptions) (t []*entities.TaskingCapability, count int, e error)
+	GetTaskingCapabilitiesByThing(id interface{}, qo *odata.QueryOptions) (t []*entities.TaskingCapability, count int, e error)
+	GetTaskingCapabilitiesByActuator(id interface{}, qo *odata.QueryOptions) (t []*entities.TaskingCapability, count int, e error)
+	GetTaskingCapabilityByTask(id interface{}, qo *odata.QueryOptions) (*entities.TaskingCapability, error)
+	PostTaskingCapability(*entities.TaskingCapability) (*entities.TaskingCapability, error)
+	PatchTaskingCapability(interface{}, *entities.TaskingCapability) (*entities.TaskingCapability, error)
+	PutTaskingCapability(interface{}, *entities.TaskingCapability) (*entities.TaskingCapability, error)
+	DeleteTaskingCapability(id interface{}) error
+	TaskingCapabilityExists(int) bool
+
+	GetTask(id interface{}, qo *odata.QueryOptions) (*entities.Task, error)
+	GetTasks(qo *odata.QueryOptions) (t []*entities.Task, count int, e error)
+	GetTasksByTaskingCapability(id interface{}, qo *odata.QueryOptions) (t []*entities.Task, count int, e error)
+	PostTask(*entities.Task) (*entities.Task, error)
+	PatchTask(interface{}, *entities.Task) (*entities.Task, error)
+	PutTask(interface{}, *entities.Task) (*entities.Task, error)
+	DeleteTask(id interface{}) error
+	TaskExists(int) bool
+
 	ThingExists(thingID interface{}) bool
 	LocationExists(thingID interface{}) bool
+	ResolveNavigation(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (interface{}, error)
+	// LinkEntities links the child to the parent entity, linked is false when the entities were already linked
+	LinkEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) (linked bool, err error)
+	UnlinkEntities(parent entities.EntityType, parentID interface{}, child entities.EntityType, childID interface{}) error
 }
 
 // MQTTClient interface defines the needed MQTT client operations
@@ -259,11 +376,31 @@ type APIVersion struct {
 
 // ArrayResponse is the default response format for sending content back
 type ArrayResponse struct {
-	Count    int          `json:"count,omitempty"`
+	Count    *int         `json:"@iot.count,omitempty"`
 	NextLink string       `json:"@iot.nextLink,omitempty"`
 	Data     *interface{} `json:"value"`
 }
 
+// DataArrayResponse is the response format for observations requested with $resultFormat=dataArray,
+// observations are grouped per Datastream and every observation is an array of values in DataArray,
+// the names of the values are described in the same order by Components
+type DataArrayResponse struct {
+	NavDatastream string          `json:"Datastream@iot.navigationLink,omitempty"`
+	Components    []string        `json:"components"`
+	Count         int             `json:"dataArray@iot.count"`
+	DataArray     [][]interface{} `json:"dataArray"`
+}
+
+// DataArrayRequest holds observations of one Datastream send to CreateObservations, every row in DataArray is an
+// observation of which the values are described in the same order by Components such as phenomenonTime, result
+// and FeatureOfInterest/id
+type DataArrayRequest struct {
+	Datastream *entities.Datastream `json:"Datastream"`
+	Components []string             `json:"components"`
+	Count      int                  `json:"dataArray@iot.count,omitempty"`
+	DataArray  [][]interface{}      `json:"dataArray"`
+}
+
 // ErrorResponse is the default response format for sending errors back
 type ErrorResponse struct {
 	Error ErrorContent `json:"error"`
@@ -8,7 +8,8 @@ import (
 )
 
 var topics = map[string]models.MQTTInternalHandler{
-	"GOST/Datastreams()/Observations": observationsByDatastream,
+	"GOST/Datastreams()/Observations":      observationsByDatastream,
+	"GOST/MultiDatastreams()/Observations": observationsByMultiDatastream,
 }
 
 // MainMqttHandler handles all messages on GOST/# and maps them to the appropriate
@@ -44,3 +45,14 @@ func observationsByDatastream(a *models.API, message []byte, id string) {
 		//log.Printf("%v", err2)
 	}
 }
+
+func observationsByMultiDatastream(a *models.API, message []byte, id string) {
+	o := entities.Observation{}
+	err := o.ParseEntity(message)
+	if err != nil {
+		return
+	}
+
+	api := *a
+	api.PostObservationByMultiDatastream(id, &o)
+}
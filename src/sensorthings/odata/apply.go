@@ -0,0 +1,343 @@
+package odata
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ApplyTransformationType is the type of a transformation inside $apply
+type ApplyTransformationType string
+
+// List of supported $apply transformations
+const (
+	ApplyTransformationFilter    ApplyTransformationType = "filter"
+	ApplyTransformationGroupBy   ApplyTransformationType = "groupby"
+	ApplyTransformationAggregate ApplyTransformationType = "aggregate"
+)
+
+// List of supported aggregation methods, ApplyMethodCount is used for $count as alias
+const (
+	ApplyMethodSum           = "sum"
+	ApplyMethodMin           = "min"
+	ApplyMethodMax           = "max"
+	ApplyMethodAverage       = "average"
+	ApplyMethodCountDistinct = "countdistinct"
+	ApplyMethodCount         = "count"
+)
+
+// ApplyMethods is a list of the aggregation methods which can be used with "property with method as alias"
+var ApplyMethods = []string{ApplyMethodSum, ApplyMethodMin, ApplyMethodMax, ApplyMethodAverage, ApplyMethodCountDistinct}
+
+// QueryApply holds the transformations of $apply in the requested order, for example
+// filter(result gt 0)/groupby((Datastream/id,bucket(phenomenonTime,'PT1H') as hour),aggregate(result with average as avg))
+// Filters can only be used before the aggregation, groupby or aggregate is always the last transformation
+type QueryApply struct {
+	QueryBase
+	Transformations []*ApplyTransformation
+}
+
+// ApplyTransformation is a single transformation of $apply, Filter is set for a filter transformation,
+// GroupBy and Aggregates are set for groupby, only Aggregates is set for aggregate
+type ApplyTransformation struct {
+	Type       ApplyTransformationType
+	Filter     Expression
+	GroupBy    []*ApplyGroupProperty
+	Aggregates []*ApplyAggregate
+}
+
+// ApplyGroupProperty is a property to group on, a time property can be grouped in buckets of Interval
+// using bucket(phenomenonTime,'PT1H'). Alias is the name of the property in the result
+type ApplyGroupProperty struct {
+	Property string
+	Interval *ApplyInterval
+	Alias    string
+}
+
+// ApplyInterval is the size of a time bucket, an interval is either a number of seconds or one month or year
+type ApplyInterval struct {
+	Months  int
+	Seconds int
+}
+
+// ApplyAggregate is an aggregation of a property such as "result with average as avg", Property is empty
+// for the number of entities in a group requested by "$count as alias"
+type ApplyAggregate struct {
+	Property string
+	Method   string
+	Alias    string
+}
+
+// Parse splits the value into transformations separated by / and parses every transformation
+func (q *QueryApply) Parse(value string) error {
+	q.RawQuery = value
+	parts, err := splitOutsideParentheses(value, '/')
+	if err != nil {
+		return CreateQueryError(QueryApplyInvalid, http.StatusBadRequest, value, err.Error())
+	}
+
+	for i, p := range parts {
+		t, err := parseApplyTransformation(strings.TrimSpace(p))
+		if err != nil {
+			return CreateQueryError(QueryApplyInvalid, http.StatusBadRequest, value, err.Error())
+		}
+
+		if t.Type != ApplyTransformationFilter && i != len(parts)-1 {
+			return CreateQueryError(QueryApplyInvalid, http.StatusBadRequest, value, fmt.Sprintf("%s has to be the last transformation", t.Type))
+		}
+
+		q.Transformations = append(q.Transformations, t)
+	}
+
+	if q.Aggregation() == nil {
+		return CreateQueryError(QueryApplyInvalid, http.StatusBadRequest, value, "groupby or aggregate is required, use $filter to only filter")
+	}
+
+	aliases := map[string]bool{}
+	for _, a := range q.Aliases() {
+		if aliases[strings.ToLower(a)] {
+			return CreateQueryError(QueryApplyInvalid, http.StatusBadRequest, value, fmt.Sprintf("%s is used more than once", a))
+		}
+
+		aliases[strings.ToLower(a)] = true
+	}
+
+	return nil
+}
+
+// Filters returns the expressions of the filter transformations
+func (q *QueryApply) Filters() []Expression {
+	filters := []Expression{}
+	for _, t := range q.Transformations {
+		if t.Type == ApplyTransformationFilter {
+			filters = append(filters, t.Filter)
+		}
+	}
+
+	return filters
+}
+
+// Aggregation returns the groupby or aggregate transformation
+func (q *QueryApply) Aggregation() *ApplyTransformation {
+	for _, t := range q.Transformations {
+		if t.Type != ApplyTransformationFilter {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// Aliases returns the names of the properties in the result of the aggregation
+func (q *QueryApply) Aliases() []string {
+	aliases := []string{}
+	if a := q.Aggregation(); a != nil {
+		for _, g := range a.GroupBy {
+			aliases = append(aliases, g.Alias)
+		}
+
+		for _, ag := range a.Aggregates {
+			aliases = append(aliases, ag.Alias)
+		}
+	}
+
+	return aliases
+}
+
+// IsValid always returns true, errors are already filtered out by parse
+func (q *QueryApply) IsValid() (bool, error) {
+	return true, nil
+}
+
+// GetQueryOptionType returns the QueryOptionType for QueryApply
+func (q *QueryApply) GetQueryOptionType() QueryOptionType {
+	return QueryOptionApply
+}
+
+// IsNil checks if *QueryApply is nil
+func (q *QueryApply) IsNil() bool {
+	if q == nil {
+		return true
+	}
+
+	return false
+}
+
+// parseApplyTransformation parses a transformation such as filter(...), groupby(...) or aggregate(...)
+func parseApplyTransformation(value string) (*ApplyTransformation, error) {
+	name, args, err := splitApplyFunction(value)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &ApplyTransformation{Type: ApplyTransformationType(strings.ToLower(name))}
+	switch t.Type {
+	case ApplyTransformationFilter:
+		if t.Filter, err = ParseODATAFilter(args); err != nil {
+			return nil, err
+		}
+	case ApplyTransformationAggregate:
+		if t.Aggregates, err = parseApplyAggregates(args); err != nil {
+			return nil, err
+		}
+	case ApplyTransformationGroupBy:
+		parts, err := splitOutsideParentheses(args, ',')
+		if err != nil {
+			return nil, err
+		}
+
+		groups := strings.TrimSpace(parts[0])
+		if len(parts) > 2 || !strings.HasPrefix(groups, "(") || !strings.HasSuffix(groups, ")") {
+			return nil, fmt.Errorf("expected groupby((properties)) or groupby((properties),aggregate(...))")
+		}
+
+		if t.GroupBy, err = parseApplyGroupProperties(groups[1 : len(groups)-1]); err != nil {
+			return nil, err
+		}
+
+		if len(parts) == 2 {
+			aggregate, err := parseApplyTransformation(strings.TrimSpace(parts[1]))
+			if err != nil {
+				return nil, err
+			}
+
+			if aggregate.Type != ApplyTransformationAggregate {
+				return nil, fmt.Errorf("only aggregate can be used inside groupby")
+			}
+
+			t.Aggregates = aggregate.Aggregates
+		}
+	default:
+		return nil, fmt.Errorf("unknown transformation %s", name)
+	}
+
+	return t, nil
+}
+
+// splitApplyFunction splits name(args) into its name and arguments
+func splitApplyFunction(value string) (string, string, error) {
+	start := strings.Index(value, "(")
+	if start < 1 || !strings.HasSuffix(value, ")") {
+		return "", "", fmt.Errorf("expected a transformation such as filter(...), groupby(...) or aggregate(...) but got %s", value)
+	}
+
+	return strings.TrimSpace(value[:start]), value[start+1 : len(value)-1], nil
+}
+
+var (
+	applyAggregateRegex = regexp.MustCompile(`^(?i)([\w/]+)\s+with\s+(\w+)\s+as\s+(\w+)$`)
+	applyCountRegex     = regexp.MustCompile(`^(?i)\$count\s+as\s+(\w+)$`)
+	applyGroupRegex     = regexp.MustCompile(`^(?i)([\w/]+)(?:\s+as\s+(\w+))?$`)
+	applyBucketRegex    = regexp.MustCompile(`^(?i)bucket\(\s*([\w/]+)\s*,\s*'([^']*)'\s*\)\s+as\s+(\w+)$`)
+	applyIntervalRegex  = regexp.MustCompile(`^P(?:(\d+)Y)?(?:(\d+)M)?(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+)
+
+// parseApplyAggregates parses the comma separated aggregates of aggregate(...)
+func parseApplyAggregates(value string) ([]*ApplyAggregate, error) {
+	parts, err := splitOutsideParentheses(value, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	aggregates := []*ApplyAggregate{}
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if m := applyCountRegex.FindStringSubmatch(p); m != nil {
+			aggregates = append(aggregates, &ApplyAggregate{Method: ApplyMethodCount, Alias: m[1]})
+			continue
+		}
+
+		m := applyAggregateRegex.FindStringSubmatch(p)
+		if m == nil {
+			return nil, fmt.Errorf("expected \"property with method as alias\" or \"$count as alias\" but got %s", p)
+		}
+
+		method := strings.ToLower(m[2])
+		if !containsString(ApplyMethods, method) {
+			return nil, fmt.Errorf("unknown aggregation method %s, supported methods are %s", m[2], strings.Join(ApplyMethods, ", "))
+		}
+
+		aggregates = append(aggregates, &ApplyAggregate{Property: m[1], Method: method, Alias: m[3]})
+	}
+
+	return aggregates, nil
+}
+
+// parseApplyGroupProperties parses the comma separated properties of groupby((...))
+func parseApplyGroupProperties(value string) ([]*ApplyGroupProperty, error) {
+	parts, err := splitOutsideParentheses(value, ',')
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []*ApplyGroupProperty{}
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if m := applyBucketRegex.FindStringSubmatch(p); m != nil {
+			interval, err := parseApplyInterval(m[2])
+			if err != nil {
+				return nil, err
+			}
+
+			groups = append(groups, &ApplyGroupProperty{Property: m[1], Interval: interval, Alias: m[3]})
+			continue
+		}
+
+		m := applyGroupRegex.FindStringSubmatch(p)
+		if m == nil {
+			return nil, fmt.Errorf("expected \"property\", \"property as alias\" or \"bucket(property,'duration') as alias\" but got %s", p)
+		}
+
+		alias := m[2]
+		if len(alias) == 0 {
+			alias = m[1]
+		}
+
+		groups = append(groups, &ApplyGroupProperty{Property: m[1], Alias: alias})
+	}
+
+	return groups, nil
+}
+
+// parseApplyInterval parses an ISO 8601 duration such as PT15M or P1D into an interval, months
+// and years can only be used on their own as P1M or P1Y
+func parseApplyInterval(value string) (*ApplyInterval, error) {
+	m := applyIntervalRegex.FindStringSubmatch(strings.ToUpper(value))
+	if m == nil || value == "P" || strings.HasSuffix(value, "T") {
+		return nil, fmt.Errorf("invalid duration %s for bucket, use an ISO 8601 duration such as PT15M or P1D", value)
+	}
+
+	parts := make([]int, len(m)-1)
+	for i, v := range m[1:] {
+		parts[i], _ = strconv.Atoi(v)
+	}
+
+	seconds := parts[2]*7*86400 + parts[3]*86400 + parts[4]*3600 + parts[5]*60 + parts[6]
+	months := parts[0]*12 + parts[1]
+	if months > 0 {
+		if seconds > 0 || (months != 1 && months != 12) {
+			return nil, fmt.Errorf("invalid duration %s for bucket, months and years can only be used as P1M or P1Y", value)
+		}
+
+		return &ApplyInterval{Months: months}, nil
+	}
+
+	if seconds == 0 {
+		return nil, fmt.Errorf("invalid duration %s for bucket, the duration has to be positive", value)
+	}
+
+	return &ApplyInterval{Seconds: seconds}, nil
+}
+
+// containsString returns true when the list contains the value
+func containsString(list []string, value string) bool {
+	for _, l := range list {
+		if l == value {
+			return true
+		}
+	}
+
+	return false
+}
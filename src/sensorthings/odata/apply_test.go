@@ -0,0 +1,74 @@
+package odata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseApply(t *testing.T) {
+	//arrange
+	apply := QueryApply{}
+
+	//act
+	err := apply.Parse("filter(result gt 0)/groupby((Datastream/id,bucket(phenomenonTime,'PT1H') as hour),aggregate(result with average as avg,$count as n))")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, QueryOptionApply, apply.GetQueryOptionType())
+	assert.Len(t, apply.Filters(), 1)
+	aggregation := apply.Aggregation()
+	assert.Equal(t, ApplyTransformationGroupBy, aggregation.Type)
+	assert.Equal(t, "Datastream/id", aggregation.GroupBy[0].Alias)
+	assert.Equal(t, 3600, aggregation.GroupBy[1].Interval.Seconds)
+	assert.Equal(t, ApplyMethodAverage, aggregation.Aggregates[0].Method)
+	assert.Equal(t, ApplyMethodCount, aggregation.Aggregates[1].Method)
+	assert.Equal(t, []string{"Datastream/id", "hour", "avg", "n"}, apply.Aliases())
+}
+
+func TestParseFailApply(t *testing.T) {
+	//arrange
+	apply := QueryApply{}
+
+	//act
+	errFilterOnly := apply.Parse("filter(result gt 0)")
+	errOrder := (&QueryApply{}).Parse("aggregate(result with sum as s)/filter(result gt 0)")
+	errMethod := (&QueryApply{}).Parse("aggregate(result with median as m)")
+	errAlias := (&QueryApply{}).Parse("groupby((Datastream/id as a),aggregate(result with sum as a))")
+	errUnknown := (&QueryApply{}).Parse("compute(result as r)")
+
+	//assert
+	assert.NotNil(t, errFilterOnly)
+	assert.NotNil(t, errOrder)
+	assert.NotNil(t, errMethod)
+	assert.NotNil(t, errAlias)
+	assert.NotNil(t, errUnknown)
+}
+
+func TestParseApplyInterval(t *testing.T) {
+	//act
+	day, _ := parseApplyInterval("P1D")
+	minutes, _ := parseApplyInterval("PT15M")
+	month, _ := parseApplyInterval("P1M")
+	_, errMixed := parseApplyInterval("P1MT1H")
+	_, errEmpty := parseApplyInterval("PT")
+
+	//assert
+	assert.Equal(t, 86400, day.Seconds)
+	assert.Equal(t, 900, minutes.Seconds)
+	assert.Equal(t, 1, month.Months)
+	assert.NotNil(t, errMixed)
+	assert.NotNil(t, errEmpty)
+}
+
+func TestApplyShouldNotBeCombinedWithFilter(t *testing.T) {
+	//act
+	_, errs := CreateQueryOptions(map[string]string{"$apply": "aggregate(result with sum as s)", "$filter": "result gt 0"})
+	_, errsOrderBy := CreateQueryOptions(map[string]string{"$apply": "aggregate(result with sum as s)", "$orderby": "result"})
+	_, errsAlias := CreateQueryOptions(map[string]string{"$apply": "aggregate(result with sum as s)", "$orderby": "s desc"})
+
+	//assert
+	assert.NotEmpty(t, errs)
+	assert.NotEmpty(t, errsOrderBy)
+	assert.Empty(t, errsAlias)
+}
@@ -30,6 +30,12 @@ func (q *QueryCount) Parse(value string) error {
 	return nil
 }
 
+// IsTrue returns true when $count=true is requested, the total count of a collection
+// is only retrieved and returned in that case
+func (q *QueryCount) IsTrue() bool {
+	return !q.IsNil() && q.count
+}
+
 // IsValid always returns true, errors are already filtered out by parse
 func (q *QueryCount) IsValid() (bool, error) {
 	return true, nil
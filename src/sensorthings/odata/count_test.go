@@ -40,6 +40,20 @@ func TestParseFailCount(t *testing.T) {
 	assert.Equal(t, false, qOptCount.count, "QueryCount.count should have been false")
 }
 
+func TestIsTrueCount(t *testing.T) {
+	//arrange
+	qo := QueryOptions{}
+	qCountFalse := &QueryCount{}
+	qCountFalse.Parse("false")
+	qCountTrue := &QueryCount{}
+	qCountTrue.Parse("true")
+
+	//assert
+	assert.False(t, qo.QueryCount.IsTrue(), "nil QueryCount should not request a count")
+	assert.False(t, qCountFalse.IsTrue())
+	assert.True(t, qCountTrue.IsTrue())
+}
+
 func TestIsNilTrueCount(t *testing.T) {
 	//arrange
 	qo := QueryOptions{}
@@ -2,67 +2,95 @@ package odata
 
 import (
 	"fmt"
-	"github.com/geodan/gost/src/sensorthings/entities"
 	"net/http"
 	"strings"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
 )
 
-// ExpandOperation holds information on a received $expand query
+// ExpandOperation holds information on a received $expand query, Name is the requested navigation
+// property such as Datastreams, the query options contain the $filter, $select, $orderby, $top, $skip
+// and $count for the expanded entities and a QueryExpand when the expand continues to a deeper level
 type ExpandOperation struct {
-	Entity          entities.Entity
-	QueryOptions    *QueryOptions
-	ExpandOperation *ExpandOperation
+	Name         string
+	Entity       entities.Entity
+	QueryOptions *QueryOptions
 }
 
-// Create tries to construct the ExpandOperation from given query string
+// Create tries to construct the ExpandOperation from given query string, a path such as
+// Datastreams/Observations($top=5) is turned into an expand of Observations inside the
+// QueryOptions of Datastreams
 func (e *ExpandOperation) Create(eo string) []error {
-	slashIndex := strings.Index(eo, "/")
-	var fp, trail string
-
-	if slashIndex != -1 {
-		trail = eo[slashIndex+1:]
-		fp = eo[:slashIndex]
-	} else {
-		fp = eo
+	path, err := splitOutsideParentheses(eo, '/')
+	if err != nil {
+		return []error{CreateQueryError(QueryExpandInvalid, http.StatusBadRequest, eo, err.Error())}
 	}
 
+	fp := strings.TrimSpace(path[0])
 	var queryString string
 	queryIndexStart := strings.Index(fp, "(")
 	if queryIndexStart != -1 {
+		if !strings.HasSuffix(fp, ")") {
+			return []error{CreateQueryError(QueryExpandInvalid, http.StatusBadRequest, eo, "missing closing parenthesis")}
+		}
+
 		queryString = fp[queryIndexStart+1 : len(fp)-1]
-		fp = fp[:queryIndexStart]
+		fp = strings.TrimSpace(fp[:queryIndexStart])
 	}
 
 	et, err := entities.EntityFromString(fp)
 	if err != nil {
-		return []error{fmt.Errorf("Unable to create expand query, unknown entity %s", fp)}
+		return []error{CreateQueryError(QueryExpandInvalid, http.StatusBadRequest, eo, fmt.Sprintf("unknown entity %s", fp))}
 	}
 
+	e.Name = fp
 	e.Entity = et
 
-	if len(queryString) > 0 {
-		splitQuery := strings.Split(queryString, ";")
-		values := map[string]string{}
+	if len(strings.TrimSpace(queryString)) > 0 {
+		splitQuery, err := splitOutsideParentheses(queryString, ';')
+		if err != nil {
+			return []error{CreateQueryError(QueryExpandInvalid, http.StatusBadRequest, eo, err.Error())}
+		}
 
+		values := map[string]string{}
 		for _, q := range splitQuery {
-			kvp := strings.Split(q, "=")
-			if len(kvp) != 2 {
-				return []error{fmt.Errorf("Invalid query (%s) inside $expand %s", queryString, e.Entity.GetEntityType().ToString())}
+			kvp := strings.SplitN(q, "=", 2)
+			if len(kvp) != 2 || len(strings.TrimSpace(kvp[0])) == 0 {
+				return []error{CreateQueryError(QueryExpandInvalid, http.StatusBadRequest, eo, fmt.Sprintf("invalid query %s inside $expand of %s", q, fp))}
 			}
-			values[kvp[0]] = kvp[1]
+
+			values[strings.TrimSpace(kvp[0])] = kvp[1]
 		}
 
-		qo, err := CreateQueryOptions(values)
-		if err != nil {
-			return err
+		qo, errs := CreateQueryOptions(values)
+		if errs != nil {
+			return errs
+		}
+
+		if !qo.QueryApply.IsNil() {
+			return []error{CreateQueryError(QueryExpandInvalid, http.StatusBadRequest, eo, "$apply cannot be used inside $expand")}
 		}
+
 		e.QueryOptions = qo
 	}
 
-	if len(trail) > 0 {
+	if len(path) > 1 {
+		trail := strings.Join(path[1:], "/")
 		neo := &ExpandOperation{}
-		neo.Create(trail)
-		e.ExpandOperation = neo
+		if errs := neo.Create(trail); errs != nil {
+			return errs
+		}
+
+		if e.QueryOptions == nil {
+			e.QueryOptions = &QueryOptions{}
+		}
+
+		if e.QueryOptions.QueryExpand == nil {
+			e.QueryOptions.QueryExpand = &QueryExpand{}
+		}
+
+		e.QueryOptions.QueryExpand.add(neo)
+		e.QueryOptions.QueryExpand.RawQuery = e.QueryOptions.QueryExpand.String()
 	}
 
 	return nil
@@ -73,58 +101,151 @@ func (e *ExpandOperation) Create(eo string) []error {
 type QueryExpand struct {
 	QueryBase
 	Params     []string
-	Operations []ExpandOperation
+	Operations []*ExpandOperation
 }
 
-// Parse splits the given values by the , delimiter and stores the params, if the delimiter is not
-// a comma the IsValid will filter it out later on
+// Parse splits the given values by the , delimiter and stores the params, commas inside the query
+// options of an expanded entity are ignored. Params holds the names of the expanded navigation
+// properties, if an unknown name is given IsValid will filter it out later on
 func (q *QueryExpand) Parse(value string) error {
 	q.RawQuery = value
-	q.Params = strings.Split(value, ",")
 
-	l1 := strings.Split(value, ",") // split layer 1, for example $expand=Observations/Things,Sensor,ObservedProperty
+	l1, err := splitOutsideParentheses(value, ',') // split layer 1, for example $expand=Datastreams/Observations($top=5),Locations
+	if err != nil {
+		return CreateQueryError(QueryExpandInvalid, http.StatusBadRequest, value, err.Error())
+	}
+
 	for _, sl1 := range l1 {
-		eo := ExpandOperation{}
+		q.addParam(expandName(sl1))
+	}
+
+	for _, sl1 := range l1 {
+		eo := &ExpandOperation{}
 		if err := eo.Create(sl1); err != nil {
 			return err[0]
 		}
 
-		q.Operations = append(q.Operations, eo)
+		q.add(eo)
 	}
 
 	return nil
 }
 
-// debug
-func displayExpandOperation(e ExpandOperation) {
-	fmt.Println("====== top level expand: " + e.Entity.GetEntityType().ToString() + " ======")
-	printQueries(e.QueryOptions)
-	if e.ExpandOperation != nil {
-		displayExpandOperationLower(e.ExpandOperation, 1)
+// add adds an operation to the expand, an operation on an already expanded navigation property
+// is merged into the existing operation so that Datastreams/Sensor,Datastreams/Thing results
+// in one expand of Datastreams
+func (q *QueryExpand) add(eo *ExpandOperation) {
+	q.addParam(eo.Name)
+	for _, o := range q.Operations {
+		if strings.ToLower(o.Name) == strings.ToLower(eo.Name) {
+			o.merge(eo)
+			return
+		}
+	}
+
+	q.Operations = append(q.Operations, eo)
+}
+
+// merge adds the nested expands of eo to the operation, the other query options
+// of eo are used when the operation does not define query options itself
+func (e *ExpandOperation) merge(eo *ExpandOperation) {
+	if eo.QueryOptions == nil {
+		return
+	}
+
+	if e.QueryOptions == nil {
+		e.QueryOptions = eo.QueryOptions
+		return
+	}
+
+	nested := eo.QueryOptions.QueryExpand
+	if !e.QueryOptions.hasOptions() {
+		expand := e.QueryOptions.QueryExpand
+		e.QueryOptions = eo.QueryOptions
+		e.QueryOptions.QueryExpand = expand
+	}
+
+	if nested == nil {
+		return
+	}
+
+	if e.QueryOptions.QueryExpand == nil {
+		e.QueryOptions.QueryExpand = &QueryExpand{}
+	}
+
+	for _, no := range nested.Operations {
+		e.QueryOptions.QueryExpand.add(no)
+	}
+
+	e.QueryOptions.QueryExpand.RawQuery = e.QueryOptions.QueryExpand.String()
+}
+
+// String returns the expand as it can be used in a request, used to create the $expand of
+// a nested expand which has no raw query of its own
+func (q *QueryExpand) String() string {
+	operations := make([]string, len(q.Operations))
+	for i, o := range q.Operations {
+		operations[i] = o.String()
 	}
+
+	return strings.Join(operations, ",")
 }
 
-func displayExpandOperationLower(e *ExpandOperation, level int) {
-	fmt.Printf("--- sub expand %v: %s ---\n", level, e.Entity.GetEntityType().ToString())
-	printQueries(e.QueryOptions)
+// String returns the name of the operation followed by its query options between parentheses
+func (e *ExpandOperation) String() string {
+	qo := e.QueryOptions
+	if qo == nil {
+		return e.Name
+	}
+
+	options := []string{}
+	if !qo.QueryFilter.IsNil() {
+		options = append(options, fmt.Sprintf("%v=%v", QueryOptionFilter.String(), qo.QueryFilter.RawQuery))
+	}
+	if !qo.QuerySelect.IsNil() {
+		options = append(options, fmt.Sprintf("%v=%v", QueryOptionSelect.String(), qo.QuerySelect.RawQuery))
+	}
+	if !qo.QueryExpand.IsNil() {
+		options = append(options, fmt.Sprintf("%v=%v", QueryOptionExpand.String(), qo.QueryExpand.RawQuery))
+	}
+	if !qo.QueryOrderBy.IsNil() {
+		options = append(options, fmt.Sprintf("%v=%v", QueryOptionOrderBy.String(), qo.QueryOrderBy.RawQuery))
+	}
+	if !qo.QueryTop.IsNil() {
+		options = append(options, fmt.Sprintf("%v=%v", QueryOptionTop.String(), qo.QueryTop.RawQuery))
+	}
+	if !qo.QuerySkip.IsNil() {
+		options = append(options, fmt.Sprintf("%v=%v", QueryOptionSkip.String(), qo.QuerySkip.RawQuery))
+	}
+	if !qo.QueryCount.IsNil() {
+		options = append(options, fmt.Sprintf("%v=%v", QueryOptionCount.String(), qo.QueryCount.RawQuery))
+	}
+
+	if len(options) == 0 {
+		return e.Name
+	}
+
+	return fmt.Sprintf("%s(%s)", e.Name, strings.Join(options, ";"))
 }
 
-func printQueries(qo *QueryOptions) {
-	if qo != nil {
-		fmt.Println(" Queries:")
-		test2 := *qo.QuerySelect
-		fmt.Println("    " + test2.RawQuery)
-	} else {
-		fmt.Println(" No queries defined")
+// addParam adds a name to the params when it was not added yet
+func (q *QueryExpand) addParam(name string) {
+	for _, p := range q.Params {
+		if strings.ToLower(p) == strings.ToLower(name) {
+			return
+		}
 	}
+
+	q.Params = append(q.Params, name)
 }
 
-// IsValid checks if the endpoint supports the expand params given by the user
+// IsValid checks if the endpoint supports the expand params given by the user, the query options
+// of the expanded entities are validated by the endpoint of the expanded entity
 func (q *QueryExpand) IsValid(values []string, endpointName string) (bool, error) {
 	for _, value := range q.Params {
 		found := false
 		for _, param := range values {
-			if param == value {
+			if strings.ToLower(param) == strings.ToLower(value) {
 				found = true
 				break
 			}
@@ -151,3 +272,51 @@ func (q *QueryExpand) IsNil() bool {
 
 	return false
 }
+
+// hasOptions returns true when one of the query options, other than $expand, is set
+func (qo *QueryOptions) hasOptions() bool {
+	return !qo.QueryTop.IsNil() || !qo.QuerySkip.IsNil() || !qo.QuerySelect.IsNil() || !qo.QueryOrderBy.IsNil() ||
+		!qo.QueryCount.IsNil() || !qo.QueryFilter.IsNil() || !qo.QueryResultFormat.IsNil()
+}
+
+// expandName returns the navigation property of an expand, Datastreams/Observations($top=5) returns Datastreams
+func expandName(value string) string {
+	name := strings.TrimSpace(value)
+	if i := strings.IndexAny(name, "/("); i != -1 {
+		name = strings.TrimSpace(name[:i])
+	}
+
+	return name
+}
+
+// splitOutsideParentheses splits the value on the separator, separators inside parentheses
+// or quoted strings are not used to split. Returns an error when the parentheses are unbalanced
+func splitOutsideParentheses(value string, separator rune) ([]string, error) {
+	parts := []string{}
+	depth := 0
+	quoted := false
+	start := 0
+	for i, c := range value {
+		switch {
+		case c == '\'':
+			quoted = !quoted
+		case quoted:
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("unexpected ) at position %v", i)
+			}
+		case c == separator && depth == 0:
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+
+	if depth != 0 || quoted {
+		return nil, fmt.Errorf("missing closing parenthesis or quote")
+	}
+
+	return append(parts, value[start:]), nil
+}
@@ -53,3 +53,59 @@ func TestExpandisNilNotShouldReturnNotNil(t *testing.T) {
 	//assert
 	assert.False(t, res, "Expand should not be nil")
 }
+
+func TestExpandParseShouldCreateNestedOperations(t *testing.T) {
+	//arrange
+	expand := QueryExpand{}
+
+	//act
+	err := expand.Parse("Datastreams/Observations($top=5;$orderby=phenomenonTime desc),Locations($select=name,location)")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Datastreams", "Locations"}, expand.Params)
+	assert.Len(t, expand.Operations, 2)
+	assert.Equal(t, "Locations", expand.Operations[1].Name)
+	assert.Equal(t, []string{"name", "location"}, expand.Operations[1].QueryOptions.QuerySelect.Params)
+
+	nested := expand.Operations[0].QueryOptions.QueryExpand
+	assert.NotNil(t, nested)
+	assert.Equal(t, []string{"Observations"}, nested.Params)
+	assert.Equal(t, 5, nested.Operations[0].QueryOptions.QueryTop.Limit)
+	assert.Equal(t, "desc", nested.Operations[0].QueryOptions.QueryOrderBy.Params[0].Suffix)
+	assert.Equal(t, "Observations($orderby=phenomenonTime desc;$top=5)", nested.RawQuery)
+}
+
+func TestExpandParseShouldMergeOperationsOnSameNavigation(t *testing.T) {
+	//arrange
+	expand := QueryExpand{}
+
+	//act
+	err := expand.Parse("Datastreams/Sensor,Datastreams($top=2)/Thing,Datastreams/Observations($expand=FeatureOfInterest)")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Len(t, expand.Operations, 1)
+	qo := expand.Operations[0].QueryOptions
+	assert.Equal(t, 2, qo.QueryTop.Limit)
+	assert.Equal(t, []string{"Sensor", "Thing", "Observations"}, qo.QueryExpand.Params)
+	assert.Equal(t, []string{"FeatureOfInterest"}, qo.QueryExpand.Operations[2].QueryOptions.QueryExpand.Params)
+}
+
+func TestExpandParseShouldReturnNestedErrors(t *testing.T) {
+	//arrange
+	expandTop := QueryExpand{}
+	expandEntity := QueryExpand{}
+	expandParentheses := QueryExpand{}
+
+	//act
+	errTop := expandTop.Parse("Datastreams/Observations($top=-1)")
+	errEntity := expandEntity.Parse("Datastreams/Unknown")
+	errParentheses := expandParentheses.Parse("Datastreams($top=1")
+
+	//assert
+	assert.NotNil(t, errTop)
+	assert.NotNil(t, errEntity)
+	assert.Contains(t, errEntity.Error(), "Unknown")
+	assert.NotNil(t, errParentheses)
+}
@@ -5,18 +5,22 @@ import (
 )
 
 // QueryFilter is used to perform conditional operations on the parameter values
-// Count is used to retrieve the total number of items in a collection matching the requested entity.
+// Expression holds the parsed filter, translate it to the used storage to apply the filter
 type QueryFilter struct {
 	QueryBase
-	Predicate *Predicate
+	Expression Expression
 }
 
-// Parse tries to parse the given filter
+// Parse tries to parse the given filter into an expression tree
 func (q *QueryFilter) Parse(value string) error {
 	var err error
 	q.RawQuery = value
-	q.Predicate, err = ParseODATAFilter(value)
+	q.Expression, err = ParseODATAFilter(value)
 	if err != nil {
+		if syntaxError, ok := err.(*FilterSyntaxError); ok {
+			return CreateQueryError(QueryFilterSyntaxError, http.StatusBadRequest, value, syntaxError.Error())
+		}
+
 		return CreateQueryError(QueryfilterFormatInvalid, http.StatusBadRequest, value)
 	}
 
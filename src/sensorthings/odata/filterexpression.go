@@ -0,0 +1,132 @@
+package odata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Expression is a node in the tree created by parsing a $filter query
+type Expression interface {
+	// Position returns the offset of the expression inside the original filter string
+	Position() int
+	String() string
+}
+
+// LiteralType describes the type of a constant value inside a filter expression
+type LiteralType int
+
+// List of all supported literal types
+const (
+	LiteralString LiteralType = iota
+	LiteralNumber
+	LiteralBoolean
+	LiteralNull
+	LiteralDateTime
+	LiteralGeometry
+)
+
+// LiteralExpression is a constant value such as 'text', 10.5, true, null, 2016-01-01T00:00:00Z or
+// geography'POINT(5 52)', Value holds a string, float64, bool or nil depending on the LiteralType,
+// the Value of a LiteralGeometry is the WKT of the geometry
+type LiteralExpression struct {
+	Type  LiteralType
+	Value interface{}
+	Raw   string
+	Pos   int
+}
+
+// Position returns the offset of the literal inside the filter string
+func (l *LiteralExpression) Position() int {
+	return l.Pos
+}
+
+// String returns the literal as it was written in the filter
+func (l *LiteralExpression) String() string {
+	if l.Type == LiteralString {
+		return fmt.Sprintf("'%s'", strings.Replace(l.Raw, "'", "''", -1))
+	}
+	if l.Type == LiteralGeometry {
+		return fmt.Sprintf("geography'%s'", l.Raw)
+	}
+
+	return l.Raw
+}
+
+// PropertyExpression references a property of the requested entity, a path
+// of more than one element navigates through related entities: Datastream/Thing/name
+type PropertyExpression struct {
+	Path []string
+	Pos  int
+}
+
+// Position returns the offset of the property inside the filter string
+func (p *PropertyExpression) Position() int {
+	return p.Pos
+}
+
+// String returns the property path separated by /
+func (p *PropertyExpression) String() string {
+	return strings.Join(p.Path, "/")
+}
+
+// Name returns the last element of the property path
+func (p *PropertyExpression) Name() string {
+	return p.Path[len(p.Path)-1]
+}
+
+// UnaryExpression applies an Operator to a single operand, for example: not (id eq 1)
+type UnaryExpression struct {
+	Operator Operator
+	Operand  Expression
+	Pos      int
+}
+
+// Position returns the offset of the operator inside the filter string
+func (u *UnaryExpression) Position() int {
+	return u.Pos
+}
+
+// String returns the expression in a fully parenthesized form
+func (u *UnaryExpression) String() string {
+	return fmt.Sprintf("%s (%s)", u.Operator, u.Operand)
+}
+
+// BinaryExpression applies an Operator to a left and right operand, for example: id gt 10
+type BinaryExpression struct {
+	Operator Operator
+	Left     Expression
+	Right    Expression
+	Pos      int
+}
+
+// Position returns the offset of the operator inside the filter string
+func (b *BinaryExpression) Position() int {
+	return b.Pos
+}
+
+// String returns the expression in a fully parenthesized form
+func (b *BinaryExpression) String() string {
+	return fmt.Sprintf("(%s %s %s)", b.Left, b.Operator, b.Right)
+}
+
+// FunctionExpression is a call to one of the supported OData functions, for example: startswith(name, 'a')
+type FunctionExpression struct {
+	Name      string
+	Arguments []Expression
+	Pos       int
+}
+
+// Position returns the offset of the function name inside the filter string
+func (f *FunctionExpression) Position() int {
+	return f.Pos
+}
+
+// String returns the function call with its arguments
+func (f *FunctionExpression) String() string {
+	args := make([]string, len(f.Arguments))
+	for i, a := range f.Arguments {
+		args[i] = a.String()
+	}
+
+	return fmt.Sprintf("%s(%s)", f.Name, strings.Join(args, ", "))
+}
@@ -0,0 +1,185 @@
+package odata
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ValueType describes the type of the value an expression results in
+type ValueType int
+
+// List of all value types known inside a filter expression
+const (
+	ValueTypeAny ValueType = iota // type is unknown when parsing, for example the value of a property
+	ValueTypeString
+	ValueTypeNumber
+	ValueTypeBoolean
+	ValueTypeDateTime
+	ValueTypeTime
+	ValueTypeGeometry
+)
+
+// ToString returns the name of the ValueType as used in error messages
+func (v ValueType) ToString() string {
+	switch v {
+	case ValueTypeString:
+		return "string"
+	case ValueTypeNumber:
+		return "number"
+	case ValueTypeBoolean:
+		return "boolean"
+	case ValueTypeDateTime:
+		return "datetime"
+	case ValueTypeTime:
+		return "time"
+	case ValueTypeGeometry:
+		return "geometry"
+	}
+
+	return "any"
+}
+
+// Accepts returns true if a value of type t can be used where a value of type v is expected
+func (v ValueType) Accepts(t ValueType) bool {
+	return v == ValueTypeAny || t == ValueTypeAny || v == t
+}
+
+// FunctionSignature describes the argument types and result type of a filter function
+type FunctionSignature struct {
+	Arguments []ValueType
+	Result    ValueType
+}
+
+// FunctionSignatures maps a function name to its signatures, a function with optional
+// arguments such as substring has a signature for every supported number of arguments
+var FunctionSignatures = map[string][]FunctionSignature{
+	OSSubstringOf.ToString(): {{Arguments: []ValueType{ValueTypeString, ValueTypeString}, Result: ValueTypeBoolean}},
+	OSEndsWith.ToString():    {{Arguments: []ValueType{ValueTypeString, ValueTypeString}, Result: ValueTypeBoolean}},
+	OSStartsWith.ToString():  {{Arguments: []ValueType{ValueTypeString, ValueTypeString}, Result: ValueTypeBoolean}},
+	OSContains.ToString():    {{Arguments: []ValueType{ValueTypeString, ValueTypeString}, Result: ValueTypeBoolean}},
+	OSLength.ToString():      {{Arguments: []ValueType{ValueTypeString}, Result: ValueTypeNumber}},
+	OSIndexOf.ToString():     {{Arguments: []ValueType{ValueTypeString, ValueTypeString}, Result: ValueTypeNumber}},
+	OSSubstring.ToString(): {
+		{Arguments: []ValueType{ValueTypeString, ValueTypeNumber}, Result: ValueTypeString},
+		{Arguments: []ValueType{ValueTypeString, ValueTypeNumber, ValueTypeNumber}, Result: ValueTypeString},
+	},
+	OSToLower.ToString(): {{Arguments: []ValueType{ValueTypeString}, Result: ValueTypeString}},
+	OSToUpper.ToString(): {{Arguments: []ValueType{ValueTypeString}, Result: ValueTypeString}},
+	OSTrim.ToString():    {{Arguments: []ValueType{ValueTypeString}, Result: ValueTypeString}},
+	OSConcat.ToString():  {{Arguments: []ValueType{ValueTypeString, ValueTypeString}, Result: ValueTypeString}},
+
+	ODYear.ToString():              {{Arguments: []ValueType{ValueTypeDateTime}, Result: ValueTypeNumber}},
+	ODMonth.ToString():             {{Arguments: []ValueType{ValueTypeDateTime}, Result: ValueTypeNumber}},
+	ODDay.ToString():               {{Arguments: []ValueType{ValueTypeDateTime}, Result: ValueTypeNumber}},
+	ODHour.ToString():              {{Arguments: []ValueType{ValueTypeDateTime}, Result: ValueTypeNumber}},
+	ODMinute.ToString():            {{Arguments: []ValueType{ValueTypeDateTime}, Result: ValueTypeNumber}},
+	ODSecond.ToString():            {{Arguments: []ValueType{ValueTypeDateTime}, Result: ValueTypeNumber}},
+	ODFractionalSeconds.ToString(): {{Arguments: []ValueType{ValueTypeDateTime}, Result: ValueTypeNumber}},
+	ODDate.ToString():              {{Arguments: []ValueType{ValueTypeDateTime}, Result: ValueTypeDateTime}},
+	ODTime.ToString():              {{Arguments: []ValueType{ValueTypeDateTime}, Result: ValueTypeTime}},
+	ODNow.ToString():               {{Arguments: []ValueType{}, Result: ValueTypeDateTime}},
+	ODMinDateTime.ToString():       {{Arguments: []ValueType{}, Result: ValueTypeDateTime}},
+	ODMaxDateTime.ToString():       {{Arguments: []ValueType{}, Result: ValueTypeDateTime}},
+
+	OMRound.ToString():   {{Arguments: []ValueType{ValueTypeNumber}, Result: ValueTypeNumber}},
+	OMFloor.ToString():   {{Arguments: []ValueType{ValueTypeNumber}, Result: ValueTypeNumber}},
+	OMCeiling.ToString(): {{Arguments: []ValueType{ValueTypeNumber}, Result: ValueTypeNumber}},
+
+	OGSDistance.ToString():   {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeNumber}},
+	OGSLength.ToString():     {{Arguments: []ValueType{ValueTypeGeometry}, Result: ValueTypeNumber}},
+	OGSIntersects.ToString(): {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeBoolean}},
+
+	OSREquals.ToString():     {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeBoolean}},
+	OSRDisjoint.ToString():   {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeBoolean}},
+	OSRTouches.ToString():    {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeBoolean}},
+	OSRWithin.ToString():     {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeBoolean}},
+	OSROverlaps.ToString():   {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeBoolean}},
+	OSRCrosses.ToString():    {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeBoolean}},
+	OSRIntersects.ToString(): {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeBoolean}},
+	OSRContains.ToString():   {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry}, Result: ValueTypeBoolean}},
+	OSRRelate.ToString():     {{Arguments: []ValueType{ValueTypeGeometry, ValueTypeGeometry, ValueTypeString}, Result: ValueTypeBoolean}},
+}
+
+// ExpressionType returns the type of the value the given expression results in,
+// ValueTypeAny is returned when the type can only be determined by the storage
+func ExpressionType(e Expression) ValueType {
+	switch t := e.(type) {
+	case *LiteralExpression:
+		switch t.Type {
+		case LiteralString:
+			return ValueTypeString
+		case LiteralNumber:
+			return ValueTypeNumber
+		case LiteralBoolean:
+			return ValueTypeBoolean
+		case LiteralDateTime:
+			return ValueTypeDateTime
+		case LiteralGeometry:
+			return ValueTypeGeometry
+		}
+	case *UnaryExpression:
+		return ValueTypeBoolean
+	case *BinaryExpression:
+		if t.Operator.IsLogical() || t.Operator.IsComparison() {
+			return ValueTypeBoolean
+		}
+		if t.Operator.IsArithmetic() {
+			return ValueTypeNumber
+		}
+	case *FunctionExpression:
+		if s := matchSignature(t); s != nil {
+			return s.Result
+		}
+	}
+
+	return ValueTypeAny
+}
+
+// matchSignature returns the signature of the function with the same number of arguments
+func matchSignature(f *FunctionExpression) *FunctionSignature {
+	for _, s := range FunctionSignatures[f.Name] {
+		if len(s.Arguments) == len(f.Arguments) {
+			return &s
+		}
+	}
+
+	return nil
+}
+
+// checkFunctionArguments validates the number and types of the arguments given to a function,
+// functions without a known signature are not checked
+func checkFunctionArguments(f *FunctionExpression) error {
+	signatures, ok := FunctionSignatures[f.Name]
+	if !ok {
+		return nil
+	}
+
+	s := matchSignature(f)
+	if s == nil {
+		counts := make([]string, len(signatures))
+		for i, sig := range signatures {
+			counts[i] = fmt.Sprintf("%v", len(sig.Arguments))
+		}
+
+		return newFilterSyntaxError(f.Pos, "Function %s expects %s arguments but got %v", f.Name, strings.Join(counts, " or "), len(f.Arguments))
+	}
+
+	for i, a := range f.Arguments {
+		if t := ExpressionType(a); !s.Arguments[i].Accepts(t) {
+			return newFilterSyntaxError(a.Position(), "Argument %v of %s should be a %s but got a %s", i+1, f.Name, s.Arguments[i].ToString(), t.ToString())
+		}
+	}
+
+	return nil
+}
+
+// checkArithmeticOperands validates that both operands of an arithmetic operator can be numeric
+func checkArithmeticOperands(b *BinaryExpression) error {
+	for _, operand := range []Expression{b.Left, b.Right} {
+		if t := ExpressionType(operand); !ValueTypeNumber.Accepts(t) {
+			return newFilterSyntaxError(operand.Position(), "Operator %s expects a number but got a %s", b.Operator, t.ToString())
+		}
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // Operator are the supporter ODATA operators
@@ -48,7 +49,7 @@ func (o Operator) IsLogical() bool {
 // IsUnary returns whether a defined operation is unary or binary.  Will return true
 // if the operation only supports a subject with no value.
 func (o Operator) IsUnary() bool {
-	if o == IsNull {
+	if o == IsNull || o == Not {
 		return true
 	}
 
@@ -78,199 +79,349 @@ var ODATAOperators = map[string]Operator{
 	IsNull.ToString():              IsNull,
 
 	// arithmetic
-	Addition.ToString():       LessThanOrEquals,
+	Addition.ToString():       Addition,
 	Subtraction.ToString():    Subtraction,
 	Multiplication.ToString(): Multiplication,
 	Division.ToString():       Division,
 	Modulo.ToString():         Modulo,
 }
 
-// Predicate is the basic model construct of the odata expression
-type Predicate struct {
-	Left     interface{}
-	Right    interface{}
-	Operator Operator
+// IsArithmetic returns whether a defined operation calculates a numeric value
+func (o Operator) IsArithmetic() bool {
+	switch o {
+	case Addition, Subtraction, Multiplication, Division, Modulo:
+		return true
+	}
+
+	return false
 }
 
-// Split separates the left and right predicates when containing a *Predicate
-// and returns a slice of predicates without a predicate inside left or right,
-// the predicates can be coupled with the logical operators inside the OdataOperator slice
-//
-//  predicate := ParseODATAFilter("id ge 10 and id lt 100 or user eq 'tim' and status 'active'")
-//  ps, ops := predicate.Split()
-//  for i, p := range ps {
-//  	log.Printf("%v <- %v -> %v", p.Left, p.Operator, p.Right)
-//  	if len(ops)-1 >= i {
-//  		log.Printf("--%v--", ops[i])
-//  	}
-//  }
+// IsComparison returns whether a defined operation compares two values
+func (o Operator) IsComparison() bool {
+	switch o {
+	case Equals, NotEquals, GreaterThan, GreaterThanOrEquals, LessThan, LessThanOrEquals:
+		return true
+	}
+
+	return false
+}
+
+var errorInvalidFilter = errors.New("Invalid filter")
+
+// ParseODATAFilter parses a filter string into an expression tree, operators are
+// parsed using the OData precedence rules (from low to high): or, and, not, comparison,
+// additive (add, sub) and multiplicative (mul, div, mod).
+// Parentheses can be used to group expressions, syntax errors are returned as
+// *FilterSyntaxError containing the position of the offending token
 //
-//  output = id <- ge -> 10 --and-- id <- lt -> 100 --or-- user <- eq -> 'tim' --and-- status <- eq -> 'active'
-func (p *Predicate) Split() ([]*Predicate, []Operator) {
-	var pr []*Predicate
-	predicates := &pr
+//	e, _ := ParseODATAFilter("id ge 10 and (name eq 'tim' or not startswith(name, 'a'))")
+//	e.String() = ((id ge 10) and ((name eq 'tim') or not (startswith(name, 'a'))))
+func ParseODATAFilter(filterStr string) (Expression, error) {
+	if len(strings.TrimSpace(filterStr)) == 0 {
+		return nil, errorInvalidFilter
+	}
+
+	tokens, err := TokenizeFilter(filterStr)
+	if err != nil {
+		return nil, err
+	}
 
-	var ops []Operator
-	operators := &ops
+	p := &filterParser{tokens: tokens}
+	e, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
 
-	if p.Operator.IsLogical() {
-		internalSplit(p, predicates, operators)
-	} else {
-		*predicates = append(*predicates, p)
+	if t := p.peek(); t.Type != TokenEOF {
+		return nil, newFilterSyntaxError(t.Position, "Unexpected '%s'", t.Value)
 	}
 
-	return pr, ops
+	return e, nil
+}
+
+// filterParser is a recursive descent parser working on the tokens of a $filter expression
+type filterParser struct {
+	tokens []Token
+	pos    int
+}
+
+func (p *filterParser) peek() Token {
+	return p.tokens[p.pos]
 }
 
-func internalSplit(p *Predicate, result *[]*Predicate, ops *[]Operator) {
-	if p.Operator.IsLogical() {
-		*ops = append(*ops, p.Operator)
-		internalSplit(p.Left.(*Predicate), result, ops)
-		internalSplit(p.Right.(*Predicate), result, ops)
-	} else {
-		*result = append(*result, p)
+func (p *filterParser) next() Token {
+	t := p.tokens[p.pos]
+	if t.Type != TokenEOF {
+		p.pos++
 	}
+
+	return t
 }
 
-// ParseODATAFilter parses a filter string into a predicate, simple implementation for now
-// ToDo: handle separators ( ) -> also in split function
-// ToDo: handle 'property/property' inside filter
-// ToDo: get the 'not' operator working
-// ToDo: check for valid operators -> return error
-// ToDo: handle arithmetic operators
-// ToDo: handle functions (string, date, math, geospatial, spatialRelationship)
-func ParseODATAFilter(filterStr string) (*Predicate, error) {
-	if len(filterStr) == 0 {
-		return nil, errorInvalidFilter
+// peekOperator returns the operator when the next token is one of the given operators
+func (p *filterParser) peekOperator(operators ...Operator) (Operator, bool) {
+	t := p.peek()
+	if t.Type != TokenIdentifier {
+		return "", false
 	}
 
-	filter := strings.TrimSpace(filterStr)
-	if len(filter) == 0 {
-		return nil, errorInvalidFilter
+	for _, o := range operators {
+		if strings.ToLower(t.Value) == o.ToString() {
+			return o, true
+		}
 	}
 
-	fragment, err := parseFragment(filter)
-	return fragment, err
+	return "", false
 }
 
-var odataRegex = map[string]string{
-	"regexParenthesis": "^([(](.*)[)])$",
-	"regexAndor":       "^(.*?) (or|and)+ (.*)$",
-	"regexOp":          "(\\w*) (eq|gt|lt|ge|le|ne) (datetimeoffset'(.*)'|'(.*)'|[0-9]*)",
-	"regexStartsWith":  "^startswith[(](.*),'(.*)'[)]",
-	"regexEndsWith":    "^endswith[(](.*),'(.*)'[)]",
-	"regexContains":    "^contains[(](.*),'(.*)'[)]",
+func (p *filterParser) expect(tokenType TokenType, value string) (Token, error) {
+	t := p.next()
+	if t.Type != tokenType {
+		if t.Type == TokenEOF {
+			return t, newFilterSyntaxError(t.Position, "Expected '%s' but reached end of filter", value)
+		}
+
+		return t, newFilterSyntaxError(t.Position, "Expected '%s' but found '%s'", value, t.Value)
+	}
+
+	return t, nil
 }
 
-var errorInvalidFilter = errors.New("Invalid filter")
+func (p *filterParser) parseOr() (Expression, error) {
+	return p.parseBinary(p.parseAnd, Or)
+}
+
+func (p *filterParser) parseAnd() (Expression, error) {
+	return p.parseBinary(p.parseNot, And)
+}
+
+// parseBinary parses a left associative chain of operands separated by one of the given operators
+func (p *filterParser) parseBinary(operand func() (Expression, error), operators ...Operator) (Expression, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
 
-func parseFragment(filter string) (*Predicate, error) {
-	var err error
-	found := false
-	predicate := &Predicate{}
+	for {
+		o, ok := p.peekOperator(operators...)
+		if !ok {
+			return left, nil
+		}
 
-	for k, regex := range odataRegex {
-		if found {
-			break
+		t := p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
 		}
 
-		r, _ := regexp.Compile(regex)
-		match := r.FindStringSubmatch(filter)
-
-		if len(match) > 0 {
-			if k == "regexParenthesis" {
-				if len(match) > 2 {
-					if strings.Index(match[2], ")") < strings.Index(match[2], "(") {
-						continue
-					}
-
-					if predicate, err = parseFragment(match[2]); err != nil {
-						return nil, errorInvalidFilter
-					}
-				}
-			} else if k == "regexAndor" {
-				if len(match) < 4 {
-					return nil, errorInvalidFilter
-				}
-
-				predicate = &Predicate{
-					Operator: ODATAOperators[match[2]],
-				}
-
-				if predicate.Left, err = parseFragment(match[1]); err != nil {
-					return nil, errorInvalidFilter
-				}
-
-				if predicate.Right, err = parseFragment(match[3]); err != nil {
-					return nil, errorInvalidFilter
-				}
-
-			} else if k == "regexOp" {
-				if len(match) < 4 {
-					return nil, errorInvalidFilter
-				}
-
-				var val interface{}
-
-				// if not string value
-				if strings.Index(match[3], "'") == -1 {
-					if val, err = strconv.ParseFloat(match[3], 64); err != nil {
-						val = fmt.Sprintf("'%v'", match[3])
-					}
-				} else {
-					val = match[3]
-				}
-
-				predicate = &Predicate{
-					Left:     match[1],
-					Operator: ODATAOperators[match[2]],
-					Right:    val,
-				}
-
-				/*if(predicate.Value.indexOf && predicate.Value.indexOf("datetimeoffset") == 0)
-				{
-					var m = predicate.Value.match(/^datetimeoffset'(.*)'$/);
-					if( m && m.length > 1) {
-						obj.value = new Date(m[1]);
-					}
-				}*/
-			} else if k == "regexStartsWith" || k == "regexEndsWith" || k == "regexContains" {
-				predicate, err = buildLike(match, k)
-				if err != nil {
-					return nil, err
-				}
+		b := &BinaryExpression{Operator: o, Left: left, Right: right, Pos: t.Position}
+		if o.IsArithmetic() {
+			if err = checkArithmeticOperands(b); err != nil {
+				return nil, err
 			}
+		}
 
-			found = true
+		left = b
+	}
+}
+
+func (p *filterParser) parseNot() (Expression, error) {
+	if _, ok := p.peekOperator(Not); ok {
+		t := p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
 		}
+
+		return &UnaryExpression{Operator: Not, Operand: operand, Pos: t.Position}, nil
 	}
 
-	return predicate, nil
+	return p.parseComparison()
 }
 
-func buildLike(match []string, key string) (*Predicate, error) {
-	if len(match) < 3 {
-		return nil, errorInvalidFilter
+func (p *filterParser) parseComparison() (Expression, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	o, ok := p.peekOperator(Equals, NotEquals, GreaterThan, GreaterThanOrEquals, LessThan, LessThanOrEquals)
+	if !ok {
+		return left, nil
+	}
+
+	t := p.next()
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	return &BinaryExpression{Operator: o, Left: left, Right: right, Pos: t.Position}, nil
+}
+
+func (p *filterParser) parseAdditive() (Expression, error) {
+	return p.parseBinary(p.parseMultiplicative, Addition, Subtraction)
+}
+
+func (p *filterParser) parseMultiplicative() (Expression, error) {
+	return p.parseBinary(p.parsePrimary, Multiplication, Division, Modulo)
+}
+
+func (p *filterParser) parsePrimary() (Expression, error) {
+	t := p.next()
+	switch t.Type {
+	case TokenOpenParen:
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err = p.expect(TokenCloseParen, ")"); err != nil {
+			return nil, err
+		}
+
+		return e, nil
+	case TokenString:
+		return &LiteralExpression{Type: LiteralString, Value: t.Value, Raw: t.Value, Pos: t.Position}, nil
+	case TokenNumber:
+		f, err := strconv.ParseFloat(t.Value, 64)
+		if err != nil {
+			return nil, newFilterSyntaxError(t.Position, "Invalid number %s", t.Value)
+		}
+
+		return &LiteralExpression{Type: LiteralNumber, Value: f, Raw: t.Value, Pos: t.Position}, nil
+	case TokenDateTime:
+		return parseDateTimeLiteral(t.Value, t.Position)
+	case TokenTypedLiteral:
+		return parseTypedLiteral(t)
+	case TokenIdentifier:
+		return p.parseIdentifier(t)
+	case TokenEOF:
+		return nil, newFilterSyntaxError(t.Position, "Unexpected end of filter")
+	}
+
+	return nil, newFilterSyntaxError(t.Position, "Unexpected '%s'", t.Value)
+}
+
+// parseIdentifier parses keywords, function calls and property paths
+func (p *filterParser) parseIdentifier(t Token) (Expression, error) {
+	lower := strings.ToLower(t.Value)
+	switch lower {
+	case "true", "false":
+		return &LiteralExpression{Type: LiteralBoolean, Value: lower == "true", Raw: lower, Pos: t.Position}, nil
+	case "null":
+		return &LiteralExpression{Type: LiteralNull, Value: nil, Raw: lower, Pos: t.Position}, nil
+	}
+
+	if _, ok := ODATAOperators[lower]; ok {
+		return nil, newFilterSyntaxError(t.Position, "Unexpected operator '%s'", t.Value)
+	}
+
+	if p.peek().Type == TokenOpenParen {
+		return p.parseFunction(t)
 	}
 
-	var right string
-	if key == "startsWith" {
-		right = match[2] + "*"
-	} else {
-		if key == "endsWith" {
-			right = "*" + match[2]
-		} else {
-			right = "*" + match[2] + "*"
+	path := strings.Split(t.Value, "/")
+	for _, segment := range path {
+		if len(segment) == 0 {
+			return nil, newFilterSyntaxError(t.Position, "Invalid property path '%s'", t.Value)
 		}
 	}
 
-	p := &Predicate{
-		Left:     match[1],
-		Operator: Like,
-		Right:    right,
+	return &PropertyExpression{Path: path, Pos: t.Position}, nil
+}
+
+func (p *filterParser) parseFunction(t Token) (Expression, error) {
+	name := strings.ToLower(t.Value)
+	if !IsFilterFunction(name) {
+		return nil, newFilterSyntaxError(t.Position, "Unknown function '%s'", t.Value)
 	}
 
-	return p, nil
+	p.next() // (
+	f := &FunctionExpression{Name: name, Pos: t.Position}
+	if p.peek().Type == TokenCloseParen {
+		p.next()
+		if err := checkFunctionArguments(f); err != nil {
+			return nil, err
+		}
+
+		return f, nil
+	}
+
+	for {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		f.Arguments = append(f.Arguments, arg)
+
+		n := p.next()
+		if n.Type == TokenCloseParen {
+			if err = checkFunctionArguments(f); err != nil {
+				return nil, err
+			}
+
+			return f, nil
+		}
+
+		if n.Type != TokenComma {
+			if n.Type == TokenEOF {
+				return nil, newFilterSyntaxError(n.Position, "Expected ')' but reached end of filter")
+			}
+
+			return nil, newFilterSyntaxError(n.Position, "Expected ',' or ')' but found '%s'", n.Value)
+		}
+	}
+}
+
+func parseDateTimeLiteral(value string, position int) (Expression, error) {
+	if _, err := time.Parse(time.RFC3339Nano, value); err != nil {
+		if _, err = time.Parse("2006-01-02", value); err != nil {
+			return nil, newFilterSyntaxError(position, "Invalid date time %s", value)
+		}
+	}
+
+	return &LiteralExpression{Type: LiteralDateTime, Value: value, Raw: value, Pos: position}, nil
+}
+
+// wktRegex matches well known text geometries with an optional SRID such as SRID=4326;POINT(5 52)
+var wktRegex = regexp.MustCompile(`(?i)^(SRID=\d+;)?\s*(POINT|LINESTRING|POLYGON|MULTIPOINT|MULTILINESTRING|MULTIPOLYGON|GEOMETRYCOLLECTION)\s*(Z|M|ZM)?\s*\(.*\)$`)
+
+func parseTypedLiteral(t Token) (Expression, error) {
+	switch t.Prefix {
+	case "datetimeoffset", "datetime":
+		return parseDateTimeLiteral(t.Value, t.Position)
+	case "geography", "geometry":
+		wkt := strings.TrimSpace(t.Value)
+		if !wktRegex.MatchString(wkt) {
+			return nil, newFilterSyntaxError(t.Position, "Invalid geometry %s", t.Value)
+		}
+
+		return &LiteralExpression{Type: LiteralGeometry, Value: wkt, Raw: wkt, Pos: t.Position}, nil
+	}
+
+	return nil, newFilterSyntaxError(t.Position, "Unsupported literal type '%s'", t.Prefix)
+}
+
+// IsFilterFunction returns true if the given name is a known OData function
+func IsFilterFunction(name string) bool {
+	if _, ok := StringFunctions[name]; ok {
+		return true
+	}
+	if _, ok := DateFunctions[name]; ok {
+		return true
+	}
+	if _, ok := MathFunctions[name]; ok {
+		return true
+	}
+	if _, ok := GeospatialFunctions[name]; ok {
+		return true
+	}
+	if _, ok := SpatialRelationshipFunctions[name]; ok {
+		return true
+	}
+
+	return false
 }
 
 // StringFunction are the supporter ODATA string functions
@@ -288,6 +439,7 @@ const (
 	OSToUpper     StringFunction = "toupper"
 	OSTrim        StringFunction = "trim"
 	OSConcat      StringFunction = "concat"
+	OSContains    StringFunction = "contains"
 )
 
 // ToString representation of a StringFunction
@@ -307,6 +459,7 @@ var StringFunctions = map[string]StringFunction{
 	OSToUpper.ToString():     OSToUpper,
 	OSTrim.ToString():        OSTrim,
 	OSConcat.ToString():      OSConcat,
+	OSContains.ToString():    OSContains,
 }
 
 // DateFunction are the supporter ODATA date functions
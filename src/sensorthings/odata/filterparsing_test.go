@@ -0,0 +1,228 @@
+package odata
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestParseODATAFilterShouldRespectPrecedence(t *testing.T) {
+	//arrange
+	filter := "id ge 10 and (name eq 'tim' or not startswith(name, 'a'))"
+
+	//act
+	e, err := ParseODATAFilter(filter)
+
+	//assert
+	assert.Nil(t, err, "Filter should be parsed without error")
+	assert.Equal(t, "((id ge 10) and ((name eq 'tim') or not (startswith(name, 'a'))))", e.String())
+}
+
+func TestParseODATAFilterAndShouldBindStrongerThanOr(t *testing.T) {
+	//act
+	e, err := ParseODATAFilter("a eq 1 or b eq 2 and c eq 3")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "((a eq 1) or ((b eq 2) and (c eq 3)))", e.String())
+}
+
+func TestParseODATAFilterShouldParseLiterals(t *testing.T) {
+	//act
+	e, err := ParseODATAFilter("phenomenonTime gt 2016-11-18T11:04:15.790Z and result eq -1.5 and valid eq true and name ne null and description eq 'it''s'")
+
+	//assert
+	assert.Nil(t, err)
+	literals := make([]*LiteralExpression, 0)
+	var walk func(Expression)
+	walk = func(e Expression) {
+		switch t := e.(type) {
+		case *BinaryExpression:
+			walk(t.Left)
+			walk(t.Right)
+		case *LiteralExpression:
+			literals = append(literals, t)
+		}
+	}
+	walk(e)
+
+	assert.Equal(t, 5, len(literals))
+	assert.Equal(t, LiteralDateTime, literals[0].Type)
+	assert.Equal(t, "2016-11-18T11:04:15.790Z", literals[0].Value)
+	assert.Equal(t, LiteralNumber, literals[1].Type)
+	assert.Equal(t, -1.5, literals[1].Value)
+	assert.Equal(t, LiteralBoolean, literals[2].Type)
+	assert.Equal(t, true, literals[2].Value)
+	assert.Equal(t, LiteralNull, literals[3].Type)
+	assert.Equal(t, LiteralString, literals[4].Type)
+	assert.Equal(t, "it's", literals[4].Value)
+}
+
+func TestParseODATAFilterShouldParseNavigationPath(t *testing.T) {
+	//act
+	e, err := ParseODATAFilter("Datastream/Thing/name eq 'a'")
+
+	//assert
+	assert.Nil(t, err)
+	b, ok := e.(*BinaryExpression)
+	assert.True(t, ok, "Expression should be a BinaryExpression")
+	p, ok := b.Left.(*PropertyExpression)
+	assert.True(t, ok, "Left side should be a PropertyExpression")
+	assert.Equal(t, []string{"Datastream", "Thing", "name"}, p.Path)
+	assert.Equal(t, "name", p.Name())
+}
+
+func TestParseODATAFilterShouldParseTypedDateTime(t *testing.T) {
+	//act
+	e, err := ParseODATAFilter("resultTime lt datetimeoffset'2016-01-01T00:00:00Z'")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "(resultTime lt 2016-01-01T00:00:00Z)", e.String())
+}
+
+func TestParseODATAFilterShouldReturnSyntaxErrorWithPosition(t *testing.T) {
+	//arrange
+	filters := map[string]int{
+		"name eq 'a":             8,
+		"(id eq 1":               8,
+		"id eq":                  5,
+		"id eq 1)":               7,
+		"id eq 1 and":            11,
+		"unknownfunc(name) eq 1": 0,
+		"id # 1":                 3,
+	}
+
+	for filter, position := range filters {
+		//act
+		_, err := ParseODATAFilter(filter)
+
+		//assert
+		assert.NotNil(t, err, "Filter %s should give an error", filter)
+		syntaxError, ok := err.(*FilterSyntaxError)
+		assert.True(t, ok, "Filter %s should give a FilterSyntaxError", filter)
+		if ok {
+			assert.Equal(t, position, syntaxError.Position, "Wrong error position for %s", filter)
+		}
+	}
+}
+
+func TestQueryFilterParseShouldReturnBadRequest(t *testing.T) {
+	//arrange
+	filter := QueryFilter{}
+
+	//act
+	err := filter.Parse("id eq (1")
+
+	//assert
+	assert.NotNil(t, err)
+	assert.Nil(t, filter.Expression)
+}
+
+func TestParseODATAFilterShouldParseNestedStringFunctions(t *testing.T) {
+	//act
+	e, err := ParseODATAFilter("length(trim(tolower(name))) gt 3 and contains(concat(name, description), 'abc')")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "((length(trim(tolower(name))) gt 3) and contains(concat(name, description), 'abc'))", e.String())
+}
+
+func TestParseODATAFilterShouldCheckFunctionArguments(t *testing.T) {
+	//arrange
+	filters := []string{
+		"startswith(name)",
+		"startswith(name, 1)",
+		"length(name, 'a') eq 1",
+		"substring(name, 'a') eq 'b'",
+		"substring(name, 1, 2, 3) eq 'b'",
+		"tolower(length(name)) eq 'a'",
+	}
+
+	for _, filter := range filters {
+		//act
+		_, err := ParseODATAFilter(filter)
+
+		//assert
+		assert.NotNil(t, err, "Filter %s should give an error", filter)
+	}
+}
+
+func TestExpressionTypeShouldReturnFunctionResult(t *testing.T) {
+	//arrange
+	e, _ := ParseODATAFilter("substring(name, 1)")
+	i, _ := ParseODATAFilter("indexof(name, 'a')")
+
+	//assert
+	assert.Equal(t, ValueTypeString, ExpressionType(e))
+	assert.Equal(t, ValueTypeNumber, ExpressionType(i))
+}
+
+func TestParseODATAFilterShouldCheckDateFunctionArguments(t *testing.T) {
+	//arrange
+	filters := []string{"year('2016') eq 2016", "now(resultTime) gt resultTime", "hour(phenomenonTime, 1) eq 1"}
+
+	for _, filter := range filters {
+		//act
+		_, err := ParseODATAFilter(filter)
+
+		//assert
+		assert.NotNil(t, err, "Filter %s should give an error", filter)
+	}
+
+	//act
+	_, err := ParseODATAFilter("hour(phenomenonTime) ge 8 and year(resultTime) eq 2026 and resultTime lt now()")
+
+	//assert
+	assert.Nil(t, err)
+}
+
+func TestParseODATAFilterShouldRespectArithmeticPrecedence(t *testing.T) {
+	//act
+	e, err := ParseODATAFilter("result mul 1.8 add 32 gt 90 and result sub 2 div 4 mod 3 lt 1")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "((((result mul 1.8) add 32) gt 90) and ((result sub ((2 div 4) mod 3)) lt 1))", e.String())
+	assert.Equal(t, Addition, ODATAOperators["add"])
+}
+
+func TestParseODATAFilterShouldRejectNonNumericArithmetic(t *testing.T) {
+	//arrange
+	filters := []string{"result add 'a' gt 1", "name mul true eq 1", "round('a') eq 1", "tolower(name) sub 1 eq 1"}
+
+	for _, filter := range filters {
+		//act
+		_, err := ParseODATAFilter(filter)
+
+		//assert
+		assert.NotNil(t, err, "Filter %s should give an error", filter)
+	}
+}
+
+func TestParseODATAFilterShouldParseGeometryLiterals(t *testing.T) {
+	//act
+	e, err := ParseODATAFilter("geo.intersects(location, geography'POINT(5 52)')")
+
+	//assert
+	assert.Nil(t, err)
+	f, ok := e.(*FunctionExpression)
+	assert.True(t, ok, "Expression should be a FunctionExpression")
+	if ok {
+		l := f.Arguments[1].(*LiteralExpression)
+		assert.Equal(t, LiteralGeometry, l.Type)
+		assert.Equal(t, "POINT(5 52)", l.Value)
+	}
+}
+
+func TestParseODATAFilterShouldRejectInvalidGeometry(t *testing.T) {
+	//arrange
+	filters := []string{"geo.intersects(location, geography'CIRCLE(5 52)')", "st_within(location, 'POINT(5 52)')", "geo.distance(location) lt 1"}
+
+	for _, filter := range filters {
+		//act
+		_, err := ParseODATAFilter(filter)
+
+		//assert
+		assert.NotNil(t, err, "Filter %s should give an error", filter)
+	}
+}
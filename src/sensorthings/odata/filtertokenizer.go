@@ -0,0 +1,151 @@
+package odata
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// TokenType describes the kind of a token found in a $filter expression
+type TokenType int
+
+// List of all token types produced by the filter tokenizer
+const (
+	TokenEOF TokenType = iota
+	TokenIdentifier
+	TokenString
+	TokenNumber
+	TokenDateTime
+	TokenTypedLiteral
+	TokenOpenParen
+	TokenCloseParen
+	TokenComma
+)
+
+// Token is a single lexical element of a $filter expression, Position is the
+// zero based offset of the token inside the filter string
+type Token struct {
+	Type     TokenType
+	Value    string
+	Prefix   string // type prefix of a TokenTypedLiteral such as datetimeoffset or geography
+	Position int
+}
+
+// FilterSyntaxError is returned when a $filter expression cannot be tokenized or parsed
+type FilterSyntaxError struct {
+	Position int
+	Message  string
+}
+
+// Error implements the error interface for FilterSyntaxError
+func (e *FilterSyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Position)
+}
+
+func newFilterSyntaxError(position int, format string, value ...interface{}) *FilterSyntaxError {
+	return &FilterSyntaxError{Position: position, Message: fmt.Sprintf(format, value...)}
+}
+
+// dateTimeRegex matches unquoted ISO 8601 dates and date times such as 2016-11-18T11:04:15.790Z
+var dateTimeRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}(:\d{2}(\.\d+)?)?(Z|[+-]\d{2}:\d{2})?)?`)
+
+// numberRegex matches integer and decimal numbers including an optional exponent
+var numberRegex = regexp.MustCompile(`^-?\d+(\.\d+)?([eE][+-]?\d+)?`)
+
+// TokenizeFilter splits the given filter into tokens, the last token is always TokenEOF
+func TokenizeFilter(filter string) ([]Token, error) {
+	var tokens []Token
+	pos := 0
+
+	for pos < len(filter) {
+		c := rune(filter[pos])
+		switch {
+		case unicode.IsSpace(c):
+			pos++
+		case c == '(':
+			tokens = append(tokens, Token{Type: TokenOpenParen, Value: "(", Position: pos})
+			pos++
+		case c == ')':
+			tokens = append(tokens, Token{Type: TokenCloseParen, Value: ")", Position: pos})
+			pos++
+		case c == ',':
+			tokens = append(tokens, Token{Type: TokenComma, Value: ",", Position: pos})
+			pos++
+		case c == '\'':
+			value, end, err := readQuoted(filter, pos)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, Token{Type: TokenString, Value: value, Position: pos})
+			pos = end
+		case unicode.IsDigit(c) || (c == '-' && pos+1 < len(filter) && unicode.IsDigit(rune(filter[pos+1]))):
+			if m := dateTimeRegex.FindString(filter[pos:]); len(m) > 0 {
+				tokens = append(tokens, Token{Type: TokenDateTime, Value: m, Position: pos})
+				pos += len(m)
+				break
+			}
+
+			m := numberRegex.FindString(filter[pos:])
+			tokens = append(tokens, Token{Type: TokenNumber, Value: m, Position: pos})
+			pos += len(m)
+		case isIdentifierStart(c):
+			start := pos
+			for pos < len(filter) && isIdentifierPart(rune(filter[pos])) {
+				pos++
+			}
+
+			ident := filter[start:pos]
+			// a quote directly after an identifier denotes a typed literal: datetimeoffset'2016-01-01T00:00:00Z'
+			if pos < len(filter) && filter[pos] == '\'' {
+				value, end, err := readQuoted(filter, pos)
+				if err != nil {
+					return nil, err
+				}
+				tokens = append(tokens, Token{Type: TokenTypedLiteral, Value: value, Prefix: strings.ToLower(ident), Position: start})
+				pos = end
+				break
+			}
+
+			tokens = append(tokens, Token{Type: TokenIdentifier, Value: ident, Position: start})
+		default:
+			return nil, newFilterSyntaxError(pos, "Unexpected character '%c'", c)
+		}
+	}
+
+	tokens = append(tokens, Token{Type: TokenEOF, Position: len(filter)})
+	return tokens, nil
+}
+
+// readQuoted reads a single quoted string starting at start, a quote inside the
+// string is escaped by doubling it. Returns the unescaped value and the position
+// after the closing quote
+func readQuoted(filter string, start int) (string, int, error) {
+	var value bytes.Buffer
+	pos := start + 1
+	for pos < len(filter) {
+		if filter[pos] == '\'' {
+			if pos+1 < len(filter) && filter[pos+1] == '\'' {
+				value.WriteByte('\'')
+				pos += 2
+				continue
+			}
+
+			return value.String(), pos + 1, nil
+		}
+
+		value.WriteByte(filter[pos])
+		pos++
+	}
+
+	return "", 0, newFilterSyntaxError(start, "Unterminated string literal")
+}
+
+func isIdentifierStart(c rune) bool {
+	return unicode.IsLetter(c) || c == '_' || c == '@' || c == '$'
+}
+
+func isIdentifierPart(c rune) bool {
+	return isIdentifierStart(c) || unicode.IsDigit(c) || c == '.' || c == '/'
+}
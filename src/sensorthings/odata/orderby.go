@@ -9,36 +9,62 @@ import (
 // orderby Is used to specify which properties are used to order the collection of entities identified by the resource path.
 type QueryOrderBy struct {
 	QueryBase
-	Suffix   string
+	Params []OrderByParam
+}
+
+// OrderByParam is a single sort key of $orderby, Property can be a path into a json property or
+// through related entities such as Datastream/name, Suffix is "asc" or "desc"
+type OrderByParam struct {
 	Property string
+	Suffix   string
 }
 
-// Parse tries to parse the OrderBy query into a suffix and property value
-// if the suffix is not "asc" or "desc" or no property is given then
-// parse will return an error
+// Parse tries to parse the comma separated sort keys of the OrderBy query into a property and suffix,
+// the suffix defaults to "asc" when not given. If a suffix is not "asc" or "desc" or no property is
+// given then parse will return an error
 func (q *QueryOrderBy) Parse(value string) error {
 	q.RawQuery = value
-	ob := strings.Split(value, " ")
-	if len(ob) != 2 || (strings.ToLower(ob[1]) != "asc" && strings.ToLower(ob[1]) != "desc") || len(ob[0]) < 1 {
-		return CreateQueryError(QueryOrderByInvalid, http.StatusBadRequest, value)
+	q.Params = nil
+	for _, p := range strings.Split(value, ",") {
+		ob := strings.Fields(p)
+		if len(ob) < 1 || len(ob) > 2 {
+			return CreateQueryError(QueryOrderByInvalid, http.StatusBadRequest, value)
+		}
+
+		param := OrderByParam{Property: ob[0], Suffix: "asc"}
+		if len(ob) == 2 {
+			param.Suffix = strings.ToLower(ob[1])
+			if param.Suffix != "asc" && param.Suffix != "desc" {
+				return CreateQueryError(QueryOrderByInvalid, http.StatusBadRequest, value)
+			}
+		}
+
+		q.Params = append(q.Params, param)
 	}
 
-	q.Property = ob[0]
-	q.Suffix = ob[1]
 	return nil
 }
 
-// IsValid checks if the given property value in the request is valid for the
-// used endpoint, returns an error if not supported
+// IsValid checks if the given property values in the request are valid for the
+// used endpoint, returns an error if not supported. Only the first element of a
+// property path is checked, the remaining path is validated when creating the query
 // values = available properties of an entity
 func (q *QueryOrderBy) IsValid(values []string) (bool, error) {
-	for _, s := range values {
-		if q.Property == s {
-			return true, nil
+	for _, p := range q.Params {
+		property := strings.Split(p.Property, "/")[0]
+		found := false
+		for _, s := range values {
+			if strings.ToLower(property) == strings.ToLower(s) {
+				found = true
+			}
+		}
+
+		if !found {
+			return false, CreateQueryError(QueryOrderByInvalid, http.StatusBadRequest, p.Property)
 		}
 	}
 
-	return false, CreateQueryError(QueryOrderByInvalid, http.StatusBadRequest, q.Property)
+	return true, nil
 }
 
 // GetQueryOptionType returns the QueryOptionType for QueryOrderBy
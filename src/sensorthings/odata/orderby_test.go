@@ -25,11 +25,54 @@ func TestOrderByParsingShouldGivePropertyAndSuffix(t *testing.T) {
 
 	//assert
 	assert.Nil(t, err, "Empty QueryOrderBy should give errors")
-	assert.Equal(t, orderby.Property, "hallo", "orderby.property should be correct")
-	assert.Equal(t, orderby.Suffix, "asc", "orderby.suffix should be correct")
+	assert.Equal(t, orderby.Params[0].Property, "hallo", "orderby.property should be correct")
+	assert.Equal(t, orderby.Params[0].Suffix, "asc", "orderby.suffix should be correct")
 }
 
-// todo: add test for IQueryOrderBy.IsValid when implementing
+func TestOrderByParsingMultipleKeysShouldDefaultToAsc(t *testing.T) {
+	//arrange
+	orderby := QueryOrderBy{}
+
+	//act
+	err := orderby.Parse("phenomenonTime, Datastream/name DESC,id")
+
+	//assert
+	assert.Nil(t, err, "QueryOrderBy with multiple keys should not give an error")
+	assert.Equal(t, []OrderByParam{{"phenomenonTime", "asc"}, {"Datastream/name", "desc"}, {"id", "asc"}}, orderby.Params)
+}
+
+func TestOrderByParsingWithInvalidKeysShouldReturnError(t *testing.T) {
+	//arrange
+	values := []string{"name up", "name asc,", "name asc desc", ",name"}
+
+	for _, v := range values {
+		orderby := QueryOrderBy{}
+
+		//act
+		err := orderby.Parse(v)
+
+		//assert
+		assert.NotNil(t, err, "QueryOrderBy %s should give an error", v)
+	}
+}
+
+func TestOrderByIsValidShouldCheckProperties(t *testing.T) {
+	//arrange
+	valid := QueryOrderBy{}
+	valid.Parse("Name desc,properties/owner,Datastream/name")
+	invalid := QueryOrderBy{}
+	invalid.Parse("name,owner")
+
+	//act
+	ok, err := valid.IsValid([]string{"name", "properties", "Datastream"})
+	notOk, err2 := invalid.IsValid([]string{"name", "properties", "Datastream"})
+
+	//assert
+	assert.True(t, ok)
+	assert.Nil(t, err)
+	assert.False(t, notOk)
+	assert.NotNil(t, err2)
+}
 
 func TestOrderByNotShouldReturnNotNil(t *testing.T) {
 	//arrange
@@ -1,7 +1,9 @@
 package odata
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
 )
 
 // QueryOption contains user requested query information for retrieving objects
@@ -28,6 +30,8 @@ const (
 	QueryOptionResultFormat
 	QueryOptionRef
 	QueryOptionValue
+	QueryOptionSkipToken
+	QueryOptionApply
 )
 
 // QueryOptionValues is a list of names mapped to their QueryOptionType
@@ -42,6 +46,8 @@ var QueryOptionValues = []string{
 	QueryOptionResultFormat: "$resultFormat",
 	QueryOptionRef:          "$ref",
 	QueryOptionValue:        "$value",
+	QueryOptionSkipToken:    "$skiptoken",
+	QueryOptionApply:        "$apply",
 }
 
 // String returns the string representation of the current QueryOptionType
@@ -64,6 +70,8 @@ type QueryOptions struct {
 	QueryCount        *QueryCount
 	QueryFilter       *QueryFilter
 	QueryResultFormat *QueryResultFormat
+	QuerySkipToken    *QuerySkipToken
+	QueryApply        *QueryApply
 	QueryOptionRef    bool
 	QueryOptionValue  bool
 }
@@ -110,6 +118,14 @@ func CreateQueryOptions(queryParams map[string]string) (*QueryOptions, []error)
 			qo.QueryResultFormat = &QueryResultFormat{}
 			ParseQueryOption(value, qo.QueryResultFormat, err)
 			break
+		case QueryOptionSkipToken.String():
+			qo.QuerySkipToken = &QuerySkipToken{}
+			ParseQueryOption(value, qo.QuerySkipToken, err)
+			break
+		case QueryOptionApply.String():
+			qo.QueryApply = &QueryApply{}
+			ParseQueryOption(value, qo.QueryApply, err)
+			break
 		case QueryOptionRef.String():
 			qo.QueryOptionRef = true
 			break
@@ -123,6 +139,15 @@ func CreateQueryOptions(queryParams map[string]string) (*QueryOptions, []error)
 		}
 	}
 
+	// a $skiptoken already points to the start of the page
+	if qo.QuerySkip != nil && qo.QuerySkipToken != nil {
+		errorList = append(errorList, CreateQueryError(QuerySkipTokenWithSkip, http.StatusBadRequest))
+	}
+
+	if len(errorList) == 0 && !qo.QueryApply.IsNil() {
+		errorList = append(errorList, qo.checkApply()...)
+	}
+
 	if len(errorList) > 0 {
 		return nil, errorList
 	}
@@ -130,6 +155,46 @@ func CreateQueryOptions(queryParams map[string]string) (*QueryOptions, []error)
 	return qo, nil
 }
 
+// checkApply checks if the other query options can be used on the result of $apply, the result can only
+// be ordered by the group properties and aggregates and other entities cannot be selected or expanded
+func (qo *QueryOptions) checkApply() []error {
+	var errorList []error
+	if !qo.QueryFilter.IsNil() {
+		errorList = append(errorList, CreateQueryError(QueryApplyCombination, http.StatusBadRequest, QueryOptionFilter.String(), "use filter() inside $apply"))
+	}
+	if !qo.QuerySelect.IsNil() {
+		errorList = append(errorList, CreateQueryError(QueryApplyCombination, http.StatusBadRequest, QueryOptionSelect.String(), "the aggregated properties are returned"))
+	}
+	if !qo.QueryExpand.IsNil() {
+		errorList = append(errorList, CreateQueryError(QueryApplyCombination, http.StatusBadRequest, QueryOptionExpand.String(), "aggregated results cannot be expanded"))
+	}
+	if !qo.QuerySkipToken.IsNil() {
+		errorList = append(errorList, CreateQueryError(QueryApplyCombination, http.StatusBadRequest, QueryOptionSkipToken.String(), "use $skip to page aggregated results"))
+	}
+	if qo.QueryResultFormat.IsDataArray() {
+		errorList = append(errorList, CreateQueryError(QueryApplyCombination, http.StatusBadRequest, "$resultFormat=dataArray", "aggregated results are not Observations"))
+	}
+
+	if !qo.QueryOrderBy.IsNil() {
+		aliases := qo.QueryApply.Aliases()
+		for _, p := range qo.QueryOrderBy.Params {
+			found := false
+			for _, a := range aliases {
+				if strings.ToLower(a) == strings.ToLower(p.Property) {
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				errorList = append(errorList, CreateQueryError(QueryApplyCombination, http.StatusBadRequest, QueryOptionOrderBy.String(), fmt.Sprintf("%s is not a property of the aggregated result", p.Property)))
+			}
+		}
+	}
+
+	return errorList
+}
+
 // ParseQueryOption tries to parse the user supplied values into the desired QueryOption
 // if an error occurred in the parsing process a new error will be added
 // to the supplied errorlist
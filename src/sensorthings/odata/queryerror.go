@@ -12,13 +12,20 @@ type QueryErrorMessage string
 const (
 	QueryTopInvalid          QueryErrorMessage = "The value %s for $top is invalid, please provide a non-negative integer"
 	QuerySkipInvalid         QueryErrorMessage = "The value %s for $skip is invalid, please provide a non-negative integer"
-	QueryOrderByInvalid      QueryErrorMessage = "The value %s for $orderby is invalid, please use the following format $orderby=\"propertyname\" \"asc/desc\""
+	QuerySkipTokenInvalid    QueryErrorMessage = "The value %s for $skiptoken is invalid, please use the @iot.nextLink returned by the server"
+	QuerySkipTokenWithSkip   QueryErrorMessage = "$skip cannot be combined with $skiptoken"
+	QueryOrderByInvalid      QueryErrorMessage = "The value %s for $orderby is invalid, please use the following format $orderby=\"propertyname\" \"asc/desc\",\"propertyname\" \"asc/desc\""
 	QueryCountInvalid        QueryErrorMessage = "The value %s for $count is invalid, available options: \"true\" or \"false\" "
-	QueryResultFormatInvalid QueryErrorMessage = "The value %s for $resultFormat is invalid, available options: dataArray"
+	QueryResultFormatInvalid QueryErrorMessage = "The value %s for $resultFormat is invalid, available options: dataArray, CSV, GeoJSON, NDJSON"
 	QueryfilterFormatInvalid QueryErrorMessage = "The value %s for filter is invalid"
+	QueryFilterSyntaxError   QueryErrorMessage = "The value %s for $filter is invalid: %s"
 	QueryUnknown             QueryErrorMessage = "The query parameter %s is not supported"
 	QueryNotAvailable        QueryErrorMessage = "Query %s is not available on endpoint %s"
 	QueryExpandAvailable     QueryErrorMessage = "Expand %s is not available on endpoint %s"
+	QueryExpandInvalid       QueryErrorMessage = "The value %s for $expand is invalid: %s"
+	QuerySelectInvalid       QueryErrorMessage = "The value %s for $select is invalid, the property is not available"
+	QueryApplyInvalid        QueryErrorMessage = "The value %s for $apply is invalid: %s"
+	QueryApplyCombination    QueryErrorMessage = "%s cannot be used together with $apply, %s"
 )
 
 // CreateQueryError formats a query error, adding a value into the defined message
@@ -1,25 +1,55 @@
 package odata
 
-import "net/http"
+import (
+	"net/http"
+	"strings"
+)
+
+// List of supported values for $resultFormat
+const (
+	ResultFormatDataArray = "dataArray"
+	ResultFormatCSV       = "CSV"
+	ResultFormatGeoJSON   = "GeoJSON"
+	ResultFormatNDJSON    = "NDJSON"
+)
+
+// ResultFormats is a list of all supported values for $resultFormat
+var ResultFormats = []string{ResultFormatDataArray, ResultFormatCSV, ResultFormatGeoJSON, ResultFormatNDJSON}
 
 // QueryResultFormat is used to return Observations in a data array format, a components section
-// is returned in the response to describe the order of returned values.
+// is returned in the response to describe the order of returned values. The other formats
+// change the encoding of the response such as CSV, GeoJSON or newline delimited JSON
 type QueryResultFormat struct {
 	QueryBase
-	//ToDo: make a type out of format, not done because there is currently only the dataArray option
 	format string
 }
 
-// Parse tries to parse the given data format, if the data format is not supported
-// it will return an error
+// Parse tries to parse the given data format, the format is case-insensitive, if the
+// data format is not supported it will return an error
 func (q *QueryResultFormat) Parse(value string) error {
 	q.RawQuery = value
-	if value != "dataArray" {
-		return CreateQueryError(QueryResultFormatInvalid, http.StatusBadRequest, value)
+	for _, f := range ResultFormats {
+		if strings.ToLower(value) == strings.ToLower(f) {
+			q.format = f
+			return nil
+		}
+	}
+
+	return CreateQueryError(QueryResultFormatInvalid, http.StatusBadRequest, value)
+}
+
+// Format returns the requested format as one of the ResultFormat values
+func (q *QueryResultFormat) Format() string {
+	if q.IsNil() {
+		return ""
 	}
 
-	q.format = value
-	return nil
+	return q.format
+}
+
+// IsDataArray returns true when the dataArray format is requested
+func (q *QueryResultFormat) IsDataArray() bool {
+	return q.Format() == ResultFormatDataArray
 }
 
 // IsValid always returns true, errors are already filtered out by parse
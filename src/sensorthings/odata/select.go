@@ -1,11 +1,15 @@
 package odata
 
 import (
+	"net/http"
 	"strings"
 
-	"fmt"
+	"github.com/geodan/gost/src/sensorthings/entities"
 )
 
+// SelectIotID is the name of the id annotation which can be used in $select to select the id of an entity
+const SelectIotID = "@iot.id"
+
 // QuerySelect is used to return only the entity property values desired, this is used
 // help to reduce the amount of information in a response from the server.
 // If set, the result will include the specified property of the SensorThing entity object.
@@ -16,17 +20,27 @@ type QuerySelect struct {
 
 // Parse $select values in QuerySelect, at this stage we don't know
 // if the select params are valid, this depends on Select values available
-// for the used endpoint
+// for the used endpoint. @iot.id is stored as id
 func (q *QuerySelect) Parse(value string) error {
 	q.RawQuery = value
-	q.Params = strings.Split(value, ",")
+	q.Params = []string{}
+	for _, p := range strings.Split(value, ",") {
+		p = strings.TrimSpace(p)
+		if strings.ToLower(p) == strings.ToLower(SelectIotID) {
+			p = "id"
+		}
+
+		q.Params = append(q.Params, p)
+	}
+
 	return nil
 }
 
-// IsValid checks if the given $select values are supported for the endpoint
+// IsValid checks if the given $select values are supported for the endpoint, names are compared
+// case-insensitive, @iot.selfLink can be selected on every endpoint
 func (q *QuerySelect) IsValid(values []string) (bool, error) {
 	for _, rp := range q.Params {
-		found := false
+		found := strings.ToLower(rp) == strings.ToLower(entities.SelfLinkName)
 		for _, hp := range values {
 			if strings.ToLower(rp) == strings.ToLower(hp) {
 				found = true
@@ -34,14 +48,32 @@ func (q *QuerySelect) IsValid(values []string) (bool, error) {
 		}
 
 		if !found {
-			return false, fmt.Errorf("Parameter %s not supported", rp)
+			return false, CreateQueryError(QuerySelectInvalid, http.StatusBadRequest, rp)
 		}
-
 	}
 
 	return true, nil
 }
 
+// Contains returns true when the given property, navigation property or annotation is selected
+func (q *QuerySelect) Contains(name string) bool {
+	if q.IsNil() {
+		return false
+	}
+
+	if strings.ToLower(name) == strings.ToLower(SelectIotID) {
+		name = "id"
+	}
+
+	for _, p := range q.Params {
+		if strings.ToLower(p) == strings.ToLower(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 // GetQueryOptionType returns the QueryOptionType for QuerySelect
 func (q *QuerySelect) GetQueryOptionType() QueryOptionType {
 	return QueryOptionSelect
@@ -0,0 +1,49 @@
+package odata
+
+import (
+	"testing"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectParseShouldTrimAndMapIotID(t *testing.T) {
+	//arrange
+	sel := QuerySelect{}
+
+	//act
+	err := sel.Parse("@iot.id, name ,Datastreams,@iot.selfLink")
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"id", "name", "Datastreams", "@iot.selfLink"}, sel.Params)
+}
+
+func TestSelectIsValidShouldReturnBadRequestForUnknownProperty(t *testing.T) {
+	//arrange
+	sel := QuerySelect{}
+	sel.Parse("Name,@iot.selfLink,unknown")
+
+	//act
+	valid, err := sel.IsValid([]string{"id", "name"})
+
+	//assert
+	assert.False(t, valid)
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "unknown")
+	assert.Equal(t, 400, err.(gostErrors.APIError).GetHTTPErrorStatusCode())
+}
+
+func TestSelectContains(t *testing.T) {
+	//arrange
+	sel := &QuerySelect{}
+	sel.Parse("@iot.id,datastreams")
+	var nilSelect *QuerySelect
+
+	//assert
+	assert.True(t, sel.Contains("id"))
+	assert.True(t, sel.Contains("@iot.id"))
+	assert.True(t, sel.Contains("Datastreams"))
+	assert.False(t, sel.Contains("name"))
+	assert.False(t, nilSelect.Contains("id"))
+}
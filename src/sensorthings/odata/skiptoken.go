@@ -0,0 +1,55 @@
+package odata
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+)
+
+// QuerySkipToken is used for keyset pagination, the token is created by the server for the @iot.nextLink
+// and holds the values of the $orderby properties and id of the last entity of the previous page.
+// The next page starts after the entity described by the token, the token is opaque to the client
+type QuerySkipToken struct {
+	QueryBase
+	Values []json.RawMessage
+}
+
+// CreateSkipToken encodes the JSON array of key values, as returned by the database for the
+// last entity of a page, into an opaque token which can be used in the $skiptoken query
+func CreateSkipToken(keys string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(keys))
+}
+
+// Parse decodes the $skiptoken into its key values, returns an error if the token was not created by CreateSkipToken
+func (q *QuerySkipToken) Parse(value string) error {
+	q.RawQuery = value
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return CreateQueryError(QuerySkipTokenInvalid, http.StatusBadRequest, value)
+	}
+
+	if err = json.Unmarshal(b, &q.Values); err != nil || len(q.Values) == 0 {
+		return CreateQueryError(QuerySkipTokenInvalid, http.StatusBadRequest, value)
+	}
+
+	return nil
+}
+
+// IsValid always returns true, errors are already filtered out by parse
+func (q *QuerySkipToken) IsValid() (bool, error) {
+	return true, nil
+}
+
+// GetQueryOptionType returns the QueryOptionType for QuerySkipToken
+func (q *QuerySkipToken) GetQueryOptionType() QueryOptionType {
+	return QueryOptionSkipToken
+}
+
+// IsNil checks if *QuerySkipToken is nil
+func (q *QuerySkipToken) IsNil() bool {
+	if q == nil {
+		return true
+	}
+
+	return false
+}
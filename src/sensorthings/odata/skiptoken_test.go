@@ -0,0 +1,44 @@
+package odata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSkipToken(t *testing.T) {
+	//arrange
+	skipToken := QuerySkipToken{}
+
+	//act
+	err := skipToken.Parse(CreateSkipToken(`["2016-01-01T00:00:00Z",12]`))
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, QueryOptionSkipToken, skipToken.GetQueryOptionType())
+	assert.Len(t, skipToken.Values, 2)
+	assert.Equal(t, "12", string(skipToken.Values[1]))
+}
+
+func TestParseFailSkipToken(t *testing.T) {
+	//arrange
+	skipToken := QuerySkipToken{}
+
+	//act
+	errEncoding := skipToken.Parse("not a token!")
+	errJSON := skipToken.Parse(CreateSkipToken("{}"))
+	errEmpty := skipToken.Parse(CreateSkipToken("[]"))
+
+	//assert
+	assert.NotNil(t, errEncoding)
+	assert.NotNil(t, errJSON)
+	assert.NotNil(t, errEmpty)
+}
+
+func TestSkipTokenShouldNotBeCombinedWithSkip(t *testing.T) {
+	//act
+	_, errs := CreateQueryOptions(map[string]string{"$skip": "10", "$skiptoken": CreateSkipToken("[1]")})
+
+	//assert
+	assert.NotNil(t, errs)
+}
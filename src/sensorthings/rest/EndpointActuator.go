@@ -0,0 +1,48 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+func createActuatorsEndpoint(externalURL string) *Endpoint {
+	return &Endpoint{
+		Name:       "Actuators",
+		OutputInfo: true,
+		URL:        fmt.Sprintf("%s/%s/%s", externalURL, models.APIPrefix, fmt.Sprintf("%v", "Actuators")),
+		SupportedQueryOptions: []odata.QueryOptionType{
+			odata.QueryOptionTop, odata.QueryOptionSkip, odata.QueryOptionOrderBy, odata.QueryOptionCount, odata.QueryOptionResultFormat,
+			odata.QueryOptionExpand, odata.QueryOptionSelect, odata.QueryOptionFilter,
+		},
+		SupportedExpandParams: []string{
+			"TaskingCapabilities",
+		},
+		SupportedSelectParams: []string{
+			"id",
+			"name",
+			"description",
+			"encodingType",
+			"metadata",
+			"TaskingCapabilities",
+		},
+		Operations: []models.EndpointOperation{
+			{models.HTTPOperationGet, "/v1.0/actuators", HandleGetActuators},
+			{models.HTTPOperationGet, "/v1.0/actuators{id}", HandleGetActuator},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/actuator", HandleGetActuatorByTaskingCapability},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/actuator/{params}", HandleGetActuatorByTaskingCapability},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/actuator/{params}/$value", HandleGetActuatorByTaskingCapability},
+			{models.HTTPOperationGet, "/v1.0/actuators{id}/{params}", HandleGetActuator},
+			{models.HTTPOperationGet, "/v1.0/actuators{id}/{params}/$value", HandleGetActuator},
+			{models.HTTPOperationGet, "/v1.0/actuators/{params}", HandleGetActuators},
+
+			{models.HTTPOperationPost, "/v1.0/actuators", HandlePostActuator},
+			{models.HTTPOperationDelete, "/v1.0/actuators{id}", HandleDeleteActuator},
+			{models.HTTPOperationPatch, "/v1.0/actuators{id}", HandlePatchActuator},
+			{models.HTTPOperationPut, "/v1.0/actuators{id}", HandlePutActuator},
+
+			{models.HTTPOperationPost, "/v1.0/actuators{id}/{navigation}/$ref", HandlePostRef},
+		},
+	}
+}
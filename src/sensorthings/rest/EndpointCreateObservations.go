@@ -0,0 +1,18 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/geodan/gost/src/sensorthings/models"
+)
+
+func createCreateObservationsEndpoint(externalURL string) *Endpoint {
+	return &Endpoint{
+		Name:       "CreateObservations",
+		OutputInfo: false,
+		URL:        fmt.Sprintf("%s/%s/%s", externalURL, models.APIPrefix, "CreateObservations"),
+		Operations: []models.EndpointOperation{
+			{models.HTTPOperationPost, "/v1.0/createobservations", HandlePostCreateObservations},
+		},
+	}
+}
@@ -59,27 +59,8 @@ func createDatastreamsEndpoint(externalURL string) *Endpoint {
 			{models.HTTPOperationPatch, "/v1.0/datastreams{id}", HandlePatchDatastream},
 			{models.HTTPOperationPut, "/v1.0/datastreams{id}", HandlePutDatastream},
 
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams", HandleGetDatastreams},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}", HandleGetDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observedproperties{id}/datastreams", HandleGetDatastreamsByObservedProperty},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observedproperties{id}/datastreams/{params}", HandleGetDatastreamsByObservedProperty},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observedproperties{id}/datastreams/{params}/$value", HandleGetDatastreamsByObservedProperty},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations{id}/datastream", HandleGetDatastreamByObservation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations{id}/datastream/{params}", HandleGetDatastreamByObservation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations{id}/datastream/{params}/$value", HandleGetDatastreamByObservation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/sensors{id}/datastreams", HandleGetDatastreamsBySensor},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/sensors{id}/datastreams/{params}", HandleGetDatastreamsBySensor},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things{id}/datastreams", HandleGetDatastreamsByThing},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things{id}/datastreams/{params}", HandleGetDatastreamsByThing},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/{params}", HandleGetDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/{params}/$value", HandleGetDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams/{params}", HandleGetDatastreams},
-
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/datastreams", HandlePostDatastream},
-			{models.HTTPOperationDelete, "/v1.0/{c:.*}/datastreams{id}", HandleDeleteDatastream},
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/things{id}/datastreams", HandlePostDatastreamByThing},
-			{models.HTTPOperationPatch, "/v1.0/{c:.*}/datastreams{id}", HandlePatchDatastream},
-			{models.HTTPOperationPut, "/v1.0/{c:.*}/datastreams{id}", HandlePutDatastream},
+			{models.HTTPOperationPost, "/v1.0/datastreams{id}/{navigation}/$ref", HandlePostRef},
+			{models.HTTPOperationPut, "/v1.0/datastreams{id}/{navigation}/$ref", HandlePutRef},
 		},
 	}
 }
@@ -17,7 +17,7 @@ func createFeaturesOfInterestEndpoint(externalURL string) *Endpoint {
 			odata.QueryOptionExpand, odata.QueryOptionSelect, odata.QueryOptionFilter,
 		},
 		SupportedExpandParams: []string{
-			"Observation",
+			"Observations",
 		},
 		SupportedSelectParams: []string{
 			"id",
@@ -41,19 +41,7 @@ func createFeaturesOfInterestEndpoint(externalURL string) *Endpoint {
 			{models.HTTPOperationPatch, "/v1.0/featuresofinterest{id}", HandlePatchFeatureOfInterest},
 			{models.HTTPOperationPut, "/v1.0/featuresofinterest{id}", HandlePutFeatureOfInterest},
 
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/featuresofinterest", HandleGetFeatureOfInterests},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/featuresofinterest{id}", HandleGetFeatureOfInterest},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/featuresofinterest{id}/{params}", HandleGetFeatureOfInterest},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/featuresofinterest{id}/{params}/$value", HandleGetFeatureOfInterest},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations{id}/featureofInterest", HandleGetFeatureOfInterestByObservation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations{id}/featureofinterest/{params}", HandleGetFeatureOfInterestByObservation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations{id}/featureofinterest/{params}/$value", HandleGetFeatureOfInterestByObservation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/featuresofinterest/{params}", HandleGetFeatureOfInterests},
-
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/featuresofinterest", HandlePostFeatureOfInterest},
-			{models.HTTPOperationDelete, "/v1.0/{c:.*}/featuresofinterest{id}", HandleDeleteFeatureOfInterest},
-			{models.HTTPOperationPatch, "/v1.0/{c:.*}/featuresofinterest{id}", HandlePatchFeatureOfInterest},
-			{models.HTTPOperationPut, "/v1.0/{c:.*}/featuresofinterest{id}", HandlePutFeatureOfInterest},
+			{models.HTTPOperationPost, "/v1.0/featuresofinterest{id}/{navigation}/$ref", HandlePostRef},
 		},
 	}
 }
@@ -17,12 +17,14 @@ func createHistoricalLocationsEndpoint(externalURL string) *Endpoint {
 			odata.QueryOptionExpand, odata.QueryOptionSelect, odata.QueryOptionFilter,
 		},
 		SupportedExpandParams: []string{
-			"locations",
-			"thing",
+			"Locations",
+			"Thing",
 		},
 		SupportedSelectParams: []string{
 			"id",
 			"time",
+			"Thing",
+			"Locations",
 		},
 		Operations: []models.EndpointOperation{
 			{models.HTTPOperationGet, "/v1.0/historicallocations", HandleGetHistoricalLocations},
@@ -41,21 +43,9 @@ func createHistoricalLocationsEndpoint(externalURL string) *Endpoint {
 			{models.HTTPOperationPatch, "/v1.0/historicallocations{id}", HandlePatchHistoricalLocations},
 			{models.HTTPOperationPut, "/v1.0/historicallocations{id}", HandlePutHistoricalLocation},
 
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations", HandleGetHistoricalLocations},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations{id}", HandleGetHistoricalLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things{id}/historicallocations", HandleGetHistoricalLocationsByThing},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things{id}/historicallocations/{params}", HandleGetHistoricalLocationsByThing},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations{id}/historicallocations", HandleGetHistoricalLocationsByLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations{id}/historicallocations/{params}", HandleGetHistoricalLocationsByLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations{id}/historicallocations/{params}/$value", HandleGetHistoricalLocationsByLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations{id}/{params}", HandleGetHistoricalLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations{id}/{params}/$value", HandleGetHistoricalLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations/{params}", HandleGetHistoricalLocations},
-
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/historicallocations", HandlePostHistoricalLocation},
-			{models.HTTPOperationDelete, "/v1.0/{c:.*}/historicallocations{id}", HandleDeleteHistoricalLocations},
-			{models.HTTPOperationPatch, "/v1.0/{c:.*}/historicallocations{id}", HandlePatchHistoricalLocations},
-			{models.HTTPOperationPut, "/v1.0/{c:.*}/historicallocations{id}", HandlePutHistoricalLocation},
+			{models.HTTPOperationPost, "/v1.0/historicallocations{id}/{navigation}/$ref", HandlePostRef},
+			{models.HTTPOperationPut, "/v1.0/historicallocations{id}/{navigation}/$ref", HandlePutRef},
+			{models.HTTPOperationDelete, "/v1.0/historicallocations{id}/{navigation}/$ref", HandleDeleteRef},
 		},
 	}
 }
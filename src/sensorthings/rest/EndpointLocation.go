@@ -46,21 +46,8 @@ func createLocationsEndpoint(externalURL string) *Endpoint {
 			{models.HTTPOperationPatch, "/v1.0/locations{id}", HandlePatchLocation},
 			{models.HTTPOperationPut, "/v1.0/locations{id}", HandlePutLocation},
 
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations", HandleGetLocations},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations{id}", HandleGetLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations{id}/locations", HandleGetLocationsByHistoricalLocations},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things{id}/locations", HandleGetLocationsByThing},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things{id}/locations/{params}", HandleGetLocationsByThing},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations{id}/locations/{params}", HandleGetLocationsByHistoricalLocations},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations{id}/{params}", HandleGetLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations{id}/{params}/$value", HandleGetLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations/{params}", HandleGetLocations},
-
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/locations", HandlePostLocation},
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/things{id}/locations", HandlePostLocationByThing},
-			{models.HTTPOperationDelete, "/v1.0/{c:.*}/locations{id}", HandleDeleteLocation},
-			{models.HTTPOperationPatch, "/v1.0/{c:.*}/locations{id}", HandlePatchLocation},
-			{models.HTTPOperationPut, "/v1.0/{c:.*}/locations{id}", HandlePutLocation},
+			{models.HTTPOperationPost, "/v1.0/locations{id}/{navigation}/$ref", HandlePostRef},
+			{models.HTTPOperationDelete, "/v1.0/locations{id}/{navigation}/$ref", HandleDeleteRef},
 		},
 	}
 }
@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+func createMultiDatastreamsEndpoint(externalURL string) *Endpoint {
+	return &Endpoint{
+		Name:       "MultiDatastreams",
+		OutputInfo: true,
+		URL:        fmt.Sprintf("%s/%s/%s", externalURL, models.APIPrefix, fmt.Sprintf("%v", "MultiDatastreams")),
+		SupportedQueryOptions: []odata.QueryOptionType{
+			odata.QueryOptionTop, odata.QueryOptionSkip, odata.QueryOptionOrderBy, odata.QueryOptionCount, odata.QueryOptionResultFormat,
+			odata.QueryOptionExpand, odata.QueryOptionSelect, odata.QueryOptionFilter,
+		},
+		SupportedExpandParams: []string{
+			"Thing",
+			"Sensor",
+			"ObservedProperties",
+			"Observations",
+		},
+		SupportedSelectParams: []string{
+			"id",
+			"name",
+			"description",
+			"unitOfMeasurements",
+			"observationType",
+			"multiObservationDataTypes",
+			"observedArea",
+			"phenomenonTime",
+			"resultTime",
+			"Thing",
+			"Sensor",
+			"ObservedProperties",
+			"Observations",
+		},
+		Operations: []models.EndpointOperation{
+			{models.HTTPOperationGet, "/v1.0/multidatastreams", HandleGetMultiDatastreams},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}", HandleGetMultiDatastream},
+			{models.HTTPOperationGet, "/v1.0/observedproperties{id}/multidatastreams", HandleGetMultiDatastreamsByObservedProperty},
+			{models.HTTPOperationGet, "/v1.0/observedproperties{id}/multidatastreams/{params}", HandleGetMultiDatastreamsByObservedProperty},
+			{models.HTTPOperationGet, "/v1.0/observations{id}/multidatastream", HandleGetMultiDatastreamByObservation},
+			{models.HTTPOperationGet, "/v1.0/observations{id}/multidatastream/{params}", HandleGetMultiDatastreamByObservation},
+			{models.HTTPOperationGet, "/v1.0/observations{id}/multidatastream/{params}/$value", HandleGetMultiDatastreamByObservation},
+			{models.HTTPOperationGet, "/v1.0/sensors{id}/multidatastreams", HandleGetMultiDatastreamsBySensor},
+			{models.HTTPOperationGet, "/v1.0/sensors{id}/multidatastreams/{params}", HandleGetMultiDatastreamsBySensor},
+			{models.HTTPOperationGet, "/v1.0/things{id}/multidatastreams", HandleGetMultiDatastreamsByThing},
+			{models.HTTPOperationGet, "/v1.0/things{id}/multidatastreams/{params}", HandleGetMultiDatastreamsByThing},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/{params}", HandleGetMultiDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/{params}/$value", HandleGetMultiDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams/{params}", HandleGetMultiDatastreams},
+
+			{models.HTTPOperationPost, "/v1.0/multidatastreams", HandlePostMultiDatastream},
+			{models.HTTPOperationPost, "/v1.0/things{id}/multidatastreams", HandlePostMultiDatastreamByThing},
+			{models.HTTPOperationDelete, "/v1.0/multidatastreams{id}", HandleDeleteMultiDatastream},
+			{models.HTTPOperationPatch, "/v1.0/multidatastreams{id}", HandlePatchMultiDatastream},
+			{models.HTTPOperationPut, "/v1.0/multidatastreams{id}", HandlePutMultiDatastream},
+
+			{models.HTTPOperationPost, "/v1.0/multidatastreams{id}/{navigation}/$ref", HandlePostRef},
+			{models.HTTPOperationPut, "/v1.0/multidatastreams{id}/{navigation}/$ref", HandlePutRef},
+		},
+	}
+}
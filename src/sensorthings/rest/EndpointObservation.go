@@ -14,10 +14,11 @@ func createObservationsEndpoint(externalURL string) *Endpoint {
 		URL:        fmt.Sprintf("%s/%s/%s", externalURL, models.APIPrefix, fmt.Sprintf("%v", "Observations")),
 		SupportedQueryOptions: []odata.QueryOptionType{
 			odata.QueryOptionTop, odata.QueryOptionSkip, odata.QueryOptionOrderBy, odata.QueryOptionCount, odata.QueryOptionResultFormat,
-			odata.QueryOptionExpand, odata.QueryOptionSelect, odata.QueryOptionFilter,
+			odata.QueryOptionExpand, odata.QueryOptionSelect, odata.QueryOptionFilter, odata.QueryOptionSkipToken, odata.QueryOptionApply,
 		},
 		SupportedExpandParams: []string{
 			"Datastream",
+			"MultiDatastream",
 			"FeatureOfInterest",
 		},
 		SupportedSelectParams: []string{
@@ -29,6 +30,7 @@ func createObservationsEndpoint(externalURL string) *Endpoint {
 			"validTime",
 			"parameters",
 			"Datastream",
+			"MultiDatastream",
 			"FeatureOfInterest",
 		},
 		Operations: []models.EndpointOperation{
@@ -36,6 +38,8 @@ func createObservationsEndpoint(externalURL string) *Endpoint {
 			{models.HTTPOperationGet, "/v1.0/observations{id}", HandleGetObservation},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/observations", HandleGetObservationsByDatastream},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/observations/{params}", HandleGetObservationsByDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/observations", HandleGetObservationsByMultiDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/observations/{params}", HandleGetObservationsByMultiDatastream},
 			{models.HTTPOperationGet, "/v1.0/featureofinterest{id}/observations", HandleGetObservationsByFeatureOfInterest},
 			{models.HTTPOperationGet, "/v1.0/featuresofinterest{id}/observations", HandleGetObservationsByFeatureOfInterest},
 			{models.HTTPOperationGet, "/v1.0/featureofinterest{id}/observations/{params}", HandleGetObservationsByFeatureOfInterest},
@@ -46,25 +50,12 @@ func createObservationsEndpoint(externalURL string) *Endpoint {
 
 			{models.HTTPOperationPost, "/v1.0/observations", HandlePostObservation},
 			{models.HTTPOperationPost, "/v1.0/datastreams{id}/observations", HandlePostObservationByDatastream},
+			{models.HTTPOperationPost, "/v1.0/multidatastreams{id}/observations", HandlePostObservationByMultiDatastream},
 			{models.HTTPOperationDelete, "/v1.0/observations{id}", HandleDeleteObservation},
 			{models.HTTPOperationPatch, "/v1.0/observations{id}", HandlePatchObservation},
 			{models.HTTPOperationPut, "/v1.0/observations{id}", HandlePutObservation},
 
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations", HandleGetObservations},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations{id}", HandleGetObservation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/featuresofinterest{id}/observations", HandleGetObservationsByFeatureOfInterest},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/featuresofinterest{id}/observations/{params}", HandleGetObservationsByFeatureOfInterest},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/observations", HandleGetObservationsByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/observations/{params}", HandleGetObservationsByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations{id}/{params}", HandleGetObservation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations{id}/{params}/$value", HandleGetObservation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observations/{params}", HandleGetObservations},
-
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/observations", HandlePostObservation},
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/datastreams{id}/observations", HandlePostObservationByDatastream},
-			{models.HTTPOperationDelete, "/v1.0/{c:.*}/observations{id}", HandleDeleteObservation},
-			{models.HTTPOperationPatch, "/v1.0/{c:.*}/observations{id}", HandlePatchObservation},
-			{models.HTTPOperationPut, "/v1.0/{c:.*}/observations{id}", HandlePutObservation},
+			{models.HTTPOperationPut, "/v1.0/observations{id}/{navigation}/$ref", HandlePutRef},
 		},
 	}
 }
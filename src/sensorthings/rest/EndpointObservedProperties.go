@@ -18,6 +18,7 @@ func createObservedPropertiesEndpoint(externalURL string) *Endpoint {
 		},
 		SupportedExpandParams: []string{
 			"Datastreams",
+			"MultiDatastreams",
 		},
 		SupportedSelectParams: []string{
 			"id",
@@ -25,12 +26,15 @@ func createObservedPropertiesEndpoint(externalURL string) *Endpoint {
 			"definition",
 			"description",
 			"Datastreams",
+			"MultiDatastreams",
 		},
 		Operations: []models.EndpointOperation{
 			{models.HTTPOperationGet, "/v1.0/observedproperties", HandleGetObservedProperties},
 			{models.HTTPOperationGet, "/v1.0/observedproperties{id}", HandleGetObservedProperty},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/observedproperty", HandleGetObservedPropertyByDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/observedproperties", HandleGetObservedPropertiesByMultiDatastream},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/observedproperty/{params}", HandleGetObservedPropertyByDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/observedproperties/{params}", HandleGetObservedPropertiesByMultiDatastream},
 			{models.HTTPOperationGet, "/v1.0/observedproperties{id}/{params}", HandleGetObservedProperty},
 			{models.HTTPOperationGet, "/v1.0/observedproperties{id}/{params}/$value", HandleGetObservedProperty},
 			{models.HTTPOperationGet, "/v1.0/observedproperties/{params}", HandleGetObservedProperties},
@@ -41,19 +45,7 @@ func createObservedPropertiesEndpoint(externalURL string) *Endpoint {
 			{models.HTTPOperationPatch, "/v1.0/observedproperties{id}", HandlePatchObservedProperty},
 			{models.HTTPOperationPut, "/v1.0/observedproperties{id}", HandlePutObservedProperty},
 
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observedproperties", HandleGetObservedProperties},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observedproperties{id}", HandleGetObservedProperty},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/observedproperty", HandleGetObservedPropertyByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/observedproperty/{params}", HandleGetObservedPropertyByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observedproperties{id}/{params}", HandleGetObservedProperty},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observedproperties{id}/{params}/$value", HandleGetObservedProperty},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observedproperties/{params}", HandleGetObservedProperties},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/observedproperties/{params}/$value", HandleGetObservedProperties},
-
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/observedproperties", HandlePostObservedProperty},
-			{models.HTTPOperationDelete, "/v1.0/{c:.*}/observedproperties{id}", HandleDeleteObservedProperty},
-			{models.HTTPOperationPatch, "/v1.0/{c:.*}/observedproperties{id}", HandlePatchObservedProperty},
-			{models.HTTPOperationPut, "/v1.0/{c:.*}/observedproperties{id}", HandlePutObservedProperty},
+			{models.HTTPOperationPost, "/v1.0/observedproperties{id}/{navigation}/$ref", HandlePostRef},
 		},
 	}
 }
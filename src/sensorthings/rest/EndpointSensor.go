@@ -17,7 +17,8 @@ func createSensorsEndpoint(externalURL string) *Endpoint {
 			odata.QueryOptionExpand, odata.QueryOptionSelect, odata.QueryOptionFilter,
 		},
 		SupportedExpandParams: []string{
-			"Datastream",
+			"Datastreams",
+			"MultiDatastreams",
 		},
 		SupportedSelectParams: []string{
 			"id",
@@ -25,14 +26,18 @@ func createSensorsEndpoint(externalURL string) *Endpoint {
 			"description",
 			"encodingType",
 			"metadata",
-			"Datastream",
+			"Datastreams",
+			"MultiDatastreams",
 		},
 		Operations: []models.EndpointOperation{
 			{models.HTTPOperationGet, "/v1.0/sensors", HandleGetSensors},
 			{models.HTTPOperationGet, "/v1.0/sensors{id}", HandleGetSensor},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/sensor", HandleGetSensorByDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/sensor", HandleGetSensorByMultiDatastream},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/sensor/{params}", HandleGetSensorByDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/sensor/{params}", HandleGetSensorByMultiDatastream},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/sensor/{params}/$value", HandleGetSensorByDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/sensor/{params}/$value", HandleGetSensorByMultiDatastream},
 			{models.HTTPOperationGet, "/v1.0/sensors{id}/{params}", HandleGetSensor},
 			{models.HTTPOperationGet, "/v1.0/sensors{id}/{params}/$value", HandleGetSensor},
 			{models.HTTPOperationGet, "/v1.0/sensors/{params}", HandleGetSensors},
@@ -42,19 +47,7 @@ func createSensorsEndpoint(externalURL string) *Endpoint {
 			{models.HTTPOperationPatch, "/v1.0/sensors{id}", HandlePatchSensor},
 			{models.HTTPOperationPut, "/v1.0/sensors{id}", HandlePutSensor},
 
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/sensors", HandleGetSensors},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/sensors{id}", HandleGetSensor},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/sensor", HandleGetSensorByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/sensor/{params}", HandleGetSensorByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/sensor/{params}/$value", HandleGetSensorByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/sensors{id}/{params}", HandleGetSensor},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/sensors{id}/{params}/$value", HandleGetSensor},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/sensors/{params}", HandleGetSensors},
-
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/sensors", HandlePostSensors},
-			{models.HTTPOperationDelete, "/v1.0/{c:.*}/sensors{id}", HandleDeleteSensor},
-			{models.HTTPOperationPatch, "/v1.0/{c:.*}/sensors{id}", HandlePatchSensor},
-			{models.HTTPOperationPut, "/v1.0/{c:.*}/sensors{id}", HandlePutSensor},
+			{models.HTTPOperationPost, "/v1.0/sensors{id}/{navigation}/$ref", HandlePostRef},
 		},
 	}
 }
@@ -0,0 +1,46 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+func createTasksEndpoint(externalURL string) *Endpoint {
+	return &Endpoint{
+		Name:       "Tasks",
+		OutputInfo: true,
+		URL:        fmt.Sprintf("%s/%s/%s", externalURL, models.APIPrefix, fmt.Sprintf("%v", "Tasks")),
+		SupportedQueryOptions: []odata.QueryOptionType{
+			odata.QueryOptionTop, odata.QueryOptionSkip, odata.QueryOptionOrderBy, odata.QueryOptionCount, odata.QueryOptionResultFormat,
+			odata.QueryOptionExpand, odata.QueryOptionSelect, odata.QueryOptionFilter,
+		},
+		SupportedExpandParams: []string{
+			"TaskingCapability",
+		},
+		SupportedSelectParams: []string{
+			"id",
+			"creationTime",
+			"taskingParameters",
+			"TaskingCapability",
+		},
+		Operations: []models.EndpointOperation{
+			{models.HTTPOperationGet, "/v1.0/tasks", HandleGetTasks},
+			{models.HTTPOperationGet, "/v1.0/tasks{id}", HandleGetTask},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/tasks", HandleGetTasksByTaskingCapability},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/tasks/{params}", HandleGetTasksByTaskingCapability},
+			{models.HTTPOperationGet, "/v1.0/tasks{id}/{params}", HandleGetTask},
+			{models.HTTPOperationGet, "/v1.0/tasks{id}/{params}/$value", HandleGetTask},
+			{models.HTTPOperationGet, "/v1.0/tasks/{params}", HandleGetTasks},
+
+			{models.HTTPOperationPost, "/v1.0/tasks", HandlePostTask},
+			{models.HTTPOperationPost, "/v1.0/taskingcapabilities{id}/tasks", HandlePostTaskByTaskingCapability},
+			{models.HTTPOperationDelete, "/v1.0/tasks{id}", HandleDeleteTask},
+			{models.HTTPOperationPatch, "/v1.0/tasks{id}", HandlePatchTask},
+			{models.HTTPOperationPut, "/v1.0/tasks{id}", HandlePutTask},
+
+			{models.HTTPOperationPut, "/v1.0/tasks{id}/{navigation}/$ref", HandlePutRef},
+		},
+	}
+}
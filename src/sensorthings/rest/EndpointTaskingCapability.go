@@ -0,0 +1,58 @@
+package rest
+
+import (
+	"fmt"
+
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+func createTaskingCapabilitiesEndpoint(externalURL string) *Endpoint {
+	return &Endpoint{
+		Name:       "TaskingCapabilities",
+		OutputInfo: true,
+		URL:        fmt.Sprintf("%s/%s/%s", externalURL, models.APIPrefix, fmt.Sprintf("%v", "TaskingCapabilities")),
+		SupportedQueryOptions: []odata.QueryOptionType{
+			odata.QueryOptionTop, odata.QueryOptionSkip, odata.QueryOptionOrderBy, odata.QueryOptionCount, odata.QueryOptionResultFormat,
+			odata.QueryOptionExpand, odata.QueryOptionSelect, odata.QueryOptionFilter,
+		},
+		SupportedExpandParams: []string{
+			"Thing",
+			"Actuator",
+			"Tasks",
+		},
+		SupportedSelectParams: []string{
+			"id",
+			"name",
+			"description",
+			"properties",
+			"taskingParameters",
+			"Thing",
+			"Actuator",
+			"Tasks",
+		},
+		Operations: []models.EndpointOperation{
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities", HandleGetTaskingCapabilities},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}", HandleGetTaskingCapability},
+			{models.HTTPOperationGet, "/v1.0/things{id}/taskingcapabilities", HandleGetTaskingCapabilitiesByThing},
+			{models.HTTPOperationGet, "/v1.0/things{id}/taskingcapabilities/{params}", HandleGetTaskingCapabilitiesByThing},
+			{models.HTTPOperationGet, "/v1.0/actuators{id}/taskingcapabilities", HandleGetTaskingCapabilitiesByActuator},
+			{models.HTTPOperationGet, "/v1.0/actuators{id}/taskingcapabilities/{params}", HandleGetTaskingCapabilitiesByActuator},
+			{models.HTTPOperationGet, "/v1.0/tasks{id}/taskingcapability", HandleGetTaskingCapabilityByTask},
+			{models.HTTPOperationGet, "/v1.0/tasks{id}/taskingcapability/{params}", HandleGetTaskingCapabilityByTask},
+			{models.HTTPOperationGet, "/v1.0/tasks{id}/taskingcapability/{params}/$value", HandleGetTaskingCapabilityByTask},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/{params}", HandleGetTaskingCapability},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/{params}/$value", HandleGetTaskingCapability},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities/{params}", HandleGetTaskingCapabilities},
+
+			{models.HTTPOperationPost, "/v1.0/taskingcapabilities", HandlePostTaskingCapability},
+			{models.HTTPOperationPost, "/v1.0/things{id}/taskingcapabilities", HandlePostTaskingCapabilityByThing},
+			{models.HTTPOperationDelete, "/v1.0/taskingcapabilities{id}", HandleDeleteTaskingCapability},
+			{models.HTTPOperationPatch, "/v1.0/taskingcapabilities{id}", HandlePatchTaskingCapability},
+			{models.HTTPOperationPut, "/v1.0/taskingcapabilities{id}", HandlePutTaskingCapability},
+
+			{models.HTTPOperationPost, "/v1.0/taskingcapabilities{id}/{navigation}/$ref", HandlePostRef},
+			{models.HTTPOperationPut, "/v1.0/taskingcapabilities{id}/{navigation}/$ref", HandlePutRef},
+		},
+	}
+}
@@ -19,6 +19,8 @@ func createThingsEndpoint(externalURL string) *Endpoint {
 		SupportedExpandParams: []string{
 			"Locations",
 			"Datastreams",
+			"MultiDatastreams",
+			"TaskingCapabilities",
 			"HistoricalLocations",
 		},
 		SupportedSelectParams: []string{
@@ -28,6 +30,8 @@ func createThingsEndpoint(externalURL string) *Endpoint {
 			"description",
 			"Locations",
 			"Datastreams",
+			"MultiDatastreams",
+			"TaskingCapabilities",
 			"HistoricalLocations",
 		},
 		Operations: []models.EndpointOperation{
@@ -37,8 +41,14 @@ func createThingsEndpoint(externalURL string) *Endpoint {
 			{models.HTTPOperationGet, "/v1.0/historicallocations{id}/thing/{params}", HandleGetThingByHistoricalLocation},
 			{models.HTTPOperationGet, "/v1.0/historicallocations{id}/thing/{params}/$value", HandleGetThingByHistoricalLocation},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/thing", HandleGetThingByDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/thing", HandleGetThingByMultiDatastream},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/thing", HandleGetThingByTaskingCapability},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/thing/{params}", HandleGetThingByDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/thing/{params}", HandleGetThingByMultiDatastream},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/thing/{params}", HandleGetThingByTaskingCapability},
 			{models.HTTPOperationGet, "/v1.0/datastreams{id}/thing/{params}/$value", HandleGetThingByDatastream},
+			{models.HTTPOperationGet, "/v1.0/multidatastreams{id}/thing/{params}/$value", HandleGetThingByMultiDatastream},
+			{models.HTTPOperationGet, "/v1.0/taskingcapabilities{id}/thing/{params}/$value", HandleGetThingByTaskingCapability},
 			{models.HTTPOperationGet, "/v1.0/locations{id}/things", HandleGetThingsByLocation},
 			{models.HTTPOperationGet, "/v1.0/locations{id}/things/{params}", HandleGetThingsByLocation},
 			{models.HTTPOperationGet, "/v1.0/things{id}/{params}", HandleGetThing},
@@ -50,24 +60,8 @@ func createThingsEndpoint(externalURL string) *Endpoint {
 			{models.HTTPOperationPatch, "/v1.0/things{id}", HandlePatchThing},
 			{models.HTTPOperationPut, "/v1.0/things{id}", HandlePutThing},
 
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things", HandleGetThings},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things{id}", HandleGetThing},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations{id}/things", HandleGetThingsByLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/locations{id}/things/{params}", HandleGetThingsByLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/thing", HandleGetThingByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/thing/{params}", HandleGetThingByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/datastreams{id}/thing/{params}/$value", HandleGetThingByDatastream},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations{id}/thing", HandleGetThingByHistoricalLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations{id}/thing/{params}", HandleGetThingByHistoricalLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/historicallocations{id}/thing/{params}/$value", HandleGetThingByHistoricalLocation},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things{id}/{params}", HandleGetThing},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things{id}/{params}/$value", HandleGetThing},
-			{models.HTTPOperationGet, "/v1.0/{c:.*}/things/{params}", HandleGetThings},
-
-			{models.HTTPOperationPost, "/v1.0/{c:.*}/things", HandlePostThing},
-			{models.HTTPOperationDelete, "/v1.0/{c:.*}/things{id}", HandleDeleteThing},
-			{models.HTTPOperationPatch, "/v1.0/{c:.*}/things{id}", HandlePatchThing},
-			{models.HTTPOperationPut, "/v1.0/{c:.*}/things{id}", HandlePutThing},
+			{models.HTTPOperationPost, "/v1.0/things{id}/{navigation}/$ref", HandlePostRef},
+			{models.HTTPOperationDelete, "/v1.0/things{id}/{navigation}/$ref", HandleDeleteRef},
 		},
 	}
 }
@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// HandleGetActuators ...
+func HandleGetActuators(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetActuators(q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetActuator ...
+func HandleGetActuator(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetActuator(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetActuatorByTaskingCapability ...
+func HandleGetActuatorByTaskingCapability(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetActuatorByTaskingCapability(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandlePostActuator ...
+func HandlePostActuator(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	actuator := &entities.Actuator{}
+	handle := func() (interface{}, []error) { return a.PostActuator(actuator) }
+	handlePostRequest(w, endpoint, r, actuator, &handle)
+}
+
+// HandleDeleteActuator ...
+func HandleDeleteActuator(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func() error { return a.DeleteActuator(getEntityID(r)) }
+	handleDeleteRequest(w, endpoint, r, &handle)
+}
+
+// HandlePatchActuator ...
+func HandlePatchActuator(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	actuator := &entities.Actuator{}
+	handle := func() (interface{}, error) { return a.PatchActuator(getEntityID(r), actuator) }
+	current := func(tx models.API) (interface{}, error) { return tx.GetActuator(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutActuator(getEntityID(r), actuator) }
+	handlePatchRequest(w, endpoint, r, a, actuator, &handle, &current, &replace)
+}
+
+// HandlePutActuator ...
+func HandlePutActuator(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	actuator := &entities.Actuator{}
+	handle := func() (interface{}, []error) { return a.PutActuator(getEntityID(r), actuator) }
+	handlePutRequest(w, endpoint, r, actuator, &handle)
+}
@@ -88,7 +88,9 @@ func HandlePatchDatastream(w http.ResponseWriter, r *http.Request, endpoint *mod
 	a := *api
 	ds := &entities.Datastream{}
 	handle := func() (interface{}, error) { return a.PatchDatastream(getEntityID(r), ds) }
-	handlePatchRequest(w, endpoint, r, ds, &handle)
+	current := func(tx models.API) (interface{}, error) { return tx.GetDatastream(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutDatastream(getEntityID(r), ds) }
+	handlePatchRequest(w, endpoint, r, a, ds, &handle, &current, &replace)
 }
 
 // HandlePutDatastream ...
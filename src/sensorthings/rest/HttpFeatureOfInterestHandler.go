@@ -53,7 +53,9 @@ func HandlePatchFeatureOfInterest(w http.ResponseWriter, r *http.Request, endpoi
 	a := *api
 	foi := &entities.FeatureOfInterest{}
 	handle := func() (interface{}, error) { return a.PatchFeatureOfInterest(getEntityID(r), foi) }
-	handlePatchRequest(w, endpoint, r, foi, &handle)
+	current := func(tx models.API) (interface{}, error) { return tx.GetFeatureOfInterest(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutFeatureOfInterest(getEntityID(r), foi) }
+	handlePatchRequest(w, endpoint, r, a, foi, &handle, &current, &replace)
 }
 
 // HandlePutFeatureOfInterest ...
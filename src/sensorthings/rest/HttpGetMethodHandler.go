@@ -36,5 +36,5 @@ func handleGetRequest(w http.ResponseWriter, e *models.Endpoint, r *http.Request
 		return
 	}
 
-	sendJSONResponse(w, http.StatusOK, data, queryOptions)
+	sendResponse(w, r, http.StatusOK, data, queryOptions)
 }
@@ -73,5 +73,7 @@ func HandlePatchHistoricalLocations(w http.ResponseWriter, r *http.Request, endp
 	a := *api
 	hl := &entities.HistoricalLocation{}
 	handle := func() (interface{}, error) { return a.PatchHistoricalLocation(getEntityID(r), hl) }
-	handlePatchRequest(w, endpoint, r, hl, &handle)
+	current := func(tx models.API) (interface{}, error) { return tx.GetHistoricalLocation(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutHistoricalLocation(getEntityID(r), hl) }
+	handlePatchRequest(w, endpoint, r, a, hl, &handle, &current, &replace)
 }
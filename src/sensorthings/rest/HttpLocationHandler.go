@@ -70,7 +70,9 @@ func HandlePatchLocation(w http.ResponseWriter, r *http.Request, endpoint *model
 	a := *api
 	loc := &entities.Location{}
 	handle := func() (interface{}, error) { return a.PatchLocation(getEntityID(r), loc) }
-	handlePatchRequest(w, endpoint, r, loc, &handle)
+	current := func(tx models.API) (interface{}, error) { return tx.GetLocation(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutLocation(getEntityID(r), loc) }
+	handlePatchRequest(w, endpoint, r, a, loc, &handle, &current, &replace)
 }
 
 // HandlePutLocation patches a location by given id
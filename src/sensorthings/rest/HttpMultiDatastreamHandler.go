@@ -0,0 +1,102 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// HandleGetMultiDatastreams retrieves MultiDatastreams based on Query Parameters
+func HandleGetMultiDatastreams(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) { return a.GetMultiDatastreams(q, path) }
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetMultiDatastream retrieves a MultiDatastream by given id
+func HandleGetMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetMultiDatastream(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetMultiDatastreamByObservation ...
+func HandleGetMultiDatastreamByObservation(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetMultiDatastreamByObservation(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetMultiDatastreamsByThing ...
+func HandleGetMultiDatastreamsByThing(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetMultiDatastreamsByThing(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetMultiDatastreamsBySensor ...
+func HandleGetMultiDatastreamsBySensor(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetMultiDatastreamsBySensor(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetMultiDatastreamsByObservedProperty ...
+func HandleGetMultiDatastreamsByObservedProperty(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetMultiDatastreamsByObservedProperty(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandlePostMultiDatastream ...
+func HandlePostMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	ds := &entities.MultiDatastream{}
+	handle := func() (interface{}, []error) { return a.PostMultiDatastream(ds) }
+	handlePostRequest(w, endpoint, r, ds, &handle)
+}
+
+// HandlePostMultiDatastreamByThing ...
+func HandlePostMultiDatastreamByThing(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	ds := &entities.MultiDatastream{}
+	handle := func() (interface{}, []error) { return a.PostMultiDatastreamByThing(getEntityID(r), ds) }
+	handlePostRequest(w, endpoint, r, ds, &handle)
+}
+
+// HandleDeleteMultiDatastream ...
+func HandleDeleteMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func() error { return a.DeleteMultiDatastream(getEntityID(r)) }
+	handleDeleteRequest(w, endpoint, r, &handle)
+}
+
+// HandlePatchMultiDatastream ...
+func HandlePatchMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	ds := &entities.MultiDatastream{}
+	handle := func() (interface{}, error) { return a.PatchMultiDatastream(getEntityID(r), ds) }
+	current := func(tx models.API) (interface{}, error) { return tx.GetMultiDatastream(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutMultiDatastream(getEntityID(r), ds) }
+	handlePatchRequest(w, endpoint, r, a, ds, &handle, &current, &replace)
+}
+
+// HandlePutMultiDatastream ...
+func HandlePutMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	ds := &entities.MultiDatastream{}
+	handle := func() (interface{}, []error) { return a.PutMultiDatastream(getEntityID(r), ds) }
+	handlePutRequest(w, endpoint, r, ds, &handle)
+}
@@ -1,8 +1,12 @@
 package rest
 
 import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
 	"net/http"
 
+	gostErrors "github.com/geodan/gost/src/errors"
 	"github.com/geodan/gost/src/sensorthings/entities"
 	"github.com/geodan/gost/src/sensorthings/models"
 	"github.com/geodan/gost/src/sensorthings/odata"
@@ -42,6 +46,15 @@ func HandleGetObservationsByDatastream(w http.ResponseWriter, r *http.Request, e
 	handleGetRequest(w, endpoint, r, &handle)
 }
 
+// HandleGetObservationsByMultiDatastream ...
+func HandleGetObservationsByMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetObservationsByMultiDatastream(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
 // HandlePostObservation ...
 func HandlePostObservation(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
 	a := *api
@@ -58,6 +71,39 @@ func HandlePostObservationByDatastream(w http.ResponseWriter, r *http.Request, e
 	handlePostRequest(w, endpoint, r, ob, &handle)
 }
 
+// HandlePostObservationByMultiDatastream ...
+func HandlePostObservationByMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	ob := &entities.Observation{}
+	handle := func() (interface{}, []error) { return a.PostObservationByMultiDatastream(getEntityID(r), ob) }
+	handlePostRequest(w, endpoint, r, ob, &handle)
+}
+
+// HandlePostCreateObservations creates the observations send as data arrays, the response contains
+// the self link of every created observation or the error of a row which could not be created
+func HandlePostCreateObservations(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	if !checkContentType(w, r) {
+		return
+	}
+
+	dataArrays := []*models.DataArrayRequest{}
+	byteData, _ := ioutil.ReadAll(r.Body)
+	if err := json.Unmarshal(byteData, &dataArrays); err != nil {
+		sendError(w, []error{gostErrors.NewBadRequestError(errors.New("Unable to parse CreateObservations request"))})
+		return
+	}
+
+	results, errs := a.CreateObservations(dataArrays)
+	if errs != nil {
+		sendError(w, errs)
+		return
+	}
+
+	sendJSONResponse(w, http.StatusCreated, results, nil)
+}
+
 // HandleDeleteObservation ...
 func HandleDeleteObservation(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
 	a := *api
@@ -70,7 +116,9 @@ func HandlePatchObservation(w http.ResponseWriter, r *http.Request, endpoint *mo
 	a := *api
 	ob := &entities.Observation{}
 	handle := func() (interface{}, error) { return a.PatchObservation(getEntityID(r), ob) }
-	handlePatchRequest(w, endpoint, r, ob, &handle)
+	current := func(tx models.API) (interface{}, error) { return tx.GetObservation(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutObservation(getEntityID(r), ob) }
+	handlePatchRequest(w, endpoint, r, a, ob, &handle, &current, &replace)
 }
 
 // HandlePutObservation ...
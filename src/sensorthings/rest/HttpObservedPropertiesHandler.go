@@ -53,7 +53,9 @@ func HandlePatchObservedProperty(w http.ResponseWriter, r *http.Request, endpoin
 	a := *api
 	op := &entities.ObservedProperty{}
 	handle := func() (interface{}, error) { return a.PatchObservedProperty(getEntityID(r), op) }
-	handlePatchRequest(w, endpoint, r, op, &handle)
+	current := func(tx models.API) (interface{}, error) { return tx.GetObservedProperty(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutObservedProperty(getEntityID(r), op) }
+	handlePatchRequest(w, endpoint, r, a, op, &handle, &current, &replace)
 }
 
 // HandlePutObservedProperty posts a new ObservedProperty
@@ -63,3 +65,12 @@ func HandlePutObservedProperty(w http.ResponseWriter, r *http.Request, endpoint
 	handle := func() (interface{}, []error) { return a.PutObservedProperty(getEntityID(r), op) }
 	handlePutRequest(w, endpoint, r, op, &handle)
 }
+
+// HandleGetObservedPropertiesByMultiDatastream retrieves the ObservedProperties of the given MultiDatastream id
+func HandleGetObservedPropertiesByMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetObservedPropertiesByMultiDatastream(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
@@ -3,29 +3,53 @@ package rest
 import (
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/geodan/gost/src/sensorthings/entities"
 	"github.com/geodan/gost/src/sensorthings/models"
 )
 
-// handlePatchRequest todo: currently almost same as handlePostRequest, merge if it stays like this
-func handlePatchRequest(w http.ResponseWriter, e *models.Endpoint, r *http.Request, entity entities.Entity, h *func() (interface{}, error)) {
+// handlePatchRequest patches the entity with the non empty properties of the body, a JSON Merge Patch or JSON Patch
+// document is applied to the current entity which is then replaced by the patched entity. The entity is locked
+// while the document is applied so concurrent patches of the entity are applied one after the other
+func handlePatchRequest(w http.ResponseWriter, e *models.Endpoint, r *http.Request, a models.API, entity entities.Entity, h *func() (interface{}, error), current *func(tx models.API) (interface{}, error), replace *func(tx models.API) (interface{}, []error)) {
 	w.Header().Add("Access-Control-Allow-Origin", "*")
-	if !checkContentType(w, r) {
+	contentType := r.Header.Get("Content-Type")
+	patchDocument := strings.Contains(contentType, mergePatchContentType) || strings.Contains(contentType, jsonPatchContentType)
+	if !patchDocument && !checkContentType(w, r) {
 		return
 	}
 
 	byteData, _ := ioutil.ReadAll(r.Body)
-	err := entity.ParseEntity(byteData)
-	if err != nil {
-		sendError(w, []error{err})
-		return
+	var data interface{}
+	var errs []error
+	if patchDocument {
+		err := a.WithTransaction(func(tx models.API) error {
+			if data, errs = patchCurrentEntity(tx, entity, getEntityID(r), contentType, byteData, *current, *replace); errs != nil {
+				return errs[0]
+			}
+
+			return nil
+		})
+
+		if err != nil && errs == nil {
+			errs = []error{err}
+		}
+	} else {
+		if err := entity.ParseEntity(byteData); err != nil {
+			sendError(w, []error{err})
+			return
+		}
+
+		handle := *h
+		var err2 error
+		if data, err2 = handle(); err2 != nil {
+			errs = []error{err2}
+		}
 	}
 
-	handle := *h
-	data, err2 := handle()
-	if err2 != nil {
-		sendError(w, []error{err2})
+	if errs != nil {
+		sendError(w, errs)
 		return
 	}
 
@@ -33,3 +57,31 @@ func handlePatchRequest(w http.ResponseWriter, e *models.Endpoint, r *http.Reque
 
 	sendJSONResponse(w, http.StatusOK, data, nil)
 }
+
+// patchCurrentEntity locks the entity, applies the patch document to the current entity returned by current
+// and replaces the entity with the patched document, the links and id of the entity are no part of the document
+func patchCurrentEntity(tx models.API, entity entities.Entity, id interface{}, contentType string, patch []byte, current func(tx models.API) (interface{}, error), replace func(tx models.API) (interface{}, []error)) (interface{}, []error) {
+	if err := tx.LockEntity(entity.GetEntityType(), id); err != nil {
+		return nil, []error{err}
+	}
+
+	e, err := current(tx)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	document, err := entityDocument(e)
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	if document, err = applyPatchDocument(contentType, document, patch); err != nil {
+		return nil, []error{err}
+	}
+
+	if err = entity.ParseEntity(document); err != nil {
+		return nil, []error{err}
+	}
+
+	return replace(tx)
+}
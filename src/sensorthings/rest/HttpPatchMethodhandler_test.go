@@ -0,0 +1,59 @@
+package rest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// lockAPI records the calls made on the api during a patch
+type lockAPI struct {
+	models.API
+	calls   []string
+	lockErr error
+}
+
+func (a *lockAPI) LockEntity(entityType entities.EntityType, id interface{}) error {
+	a.calls = append(a.calls, "lock "+entityType.ToString())
+	return a.lockErr
+}
+
+func TestPatchCurrentEntityShouldLockBeforeReading(t *testing.T) {
+	// arrange
+	tx := &lockAPI{}
+	thing := &entities.Thing{}
+	current := func(tx models.API) (interface{}, error) {
+		tx.(*lockAPI).calls = append(tx.(*lockAPI).calls, "current")
+		return &entities.Thing{Name: "thing", Description: "a thing"}, nil
+	}
+	replace := func(tx models.API) (interface{}, []error) {
+		tx.(*lockAPI).calls = append(tx.(*lockAPI).calls, "replace")
+		return thing, nil
+	}
+
+	// act
+	_, errs := patchCurrentEntity(tx, thing, "1", mergePatchContentType, []byte(`{"name":"patched"}`), current, replace)
+
+	// assert
+	assert.Nil(t, errs)
+	assert.Equal(t, []string{"lock Thing", "current", "replace"}, tx.calls)
+	assert.Equal(t, "patched", thing.Name)
+	assert.Equal(t, "a thing", thing.Description)
+}
+
+func TestPatchCurrentEntityShouldFailWhenLockFails(t *testing.T) {
+	// arrange
+	tx := &lockAPI{lockErr: errors.New("Thing does not exist")}
+	current := func(tx models.API) (interface{}, error) { return &entities.Thing{}, nil }
+	replace := func(tx models.API) (interface{}, []error) { return nil, nil }
+
+	// act
+	_, errs := patchCurrentEntity(tx, &entities.Thing{}, "1", mergePatchContentType, []byte(`{}`), current, replace)
+
+	// assert
+	assert.Len(t, errs, 1)
+	assert.Equal(t, []string{"lock Thing"}, tx.calls)
+}
@@ -0,0 +1,164 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/gorilla/mux"
+)
+
+// refNavigationRegex matches the navigation of a $ref request with an optional key, for example locations(3)
+var refNavigationRegex = regexp.MustCompile(`^([a-z]+)(?:\(([^()]+)\))?$`)
+
+// refKeyRegex matches the key at the end of a self link, for example http://example.org/v1.0/Locations(3)
+var refKeyRegex = regexp.MustCompile(`\(([^()]+)\)$`)
+
+// entityReference is the body of a POST or PUT on $ref, the entity is referenced by id or by self link
+type entityReference struct {
+	ID       interface{} `json:"@iot.id"`
+	SelfLink string      `json:"@iot.selfLink"`
+}
+
+// refLink holds the entities of which the link is changed by a $ref request
+type refLink struct {
+	parent   entities.EntityType
+	parentID interface{}
+	child    entities.EntityType
+	childID  interface{}
+	single   bool
+}
+
+// HandlePostRef links an entity to a collection of the entity, for example POST Things(1)/Locations/$ref
+// with body {"@iot.id": 3}
+func HandlePostRef(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(link *refLink) error {
+		if link.single {
+			return gostErrors.NewBadRequestError(fmt.Errorf("%s is single valued, use PUT to change the reference", link.child.ToString()))
+		}
+
+		return a.LinkEntities(link.parent, link.parentID, link.child, link.childID)
+	}
+	handleRefRequest(w, r, endpoint, true, handle)
+}
+
+// HandlePutRef replaces the entity of a single valued navigation property, for example PUT Datastreams(1)/Sensor/$ref
+// with body {"@iot.id": 2}
+func HandlePutRef(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(link *refLink) error {
+		if !link.single {
+			return gostErrors.NewBadRequestError(fmt.Errorf("%s is a collection, use POST to add a reference", link.child.ToString()))
+		}
+
+		return a.LinkEntities(link.parent, link.parentID, link.child, link.childID)
+	}
+	handleRefRequest(w, r, endpoint, true, handle)
+}
+
+// HandleDeleteRef removes the link between two entities, for example DELETE Things(1)/Locations(3)/$ref
+func HandleDeleteRef(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(link *refLink) error {
+		if link.childID == nil {
+			return gostErrors.NewBadRequestError(fmt.Errorf("Missing the id of the %s to remove the reference to", link.child.ToString()))
+		}
+
+		return a.UnlinkEntities(link.parent, link.parentID, link.child, link.childID)
+	}
+	handleRefRequest(w, r, endpoint, false, handle)
+}
+
+// handleRefRequest reads the link from the request and passes it to h, the referenced entity is read from the
+// body when readBody is true and from the key of the navigation otherwise
+func handleRefRequest(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, readBody bool, h func(link *refLink) error) {
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	if readBody && !checkContentType(w, r) {
+		return
+	}
+
+	link, err := getRefLink(r, *endpoint)
+	if err != nil {
+		sendError(w, []error{err})
+		return
+	}
+
+	if readBody {
+		if link.childID, err = readEntityReference(r); err != nil {
+			sendError(w, []error{err})
+			return
+		}
+	}
+
+	if err = h(link); err != nil {
+		sendError(w, []error{err})
+		return
+	}
+
+	sendJSONResponse(w, http.StatusNoContent, nil, nil)
+}
+
+// getRefLink reads the parent and navigation of a $ref request, the navigation has to be supported by the
+// endpoint of the parent
+func getRefLink(r *http.Request, endpoint models.Endpoint) (*refLink, error) {
+	parent, err := entities.EntityTypeFromString(endpoint.GetName())
+	if err != nil {
+		return nil, gostErrors.NewBadRequestError(err)
+	}
+
+	match := refNavigationRegex.FindStringSubmatch(strings.ToLower(mux.Vars(r)["navigation"]))
+	if match == nil || !containsIgnoreCase(endpoint.GetSupportedExpandParams(), match[1]) {
+		return nil, gostErrors.NewRequestNotFound(fmt.Errorf("%s has no navigation property %s", parent.ToString(), mux.Vars(r)["navigation"]))
+	}
+
+	child := entities.StringEntityMap[match[1]]
+	link := &refLink{
+		parent:   parent,
+		parentID: getEntityID(r),
+		child:    child,
+		single:   match[1] == strings.ToLower(child.ToString()),
+	}
+
+	if len(match[2]) > 0 {
+		link.childID = match[2]
+	}
+
+	return link, nil
+}
+
+// readEntityReference returns the id of the entity referenced in the body by @iot.id or @iot.selfLink
+func readEntityReference(r *http.Request) (interface{}, error) {
+	byteData, _ := ioutil.ReadAll(r.Body)
+	ref := entityReference{}
+	if err := json.Unmarshal(byteData, &ref); err != nil {
+		return nil, gostErrors.NewBadRequestError(errors.New("Unable to parse the reference, expected {\"@iot.id\": id}"))
+	}
+
+	if ref.ID != nil {
+		return ref.ID, nil
+	}
+
+	if match := refKeyRegex.FindStringSubmatch(ref.SelfLink); match != nil {
+		return match[1], nil
+	}
+
+	return nil, gostErrors.NewBadRequestError(errors.New("Missing @iot.id or @iot.selfLink of the referenced entity"))
+}
+
+func containsIgnoreCase(values []string, value string) bool {
+	for _, v := range values {
+		if strings.ToLower(v) == strings.ToLower(value) {
+			return true
+		}
+	}
+
+	return false
+}
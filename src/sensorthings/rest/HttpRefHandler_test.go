@@ -0,0 +1,52 @@
+package rest
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadEntityReference(t *testing.T) {
+	// arrange
+	byID, _ := http.NewRequest("POST", "/v1.0/things(1)/locations/$ref", bytes.NewBufferString(`{"@iot.id": 3}`))
+	bySelfLink, _ := http.NewRequest("POST", "/v1.0/things(1)/locations/$ref", bytes.NewBufferString(`{"@iot.selfLink": "http://example.org/v1.0/Locations(4)"}`))
+	empty, _ := http.NewRequest("POST", "/v1.0/things(1)/locations/$ref", bytes.NewBufferString(`{}`))
+
+	// act
+	id1, err1 := readEntityReference(byID)
+	id2, err2 := readEntityReference(bySelfLink)
+	_, err3 := readEntityReference(empty)
+
+	// assert
+	assert.Nil(t, err1)
+	assert.Equal(t, float64(3), id1)
+	assert.Nil(t, err2)
+	assert.Equal(t, "4", id2)
+	assert.NotNil(t, err3)
+}
+
+func TestGetRefLink(t *testing.T) {
+	// arrange
+	var endpoint models.Endpoint = createThingsEndpoint("")
+	r, _ := http.NewRequest("DELETE", "/v1.0/things(1)/locations(3)/$ref", nil)
+	r = mux.SetURLVars(r, map[string]string{"id": "(1)", "navigation": "locations(3)"})
+	invalid := mux.SetURLVars(r, map[string]string{"id": "(1)", "navigation": "sensors"})
+
+	// act
+	link, err := getRefLink(r, endpoint)
+	_, err2 := getRefLink(invalid, endpoint)
+
+	// assert
+	assert.Nil(t, err)
+	assert.Equal(t, entities.EntityTypeThing, link.parent)
+	assert.Equal(t, "1", link.parentID)
+	assert.Equal(t, entities.EntityTypeLocation, link.child)
+	assert.Equal(t, "3", link.childID)
+	assert.False(t, link.single)
+	assert.NotNil(t, err2)
+}
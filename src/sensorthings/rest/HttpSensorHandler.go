@@ -53,7 +53,9 @@ func HandlePatchSensor(w http.ResponseWriter, r *http.Request, endpoint *models.
 	a := *api
 	sensor := &entities.Sensor{}
 	handle := func() (interface{}, error) { return a.PatchSensor(getEntityID(r), sensor) }
-	handlePatchRequest(w, endpoint, r, sensor, &handle)
+	current := func(tx models.API) (interface{}, error) { return tx.GetSensor(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutSensor(getEntityID(r), sensor) }
+	handlePatchRequest(w, endpoint, r, a, sensor, &handle, &current, &replace)
 }
 
 // HandlePutSensor ...
@@ -63,3 +65,12 @@ func HandlePutSensor(w http.ResponseWriter, r *http.Request, endpoint *models.En
 	handle := func() (interface{}, []error) { return a.PutSensor(getEntityID(r), sensor) }
 	handlePutRequest(w, endpoint, r, sensor, &handle)
 }
+
+// HandleGetSensorByMultiDatastream ...
+func HandleGetSensorByMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetSensorByMultiDatastream(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
@@ -0,0 +1,77 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// HandleGetTasks ...
+func HandleGetTasks(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetTasks(q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetTask ...
+func HandleGetTask(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetTask(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetTasksByTaskingCapability ...
+func HandleGetTasksByTaskingCapability(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetTasksByTaskingCapability(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandlePostTask ...
+func HandlePostTask(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	task := &entities.Task{}
+	handle := func() (interface{}, []error) { return a.PostTask(task) }
+	handlePostRequest(w, endpoint, r, task, &handle)
+}
+
+// HandlePostTaskByTaskingCapability ...
+func HandlePostTaskByTaskingCapability(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	task := &entities.Task{}
+	handle := func() (interface{}, []error) { return a.PostTaskByTaskingCapability(getEntityID(r), task) }
+	handlePostRequest(w, endpoint, r, task, &handle)
+}
+
+// HandleDeleteTask ...
+func HandleDeleteTask(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func() error { return a.DeleteTask(getEntityID(r)) }
+	handleDeleteRequest(w, endpoint, r, &handle)
+}
+
+// HandlePatchTask ...
+func HandlePatchTask(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	task := &entities.Task{}
+	handle := func() (interface{}, error) { return a.PatchTask(getEntityID(r), task) }
+	current := func(tx models.API) (interface{}, error) { return tx.GetTask(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutTask(getEntityID(r), task) }
+	handlePatchRequest(w, endpoint, r, a, task, &handle, &current, &replace)
+}
+
+// HandlePutTask ...
+func HandlePutTask(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	task := &entities.Task{}
+	handle := func() (interface{}, []error) { return a.PutTask(getEntityID(r), task) }
+	handlePutRequest(w, endpoint, r, task, &handle)
+}
@@ -0,0 +1,95 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// HandleGetTaskingCapabilities ...
+func HandleGetTaskingCapabilities(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetTaskingCapabilities(q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetTaskingCapability ...
+func HandleGetTaskingCapability(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetTaskingCapability(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetTaskingCapabilitiesByThing ...
+func HandleGetTaskingCapabilitiesByThing(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetTaskingCapabilitiesByThing(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetTaskingCapabilitiesByActuator ...
+func HandleGetTaskingCapabilitiesByActuator(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetTaskingCapabilitiesByActuator(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetTaskingCapabilityByTask ...
+func HandleGetTaskingCapabilityByTask(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetTaskingCapabilityByTask(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandlePostTaskingCapability ...
+func HandlePostTaskingCapability(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	tc := &entities.TaskingCapability{}
+	handle := func() (interface{}, []error) { return a.PostTaskingCapability(tc) }
+	handlePostRequest(w, endpoint, r, tc, &handle)
+}
+
+// HandlePostTaskingCapabilityByThing ...
+func HandlePostTaskingCapabilityByThing(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	tc := &entities.TaskingCapability{}
+	handle := func() (interface{}, []error) { return a.PostTaskingCapabilityByThing(getEntityID(r), tc) }
+	handlePostRequest(w, endpoint, r, tc, &handle)
+}
+
+// HandleDeleteTaskingCapability ...
+func HandleDeleteTaskingCapability(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func() error { return a.DeleteTaskingCapability(getEntityID(r)) }
+	handleDeleteRequest(w, endpoint, r, &handle)
+}
+
+// HandlePatchTaskingCapability ...
+func HandlePatchTaskingCapability(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	tc := &entities.TaskingCapability{}
+	handle := func() (interface{}, error) { return a.PatchTaskingCapability(getEntityID(r), tc) }
+	current := func(tx models.API) (interface{}, error) { return tx.GetTaskingCapability(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutTaskingCapability(getEntityID(r), tc) }
+	handlePatchRequest(w, endpoint, r, a, tc, &handle, &current, &replace)
+}
+
+// HandlePutTaskingCapability ...
+func HandlePutTaskingCapability(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	tc := &entities.TaskingCapability{}
+	handle := func() (interface{}, []error) { return a.PutTaskingCapability(getEntityID(r), tc) }
+	handlePutRequest(w, endpoint, r, tc, &handle)
+}
@@ -71,7 +71,9 @@ func HandlePatchThing(w http.ResponseWriter, r *http.Request, endpoint *models.E
 	a := *api
 	thing := &entities.Thing{}
 	handle := func() (interface{}, error) { return a.PatchThing(getEntityID(r), thing) }
-	handlePatchRequest(w, endpoint, r, thing, &handle)
+	current := func(tx models.API) (interface{}, error) { return tx.GetThing(getEntityID(r), nil, "") }
+	replace := func(tx models.API) (interface{}, []error) { return tx.PutThing(getEntityID(r), thing) }
+	handlePatchRequest(w, endpoint, r, a, thing, &handle, &current, &replace)
 }
 
 // HandlePutThing patches a thing by given id
@@ -81,3 +83,21 @@ func HandlePutThing(w http.ResponseWriter, r *http.Request, endpoint *models.End
 	handle := func() (interface{}, []error) { return a.PutThing(getEntityID(r), thing) }
 	handlePutRequest(w, endpoint, r, thing, &handle)
 }
+
+// HandleGetThingByMultiDatastream retrieves and sends a specific Thing based on the given MultiDatastream ID and filter
+func HandleGetThingByMultiDatastream(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetThingByMultiDatastream(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
+
+// HandleGetThingByTaskingCapability retrieves and sends the Thing of the given TaskingCapability ID and filter
+func HandleGetThingByTaskingCapability(w http.ResponseWriter, r *http.Request, endpoint *models.Endpoint, api *models.API) {
+	a := *api
+	handle := func(q *odata.QueryOptions, path string) (interface{}, error) {
+		return a.GetThingByTaskingCapability(getEntityID(r), q, path)
+	}
+	handleGetRequest(w, endpoint, r, &handle)
+}
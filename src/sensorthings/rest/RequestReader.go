@@ -63,10 +63,6 @@ func getQueryOptions(r *http.Request) (*odata.QueryOptions, []error) {
 		query["$top"] = "200"
 	}
 
-	if _, ok := query["$skip"]; !ok {
-		query["$skip"] = "0"
-	}
-
 	qo, e := odata.CreateQueryOptions(query)
 	return qo, e
 }
@@ -0,0 +1,275 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+	"github.com/geodan/gost/src/sensorthings/odata"
+)
+
+// ResponseEncoder writes the data of a successful response in a format other than the default JSON
+type ResponseEncoder interface {
+	// ContentType returns the value for the Content-Type header of the response
+	ContentType() string
+	// Encode writes the given data, an entity or ArrayResponse returned by the api, to w
+	Encode(w io.Writer, data interface{}) error
+}
+
+// encoderRegistration links a ResponseEncoder to a $resultFormat value and a media type used in the Accept header
+type encoderRegistration struct {
+	format    string
+	mediaType string
+	encoder   ResponseEncoder
+}
+
+var responseEncoders []encoderRegistration
+
+func init() {
+	RegisterResponseEncoder(odata.ResultFormatCSV, "text/csv", &csvEncoder{})
+	RegisterResponseEncoder(odata.ResultFormatGeoJSON, "application/geo+json", &geoJSONEncoder{})
+	RegisterResponseEncoder(odata.ResultFormatNDJSON, "application/x-ndjson", &ndjsonEncoder{})
+}
+
+// RegisterResponseEncoder adds an encoder which is used when $resultFormat=format is requested or when
+// mediaType is accepted by the client, registering an existing format replaces its encoder
+func RegisterResponseEncoder(format string, mediaType string, encoder ResponseEncoder) {
+	for i, r := range responseEncoders {
+		if strings.ToLower(r.format) == strings.ToLower(format) {
+			responseEncoders[i] = encoderRegistration{format, mediaType, encoder}
+			return
+		}
+	}
+
+	responseEncoders = append(responseEncoders, encoderRegistration{format, mediaType, encoder})
+}
+
+// getResponseEncoder returns the encoder for the requested $resultFormat, if no format is requested the
+// media types in the Accept header are tried in order of preference. Returns nil when JSON should be send
+func getResponseEncoder(qo *odata.QueryOptions, accept string) ResponseEncoder {
+	if qo != nil && !qo.QueryResultFormat.IsNil() {
+		for _, r := range responseEncoders {
+			if strings.ToLower(r.format) == strings.ToLower(qo.QueryResultFormat.Format()) {
+				return r.encoder
+			}
+		}
+
+		return nil
+	}
+
+	for _, mediaType := range acceptedMediaTypes(accept) {
+		if mediaType == "application/json" || mediaType == "*/*" {
+			return nil
+		}
+
+		for _, r := range responseEncoders {
+			if mediaType == r.mediaType {
+				return r.encoder
+			}
+		}
+	}
+
+	return nil
+}
+
+// mediaRange is a media type in an Accept header with its quality value
+type mediaRange struct {
+	mediaType string
+	quality   float64
+}
+
+// mediaRanges sorts media ranges by their quality value, highest quality first
+type mediaRanges []mediaRange
+
+func (m mediaRanges) Len() int           { return len(m) }
+func (m mediaRanges) Swap(i, j int)      { m[i], m[j] = m[j], m[i] }
+func (m mediaRanges) Less(i, j int) bool { return m[i].quality > m[j].quality }
+
+// acceptedMediaTypes parses an Accept header into its lower case media types ordered
+// by their quality value, media types with a quality of 0 are not accepted
+func acceptedMediaTypes(accept string) []string {
+	ranges := mediaRanges{}
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mr := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), quality: 1}
+		for _, p := range params[1:] {
+			kv := strings.SplitN(strings.TrimSpace(p), "=", 2)
+			if len(kv) == 2 && kv[0] == "q" {
+				if q, err := strconv.ParseFloat(kv[1], 64); err == nil {
+					mr.quality = q
+				}
+			}
+		}
+
+		if len(mr.mediaType) > 0 && mr.quality > 0 {
+			ranges = append(ranges, mr)
+		}
+	}
+
+	sort.Stable(ranges)
+	mediaTypes := make([]string, len(ranges))
+	for i, r := range ranges {
+		mediaTypes[i] = r.mediaType
+	}
+
+	return mediaTypes
+}
+
+// sendResponse sends the data in the format requested by $resultFormat or the Accept header,
+// $value and $ref requests and requests without an alternative format are send as JSON
+func sendResponse(w http.ResponseWriter, r *http.Request, status int, data interface{}, qo *odata.QueryOptions) {
+	encoder := getResponseEncoder(qo, r.Header.Get("Accept"))
+	if encoder == nil || data == nil || (qo != nil && (qo.QueryOptionValue || qo.QueryOptionRef)) {
+		sendJSONResponse(w, status, data, qo)
+		return
+	}
+
+	var b bytes.Buffer
+	if err := encoder.Encode(&b, data); err != nil {
+		sendError(w, []error{gostErrors.NewRequestInternalServerError(err)})
+		return
+	}
+
+	w.Header().Set("Content-Type", encoder.ContentType())
+	w.WriteHeader(status)
+	w.Write(b.Bytes())
+}
+
+// jsonField is a key and value of a decoded JSON object
+type jsonField struct {
+	key   string
+	value interface{}
+}
+
+// jsonObject is a decoded JSON object which keeps the order of its keys, the order of the
+// struct fields of an entity is used to order the columns and properties of the encoders
+type jsonObject []jsonField
+
+// get returns the value of the given key, ok is false when the key is not found
+func (o jsonObject) get(key string) (interface{}, bool) {
+	for _, f := range o {
+		if f.key == key {
+			return f.value, true
+		}
+	}
+
+	return nil, false
+}
+
+// MarshalJSON writes the object with its keys in the decoded order
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString("{")
+	for i, f := range o {
+		if i > 0 {
+			b.WriteString(",")
+		}
+
+		key, _ := json.Marshal(f.key)
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+
+		b.Write(key)
+		b.WriteString(":")
+		b.Write(value)
+	}
+
+	b.WriteString("}")
+	return b.Bytes(), nil
+}
+
+// decodeResponse converts the data of a response into ordered JSON objects, the entities found in the value
+// of an ArrayResponse are returned as entities, a single entity results in one entity. The top level fields
+// of an ArrayResponse such as @iot.count and @iot.nextLink are returned as response
+func decodeResponse(data interface{}) (entities []jsonObject, response jsonObject, err error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	decoded, err := decodeOrdered(decoder)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	object, ok := decoded.(jsonObject)
+	if !ok {
+		return nil, nil, fmt.Errorf("Unable to encode response of type %T", data)
+	}
+
+	value, isArray := object.get("value")
+	values, ok := value.([]interface{})
+	if !isArray || !ok {
+		return []jsonObject{object}, nil, nil
+	}
+
+	entities = []jsonObject{}
+	for _, v := range values {
+		if e, ok := v.(jsonObject); ok {
+			entities = append(entities, e)
+		}
+	}
+
+	response = jsonObject{}
+	for _, f := range object {
+		if f.key != "value" {
+			response = append(response, f)
+		}
+	}
+
+	return entities, response, nil
+}
+
+// decodeOrdered reads the next JSON value from the decoder, objects are decoded into a jsonObject
+func decodeOrdered(decoder *json.Decoder) (interface{}, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := jsonObject{}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			object = append(object, jsonField{key.(string), value})
+		}
+
+		_, err = decoder.Token()
+		return object, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+
+			array = append(array, value)
+		}
+
+		_, err = decoder.Token()
+		return array, err
+	}
+
+	return token, nil
+}
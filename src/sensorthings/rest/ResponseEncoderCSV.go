@@ -0,0 +1,133 @@
+package rest
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvEncoder writes the entities of a response as comma separated values, the first row holds the column names
+type csvEncoder struct{}
+
+// ContentType returns the media type for CSV
+func (e *csvEncoder) ContentType() string {
+	return "text/csv; charset=UTF-8"
+}
+
+// Encode writes every entity as a row, the columns are the flattened properties of all entities in the
+// order in which they are found. See flattenEntity for the naming of the columns
+func (e *csvEncoder) Encode(w io.Writer, data interface{}) error {
+	entities, _, err := decodeResponse(data)
+	if err != nil {
+		return err
+	}
+
+	columns := []string{}
+	known := map[string]bool{}
+	rows := make([]map[string]string, len(entities))
+	for i, entity := range entities {
+		rows[i] = map[string]string{}
+		for _, f := range flattenEntity("", entity, jsonObject{}) {
+			if !known[f.key] {
+				known[f.key] = true
+				columns = append(columns, f.key)
+			}
+
+			rows[i][f.key] = csvValue(f.value)
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	if err = writer.Write(columns); err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, c := range columns {
+			record[i] = row[c]
+		}
+
+		if err = writer.Write(record); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// flattenEntity flattens the properties of an entity into single columns, a nested object such as an expanded
+// entity or a json property results in columns named by their path: Datastream/name, properties/owner.
+// Entities of an expanded collection are named by their index: Datastreams/0/name. Geometries and arrays of
+// values are kept as a single JSON value, navigation links are left out
+func flattenEntity(prefix string, object jsonObject, columns jsonObject) jsonObject {
+	for _, f := range object {
+		if strings.HasSuffix(f.key, "@iot.navigationLink") {
+			continue
+		}
+
+		key := prefix + f.key
+		switch v := f.value.(type) {
+		case jsonObject:
+			if isGeometry(v) {
+				columns = append(columns, jsonField{key, v})
+			} else {
+				columns = flattenEntity(key+"/", v, columns)
+			}
+		case []interface{}:
+			if !containsObjects(v) {
+				columns = append(columns, jsonField{key, v})
+				continue
+			}
+
+			for i, item := range v {
+				if o, ok := item.(jsonObject); ok {
+					columns = flattenEntity(fmt.Sprintf("%s/%v/", key, i), o, columns)
+				}
+			}
+		default:
+			columns = append(columns, jsonField{key, v})
+		}
+	}
+
+	return columns
+}
+
+// containsObjects returns true when the array holds objects such as expanded entities
+func containsObjects(array []interface{}) bool {
+	for _, item := range array {
+		if _, ok := item.(jsonObject); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isGeometry returns true when the object is a GeoJSON geometry
+func isGeometry(object jsonObject) bool {
+	_, hasType := object.get("type")
+	_, hasCoordinates := object.get("coordinates")
+	_, hasGeometries := object.get("geometries")
+	return hasType && (hasCoordinates || hasGeometries)
+}
+
+// csvValue converts a flattened value into the text of a CSV field, null results in an empty field
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return fmt.Sprintf("%v", v)
+	}
+
+	b, _ := json.Marshal(value)
+	return string(b)
+}
@@ -0,0 +1,82 @@
+package rest
+
+import (
+	"io"
+)
+
+// geometryProperties are the entity properties holding a GeoJSON geometry, the first one
+// found in an entity is used as the geometry of the feature
+var geometryProperties = []string{"location", "feature", "observedArea"}
+
+// geoJSONEncoder writes the entities of a response as a GeoJSON FeatureCollection
+type geoJSONEncoder struct{}
+
+// ContentType returns the media type for GeoJSON
+func (e *geoJSONEncoder) ContentType() string {
+	return "application/geo+json; charset=UTF-8"
+}
+
+// Encode writes a FeatureCollection with a Feature for every entity, a single entity is written as a
+// Feature. The @iot.id of the entity is used as id of the feature and the geometry of a Location,
+// FeatureOfInterest or Datastream is the geometry of the feature, the other properties are the
+// properties of the feature. Entities without a geometry result in a feature with a null geometry
+func (e *geoJSONEncoder) Encode(w io.Writer, data interface{}) error {
+	entities, response, err := decodeResponse(data)
+	if err != nil {
+		return err
+	}
+
+	var result interface{}
+	if response == nil {
+		result = createFeature(entities[0])
+	} else {
+		features := make([]interface{}, len(entities))
+		for i, entity := range entities {
+			features[i] = createFeature(entity)
+		}
+
+		collection := jsonObject{{"type", "FeatureCollection"}}
+		collection = append(collection, response...)
+		collection = append(collection, jsonField{"features", features})
+		result = collection
+	}
+
+	b, err := JSONMarshal(result, true)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+// createFeature converts an entity into a GeoJSON Feature
+func createFeature(entity jsonObject) jsonObject {
+	var id, geometry interface{}
+	geometryKey := ""
+	for _, g := range geometryProperties {
+		if value, ok := entity.get(g); ok {
+			if object, ok := value.(jsonObject); ok && isGeometry(object) {
+				geometry = object
+				geometryKey = g
+				break
+			}
+		}
+	}
+
+	properties := jsonObject{}
+	for _, f := range entity {
+		if f.key == "@iot.id" {
+			id = f.value
+		} else if f.key != geometryKey {
+			properties = append(properties, f)
+		}
+	}
+
+	feature := jsonObject{{"type", "Feature"}}
+	if id != nil {
+		feature = append(feature, jsonField{"id", id})
+	}
+
+	return append(feature, jsonField{"geometry", geometry}, jsonField{"properties", properties})
+}
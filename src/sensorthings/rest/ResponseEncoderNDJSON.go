@@ -0,0 +1,35 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ndjsonEncoder writes the entities of a response as newline delimited JSON
+type ndjsonEncoder struct{}
+
+// ContentType returns the media type for newline delimited JSON
+func (e *ndjsonEncoder) ContentType() string {
+	return "application/x-ndjson; charset=UTF-8"
+}
+
+// Encode writes every entity as a single line of JSON, @iot.count and @iot.nextLink are not written
+func (e *ndjsonEncoder) Encode(w io.Writer, data interface{}) error {
+	entities, _, err := decodeResponse(data)
+	if err != nil {
+		return err
+	}
+
+	for _, entity := range entities {
+		b, err := json.Marshal(entity)
+		if err != nil {
+			return err
+		}
+
+		if _, err = w.Write(append(b, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
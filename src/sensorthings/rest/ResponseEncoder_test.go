@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/geodan/gost/src/sensorthings/models"
+	"github.com/geodan/gost/src/sensorthings/odata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetResponseEncoderByResultFormatAndAccept(t *testing.T) {
+	//arrange
+	qoCSV, _ := odata.CreateQueryOptions(map[string]string{"$resultFormat": "csv"})
+	qoDataArray, _ := odata.CreateQueryOptions(map[string]string{"$resultFormat": "dataArray"})
+	qo := &odata.QueryOptions{}
+
+	//act
+	csv := getResponseEncoder(qoCSV, "application/json")
+	dataArray := getResponseEncoder(qoDataArray, "text/csv")
+	geoJSON := getResponseEncoder(qo, "text/csv;q=0.5, application/geo+json")
+	json := getResponseEncoder(qo, "application/json, application/x-ndjson")
+	ndjson := getResponseEncoder(qo, "application/x-ndjson, */*;q=0.1")
+
+	//assert
+	assert.IsType(t, &csvEncoder{}, csv)
+	assert.Nil(t, dataArray, "dataArray should be send as JSON")
+	assert.IsType(t, &geoJSONEncoder{}, geoJSON)
+	assert.Nil(t, json)
+	assert.IsType(t, &ndjsonEncoder{}, ndjson)
+}
+
+func TestCSVEncoderShouldFlattenExpandedEntities(t *testing.T) {
+	//arrange
+	thing := &entities.Thing{Name: "thing", Properties: map[string]interface{}{"owner": "Bert"}}
+	thing.ID = 1
+	thing.NavDatastreams = "http://localhost/v1.0/Things(1)/Datastreams"
+	datastream := &entities.Datastream{Name: "temp, outside"}
+	datastream.ID = 2
+	thing.Datastreams = []*entities.Datastream{datastream}
+	var data interface{} = []*entities.Thing{thing}
+	response := &models.ArrayResponse{Data: &data}
+	var b bytes.Buffer
+
+	//act
+	err := (&csvEncoder{}).Encode(&b, response)
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "@iot.id,name,properties/owner,Datastreams/0/@iot.id,Datastreams/0/name\n1,thing,Bert,2,\"temp, outside\"\n", b.String())
+}
+
+func TestGeoJSONEncoderShouldCreateFeatureCollection(t *testing.T) {
+	//arrange
+	location := &entities.Location{Name: "home", Location: map[string]interface{}{"type": "Point", "coordinates": []float64{5, 52}}}
+	location.ID = 1
+	var data interface{} = []*entities.Location{location}
+	response := &models.ArrayResponse{Data: &data}
+	var b bytes.Buffer
+
+	//act
+	err := (&geoJSONEncoder{}).Encode(&b, response)
+
+	//assert
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type": "FeatureCollection", "features": [{"type": "Feature", "id": 1, "geometry": {"coordinates": [5, 52], "type": "Point"}, "properties": {"name": "home"}}]}`, b.String())
+}
+
+func TestNDJSONEncoderShouldWriteEntityPerLine(t *testing.T) {
+	//arrange
+	o1 := &entities.Observation{Result: 1}
+	o2 := &entities.Observation{Result: 2}
+	var data interface{} = []*entities.Observation{o1, o2}
+	response := &models.ArrayResponse{Data: &data}
+	var b bytes.Buffer
+
+	//act
+	err := (&ndjsonEncoder{}).Encode(&b, response)
+
+	//assert
+	assert.Nil(t, err)
+	assert.Equal(t, "{\"result\":1}\n{\"result\":2}\n", b.String())
+}
@@ -35,7 +35,7 @@ func sendJSONResponse(w http.ResponseWriter, status int, data interface{}, qo *o
 
 			mVal := []byte{}
 			for k, v := range m {
-				if strings.ToLower(k) == qo.QuerySelect.Params[0] {
+				if strings.ToLower(k) == strings.ToLower(qo.QuerySelect.Params[0]) {
 					mVal = v
 				}
 			}
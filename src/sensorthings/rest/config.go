@@ -12,12 +12,17 @@ func CreateEndPoints(externalURL string) []models.Endpoint {
 		createRootEndpoint(externalURL),
 		createThingsEndpoint(externalURL),
 		createDatastreamsEndpoint(externalURL),
+		createMultiDatastreamsEndpoint(externalURL),
 		createObservedPropertiesEndpoint(externalURL),
 		createLocationsEndpoint(externalURL),
 		createSensorsEndpoint(externalURL),
 		createObservationsEndpoint(externalURL),
+		createCreateObservationsEndpoint(externalURL),
 		createFeaturesOfInterestEndpoint(externalURL),
 		createHistoricalLocationsEndpoint(externalURL),
+		createActuatorsEndpoint(externalURL),
+		createTaskingCapabilitiesEndpoint(externalURL),
+		createTasksEndpoint(externalURL),
 	}
 
 	return endpoints
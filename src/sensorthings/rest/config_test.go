@@ -13,7 +13,7 @@ func TestCreateEndPoints(t *testing.T) {
 	endpoints := CreateEndPoints("http://test.com")
 
 	//assert
-	assert.Equal(t, 10, len(endpoints))
+	assert.Equal(t, 15, len(endpoints))
 }
 
 func TestCreateEndPointVersion(t *testing.T) {
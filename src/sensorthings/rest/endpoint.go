@@ -1,9 +1,11 @@
 package rest
 
 import (
+	"github.com/geodan/gost/src/sensorthings/entities"
 	"github.com/geodan/gost/src/sensorthings/models"
 	"github.com/geodan/gost/src/sensorthings/odata"
 	"net/http"
+	"sync"
 )
 
 // Endpoint contains all information for creating and handling a main SensorThings endpoint.
@@ -68,10 +70,15 @@ func (e *Endpoint) AreQueryOptionsSupported(queryOptions *odata.QueryOptions) (b
 	checkQueryOptionSupported(e, qo.QuerySkip, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QuerySkip.GetQueryOptionType().String(), e.Name))
 	checkQueryOptionSupported(e, qo.QuerySelect, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QuerySelect.GetQueryOptionType().String(), e.Name))
 	checkQueryOptionSupported(e, qo.QueryExpand, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QueryExpand.GetQueryOptionType().String(), e.Name))
-	checkQueryOptionSupported(e, qo.QueryOrderBy, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QueryOrderBy.GetQueryOptionType().String(), e.Name))
+	// the $orderby of an aggregation is checked against the aggregated properties when parsing $apply
+	if qo.QueryApply.IsNil() {
+		checkQueryOptionSupported(e, qo.QueryOrderBy, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QueryOrderBy.GetQueryOptionType().String(), e.Name))
+	}
 	checkQueryOptionSupported(e, qo.QueryCount, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QueryCount.GetQueryOptionType().String(), e.Name))
 	checkQueryOptionSupported(e, qo.QueryFilter, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QueryFilter.GetQueryOptionType().String(), e.Name))
 	checkQueryOptionSupported(e, qo.QueryResultFormat, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QueryResultFormat.GetQueryOptionType().String(), e.Name))
+	checkQueryOptionSupported(e, qo.QuerySkipToken, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QuerySkipToken.GetQueryOptionType().String(), e.Name))
+	checkQueryOptionSupported(e, qo.QueryApply, &errorList, odata.CreateQueryError(odata.QueryNotAvailable, http.StatusNotImplemented, qo.QueryApply.GetQueryOptionType().String(), e.Name))
 
 	if errorList != nil {
 		return false, errorList
@@ -97,13 +104,17 @@ func checkQueryOptionSupported(e *Endpoint, q odata.QueryOption, errorList *[]er
 	case *odata.QueryExpand:
 		if _, err = v.IsValid(e.SupportedExpandParams, e.Name); err != nil {
 			*errorList = append(errors, err)
+		} else {
+			checkExpandQueryOptions(v, errorList)
 		}
 	case *odata.QuerySelect:
 		if _, err = v.IsValid(e.SupportedSelectParams); err != nil {
 			*errorList = append(errors, err)
 		}
 	case *odata.QueryOrderBy:
-		//ToDo
+		if _, err = v.IsValid(e.SupportedSelectParams); err != nil {
+			*errorList = append(errors, err)
+		}
 	case *odata.QueryTop:
 		if _, err = v.IsValid(); err != nil {
 			*errorList = append(errors, err)
@@ -124,11 +135,66 @@ func checkQueryOptionSupported(e *Endpoint, q odata.QueryOption, errorList *[]er
 		if _, err = v.IsValid(); err != nil {
 			*errorList = append(errors, err)
 		}
+	case *odata.QuerySkipToken:
+		if _, err = v.IsValid(); err != nil {
+			*errorList = append(errors, err)
+		}
+	case *odata.QueryApply:
+		if _, err = v.IsValid(); err != nil {
+			*errorList = append(errors, err)
+		}
 	default:
 		//set error, unknown
 	}
 }
 
+// checkExpandQueryOptions checks the query options of each expanded entity against the endpoint
+// of the expanded entity, nested expands are checked by the endpoint of the expanded entity
+func checkExpandQueryOptions(q *odata.QueryExpand, errorList *[]error) {
+	for _, o := range q.Operations {
+		if o.QueryOptions == nil {
+			continue
+		}
+
+		ep := getEntityEndpoint(o.Entity.GetEntityType())
+		if ep == nil {
+			continue
+		}
+
+		if _, errs := ep.AreQueryOptionsSupported(o.QueryOptions); errs != nil {
+			*errorList = append(*errorList, errs...)
+		}
+	}
+}
+
+var (
+	entityEndpointsOnce sync.Once
+	entityEndpoints     map[entities.EntityType]models.Endpoint
+)
+
+// getEntityEndpoint returns the endpoint of the given entity type, nil if there is no endpoint for the entity
+func getEntityEndpoint(et entities.EntityType) *Endpoint {
+	entityEndpointsOnce.Do(func() {
+		entityEndpoints = EntityEndpoints(CreateEndPoints(""))
+	})
+
+	e, _ := entityEndpoints[et].(*Endpoint)
+	return e
+}
+
+// EntityEndpoints maps the entity types to the endpoint of their entity set, endpoints which do not serve an
+// entity set such as the root and CreateObservations are left out
+func EntityEndpoints(endpoints []models.Endpoint) map[entities.EntityType]models.Endpoint {
+	entityEndpoints := map[entities.EntityType]models.Endpoint{}
+	for _, e := range endpoints {
+		if et, err := entities.EntityTypeFromString(e.GetName()); err == nil {
+			entityEndpoints[et] = e
+		}
+	}
+
+	return entityEndpoints
+}
+
 // SupportsQueryOptionType checks if a given QueryOptionType is configured for the endpoint
 func (e *Endpoint) SupportsQueryOptionType(queryOptionType odata.QueryOptionType) bool {
 	for _, qo := range e.SupportedQueryOptions {
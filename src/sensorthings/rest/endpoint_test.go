@@ -3,6 +3,7 @@ package rest
 import (
 	"testing"
 
+	"github.com/geodan/gost/src/sensorthings/entities"
 	"github.com/geodan/gost/src/sensorthings/odata"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,3 +31,35 @@ func TestEndPointGetQueryOptions(t *testing.T) {
 	// assert
 	assert.True(t, b, "QueryOptionsSupport should be true")
 }
+
+func TestEndPointShouldValidateQueryOptionsInsideExpand(t *testing.T) {
+	// arrange
+	endpoint := createThingsEndpoint("http://localhost")
+	qoValid, _ := odata.CreateQueryOptions(map[string]string{"$expand": "Datastreams/Observations($top=5;$orderby=phenomenonTime desc;$select=result)"})
+	qoInvalidSelect, _ := odata.CreateQueryOptions(map[string]string{"$expand": "Datastreams/Observations($select=name)"})
+	qoInvalidExpand, _ := odata.CreateQueryOptions(map[string]string{"$expand": "Datastreams/Locations"})
+
+	// act
+	valid, _ := endpoint.AreQueryOptionsSupported(qoValid)
+	invalidSelect, _ := endpoint.AreQueryOptionsSupported(qoInvalidSelect)
+	invalidExpand, errs := endpoint.AreQueryOptionsSupported(qoInvalidExpand)
+
+	// assert
+	assert.True(t, valid)
+	assert.False(t, invalidSelect)
+	assert.False(t, invalidExpand)
+	assert.Contains(t, errs[0].Error(), "Datastreams")
+}
+
+func TestEntityEndpointsShouldMapEntitySets(t *testing.T) {
+	// arrange
+	endpoints := CreateEndPoints("http://localhost")
+
+	// act
+	entityEndpoints := EntityEndpoints(endpoints)
+
+	// assert
+	assert.Len(t, entityEndpoints, len(endpoints)-3, "Version, root and CreateObservations should be left out")
+	assert.Equal(t, "Things", entityEndpoints[entities.EntityTypeThing].GetName())
+	assert.Equal(t, "Observations", getEntityEndpoint(entities.EntityTypeObservation).GetName())
+}
@@ -0,0 +1,380 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	gostErrors "github.com/geodan/gost/src/errors"
+)
+
+const (
+	// mergePatchContentType is the content type of a JSON Merge Patch document (RFC 7396)
+	mergePatchContentType = "application/merge-patch+json"
+	// jsonPatchContentType is the content type of a JSON Patch document (RFC 6902)
+	jsonPatchContentType = "application/json-patch+json"
+)
+
+// jsonPatchOperation is a single operation of a JSON Patch document, Value is nil when the operation has no
+// value member and holds null when the value is null
+type jsonPatchOperation struct {
+	Op    string
+	Path  *string
+	From  *string
+	Value json.RawMessage
+}
+
+// UnmarshalJSON decodes the members of an operation, the presence of the value member is checked on the
+// members since a null value is a valid value of an operation
+func (o *jsonPatchOperation) UnmarshalJSON(data []byte) error {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{"op": &o.Op, "path": &o.Path, "from": &o.From}
+	for name, field := range fields {
+		if member, ok := members[name]; ok {
+			if err := json.Unmarshal(member, field); err != nil {
+				return err
+			}
+		}
+	}
+
+	o.Value = members["value"]
+	return nil
+}
+
+// applyPatchDocument applies the JSON Merge Patch or JSON Patch document to the JSON document of an entity
+// and returns the patched document, the patched document has to be a JSON object
+func applyPatchDocument(contentType string, document []byte, patch []byte) ([]byte, error) {
+	var doc interface{}
+	if err := json.Unmarshal(document, &doc); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if strings.Contains(contentType, jsonPatchContentType) {
+		var operations []jsonPatchOperation
+		if err = json.Unmarshal(patch, &operations); err != nil {
+			return nil, gostErrors.NewBadRequestError(errors.New("Unable to parse the JSON Patch document, expected an array of operations"))
+		}
+
+		if doc, err = applyJSONPatch(doc, operations); err != nil {
+			return nil, gostErrors.NewBadRequestError(err)
+		}
+	} else {
+		var p interface{}
+		if err = json.Unmarshal(patch, &p); err != nil {
+			return nil, gostErrors.NewBadRequestError(errors.New("Unable to parse the JSON Merge Patch document"))
+		}
+
+		doc = mergePatch(doc, p)
+	}
+
+	if _, ok := doc.(map[string]interface{}); !ok {
+		return nil, gostErrors.NewBadRequestError(errors.New("The patched document is not a JSON object"))
+	}
+
+	return json.Marshal(doc)
+}
+
+// entityDocument returns the JSON document of the entity on which a patch is applied, the id and the links which
+// are set by the server are removed from the document
+func entityDocument(entity interface{}) ([]byte, error) {
+	b, err := json.Marshal(entity)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := map[string]interface{}{}
+	if err = json.Unmarshal(b, &doc); err != nil {
+		return nil, err
+	}
+
+	for k := range doc {
+		if k == "@iot.id" || k == "@iot.selfLink" || strings.HasSuffix(k, "@iot.navigationLink") {
+			delete(doc, k)
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+// mergePatch applies a JSON Merge Patch to the target as described in RFC 7396, members of the patch with a
+// null value are removed from the target and objects are merged recursively
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = map[string]interface{}{}
+	}
+
+	for k, v := range p {
+		if v == nil {
+			delete(t, k)
+		} else {
+			t[k] = mergePatch(t[k], v)
+		}
+	}
+
+	return t
+}
+
+// applyJSONPatch applies the operations of a JSON Patch document to the document as described in RFC 6902, the
+// operations are applied in order and no changes are returned when one of the operations fails
+func applyJSONPatch(doc interface{}, operations []jsonPatchOperation) (interface{}, error) {
+	var err error
+	for i, o := range operations {
+		if o.Path == nil {
+			return nil, fmt.Errorf("Missing path in operation %v", i)
+		}
+
+		var value interface{}
+		if o.Op == "add" || o.Op == "replace" || o.Op == "test" {
+			if o.Value == nil {
+				return nil, fmt.Errorf("Missing value in operation %v", i)
+			}
+
+			if err = json.Unmarshal(o.Value, &value); err != nil {
+				return nil, err
+			}
+		}
+
+		if (o.Op == "move" || o.Op == "copy") && o.From == nil {
+			return nil, fmt.Errorf("Missing from in operation %v", i)
+		}
+
+		switch o.Op {
+		case "add":
+			doc, err = addValue(doc, *o.Path, value)
+		case "remove":
+			doc, _, err = removeValue(doc, *o.Path)
+		case "replace":
+			if len(*o.Path) == 0 {
+				doc = value
+			} else if doc, _, err = removeValue(doc, *o.Path); err == nil {
+				doc, err = addValue(doc, *o.Path, value)
+			}
+		case "move":
+			if strings.HasPrefix(*o.Path, *o.From+"/") {
+				return nil, fmt.Errorf("Unable to move %s to one of its children", *o.From)
+			}
+
+			var moved interface{}
+			if doc, moved, err = removeValue(doc, *o.From); err == nil {
+				doc, err = addValue(doc, *o.Path, moved)
+			}
+		case "copy":
+			var copied interface{}
+			if copied, err = getValue(doc, *o.From); err == nil {
+				doc, err = addValue(doc, *o.Path, deepCopy(copied))
+			}
+		case "test":
+			var current interface{}
+			if current, err = getValue(doc, *o.Path); err == nil && !reflect.DeepEqual(current, value) {
+				err = fmt.Errorf("Test of %s failed", *o.Path)
+			}
+		default:
+			err = fmt.Errorf("Unknown operation %s", o.Op)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return doc, nil
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into its unescaped reference tokens
+func parsePointer(pointer string) ([]string, error) {
+	if len(pointer) == 0 {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("Invalid JSON Pointer %s", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.Replace(strings.Replace(t, "~1", "/", -1), "~0", "~", -1)
+	}
+
+	return tokens, nil
+}
+
+// arrayIndex parses the token as the index of an array with the given length, max is the highest accepted index
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || i > max || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("Invalid array index %s", token)
+	}
+
+	return i, nil
+}
+
+// getValue returns the value referenced by the pointer
+func getValue(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	current := doc
+	for _, t := range tokens {
+		switch c := current.(type) {
+		case map[string]interface{}:
+			v, ok := c[t]
+			if !ok {
+				return nil, fmt.Errorf("Path %s does not exist", pointer)
+			}
+
+			current = v
+		case []interface{}:
+			i, err := arrayIndex(t, len(c)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			current = c[i]
+		default:
+			return nil, fmt.Errorf("Path %s does not exist", pointer)
+		}
+	}
+
+	return current, nil
+}
+
+// addValue adds the value at the location referenced by the pointer, an existing member of an object is replaced
+// and a value added to an array is inserted at the given index or appended when the index is -
+func addValue(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return value, nil
+	}
+
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := getValue(doc, parentPointer)
+	if err != nil {
+		return nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+		return doc, nil
+	case []interface{}:
+		i := len(p)
+		if last != "-" {
+			if i, err = arrayIndex(last, len(p)); err != nil {
+				return nil, err
+			}
+		}
+
+		a := append(p[:i:i], value)
+		return replaceValue(doc, parentPointer, append(a, p[i:]...))
+	}
+
+	return nil, fmt.Errorf("Path %s does not exist", pointer)
+}
+
+// removeValue removes the value referenced by the pointer and returns the changed document and the removed value
+func removeValue(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	tokens, err := parsePointer(pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, nil, errors.New("Unable to remove the whole document")
+	}
+
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := getValue(doc, parentPointer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		v, ok := p[last]
+		if !ok {
+			return nil, nil, fmt.Errorf("Path %s does not exist", pointer)
+		}
+
+		delete(p, last)
+		return doc, v, nil
+	case []interface{}:
+		i, err := arrayIndex(last, len(p)-1)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		v := p[i]
+		doc, err = replaceValue(doc, parentPointer, append(p[:i:i], p[i+1:]...))
+		return doc, v, err
+	}
+
+	return nil, nil, fmt.Errorf("Path %s does not exist", pointer)
+}
+
+// replaceValue sets the value referenced by an existing pointer, it is used to store arrays which changed in size
+func replaceValue(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	if len(pointer) == 0 {
+		return value, nil
+	}
+
+	tokens, _ := parsePointer(pointer)
+	parent, err := getValue(doc, pointer[:strings.LastIndex(pointer, "/")])
+	if err != nil {
+		return nil, err
+	}
+
+	last := tokens[len(tokens)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+	case []interface{}:
+		i, _ := arrayIndex(last, len(p)-1)
+		p[i] = value
+	}
+
+	return doc, nil
+}
+
+// deepCopy copies a decoded JSON value so a copied value is not changed by later operations on the original
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			c[k] = deepCopy(item)
+		}
+
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = deepCopy(item)
+		}
+
+		return c
+	}
+
+	return value
+}
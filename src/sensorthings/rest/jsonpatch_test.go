@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/geodan/gost/src/sensorthings/entities"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyMergePatch(t *testing.T) {
+	// arrange
+	document := []byte(`{"name":"thing","description":"a thing","properties":{"a":1,"b":{"c":2}}}`)
+	patch := []byte(`{"description":null,"properties":{"a":null,"b":{"d":0}}}`)
+
+	// act
+	patched, err := applyPatchDocument(mergePatchContentType, document, patch)
+
+	// assert
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"thing","properties":{"b":{"c":2,"d":0}}}`, string(patched))
+}
+
+func TestApplyJSONPatch(t *testing.T) {
+	// arrange
+	document := []byte(`{"name":"thing","result":5,"properties":{"a":1,"list":[1,2]}}`)
+	patch := []byte(`[
+		{"op":"test","path":"/name","value":"thing"},
+		{"op":"replace","path":"/result","value":0},
+		{"op":"remove","path":"/properties/a"},
+		{"op":"add","path":"/properties/list/1","value":3},
+		{"op":"add","path":"/properties/list/-","value":4},
+		{"op":"copy","from":"/name","path":"/properties/a~1b"},
+		{"op":"move","from":"/properties/list/0","path":"/properties/first"}
+	]`)
+
+	// act
+	patched, err := applyPatchDocument(jsonPatchContentType, document, patch)
+
+	// assert
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"thing","result":0,"properties":{"a/b":"thing","first":1,"list":[3,2,4]}}`, string(patched))
+}
+
+func TestApplyJSONPatchWithNullValue(t *testing.T) {
+	// arrange
+	document := []byte(`{"name":"thing","result":5}`)
+	patch := []byte(`[
+		{"op":"replace","path":"/result","value":null},
+		{"op":"add","path":"/resultTime","value":null},
+		{"op":"test","path":"/result","value":null}
+	]`)
+
+	// act
+	patched, err := applyPatchDocument(jsonPatchContentType, document, patch)
+	_, errMissing := applyPatchDocument(jsonPatchContentType, document, []byte(`[{"op":"add","path":"/result"}]`))
+
+	// assert
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"thing","result":null,"resultTime":null}`, string(patched))
+	assert.NotNil(t, errMissing, "operation without value member should fail")
+}
+
+func TestApplyJSONPatchShouldFailOnInvalidOperations(t *testing.T) {
+	// arrange
+	document := []byte(`{"name":"thing"}`)
+
+	// act
+	_, errTest := applyPatchDocument(jsonPatchContentType, document, []byte(`[{"op":"test","path":"/name","value":"other"}]`))
+	_, errRemove := applyPatchDocument(jsonPatchContentType, document, []byte(`[{"op":"remove","path":"/description"}]`))
+	_, errOp := applyPatchDocument(jsonPatchContentType, document, []byte(`[{"op":"merge","path":"/name"}]`))
+	_, errRoot := applyPatchDocument(jsonPatchContentType, document, []byte(`[{"op":"replace","path":"","value":[]}]`))
+
+	// assert
+	assert.NotNil(t, errTest)
+	assert.NotNil(t, errRemove)
+	assert.NotNil(t, errOp)
+	assert.NotNil(t, errRoot, "patched document should be an object")
+}
+
+func TestEntityDocumentShouldRemoveIDAndLinks(t *testing.T) {
+	// arrange
+	thing := &entities.Thing{Name: "thing"}
+	thing.ID = 1
+	thing.SetAllLinks("http://localhost")
+
+	// act
+	document, err := entityDocument(thing)
+
+	// assert
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"name":"thing"}`, string(document))
+}